@@ -0,0 +1,265 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+
+	tgbotapi "github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+const (
+	// backfillCallbackPrefix marks the callback data of the "Apply" button.
+	backfillCallbackPrefix = "backfill:"
+	// backfillConfirmTTL is how long a dry run's "Apply" button stays valid.
+	backfillConfirmTTL = 15 * time.Minute
+	// backfillProgressEvery throttles progress edits, well under Telegram's edit limits.
+	backfillProgressEvery = 5 * time.Second
+	// backfillReportLines caps the per-order lines in the report, which must fit one message.
+	backfillReportLines = 40
+)
+
+// pendingBackfill is a dry run waiting for an admin to apply it.
+type pendingBackfill struct {
+	day     time.Time
+	expires time.Time
+}
+
+// backfill handles /backfill YYYY-MM-DD: a dry run whose report offers an "Apply" button.
+func (t *TgBot) backfill(b *tgbotapi.Bot, ctx *ext.Context) error {
+	userId := ctx.EffectiveUser.Id
+	if !t.isAdmin(userId) {
+		_, err := ctx.EffectiveMessage.Reply(b, "You are not authorized to use this command.", nil)
+		return err
+	}
+	if t.core == nil {
+		t.plainResponse(userId, Sanitize("Sync core is not available."))
+		return nil
+	}
+
+	args := strings.Fields(ctx.EffectiveMessage.Text)
+	day, err := parseBackfillDay(args[1:])
+	if err != nil {
+		t.plainResponse(userId, Sanitize(err.Error()))
+		return nil
+	}
+
+	go t.runBackfill(userId, day, false)
+	return nil
+}
+
+// backfillCallback handles the "Apply" button of a dry run report.
+func (t *TgBot) backfillCallback(b *tgbotapi.Bot, ctx *ext.Context) error {
+	cq := ctx.CallbackQuery
+	userId := cq.From.Id
+	if !t.isAdmin(userId) {
+		_, err := cq.Answer(b, &tgbotapi.AnswerCallbackQueryOpts{Text: "You are not authorized to use this command."})
+		return err
+	}
+
+	token := strings.TrimPrefix(cq.Data, backfillCallbackPrefix)
+	pending, ok := t.takeBackfill(token, time.Now())
+	if !ok {
+		_, err := cq.Answer(b, &tgbotapi.AnswerCallbackQueryOpts{Text: "Confirmation expired, run /backfill again."})
+		return err
+	}
+
+	if _, err := cq.Answer(b, &tgbotapi.AnswerCallbackQueryOpts{Text: "Applying..."}); err != nil {
+		t.log.Warn("answer callback", sl.Err(err))
+	}
+	// Drop the button so the same report cannot be applied twice.
+	if cq.Message != nil {
+		_, _, err := b.EditMessageReplyMarkup(&tgbotapi.EditMessageReplyMarkupOpts{
+			ChatId:    cq.Message.GetChat().Id,
+			MessageId: cq.Message.GetMessageId(),
+		})
+		if err != nil {
+			t.log.Warn("remove backfill button", sl.Err(err))
+		}
+	}
+
+	t.log.With(
+		slog.Int64("user_id", userId),
+		slog.String("day", pending.day.Format(time.DateOnly)),
+	).Info("backfill apply confirmed")
+
+	go t.runBackfill(userId, pending.day, true)
+	return nil
+}
+
+// runBackfill runs one day's backfill for the admin, editing a progress message as it goes and
+// posting the report at the end. Only one backfill runs at a time.
+func (t *TgBot) runBackfill(chatId int64, day time.Time, apply bool) {
+	t.backfillMu.Lock()
+	if t.backfillRunning {
+		t.backfillMu.Unlock()
+		t.sendText(chatId, "A backfill is already running, wait for its report.", nil)
+		return
+	}
+	t.backfillRunning = true
+	t.backfillMu.Unlock()
+	defer func() {
+		t.backfillMu.Lock()
+		t.backfillRunning = false
+		t.backfillMu.Unlock()
+	}()
+
+	title := backfillTitle(day, apply)
+	progress := t.sendText(chatId, title+": loading orders...", nil)
+
+	var last time.Time
+	res, err := t.core.RunBackfill(entity.BackfillOptions{
+		From:  day,
+		To:    day.AddDate(0, 0, 1),
+		Apply: apply,
+		Progress: func(done, total int) {
+			if progress == nil || (done < total && time.Since(last) < backfillProgressEvery) {
+				return
+			}
+			last = time.Now()
+			t.editText(chatId, progress.MessageId, fmt.Sprintf("%s: %d/%d orders checked", title, done, total))
+		},
+	})
+	if err != nil {
+		t.sendText(chatId, fmt.Sprintf("%s failed: %v", title, err), nil)
+		return
+	}
+
+	report := formatBackfillReport(day, apply, res)
+	if apply || res.Corrected == 0 {
+		t.sendText(chatId, report, nil)
+		return
+	}
+
+	token, err := t.storeBackfill(day, time.Now())
+	if err != nil {
+		t.log.Error("store backfill confirmation", sl.Err(err))
+		t.sendText(chatId, report, nil)
+		return
+	}
+	report += fmt.Sprintf("\n\nApply within %s to write these corrections to Zoho.", backfillConfirmTTL)
+	t.sendText(chatId, report, &tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{{
+			{Text: "Apply", CallbackData: backfillCallbackPrefix + token},
+		}},
+	})
+}
+
+// storeBackfill records a dry run awaiting confirmation and returns its token. Expired entries
+// are dropped on the way.
+func (t *TgBot) storeBackfill(day time.Time, now time.Time) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	t.backfillMu.Lock()
+	defer t.backfillMu.Unlock()
+	if t.backfills == nil {
+		t.backfills = make(map[string]pendingBackfill)
+	}
+	for key, pending := range t.backfills {
+		if now.After(pending.expires) {
+			delete(t.backfills, key)
+		}
+	}
+	t.backfills[token] = pendingBackfill{day: day, expires: now.Add(backfillConfirmTTL)}
+	return token, nil
+}
+
+// takeBackfill consumes a confirmation token; a token is valid once and until it expires.
+func (t *TgBot) takeBackfill(token string, now time.Time) (pendingBackfill, bool) {
+	t.backfillMu.Lock()
+	defer t.backfillMu.Unlock()
+	pending, ok := t.backfills[token]
+	if !ok {
+		return pendingBackfill{}, false
+	}
+	delete(t.backfills, token)
+	if now.After(pending.expires) {
+		return pendingBackfill{}, false
+	}
+	return pending, true
+}
+
+// sendText sends a message without markup parsing, so report text needs no escaping.
+func (t *TgBot) sendText(chatId int64, text string, markup *tgbotapi.InlineKeyboardMarkup) *tgbotapi.Message {
+	opts := &tgbotapi.SendMessageOpts{}
+	if markup != nil {
+		opts.ReplyMarkup = *markup
+	}
+	msg, err := t.api.SendMessage(chatId, text, opts)
+	if err != nil {
+		t.log.With(slog.Int64("id", chatId)).Error("sending message", sl.Err(err))
+		return nil
+	}
+	return msg
+}
+
+func (t *TgBot) editText(chatId, messageId int64, text string) {
+	_, _, err := t.api.EditMessageText(text, &tgbotapi.EditMessageTextOpts{
+		ChatId:    chatId,
+		MessageId: messageId,
+	})
+	if err != nil {
+		t.log.With(slog.Int64("id", chatId)).Warn("editing message", sl.Err(err))
+	}
+}
+
+// parseBackfillDay reads the YYYY-MM-DD day argument, in the server's local time like -backfill.
+func parseBackfillDay(args []string) (time.Time, error) {
+	const usage = "usage: /backfill YYYY-MM-DD"
+	if len(args) == 0 {
+		return time.Time{}, fmt.Errorf(usage)
+	}
+	day, err := time.ParseInLocation(time.DateOnly, args[0], time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %q, %s", args[0], usage)
+	}
+	return day, nil
+}
+
+func backfillTitle(day time.Time, apply bool) string {
+	if apply {
+		return fmt.Sprintf("Backfill %s", day.Format(time.DateOnly))
+	}
+	return fmt.Sprintf("Backfill %s (dry run)", day.Format(time.DateOnly))
+}
+
+// formatBackfillReport renders the closing report: the counts, then one line per order that was
+// (or would be) corrected, skipped or failed. Unchanged orders are only counted.
+func formatBackfillReport(day time.Time, apply bool, res entity.BackfillResult) string {
+	var sb strings.Builder
+	corrected := "corrected"
+	if !apply {
+		corrected = "to correct"
+	}
+	fmt.Fprintf(&sb, "%s\nscanned %d, %s %d, unchanged %d, skipped %d, failed %d",
+		backfillTitle(day, apply), res.Scanned, corrected, res.Corrected, res.Unchanged, res.Skipped, res.Failed)
+
+	lines := 0
+	for _, o := range res.Orders {
+		if o.Outcome == entity.BackfillUnchanged {
+			continue
+		}
+		if lines == backfillReportLines {
+			fmt.Fprintf(&sb, "\n...and more, see the service log")
+			break
+		}
+		lines++
+		switch o.Outcome {
+		case entity.BackfillCorrected, entity.BackfillWouldCorrect:
+			fmt.Fprintf(&sb, "\n#%d %s: %d row(s), discount %.2f%% -> %.2f%%", o.OrderId, o.Outcome, o.Rows, o.DiscountWas, o.DiscountNow)
+		default:
+			fmt.Fprintf(&sb, "\n#%d %s: %s", o.OrderId, o.Outcome, o.Reason)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+func TestParseBackfillDay(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid day", args: []string{"2026-07-31"}, want: "2026-07-31"},
+		{name: "missing day", args: nil, wantErr: true},
+		{name: "wrong format", args: []string{"31.07.2026"}, wantErr: true},
+		{name: "not a date", args: []string{"2026-02-30"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBackfillDay(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseBackfillDay(%q) = %v, want error", tt.args, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseBackfillDay(%q) error = %v", tt.args, err)
+			}
+			if got.Format(time.DateOnly) != tt.want || got.Location() != time.Local {
+				t.Errorf("parseBackfillDay(%q) = %v, want %s local", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackfillConfirmation(t *testing.T) {
+	tg := &TgBot{}
+	day := time.Date(2026, 7, 31, 0, 0, 0, 0, time.Local)
+	now := time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	token, err := tg.storeBackfill(day, now)
+	if err != nil {
+		t.Fatalf("storeBackfill() error = %v", err)
+	}
+
+	if _, ok := tg.takeBackfill("other", now); ok {
+		t.Error("unknown token accepted")
+	}
+
+	pending, ok := tg.takeBackfill(token, now.Add(time.Minute))
+	if !ok || !pending.day.Equal(day) {
+		t.Fatalf("takeBackfill() = %v, %v, want the stored day", pending, ok)
+	}
+	if _, ok = tg.takeBackfill(token, now.Add(time.Minute)); ok {
+		t.Error("token accepted twice")
+	}
+
+	expired, _ := tg.storeBackfill(day, now)
+	if _, ok = tg.takeBackfill(expired, now.Add(backfillConfirmTTL+time.Second)); ok {
+		t.Error("expired token accepted")
+	}
+}
+
+func TestStoreBackfill_DropsExpired(t *testing.T) {
+	tg := &TgBot{}
+	day := time.Date(2026, 7, 31, 0, 0, 0, 0, time.Local)
+	now := time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	old, _ := tg.storeBackfill(day, now)
+	_, _ = tg.storeBackfill(day, now.Add(backfillConfirmTTL+time.Second))
+
+	if _, exists := tg.backfills[old]; exists {
+		t.Error("expired confirmation kept")
+	}
+	if len(tg.backfills) != 1 {
+		t.Errorf("confirmations = %d, want 1", len(tg.backfills))
+	}
+}
+
+func TestFormatBackfillReport(t *testing.T) {
+	day := time.Date(2026, 7, 31, 0, 0, 0, 0, time.Local)
+	res := entity.BackfillResult{
+		Scanned: 3, Corrected: 1, Unchanged: 1, Skipped: 1,
+		Orders: []entity.BackfillOrder{
+			{OrderId: 17103, Outcome: entity.BackfillWouldCorrect, Rows: 4, DiscountWas: 10.79, DiscountNow: 10},
+			{OrderId: 17104, Outcome: entity.BackfillUnchanged},
+			{OrderId: 17105, Outcome: entity.BackfillSkipped, Reason: "subform has 3 rows, OpenCart has 4: edited in Zoho"},
+		},
+	}
+
+	got := formatBackfillReport(day, false, res)
+	for _, want := range []string{
+		"Backfill 2026-07-31 (dry run)",
+		"scanned 3, to correct 1, unchanged 1, skipped 1, failed 0",
+		"#17103 would_correct: 4 row(s), discount 10.79% -> 10.00%",
+		"#17105 skipped: subform has 3 rows",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatBackfillReport() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "#17104") {
+		t.Errorf("formatBackfillReport() lists an unchanged order: %q", got)
+	}
+
+	if got = formatBackfillReport(day, true, res); !strings.Contains(got, "corrected 1") || strings.Contains(got, "dry run") {
+		t.Errorf("applied report = %q", got)
+	}
+}
+
+func TestFormatBackfillReport_CapsLines(t *testing.T) {
+	var res entity.BackfillResult
+	for i := 0; i < backfillReportLines+5; i++ {
+		res.Orders = append(res.Orders, entity.BackfillOrder{OrderId: int64(i), Outcome: entity.BackfillFailed, Reason: "x"})
+	}
+
+	got := formatBackfillReport(time.Now(), true, res)
+	if lines := strings.Count(got, " failed: "); lines != backfillReportLines {
+		t.Errorf("order lines = %d, want %d", lines, backfillReportLines)
+	}
+	if !strings.Contains(got, "...and more") {
+		t.Error("truncated report does not say so")
+	}
+}
@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"zohoclient/entity"
+
+	tgbotapi "github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// Core is the part of the sync the admin commands operate.
+type Core interface {
+	PushOrderToZoho(orderId int64) (string, error)
+	OrderSyncState(orderId int64) (*entity.OrderSyncState, error)
+	SyncFailures() ([]entity.SyncFailure, error)
+	RetryOrder(orderId int64) (string, error)
+	RetryCustomer(customerId int64) error
+	PausePollers()
+	ResumePollers()
+	SyncStats() (*entity.SyncStats, error)
+	RunBackfill(opts entity.BackfillOptions) (entity.BackfillResult, error)
+	TakeSyncReport() entity.SyncReport
+	Reconcile(opts entity.ReconcileOptions) (entity.ReconcileResult, error)
+	RequestSubscription(userId int, user string, filter entity.SubscriptionFilter, admin bool) (*entity.Subscription, error)
+	ConfirmSubscription(userId int) (*entity.Subscription, error)
+	CancelSubscription(userId int) error
+	Subscriptions() ([]entity.Subscription, error)
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// SetCore connects the admin commands to the sync core.
+func (t *TgBot) SetCore(core Core) {
+	t.core = core
+}
+
+// adminCommand wraps a command that needs the core: it rejects non-admins, splits the
+// arguments, and sends the command's reply to the caller.
+func (t *TgBot) adminCommand(command func(args []string) string) func(b *tgbotapi.Bot, ctx *ext.Context) error {
+	return func(b *tgbotapi.Bot, ctx *ext.Context) error {
+		userId := ctx.EffectiveUser.Id
+		if !t.isAdmin(userId) {
+			_, err := ctx.EffectiveMessage.Reply(b, "You are not authorized to use this command.", nil)
+			return err
+		}
+		if t.core == nil {
+			t.plainResponse(userId, Sanitize("Sync core is not available."))
+			return nil
+		}
+
+		args := strings.Fields(ctx.EffectiveMessage.Text)
+		t.plainResponse(userId, Sanitize(command(args[1:])))
+		return nil
+	}
+}
+
+// push handles /push <order_id>.
+func (t *TgBot) push(args []string) string {
+	orderId, err := parseId(args, "/push <order_id>")
+	if err != nil {
+		return err.Error()
+	}
+	zohoId, err := t.core.PushOrderToZoho(orderId)
+	if err != nil {
+		return fmt.Sprintf("Order %d push failed: %v", orderId, err)
+	}
+	return fmt.Sprintf("Order %d pushed, zoho_id: %s", orderId, zohoId)
+}
+
+// order handles /order <order_id>.
+func (t *TgBot) order(args []string) string {
+	orderId, err := parseId(args, "/order <order_id>")
+	if err != nil {
+		return err.Error()
+	}
+	state, err := t.core.OrderSyncState(orderId)
+	if err != nil {
+		return fmt.Sprintf("Order %d: %v", orderId, err)
+	}
+	return formatOrderState(state)
+}
+
+// failed handles /failed.
+func (t *TgBot) failed(_ []string) string {
+	failures, err := t.core.SyncFailures()
+	if err != nil {
+		return fmt.Sprintf("Failed to list sync errors: %v", err)
+	}
+	return formatFailures(failures)
+}
+
+// retry handles /retry <order_id> and /retry customer <customer_id>.
+func (t *TgBot) retry(args []string) string {
+	const usage = "/retry <order_id> or /retry customer <customer_id>"
+	if len(args) > 0 && strings.EqualFold(args[0], "customer") {
+		customerId, err := parseId(args[1:], usage)
+		if err != nil {
+			return err.Error()
+		}
+		if err = t.core.RetryCustomer(customerId); err != nil {
+			return fmt.Sprintf("Customer %d retry failed: %v", customerId, err)
+		}
+		return fmt.Sprintf("Customer %d will be synced on the next customer run", customerId)
+	}
+
+	orderId, err := parseId(args, usage)
+	if err != nil {
+		return err.Error()
+	}
+	zohoId, err := t.core.RetryOrder(orderId)
+	if err != nil {
+		return fmt.Sprintf("Order %d retry failed: %v", orderId, err)
+	}
+	return fmt.Sprintf("Order %d pushed, zoho_id: %s", orderId, zohoId)
+}
+
+// pause handles /pause.
+func (t *TgBot) pause(_ []string) string {
+	t.core.PausePollers()
+	return "Pollers paused. Webhooks and /push still work; /resume to continue."
+}
+
+// resume handles /resume.
+func (t *TgBot) resume(_ []string) string {
+	t.core.ResumePollers()
+	return "Pollers resumed."
+}
+
+// stats handles /stats.
+func (t *TgBot) stats(_ []string) string {
+	stats, err := t.core.SyncStats()
+	if err != nil {
+		return fmt.Sprintf("Failed to read stats: %v", err)
+	}
+	return formatStats(stats, time.Now())
+}
+
+// parseId reads a positive numeric id from the first argument.
+func parseId(args []string, usage string) (int64, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("usage: %s", usage)
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id: %q, usage: %s", args[0], usage)
+	}
+	return id, nil
+}
+
+func formatOrderState(s *entity.OrderSyncState) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Order %d\n", s.OrderId)
+	fmt.Fprintf(&sb, "status: %d\n", s.StatusId)
+	fmt.Fprintf(&sb, "zoho_id: %s\n", orNone(s.ZohoId))
+	fmt.Fprintf(&sb, "payment id: %s\n", orNone(s.ZohoPaymentId))
+	fmt.Fprintf(&sb, "payment status: %s (synced: %s)\n", orNone(s.PaymentStatus), orNone(s.ZohoPaymentStatus))
+	fmt.Fprintf(&sb, "zoho modified: %s\n", formatTime(s.ZohoModifiedTime))
+	fmt.Fprintf(&sb, "modified: %s", formatTime(s.DateModified))
+	return sb.String()
+}
+
+func formatFailures(failures []entity.SyncFailure) string {
+	if len(failures) == 0 {
+		return "No orders or customers stuck in error."
+	}
+	var sb strings.Builder
+	sb.WriteString("Stuck in error:")
+	for _, f := range failures {
+		kind := "order"
+		if f.Kind == entity.SyncFailureCustomer {
+			kind = "customer"
+		}
+		fmt.Fprintf(&sb, "\n%s %d: %s <%s> %s", kind, f.ID, strings.TrimSpace(f.Name), f.Email, formatTime(f.DateModified))
+	}
+	sb.WriteString("\n\n/retry <order_id> or /retry customer <customer_id>")
+	return sb.String()
+}
+
+func formatStats(s *entity.SyncStats, now time.Time) string {
+	var sb strings.Builder
+	state := "running"
+	if s.Paused {
+		state = "paused"
+	}
+	fmt.Fprintf(&sb, "Pollers: %s\n", state)
+	if !s.StartedAt.IsZero() {
+		fmt.Fprintf(&sb, "Uptime: %s\n", now.Sub(s.StartedAt).Truncate(time.Second))
+	}
+	fmt.Fprintf(&sb, "Last order run: %s\n", formatTime(s.LastOrderRun))
+	fmt.Fprintf(&sb, "Last customer run: %s\n", formatTime(s.LastCustomerRun))
+	fmt.Fprintf(&sb, "Customers synced: %d/%d\n", s.CustomersSynced, s.CustomersTotal)
+	fmt.Fprintf(&sb, "Failed payments: %d\n", s.FailedPayments)
+	fmt.Fprintf(&sb, "Failed customers: %d", s.FailedCustomers)
+	return sb.String()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(timeLayout)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
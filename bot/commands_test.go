@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+// fakeCore records the calls the admin commands make. Methods a test does not expect panic via
+// the embedded nil interface.
+type fakeCore struct {
+	Core
+	pushed          []int64
+	retriedOrders   []int64
+	retriedCustomer []int64
+	paused          bool
+	state           *entity.OrderSyncState
+	failures        []entity.SyncFailure
+	stats           *entity.SyncStats
+	err             error
+}
+
+func (f *fakeCore) PushOrderToZoho(orderId int64) (string, error) {
+	f.pushed = append(f.pushed, orderId)
+	return "zoho-1", f.err
+}
+
+func (f *fakeCore) OrderSyncState(int64) (*entity.OrderSyncState, error) {
+	return f.state, f.err
+}
+
+func (f *fakeCore) SyncFailures() ([]entity.SyncFailure, error) {
+	return f.failures, f.err
+}
+
+func (f *fakeCore) RetryOrder(orderId int64) (string, error) {
+	f.retriedOrders = append(f.retriedOrders, orderId)
+	return "zoho-1", f.err
+}
+
+func (f *fakeCore) RetryCustomer(customerId int64) error {
+	f.retriedCustomer = append(f.retriedCustomer, customerId)
+	return f.err
+}
+
+func (f *fakeCore) PausePollers()  { f.paused = true }
+func (f *fakeCore) ResumePollers() { f.paused = false }
+
+func (f *fakeCore) SyncStats() (*entity.SyncStats, error) {
+	return f.stats, f.err
+}
+
+func TestParseId(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    int64
+		wantErr bool
+	}{
+		{name: "valid id", args: []string{"123"}, want: 123},
+		{name: "extra args ignored", args: []string{"7", "now"}, want: 7},
+		{name: "missing id", args: nil, wantErr: true},
+		{name: "not a number", args: []string{"abc"}, wantErr: true},
+		{name: "zero", args: []string{"0"}, wantErr: true},
+		{name: "negative", args: []string{"-5"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseId(tt.args, "/push <order_id>")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseId(%q) = %d, want error", tt.args, got)
+				} else if !strings.Contains(err.Error(), "/push <order_id>") {
+					t.Errorf("parseId(%q) error %q does not show usage", tt.args, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseId(%q) = %d, %v, want %d", tt.args, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushCommand(t *testing.T) {
+	core := &fakeCore{}
+	tg := &TgBot{core: core}
+
+	reply := tg.push([]string{"42"})
+	if len(core.pushed) != 1 || core.pushed[0] != 42 {
+		t.Fatalf("pushed = %v, want [42]", core.pushed)
+	}
+	if !strings.Contains(reply, "zoho-1") {
+		t.Errorf("reply %q does not show the zoho_id", reply)
+	}
+
+	core.err = errors.New("zoho down")
+	if reply = tg.push([]string{"42"}); !strings.Contains(reply, "zoho down") {
+		t.Errorf("reply %q does not show the error", reply)
+	}
+
+	if reply = tg.push(nil); !strings.HasPrefix(reply, "usage:") || len(core.pushed) != 2 {
+		t.Errorf("push without id: reply %q, pushed %v", reply, core.pushed)
+	}
+}
+
+func TestRetryCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantOrders    []int64
+		wantCustomers []int64
+		wantReply     string
+	}{
+		{name: "order", args: []string{"10"}, wantOrders: []int64{10}, wantReply: "Order 10 pushed"},
+		{name: "customer", args: []string{"customer", "20"}, wantCustomers: []int64{20}, wantReply: "Customer 20"},
+		{name: "customer case insensitive", args: []string{"Customer", "21"}, wantCustomers: []int64{21}, wantReply: "Customer 21"},
+		{name: "customer without id", args: []string{"customer"}, wantReply: "usage:"},
+		{name: "no args", args: nil, wantReply: "usage:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := &fakeCore{}
+			tg := &TgBot{core: core}
+
+			reply := tg.retry(tt.args)
+			if !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply %q, want it to contain %q", reply, tt.wantReply)
+			}
+			if !equalIds(core.retriedOrders, tt.wantOrders) {
+				t.Errorf("retried orders = %v, want %v", core.retriedOrders, tt.wantOrders)
+			}
+			if !equalIds(core.retriedCustomer, tt.wantCustomers) {
+				t.Errorf("retried customers = %v, want %v", core.retriedCustomer, tt.wantCustomers)
+			}
+		})
+	}
+}
+
+func TestPauseResumeCommands(t *testing.T) {
+	core := &fakeCore{}
+	tg := &TgBot{core: core}
+
+	tg.pause(nil)
+	if !core.paused {
+		t.Error("pause did not pause the pollers")
+	}
+	tg.resume(nil)
+	if core.paused {
+		t.Error("resume did not resume the pollers")
+	}
+}
+
+func TestFormatOrderState(t *testing.T) {
+	state := &entity.OrderSyncState{
+		OrderId:          123,
+		StatusId:         2,
+		ZohoId:           "5234567890",
+		PaymentStatus:    "paid",
+		ZohoModifiedTime: time.Date(2025, 3, 24, 11, 22, 39, 0, time.UTC),
+	}
+
+	got := formatOrderState(state)
+	for _, want := range []string{
+		"Order 123",
+		"zoho_id: 5234567890",
+		"payment id: none",
+		"payment status: paid (synced: none)",
+		"zoho modified: 2025-03-24 11:22:39",
+		"modified: never",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatOrderState() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatFailures(t *testing.T) {
+	if got := formatFailures(nil); got != "No orders or customers stuck in error." {
+		t.Errorf("formatFailures(nil) = %q", got)
+	}
+
+	got := formatFailures([]entity.SyncFailure{
+		{Kind: entity.SyncFailureOrderPayment, ID: 1, Name: "Jan Kowalski", Email: "jan@example.com"},
+		{Kind: entity.SyncFailureCustomer, ID: 2, Name: " Anna ", Email: "anna@example.com"},
+	})
+	for _, want := range []string{
+		"order 1: Jan Kowalski <jan@example.com>",
+		"customer 2: Anna <anna@example.com>",
+		"/retry",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatFailures() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatStats(t *testing.T) {
+	now := time.Date(2025, 3, 24, 12, 0, 0, 0, time.UTC)
+	stats := &entity.SyncStats{
+		StartedAt:       now.Add(-90 * time.Minute),
+		Paused:          true,
+		LastOrderRun:    now.Add(-time.Minute),
+		CustomersTotal:  100,
+		CustomersSynced: 97,
+		FailedPayments:  2,
+		FailedCustomers: 3,
+	}
+
+	got := formatStats(stats, now)
+	for _, want := range []string{
+		"Pollers: paused",
+		"Uptime: 1h30m0s",
+		"Last order run: 2025-03-24 11:59:00",
+		"Last customer run: never",
+		"Customers synced: 97/100",
+		"Failed payments: 2",
+		"Failed customers: 3",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatStats() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func equalIds(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"golang.org/x/time/rate"
+)
+
+// Telegram allows about one message per second to a chat; bursts beyond that are answered with
+// 429 and the messages lost. Alerts are therefore queued per chat and sent at a steady rate.
+const (
+	defaultSendRate  = 1.0
+	defaultSendBurst = 3
+	defaultQueueSize = 100
+)
+
+// chatOutbox is the alert queue of one chat, drained by its own goroutine.
+type chatOutbox struct {
+	queue   chan string
+	dropped int
+}
+
+// SetSendLimits sets the per-chat alert rate (messages per second) and queue length. It applies
+// to chats whose queue has not been started yet, so call it before the first alert.
+func (t *TgBot) SetSendLimits(perSecond float64, queueSize int) {
+	t.outboxMu.Lock()
+	defer t.outboxMu.Unlock()
+	if perSecond > 0 {
+		t.sendRate = perSecond
+	}
+	if queueSize > 0 {
+		t.queueSize = queueSize
+	}
+}
+
+// enqueue queues an alert for a chat without blocking. When the queue is full the alert is
+// dropped and counted; the count is reported with the next alert that gets through.
+func (t *TgBot) enqueue(chatId int64, text string) {
+	t.outboxMu.Lock()
+	defer t.outboxMu.Unlock()
+
+	box, ok := t.outboxes[chatId]
+	if !ok {
+		box = t.startOutbox(chatId)
+	}
+
+	if box.dropped > 0 {
+		notice := Sanitize(fmt.Sprintf("%d alert(s) dropped, the queue was full", box.dropped))
+		select {
+		case box.queue <- notice:
+			box.dropped = 0
+		default:
+		}
+	}
+
+	select {
+	case box.queue <- text:
+	default:
+		box.dropped++
+	}
+}
+
+// startOutbox creates the queue of a chat and its sender. Called with outboxMu held.
+func (t *TgBot) startOutbox(chatId int64) *chatOutbox {
+	if t.outboxes == nil {
+		t.outboxes = make(map[int64]*chatOutbox)
+	}
+	if t.sendCtx == nil {
+		t.sendCtx, t.sendCancel = context.WithCancel(context.Background())
+	}
+	sendRate, queueSize := t.sendRate, t.queueSize
+	if sendRate <= 0 {
+		sendRate = defaultSendRate
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	box := &chatOutbox{queue: make(chan string, queueSize)}
+	t.outboxes[chatId] = box
+
+	limiter := rate.NewLimiter(rate.Limit(sendRate), defaultSendBurst)
+	ctx := t.sendCtx
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case text := <-box.queue:
+				if err := limiter.Wait(ctx); err != nil {
+					return
+				}
+				t.plainResponse(chatId, text)
+			}
+		}
+	}()
+
+	t.log.With(
+		slog.Int64("id", chatId),
+		slog.Float64("rate", sendRate),
+		slog.Int("queue", queueSize),
+	).Debug("alert queue started")
+	return box
+}
+
+// stopOutboxes stops the alert senders; queued alerts are discarded.
+func (t *TgBot) stopOutboxes() {
+	t.outboxMu.Lock()
+	defer t.outboxMu.Unlock()
+	if t.sendCancel != nil {
+		t.sendCancel()
+		t.sendCtx, t.sendCancel = nil, nil
+	}
+	t.outboxes = nil
+}
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+// A full queue must not block the logger: the alert is dropped, counted, and the count reported
+// once there is room again.
+func TestEnqueue_DropsWhenFull(t *testing.T) {
+	box := &chatOutbox{queue: make(chan string, 2)}
+	tg := &TgBot{outboxes: map[int64]*chatOutbox{1: box}}
+
+	tg.enqueue(1, "a")
+	tg.enqueue(1, "b")
+	tg.enqueue(1, "c")
+	tg.enqueue(1, "d")
+
+	if box.dropped != 2 {
+		t.Fatalf("dropped = %d, want 2", box.dropped)
+	}
+	if got := []string{<-box.queue, <-box.queue}; got[0] != "a" || got[1] != "b" {
+		t.Fatalf("queued = %v, want [a b]", got)
+	}
+
+	tg.enqueue(1, "e")
+	notice := <-box.queue
+	if !strings.Contains(notice, "2 alert\\(s\\) dropped") {
+		t.Errorf("notice = %q, want the dropped count", notice)
+	}
+	if got := <-box.queue; got != "e" {
+		t.Errorf("queued = %q, want e", got)
+	}
+	if box.dropped != 0 {
+		t.Errorf("dropped = %d after the notice, want 0", box.dropped)
+	}
+}
+
+func TestSetSendLimits(t *testing.T) {
+	tg := &TgBot{}
+	tg.SetSendLimits(0.5, 10)
+	if tg.sendRate != 0.5 || tg.queueSize != 10 {
+		t.Errorf("limits = %v/%d, want 0.5/10", tg.sendRate, tg.queueSize)
+	}
+
+	// Zero keeps the previous values.
+	tg.SetSendLimits(0, 0)
+	if tg.sendRate != 0.5 || tg.queueSize != 10 {
+		t.Errorf("limits = %v/%d after zeros, want 0.5/10", tg.sendRate, tg.queueSize)
+	}
+}
@@ -0,0 +1,243 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+
+	tgbotapi "github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// reconcileIssuesShown caps the issues listed in a reconciliation summary.
+const reconcileIssuesShown = 30
+
+// reconcileKinds is the order the summary counts issues in.
+var reconcileKinds = []string{
+	entity.ReconcileTotal,
+	entity.ReconcileItems,
+	entity.ReconcileStatus,
+	entity.ReconcilePayment,
+	entity.ReconcileMissing,
+	entity.ReconcileUnsynced,
+	entity.ReconcileOrphan,
+	entity.ReconcileDuplicate,
+}
+
+// ReconcileJob is the scheduled reconciliation: at the scheduled time it checks the Days full
+// days before, and writes the report to ReportDir when set.
+type ReconcileJob struct {
+	Schedule      ReportSchedule
+	Days          int
+	UnsyncedAfter time.Duration
+	ReportDir     string
+}
+
+// SetReconcileUnsyncedAfter sets how old an order without a zoho_id must be for /reconcile to
+// report it.
+func (t *TgBot) SetReconcileUnsyncedAfter(d time.Duration) {
+	t.reconcileUnsynced = d
+}
+
+// reconcile handles /reconcile [YYYY-MM-DD [YYYY-MM-DD]]: a check of yesterday, one day or an
+// inclusive range of days, run in the background with the summary sent when done.
+func (t *TgBot) reconcile(b *tgbotapi.Bot, ctx *ext.Context) error {
+	userId := ctx.EffectiveUser.Id
+	if !t.isAdmin(userId) {
+		_, err := ctx.EffectiveMessage.Reply(b, "You are not authorized to use this command.", nil)
+		return err
+	}
+	if t.core == nil {
+		t.plainResponse(userId, Sanitize("Sync core is not available."))
+		return nil
+	}
+
+	args := strings.Fields(ctx.EffectiveMessage.Text)
+	from, to, err := parseReconcileRange(args[1:], time.Now())
+	if err != nil {
+		t.plainResponse(userId, Sanitize(err.Error()))
+		return nil
+	}
+
+	go func() {
+		text, _ := t.runReconcile(from, to, t.reconcileUnsynced)
+		t.sendText(userId, text, nil)
+	}()
+	return nil
+}
+
+// runReconcile runs one reconciliation and returns its summary. Only one runs at a time.
+func (t *TgBot) runReconcile(from, to time.Time, unsyncedAfter time.Duration) (string, *entity.ReconcileResult) {
+	t.reconcileMu.Lock()
+	if t.reconcileRunning {
+		t.reconcileMu.Unlock()
+		return "A reconciliation is already running, wait for its report.", nil
+	}
+	t.reconcileRunning = true
+	t.reconcileMu.Unlock()
+	defer func() {
+		t.reconcileMu.Lock()
+		t.reconcileRunning = false
+		t.reconcileMu.Unlock()
+	}()
+
+	res, err := t.core.Reconcile(entity.ReconcileOptions{From: from, To: to, UnsyncedAfter: unsyncedAfter})
+	if err != nil {
+		return fmt.Sprintf("%s failed: %v", reconcileTitle(from, to), err), nil
+	}
+	return formatReconcileReport(res), &res
+}
+
+// StartReconcile runs the reconciliation on the job's schedule until the bot is stopped.
+func (t *TgBot) StartReconcile(job ReconcileJob) {
+	recipients := job.Schedule.Recipients
+	if len(recipients) == 0 {
+		recipients = t.adminIds
+	}
+	days := max(job.Days, 1)
+	stop := make(chan struct{})
+	t.reconcileStop = stop
+
+	go func() {
+		for {
+			at := job.Schedule.next(time.Now())
+			t.log.With(slog.Time("at", at)).Debug("next reconciliation")
+
+			timer := time.NewTimer(time.Until(at))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if t.core == nil {
+				t.log.Warn("reconciliation skipped: sync core not available")
+				continue
+			}
+			local := at.In(job.Schedule.Location)
+			to := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, job.Schedule.Location)
+			from := to.AddDate(0, 0, -days)
+
+			text, res := t.runReconcile(from, to, job.UnsyncedAfter)
+			if res != nil && job.ReportDir != "" {
+				path, err := writeReconcileReport(job.ReportDir, *res)
+				if err != nil {
+					t.log.With(sl.Err(err)).Error("write reconciliation report")
+				} else {
+					text += "\n\nReport: " + path
+				}
+			}
+			for _, chatId := range recipients {
+				t.sendText(chatId, text, nil)
+			}
+		}
+	}()
+}
+
+// writeReconcileReport saves the issues as CSV, named after the range, and returns the path.
+func writeReconcileReport(dir string, res entity.ReconcileResult) (string, error) {
+	name := fmt.Sprintf("reconcile-%s_%s.csv", res.From.Format(time.DateOnly), res.To.Format(time.DateOnly))
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err = res.WriteCSV(f); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// parseReconcileRange reads the optional first and last day, in the server's local time. With
+// no day it is yesterday; with one, that day.
+func parseReconcileRange(args []string, now time.Time) (time.Time, time.Time, error) {
+	const usage = "usage: /reconcile [YYYY-MM-DD [YYYY-MM-DD]]"
+	if len(args) > 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf(usage)
+	}
+	if len(args) == 0 {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		return today.AddDate(0, 0, -1), today, nil
+	}
+
+	days := make([]time.Time, len(args))
+	for i, arg := range args {
+		day, err := time.ParseInLocation(time.DateOnly, arg, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid date: %q, %s", arg, usage)
+		}
+		days[i] = day
+	}
+	from, last := days[0], days[len(days)-1]
+	if last.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the last day is before the first, %s", usage)
+	}
+	return from, last.AddDate(0, 0, 1), nil
+}
+
+func reconcileTitle(from, to time.Time) string {
+	last := to.AddDate(0, 0, -1)
+	if !last.After(from) {
+		return fmt.Sprintf("Reconciliation %s", from.Format(time.DateOnly))
+	}
+	return fmt.Sprintf("Reconciliation %s - %s", from.Format(time.DateOnly), last.Format(time.DateOnly))
+}
+
+// formatReconcileReport renders the counts per kind of issue, then the first issues.
+func formatReconcileReport(res entity.ReconcileResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\nchecked %d, matched %d, Zoho orders %d, issues %d",
+		reconcileTitle(res.From, res.To), res.Checked, res.Matched, res.ZohoOrders, len(res.Issues))
+	if len(res.Issues) == 0 {
+		return sb.String()
+	}
+
+	var counts []string
+	for _, kind := range reconcileKinds {
+		if n := res.Count(kind); n > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", kind, n))
+		}
+	}
+	fmt.Fprintf(&sb, "\n%s\n", strings.Join(counts, ", "))
+
+	for i, issue := range res.Issues {
+		if i == reconcileIssuesShown {
+			fmt.Fprintf(&sb, "\n...and %d more", len(res.Issues)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n%s", formatReconcileIssue(issue))
+	}
+	return sb.String()
+}
+
+func formatReconcileIssue(issue entity.ReconcileIssue) string {
+	parts := []string{issue.Kind}
+	if issue.OrderId != 0 {
+		parts = append(parts, fmt.Sprintf("#%d", issue.OrderId))
+	}
+	if issue.ZohoID != "" {
+		parts = append(parts, issue.ZohoID)
+	}
+	line := strings.Join(parts, " ")
+	if issue.OpenCart != "" || issue.Zoho != "" {
+		line += fmt.Sprintf(": OpenCart %s, Zoho %s", orDash(issue.OpenCart), orDash(issue.Zoho))
+	}
+	if issue.Detail != "" {
+		line += ": " + issue.Detail
+	}
+	return line
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
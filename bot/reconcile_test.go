@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+func TestParseReconcileRange(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, time.Local)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		name     string
+		args     []string
+		from, to time.Time
+		wantErr  bool
+	}{
+		{name: "yesterday", from: day(9), to: day(10)},
+		{name: "one day", args: []string{"2026-03-05"}, from: day(5), to: day(6)},
+		{name: "range", args: []string{"2026-03-01", "2026-03-07"}, from: day(1), to: day(8)},
+		{name: "reversed", args: []string{"2026-03-07", "2026-03-01"}, wantErr: true},
+		{name: "bad date", args: []string{"03/07/2026"}, wantErr: true},
+		{name: "too many", args: []string{"2026-03-01", "2026-03-02", "2026-03-03"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseReconcileRange(tt.args, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (!from.Equal(tt.from) || !to.Equal(tt.to)) {
+				t.Errorf("range = %s - %s, want %s - %s", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestFormatReconcileReport(t *testing.T) {
+	res := entity.ReconcileResult{
+		From:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+		To:         time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local),
+		Checked:    12,
+		Matched:    10,
+		ZohoOrders: 13,
+		Issues: []entity.ReconcileIssue{
+			{Kind: entity.ReconcileTotal, OrderId: 17104, ZohoID: "ZO-2", OpenCart: "996.09 PLN", Zoho: "900.00", Detail: "diff -96.09"},
+			{Kind: entity.ReconcileStatus, OrderId: 17105, ZohoID: "ZO-3", OpenCart: "Оплачено", Zoho: "Нове"},
+			{Kind: entity.ReconcileOrphan, ZohoID: "ZO-5", Detail: "ID_site 99999"},
+		},
+	}
+
+	text := formatReconcileReport(res)
+	for _, want := range []string{
+		"Reconciliation 2026-03-01\nchecked 12, matched 10, Zoho orders 13, issues 3",
+		"total 1, status 1, orphan_in_zoho 1",
+		"total #17104 ZO-2: OpenCart 996.09 PLN, Zoho 900.00: diff -96.09",
+		"orphan_in_zoho ZO-5: ID_site 99999",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+}
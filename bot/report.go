@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"zohoclient/entity"
+)
+
+// reportIdsShown caps the order ids listed per line of the daily report.
+const reportIdsShown = 20
+
+// ReportSchedule is when and to whom the daily sync report is sent.
+type ReportSchedule struct {
+	Hour       int
+	Minute     int
+	Location   *time.Location
+	Recipients []int64 // empty sends to all admins
+}
+
+// ParseReportSchedule reads the report time (HH:MM), IANA timezone and comma-separated
+// recipient chat ids from the config.
+func ParseReportSchedule(at, timezone, recipients string) (ReportSchedule, error) {
+	var schedule ReportSchedule
+
+	clock, err := time.Parse("15:04", strings.TrimSpace(at))
+	if err != nil {
+		return schedule, fmt.Errorf("invalid report time: %q, must be HH:MM", at)
+	}
+	schedule.Hour, schedule.Minute = clock.Hour(), clock.Minute()
+
+	schedule.Location, err = time.LoadLocation(timezone)
+	if err != nil {
+		return schedule, fmt.Errorf("invalid report timezone: %q: %w", timezone, err)
+	}
+
+	schedule.Recipients, err = parseIds(recipients)
+	if err != nil {
+		return schedule, fmt.Errorf("invalid report recipients: %q, must be a comma-separated list of integers", recipients)
+	}
+	return schedule, nil
+}
+
+// next returns the first report time strictly after now.
+func (s ReportSchedule) next(now time.Time) time.Time {
+	local := now.In(s.Location)
+	at := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, s.Minute, 0, 0, s.Location)
+	if !at.After(local) {
+		at = time.Date(local.Year(), local.Month(), local.Day()+1, s.Hour, s.Minute, 0, 0, s.Location)
+	}
+	return at
+}
+
+// StartDailyReport sends the sync report on the schedule until the bot is stopped. Each report
+// covers the time since the previous one.
+func (t *TgBot) StartDailyReport(schedule ReportSchedule) {
+	recipients := schedule.Recipients
+	if len(recipients) == 0 {
+		recipients = t.adminIds
+	}
+	stop := make(chan struct{})
+	t.reportStop = stop
+
+	go func() {
+		for {
+			at := schedule.next(time.Now())
+			t.log.With(slog.Time("at", at)).Debug("next daily report")
+
+			timer := time.NewTimer(time.Until(at))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if t.core == nil {
+				t.log.Warn("daily report skipped: sync core not available")
+				continue
+			}
+			text := formatSyncReport(t.core.TakeSyncReport(), schedule.Location)
+			for _, chatId := range recipients {
+				t.sendText(chatId, text, nil)
+			}
+		}
+	}()
+}
+
+// formatSyncReport renders the daily report as plain text.
+func formatSyncReport(r entity.SyncReport, loc *time.Location) string {
+	var sb strings.Builder
+	layout := "2006-01-02 15:04"
+	if r.From.IsZero() {
+		fmt.Fprintf(&sb, "Sync report until %s\n", r.To.In(loc).Format(layout))
+	} else {
+		fmt.Fprintf(&sb, "Sync report %s - %s\n", r.From.In(loc).Format(layout), r.To.In(loc).Format(layout))
+	}
+	fmt.Fprintf(&sb, "\nOrders to Zoho: %d created, %d updated", r.OrdersCreated, r.OrdersUpdated)
+	fmt.Fprintf(&sb, "\nZoho webhooks: %d applied, %d echoes suppressed", r.WebhooksApplied, r.WebhooksEchoSuppressed)
+	fmt.Fprintf(&sb, "\nPayments: %d created, %d updated, %d errors", r.PaymentsCreated, r.PaymentsUpdated, r.PaymentsErrored)
+	fmt.Fprintf(&sb, "\nCustomers: %d synced, %d failed; %d/%d in Zoho",
+		r.CustomersSynced, r.CustomersFailed, r.CustomersInZoho, r.CustomersTotal)
+	fmt.Fprintf(&sb, "\nBlocked on product Zoho id: %s", formatIds(r.OrdersBlockedOnProducts))
+	fmt.Fprintf(&sb, "\nVAT gap over tolerance: %s", formatIds(r.OrdersWithTaxGap))
+	return sb.String()
+}
+
+// formatIds prints a count with the first order ids.
+func formatIds(ids []int64) string {
+	if len(ids) == 0 {
+		return "none"
+	}
+	shown := ids
+	if len(shown) > reportIdsShown {
+		shown = shown[:reportIdsShown]
+	}
+	parts := make([]string, len(shown))
+	for i, id := range shown {
+		parts[i] = fmt.Sprintf("#%d", id)
+	}
+	text := fmt.Sprintf("%d (%s", len(ids), strings.Join(parts, ", "))
+	if len(ids) > len(shown) {
+		text += ", ..."
+	}
+	return text + ")"
+}
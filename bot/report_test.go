@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+func TestParseReportSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		at         string
+		timezone   string
+		recipients string
+		wantErr    bool
+	}{
+		{name: "valid", at: "08:30", timezone: "Europe/Warsaw", recipients: "1, 2"},
+		{name: "no recipients", at: "23:59", timezone: "UTC"},
+		{name: "bad time", at: "8.30", timezone: "UTC", wantErr: true},
+		{name: "hour out of range", at: "24:00", timezone: "UTC", wantErr: true},
+		{name: "bad timezone", at: "08:00", timezone: "Mars/Olympus", wantErr: true},
+		{name: "bad recipients", at: "08:00", timezone: "UTC", recipients: "1,x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReportSchedule(tt.at, tt.timezone, tt.recipients)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseReportSchedule(%q, %q, %q) error = %v, wantErr %v", tt.at, tt.timezone, tt.recipients, err, tt.wantErr)
+			}
+		})
+	}
+
+	schedule, _ := ParseReportSchedule("08:30", "Europe/Warsaw", "1, 2")
+	if schedule.Hour != 8 || schedule.Minute != 30 || len(schedule.Recipients) != 2 || schedule.Recipients[1] != 2 {
+		t.Errorf("schedule = %+v, want 08:30 to [1 2]", schedule)
+	}
+}
+
+func TestReportScheduleNext(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	schedule := ReportSchedule{Hour: 8, Minute: 0, Location: warsaw}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "later today",
+			now:  time.Date(2026, 8, 1, 5, 0, 0, 0, time.UTC), // 07:00 in Warsaw
+			want: time.Date(2026, 8, 1, 8, 0, 0, 0, warsaw),
+		},
+		{
+			name: "exactly now goes to tomorrow",
+			now:  time.Date(2026, 8, 1, 8, 0, 0, 0, warsaw),
+			want: time.Date(2026, 8, 2, 8, 0, 0, 0, warsaw),
+		},
+		{
+			name: "server clock in another zone",
+			now:  time.Date(2026, 8, 1, 23, 30, 0, 0, time.UTC), // already 01:30 on the 2nd in Warsaw
+			want: time.Date(2026, 8, 2, 8, 0, 0, 0, warsaw),
+		},
+		{
+			name: "across the DST change keeps local time",
+			now:  time.Date(2026, 10, 24, 9, 0, 0, 0, warsaw),
+			want: time.Date(2026, 10, 25, 8, 0, 0, 0, warsaw),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schedule.next(tt.now); !got.Equal(tt.want) {
+				t.Errorf("next(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSyncReport(t *testing.T) {
+	report := entity.SyncReport{
+		From:                    time.Date(2026, 7, 31, 6, 0, 0, 0, time.UTC),
+		To:                      time.Date(2026, 8, 1, 6, 0, 0, 0, time.UTC),
+		OrdersCreated:           42,
+		OrdersUpdated:           3,
+		WebhooksApplied:         7,
+		WebhooksEchoSuppressed:  45,
+		PaymentsCreated:         40,
+		PaymentsUpdated:         5,
+		PaymentsErrored:         1,
+		CustomersSynced:         12,
+		CustomersTotal:          1000,
+		CustomersInZoho:         990,
+		OrdersBlockedOnProducts: []int64{17103, 17110},
+	}
+
+	got := formatSyncReport(report, time.UTC)
+	for _, want := range []string{
+		"Sync report 2026-07-31 06:00 - 2026-08-01 06:00",
+		"Orders to Zoho: 42 created, 3 updated",
+		"Zoho webhooks: 7 applied, 45 echoes suppressed",
+		"Payments: 40 created, 5 updated, 1 errors",
+		"Customers: 12 synced, 0 failed; 990/1000 in Zoho",
+		"Blocked on product Zoho id: 2 (#17103, #17110)",
+		"VAT gap over tolerance: none",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatSyncReport() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatIds_Caps(t *testing.T) {
+	ids := make([]int64, reportIdsShown+3)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+
+	got := formatIds(ids)
+	if !strings.HasPrefix(got, "23 (#1, #2") || !strings.HasSuffix(got, "#20, ...)") {
+		t.Errorf("formatIds() = %q", got)
+	}
+}
@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"zohoclient/entity"
+
+	tgbotapi "github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+const subscribeUsage = "/subscribe [status=1,2] [currency=PLN,EUR] [group=1]"
+
+// subscribe handles /subscribe for any user. Admins are subscribed at once; anyone else waits
+// for an admin to /confirm the request.
+func (t *TgBot) subscribe(_ *tgbotapi.Bot, ctx *ext.Context) error {
+	user := ctx.EffectiveUser
+	chatId := ctx.EffectiveChat.Id
+	if t.core == nil {
+		t.sendText(chatId, "Subscriptions are not available.", nil)
+		return nil
+	}
+
+	args := strings.Fields(ctx.EffectiveMessage.Text)
+	filter, err := parseSubscriptionFilter(args[1:])
+	if err != nil {
+		t.sendText(chatId, err.Error(), nil)
+		return nil
+	}
+
+	name := userName(user)
+	sub, err := t.core.RequestSubscription(int(user.Id), name, filter, t.isAdmin(user.Id))
+	if err != nil {
+		t.sendText(chatId, fmt.Sprintf("Subscription failed: %v", err), nil)
+		return nil
+	}
+	if sub.IsActive() {
+		t.sendText(chatId, fmt.Sprintf("Subscribed to order status changes: %s.", formatSubscriptionFilter(sub.SubscriptionFilter)), nil)
+		return nil
+	}
+
+	t.sendText(chatId, fmt.Sprintf("Subscription to order status changes (%s) requested. "+
+		"You will be notified once an admin confirms it.", formatSubscriptionFilter(filter)), nil)
+	request := fmt.Sprintf("%s (%d) asks for order status notifications: %s\n/confirm %d or /reject %d",
+		name, user.Id, formatSubscriptionFilter(filter), user.Id, user.Id)
+	for _, adminId := range t.adminIds {
+		t.sendText(adminId, request, nil)
+	}
+	return nil
+}
+
+// unsubscribe handles /unsubscribe for any user.
+func (t *TgBot) unsubscribe(_ *tgbotapi.Bot, ctx *ext.Context) error {
+	chatId := ctx.EffectiveChat.Id
+	if t.core == nil {
+		t.sendText(chatId, "Subscriptions are not available.", nil)
+		return nil
+	}
+	err := t.core.CancelSubscription(int(ctx.EffectiveUser.Id))
+	switch {
+	case errors.Is(err, entity.ErrSubscriptionNotFound):
+		t.sendText(chatId, "You have no subscription.", nil)
+	case err != nil:
+		t.sendText(chatId, fmt.Sprintf("Unsubscribe failed: %v", err), nil)
+	default:
+		t.sendText(chatId, "Unsubscribed from order status changes.", nil)
+	}
+	return nil
+}
+
+// subscriptions handles /subscriptions.
+func (t *TgBot) subscriptions(_ []string) string {
+	subs, err := t.core.Subscriptions()
+	if err != nil {
+		return fmt.Sprintf("Failed to list subscriptions: %v", err)
+	}
+	return formatSubscriptions(subs)
+}
+
+// confirm handles /confirm <user_id>.
+func (t *TgBot) confirm(args []string) string {
+	userId, err := parseId(args, "/confirm <user_id>")
+	if err != nil {
+		return err.Error()
+	}
+	sub, err := t.core.ConfirmSubscription(int(userId))
+	if err != nil {
+		return fmt.Sprintf("User %d: %v", userId, err)
+	}
+	t.sendText(userId, fmt.Sprintf("Your subscription to order status changes (%s) is confirmed.",
+		formatSubscriptionFilter(sub.SubscriptionFilter)), nil)
+	return fmt.Sprintf("Subscription of %s (%d) confirmed", sub.User, userId)
+}
+
+// reject handles /reject <user_id>: it declines a request or ends an active subscription.
+func (t *TgBot) reject(args []string) string {
+	userId, err := parseId(args, "/reject <user_id>")
+	if err != nil {
+		return err.Error()
+	}
+	if err = t.core.CancelSubscription(int(userId)); err != nil {
+		return fmt.Sprintf("User %d: %v", userId, err)
+	}
+	t.sendText(userId, "Your subscription to order status changes was declined.", nil)
+	return fmt.Sprintf("Subscription of user %d removed", userId)
+}
+
+// NotifyOrderStatus queues a status change for a subscriber; it goes through the same per-chat
+// rate limit as alerts.
+func (t *TgBot) NotifyOrderStatus(sub entity.Subscription, change entity.OrderStatusChange) {
+	t.enqueue(int64(sub.UserID), Sanitize(formatStatusChange(change)))
+}
+
+// parseSubscriptionFilter reads key=value arguments; each value is a comma-separated list.
+func parseSubscriptionFilter(args []string) (entity.SubscriptionFilter, error) {
+	var filter entity.SubscriptionFilter
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return filter, fmt.Errorf("invalid filter: %q, usage: %s", arg, subscribeUsage)
+		}
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			switch strings.ToLower(key) {
+			case "status":
+				id, err := strconv.Atoi(item)
+				if err != nil || id <= 0 {
+					return filter, fmt.Errorf("invalid status id: %q, usage: %s", item, subscribeUsage)
+				}
+				filter.Statuses = append(filter.Statuses, id)
+			case "currency":
+				filter.Currencies = append(filter.Currencies, strings.ToUpper(item))
+			case "group":
+				id, err := strconv.ParseInt(item, 10, 64)
+				if err != nil || id <= 0 {
+					return filter, fmt.Errorf("invalid customer group id: %q, usage: %s", item, subscribeUsage)
+				}
+				filter.CustomerGroups = append(filter.CustomerGroups, id)
+			default:
+				return filter, fmt.Errorf("unknown filter: %q, usage: %s", key, subscribeUsage)
+			}
+		}
+	}
+	return filter, nil
+}
+
+func formatSubscriptionFilter(f entity.SubscriptionFilter) string {
+	var parts []string
+	if len(f.Statuses) > 0 {
+		ids := make([]string, len(f.Statuses))
+		for i, id := range f.Statuses {
+			ids[i] = strconv.Itoa(id)
+		}
+		parts = append(parts, "status "+strings.Join(ids, ","))
+	}
+	if len(f.Currencies) > 0 {
+		parts = append(parts, "currency "+strings.Join(f.Currencies, ","))
+	}
+	if len(f.CustomerGroups) > 0 {
+		ids := make([]string, len(f.CustomerGroups))
+		for i, id := range f.CustomerGroups {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		parts = append(parts, "group "+strings.Join(ids, ","))
+	}
+	if len(parts) == 0 {
+		return "all orders"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatSubscriptions(subs []entity.Subscription) string {
+	if len(subs) == 0 {
+		return "No subscriptions."
+	}
+	var sb strings.Builder
+	sb.WriteString("Order status subscriptions:")
+	for _, s := range subs {
+		state := "active"
+		if !s.IsActive() {
+			state = "awaiting /confirm"
+		}
+		fmt.Fprintf(&sb, "\n%s (%d), %s: %s", s.User, s.UserID, state, formatSubscriptionFilter(s.SubscriptionFilter))
+	}
+	return sb.String()
+}
+
+func formatStatusChange(c entity.OrderStatusChange) string {
+	var sb strings.Builder
+	if c.Shadow {
+		sb.WriteString("[shadow] ")
+	}
+	fmt.Fprintf(&sb, "Order %d: %s (status %d → %d)", c.OrderId, orNone(c.StatusName), c.FromStatusId, c.StatusId)
+	if c.Customer != "" {
+		fmt.Fprintf(&sb, "\nCustomer: %s", c.Customer)
+	}
+	fmt.Fprintf(&sb, "\nTotal: %s", entity.NewMoney(c.Total, c.Currency))
+	fmt.Fprintf(&sb, "\nzoho_id: %s", orNone(c.ZohoId))
+	return sb.String()
+}
+
+// userName is how a Telegram user is shown to admins.
+func userName(u *tgbotapi.User) string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"zohoclient/entity"
+)
+
+func TestParseSubscriptionFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    entity.SubscriptionFilter
+		wantErr bool
+	}{
+		{name: "no filter", args: nil},
+		{name: "statuses", args: []string{"status=2,5"}, want: entity.SubscriptionFilter{Statuses: []int{2, 5}}},
+		{
+			name: "all filters",
+			args: []string{"status=1", "currency=pln,EUR", "group=6"},
+			want: entity.SubscriptionFilter{Statuses: []int{1}, Currencies: []string{"PLN", "EUR"}, CustomerGroups: []int64{6}},
+		},
+		{name: "repeated key appends", args: []string{"status=1", "status=3"}, want: entity.SubscriptionFilter{Statuses: []int{1, 3}}},
+		{name: "missing value", args: []string{"status="}, wantErr: true},
+		{name: "not key=value", args: []string{"PLN"}, wantErr: true},
+		{name: "bad status", args: []string{"status=new"}, wantErr: true},
+		{name: "bad group", args: []string{"group=0"}, wantErr: true},
+		{name: "unknown key", args: []string{"country=PL"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSubscriptionFilter(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSubscriptionFilter(%q) = %+v, want error", tt.args, got)
+				} else if !strings.Contains(err.Error(), subscribeUsage) {
+					t.Errorf("error %q does not show usage", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSubscriptionFilter(%q) = %+v, %v, want %+v", tt.args, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSubscriptionFilter(t *testing.T) {
+	if got := formatSubscriptionFilter(entity.SubscriptionFilter{}); got != "all orders" {
+		t.Errorf("empty filter = %q, want %q", got, "all orders")
+	}
+	f := entity.SubscriptionFilter{Statuses: []int{2, 5}, Currencies: []string{"PLN"}, CustomerGroups: []int64{1}}
+	if got, want := formatSubscriptionFilter(f), "status 2,5; currency PLN; group 1"; got != want {
+		t.Errorf("formatSubscriptionFilter = %q, want %q", got, want)
+	}
+}
+
+func TestFormatSubscriptions(t *testing.T) {
+	pending := entity.NewSubscription(7, "@anna")
+	active := entity.NewSubscription(8, "Bob")
+	active.Confirm()
+	active.Currencies = []string{"EUR"}
+
+	got := formatSubscriptions([]entity.Subscription{pending, active})
+	for _, want := range []string{"@anna (7), awaiting /confirm: all orders", "Bob (8), active: currency EUR"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatSubscriptions missing %q:\n%s", want, got)
+		}
+	}
+	if got = formatSubscriptions(nil); got != "No subscriptions." {
+		t.Errorf("formatSubscriptions(nil) = %q", got)
+	}
+}
+
+func TestFormatStatusChange(t *testing.T) {
+	got := formatStatusChange(entity.OrderStatusChange{
+		OrderId:      17103,
+		ZohoId:       "Z-1",
+		FromStatusId: 1,
+		StatusId:     2,
+		StatusName:   "Оплачено, формування ТТН",
+		Currency:     "PLN",
+		Customer:     "Jan Kowalski",
+		Total:        entity.AmountOf(123.4),
+	})
+	for _, want := range []string{"Order 17103: Оплачено, формування ТТН (status 1 → 2)", "Jan Kowalski", "123.40 PLN", "Z-1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatStatusChange missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "[shadow]") {
+		t.Errorf("live status change marked as shadow:\n%s", got)
+	}
+
+	got = formatStatusChange(entity.OrderStatusChange{OrderId: 17103, StatusId: 2, Shadow: true})
+	if !strings.HasPrefix(got, "[shadow] Order 17103") {
+		t.Errorf("shadow status change = %q, want it marked", got)
+	}
+}
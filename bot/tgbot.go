@@ -1,17 +1,20 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"log/slog"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"zohoclient/internal/lib/sl"
 
 	tgbotapi "github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
 )
 
 type TgBot struct {
@@ -22,19 +25,31 @@ type TgBot struct {
 	adminIds    []int64
 	minLogLevel slog.Level
 	adminLevels map[int64]slog.Level
+	core        Core
+
+	backfillMu      sync.Mutex
+	backfills       map[string]pendingBackfill
+	backfillRunning bool
+
+	outboxMu   sync.Mutex
+	outboxes   map[int64]*chatOutbox
+	sendRate   float64
+	queueSize  int
+	sendCtx    context.Context
+	sendCancel context.CancelFunc
+
+	reportStop chan struct{}
+
+	reconcileMu       sync.Mutex
+	reconcileRunning  bool
+	reconcileUnsynced time.Duration
+	reconcileStop     chan struct{}
 }
 
 func NewTgBot(botName, apiKey string, adminIdsStr string, log *slog.Logger) (*TgBot, error) {
-	var adminIds []int64
-	if adminIdsStr != "" {
-		idStrs := strings.Split(adminIdsStr, ",")
-		for _, idStr := range idStrs {
-			id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid admin_id value: %q, must be a comma-separated list of integers", adminIdsStr)
-			}
-			adminIds = append(adminIds, id)
-		}
+	adminIds, err := parseIds(adminIdsStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin_id value: %q, must be a comma-separated list of integers", adminIdsStr)
 	}
 
 	// Default to warn level if not specified
@@ -68,6 +83,22 @@ func NewTgBot(botName, apiKey string, adminIdsStr string, log *slog.Logger) (*Tg
 	return tgBot, nil
 }
 
+// parseIds reads a comma-separated list of Telegram ids; an empty string is an empty list.
+func parseIds(s string) ([]int64, error) {
+	var ids []int64
+	if s == "" {
+		return ids, nil
+	}
+	for _, idStr := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func (t *TgBot) Start() error {
 	dispatcher := ext.NewDispatcher(&ext.DispatcherOpts{
 		Error: func(b *tgbotapi.Bot, ctx *ext.Context, err error) ext.DispatcherAction {
@@ -79,6 +110,21 @@ func (t *TgBot) Start() error {
 	t.updater = ext.NewUpdater(dispatcher, nil)
 
 	dispatcher.AddHandler(handlers.NewCommand("level", t.level))
+	dispatcher.AddHandler(handlers.NewCommand("push", t.adminCommand(t.push)))
+	dispatcher.AddHandler(handlers.NewCommand("order", t.adminCommand(t.order)))
+	dispatcher.AddHandler(handlers.NewCommand("failed", t.adminCommand(t.failed)))
+	dispatcher.AddHandler(handlers.NewCommand("retry", t.adminCommand(t.retry)))
+	dispatcher.AddHandler(handlers.NewCommand("pause", t.adminCommand(t.pause)))
+	dispatcher.AddHandler(handlers.NewCommand("resume", t.adminCommand(t.resume)))
+	dispatcher.AddHandler(handlers.NewCommand("stats", t.adminCommand(t.stats)))
+	dispatcher.AddHandler(handlers.NewCommand("subscribe", t.subscribe))
+	dispatcher.AddHandler(handlers.NewCommand("unsubscribe", t.unsubscribe))
+	dispatcher.AddHandler(handlers.NewCommand("subscriptions", t.adminCommand(t.subscriptions)))
+	dispatcher.AddHandler(handlers.NewCommand("confirm", t.adminCommand(t.confirm)))
+	dispatcher.AddHandler(handlers.NewCommand("reject", t.adminCommand(t.reject)))
+	dispatcher.AddHandler(handlers.NewCommand("backfill", t.backfill))
+	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(backfillCallbackPrefix), t.backfillCallback))
+	dispatcher.AddHandler(handlers.NewCommand("reconcile", t.reconcile))
 
 	err := t.updater.StartPolling(t.api, &ext.PollingOpts{
 		DropPendingUpdates: true,
@@ -98,6 +144,15 @@ func (t *TgBot) Start() error {
 }
 
 func (t *TgBot) Stop() {
+	if t.reportStop != nil {
+		close(t.reportStop)
+		t.reportStop = nil
+	}
+	if t.reconcileStop != nil {
+		close(t.reconcileStop)
+		t.reconcileStop = nil
+	}
+	t.stopOutboxes()
 	if t.updater != nil {
 		t.log.Info("stopping telegram bot")
 		err := t.updater.Stop()
@@ -122,21 +177,22 @@ func (t *TgBot) SetAdminLogLevel(adminId int64, level slog.Level) {
 	t.adminLevels[adminId] = level
 }
 
-// level handles the /level command to set the minimum log level for admin notifications
-func (t *TgBot) level(b *tgbotapi.Bot, ctx *ext.Context) error {
-	// Get the user ID
-	userId := ctx.EffectiveUser.Id
-
-	// Check if the user is an admin
-	isAdmin := false
+// isAdmin reports whether the Telegram user is one of the configured admins.
+func (t *TgBot) isAdmin(userId int64) bool {
 	for _, adminId := range t.adminIds {
 		if userId == adminId {
-			isAdmin = true
-			break
+			return true
 		}
 	}
+	return false
+}
+
+// level handles the /level command to set the minimum log level for admin notifications
+func (t *TgBot) level(b *tgbotapi.Bot, ctx *ext.Context) error {
+	// Get the user ID
+	userId := ctx.EffectiveUser.Id
 
-	if !isAdmin {
+	if !t.isAdmin(userId) {
 		_, err := ctx.EffectiveMessage.Reply(b, "You are not authorized to use this command.", nil)
 		return err
 	}
@@ -177,7 +233,8 @@ func (t *TgBot) SendMessage(msg string) {
 	t.SendMessageWithLevel(msg, t.minLogLevel)
 }
 
-// SendMessageWithLevel sends a message to all admins with the specified log level
+// SendMessageWithLevel queues a message for all admins with the specified log level. Messages
+// are sent per chat at a rate Telegram accepts; see SetSendLimits.
 func (t *TgBot) SendMessageWithLevel(msg string, level slog.Level) {
 	// Send message to all admins who have a log level that allows this message
 	for _, adminId := range t.adminIds {
@@ -190,7 +247,7 @@ func (t *TgBot) SendMessageWithLevel(msg string, level slog.Level) {
 
 		// Only send if the message level is >= the admin's minimum level
 		if level >= adminLevel {
-			t.plainResponse(adminId, msg)
+			t.enqueue(adminId, msg)
 		}
 	}
 }
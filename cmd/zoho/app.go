@@ -0,0 +1,220 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"time"
+	"zohoclient/bot"
+	"zohoclient/impl/core"
+	"zohoclient/internal/config"
+	repository "zohoclient/internal/database/mongo"
+	"zohoclient/internal/database/sql"
+	"zohoclient/internal/lib/logger"
+	"zohoclient/internal/lib/sl"
+	"zohoclient/internal/services"
+	"zohoclient/internal/services/notifier"
+	"zohoclient/internal/services/outbound"
+)
+
+// commonFlags are accepted by every command.
+type commonFlags struct {
+	configPath string
+	logPath    string
+}
+
+func addCommonFlags(fs *flag.FlagSet) *commonFlags {
+	f := &commonFlags{}
+	fs.StringVar(&f.configPath, "conf", "config.yml", "path to config file")
+	fs.StringVar(&f.logPath, "log", "/var/log/", "path to log file directory")
+	return f
+}
+
+// app is the service wired from the config: the core with every backend that could be set
+// up. Commands use what they need; nothing is started here.
+type app struct {
+	conf     *config.Config
+	log      *slog.Logger
+	core     *core.Core
+	db       *sql.MySql
+	mongo    *repository.MongoDB
+	zoho     *services.ZohoService
+	tgBot    *bot.TgBot
+	webhooks *outbound.Dispatcher
+}
+
+// newApp loads the config and connects the backends. A backend that fails is logged and left
+// out, as the service has always done, so a command must check for what it needs.
+func newApp(flags *commonFlags) *app {
+	conf := config.MustLoad(flags.configPath)
+	lg := logger.SetupLogger(conf.Env, flags.logPath)
+	a := &app{conf: conf}
+
+	// Initialize Telegram bot if enabled
+	if conf.Telegram.Enabled {
+		tgBot, err := bot.NewTgBot(conf.Telegram.BotName, conf.Telegram.ApiKey, conf.Telegram.AdminId, lg)
+		if err != nil {
+			lg.Error("failed to initialize telegram bot", slog.String("error", err.Error()))
+		} else {
+			tgBot.SetSendLimits(conf.Telegram.SendRate, conf.Telegram.QueueSize)
+			lg = logger.SetupTelegramHandler(lg, tgBot, slog.LevelDebug, time.Duration(conf.Telegram.DedupWindow)*time.Second)
+			lg.With(
+				slog.String("bot", conf.Telegram.BotName),
+			).Info("telegram bot initialized")
+			a.tgBot = tgBot
+		}
+	}
+	a.log = lg
+
+	lg.Info("starting zohoclient", slog.String("config", flags.configPath), slog.String("env", conf.Env))
+	lg.Debug("debug messages enabled")
+
+	handler := core.New(lg, *conf)
+	a.core = handler
+
+	// In shadow mode nothing is written to Zoho, and nothing Zoho would have returned is written
+	// to OpenCart or the B2B deal store.
+	shadow := conf.Zoho.Mode == "shadow"
+	if shadow {
+		lg.Warn("zoho shadow mode: writes are recorded, not sent")
+	}
+	handler.SetShadowMode(shadow)
+
+	db, err := sql.NewSQLClient(conf, lg)
+	if err != nil {
+		lg.With(sl.Err(err)).Error("mysql client")
+	}
+	if db != nil {
+		if shadow {
+			handler.SetRepository(core.NewShadowRepository(db))
+		} else {
+			handler.SetRepository(db)
+		}
+		lg.With(
+			slog.String("host", conf.SQL.HostName),
+			slog.String("port", conf.SQL.Port),
+			slog.String("user", conf.SQL.UserName),
+			slog.String("database", conf.SQL.Database),
+		).Info("mysql client initialized")
+		a.db = db
+	}
+
+	zoho, err := services.NewZohoService(conf, lg)
+	if err != nil {
+		lg.Error("zoho service", sl.Err(err))
+	}
+
+	prodRepo, err := services.NewProductRepo(conf, lg)
+	if err != nil {
+		lg.With(sl.Err(err)).Error("product repository")
+	} else {
+		handler.SetProductRepository(prodRepo)
+		lg.With(
+			slog.String("url", conf.ProdRepo.ProdUrl),
+		).Info("product repository initialized")
+	}
+
+	if zoho != nil {
+		handler.SetZoho(zoho)
+		a.zoho = zoho
+	} else {
+		lg.Error("zoho service not initialized")
+	}
+
+	mongoClient, err := repository.NewMongoClient(conf, lg)
+	if err != nil {
+		lg.Error("failed to create mongo client", sl.Err(err))
+	}
+	if mongoClient != nil {
+		if shadow {
+			handler.SetMongoRepository(core.NewShadowMongoRepository(mongoClient))
+		} else {
+			handler.SetMongoRepository(mongoClient)
+		}
+		lg.With(
+			slog.String("host", conf.Mongo.Host),
+			slog.String("database", conf.Mongo.Database),
+		).Info("mongodb client initialized")
+		// Shadow mode keeps the writes it records in the call log, audit or not.
+		if zoho != nil && (conf.Zoho.Audit.Enabled || shadow) {
+			zoho.SetCallLog(mongoClient)
+			lg.With(slog.Int("retention_days", conf.Zoho.Audit.RetentionDays)).Info("zoho call audit enabled")
+		}
+		a.mongo = mongoClient
+	} else if shadow {
+		lg.Warn("no mongodb, shadow writes are only logged")
+	}
+
+	// Initialize SmartSender integration if enabled
+	if conf.SmartSender.Enabled {
+		smartSenderSvc, err := services.NewSmartSenderService(conf, lg)
+		if err != nil {
+			lg.Error("failed to create smartsender service", sl.Err(err))
+		} else if smartSenderSvc != nil {
+			handler.SetSmartSenderService(smartSenderSvc)
+			lg.Info("SmartSender service initialized")
+		}
+
+		zohoFuncSvc, err := services.NewZohoFunctionsService(conf, lg)
+		if err != nil {
+			lg.Error("failed to create zoho functions service", sl.Err(err))
+		} else if zohoFuncSvc != nil {
+			// Shadow mode holds back the chat messages too, recording them with the other writes.
+			if shadow && mongoClient != nil {
+				zohoFuncSvc.SetCallLog(mongoClient)
+			}
+			handler.SetZohoFunctionsService(zohoFuncSvc)
+			lg.Info("Zoho Functions service initialized")
+		}
+
+		handler.SetSmartSenderPollInterval(time.Duration(conf.SmartSender.PollInterval) * time.Second)
+	}
+
+	// Outbound webhooks keep their delivery log in MongoDB. Events of one-shot commands stay in
+	// the log until the service delivers them. In shadow mode they are off: downstream systems
+	// must not hear of records that were never created.
+	var deliveryLog outbound.Store
+	if mongoClient != nil {
+		deliveryLog = mongoClient
+	}
+	if shadow {
+		lg.Info("outbound webhooks are off in zoho shadow mode")
+	} else if webhooks, err := outbound.New(conf, deliveryLog, lg); err != nil {
+		lg.With(sl.Err(err)).Error("outbound webhooks")
+	} else if webhooks != nil {
+		handler.SetWebhookDispatcher(webhooks)
+		a.webhooks = webhooks
+	}
+
+	// A nil *TgBot must not reach the notifier as a non-nil interface.
+	var tgSender notifier.TelegramSender
+	if a.tgBot != nil {
+		tgSender = a.tgBot
+	}
+	notifications, err := notifier.New(conf, tgSender, lg, shadow)
+	if err != nil {
+		lg.With(sl.Err(err)).Error("event notifier")
+	} else if notifications != nil {
+		handler.SetMessageService(notifications)
+	}
+
+	return a
+}
+
+// requireSync reports whether the database and Zoho, which every sync command needs, are up.
+func (a *app) requireSync() bool {
+	if a.db == nil || a.zoho == nil {
+		a.log.Error("the database and the Zoho service are required, see the errors above")
+		return false
+	}
+	return true
+}
+
+// close releases the database connections.
+func (a *app) close() {
+	if a.db != nil {
+		a.db.Close()
+	}
+	if a.mongo != nil {
+		a.mongo.Close()
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// backfillProgressEvery is how many orders pass between progress lines in the log.
+const backfillProgressEvery = 100
+
+// runBackfillDiscounts repairs the orders placed in a range of days, or listed, as a dry run
+// unless --apply is given. Progress is checkpointed, so the same command run again after an
+// interruption carries on where it stopped.
+func runBackfillDiscounts(args []string) int {
+	fs, common := newFlagSet("backfill discounts")
+	from := fs.String("from", "", "first day of the orders to repair, YYYY-MM-DD")
+	to := fs.String("to", "", "last day, inclusive; defaults to --from")
+	orders := fs.String("order", "", "comma-separated order ids, instead of --from")
+	ordersFile := fs.String("orders-file", "", "file of order ids, one per line, instead of --from")
+	apply := fs.Bool("apply", false, "write the corrected rows to Zoho; without it nothing is written")
+	workers := fs.Int("workers", 4, "orders checked at once")
+	rate := fs.Float64("rate", 5, "Zoho calls per second across all workers; 0 for no limit")
+	checkpointPath := fs.String("checkpoint", "", "checkpoint file; defaults to a name derived from the run, in the working directory")
+	reportPath := fs.String("report", "", "write the per-order, per-row report to this file, JSON for .json, CSV otherwise")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	opts := entity.BackfillOptions{Apply: *apply, Workers: *workers, Rate: *rate}
+	run := backfillRun{Apply: *apply}
+	selectors := 0
+	for _, set := range []bool{*from != "", *orders != "", *ordersFile != ""} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		fmt.Fprintln(os.Stderr, "backfill discounts: give one of --from, --order or --orders-file")
+		return exitUsage
+	}
+	switch {
+	case *from != "":
+		var err error
+		if opts.From, opts.To, err = parseDays(*from, *to); err != nil {
+			fmt.Fprintln(os.Stderr, "backfill discounts:", err)
+			return exitUsage
+		}
+		run.From, run.To = opts.From.Format(time.DateOnly), opts.To.Format(time.DateOnly)
+	default:
+		list := *orders
+		if *ordersFile != "" {
+			data, err := readInput(*ordersFile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "backfill discounts:", err)
+				return exitFailed
+			}
+			list = string(data)
+		}
+		var err error
+		if opts.OrderIds, err = parseOrderIds(list); err != nil {
+			fmt.Fprintln(os.Stderr, "backfill discounts:", err)
+			return exitUsage
+		}
+		run.OrderIds = opts.OrderIds
+	}
+
+	if *checkpointPath == "" {
+		*checkpointPath = checkpointName(run)
+	}
+	checkpoint, err := openCheckpoint(*checkpointPath, run)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "backfill discounts:", err)
+		return exitFailed
+	}
+	opts.Checkpoint = checkpoint
+
+	// Run it with the service stopped: the poller must not interleave with the rewrite.
+	a := newApp(common)
+	defer a.close()
+	if !a.requireSync() {
+		_ = checkpoint.Close()
+		return exitFailed
+	}
+
+	opts.Progress = func(done, total int) {
+		if done%backfillProgressEvery == 0 || done == total {
+			a.log.With(slog.Int("done", done), slog.Int("total", total)).Info("backfill progress")
+		}
+	}
+	a.log.With(slog.String("checkpoint", *checkpointPath)).Info("backfill checkpoint")
+
+	res, err := a.core.RunBackfill(opts)
+	if err != nil {
+		_ = checkpoint.Close()
+		a.log.With(sl.Err(err)).Error("backfill failed")
+		return exitFailed
+	}
+
+	code := exitOK
+	if *reportPath != "" {
+		if err = writeReport(*reportPath, &res); err != nil {
+			a.log.With(sl.Err(err)).Error("write backfill report")
+			code = exitFailed
+		} else {
+			a.log.With(slog.String("file", *reportPath)).Info("backfill report written")
+		}
+	}
+
+	// A run with failures keeps its checkpoint, so running it again retries only those.
+	if res.Failed > 0 {
+		_ = checkpoint.Close()
+		a.log.With(slog.Int("failed", res.Failed)).Warn("run the same command again to retry the failed orders")
+		return exitFailed
+	}
+	if err = checkpoint.Remove(); err != nil {
+		a.log.With(sl.Err(err)).Warn("remove backfill checkpoint")
+	}
+	if !*apply {
+		a.log.Info("dry run: nothing was written, re-run with --apply to correct these orders")
+	}
+	return code
+}
+
+// checkpointName derives the default checkpoint file of a run, so different runs do not share
+// one.
+func checkpointName(run backfillRun) string {
+	mode := "dry-run"
+	if run.Apply {
+		mode = "apply"
+	}
+	if len(run.OrderIds) == 0 {
+		return fmt.Sprintf("backfill-%s_%s-%s.checkpoint", run.From, run.To, mode)
+	}
+	h := sha256.New()
+	for _, id := range run.OrderIds {
+		h.Write(strconv.AppendInt(nil, id, 10))
+		h.Write([]byte{','})
+	}
+	return fmt.Sprintf("backfill-orders-%s-%s.checkpoint", hex.EncodeToString(h.Sum(nil))[:12], mode)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"zohoclient/bot"
+	"zohoclient/internal/config"
+	repository "zohoclient/internal/database/mongo"
+	"zohoclient/internal/database/sql"
+	"zohoclient/internal/services"
+)
+
+// runConfigCheck loads the config, validates the settings read at startup, and connects to
+// every configured backend. Each check prints one line; any failure fails the command.
+func runConfigCheck(args []string) int {
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	configPath := fs.String("conf", "config.yml", "path to config file")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Printf("FAIL config %s: %v\n", *configPath, err)
+		return exitFailed
+	}
+	fmt.Printf("ok   config %s (env %s)\n", *configPath, conf.Env)
+
+	// The backends log their retries; the verdict is what gets printed.
+	lg := slog.New(slog.NewTextHandler(io.Discard, nil))
+	failed := false
+	check := func(name string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Printf("FAIL %s: %v\n", name, err)
+			return
+		}
+		fmt.Printf("ok   %s\n", name)
+	}
+
+	// Clients authenticate with the legacy key or a scoped token; either one is enough.
+	if conf.Listen.ApiKey == "" && len(conf.Listen.Tokens) == 0 {
+		check("listen.key", fmt.Errorf("empty and no listen.tokens, only OpenCart API keys authenticate"))
+	}
+	if conf.Telegram.Enabled {
+		if r := conf.Telegram.Report; r.Enabled {
+			_, err := bot.ParseReportSchedule(r.Time, r.Timezone, r.Recipients)
+			check("telegram.report", err)
+		}
+		if r := conf.Telegram.Reconcile; r.Enabled {
+			_, err := bot.ParseReportSchedule(r.Time, r.Timezone, r.Recipients)
+			check("telegram.reconcile", err)
+		}
+	}
+
+	if m := conf.SQL.Migrations; m != sql.MigrationsAuto && m != sql.MigrationsVerify {
+		check("sql.migrations", fmt.Errorf("%q, want %s or %s", m, sql.MigrationsAuto, sql.MigrationsVerify))
+	}
+	// The check only reads the schema, whatever sql.migrations says.
+	db, err := sql.Connect(conf, lg)
+	check(fmt.Sprintf("mysql %s:%s/%s", conf.SQL.HostName, conf.SQL.Port, conf.SQL.Database), err)
+	if db != nil {
+		check("mysql schema", db.VerifySchema())
+		db.Close()
+	}
+
+	if conf.Mongo.Enabled {
+		mongoClient, err := repository.NewMongoClient(conf, lg)
+		check(fmt.Sprintf("mongodb %s:%s/%s", conf.Mongo.Host, conf.Mongo.Port, conf.Mongo.Database), err)
+		if mongoClient != nil {
+			mongoClient.Close()
+		}
+	}
+
+	zoho, err := services.NewZohoService(conf, lg)
+	if err == nil {
+		err = zoho.CheckToken()
+	}
+	check("zoho token", err)
+
+	if failed {
+		return exitFailed
+	}
+	fmt.Fprintln(os.Stdout, "config ok")
+	return exitOK
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"zohoclient/entity"
+)
+
+// backfillRun identifies a backfill run; a checkpoint only resumes the run it was written for.
+type backfillRun struct {
+	From     string  `json:"from,omitempty"`
+	To       string  `json:"to,omitempty"`
+	OrderIds []int64 `json:"order_ids,omitempty"`
+	Apply    bool    `json:"apply"`
+}
+
+// fileCheckpoint is a backfill checkpoint kept as JSON lines: the run on the first line, then
+// one finished order per line. Lines are appended as orders finish, so a run killed at any point
+// loses at most the line being written.
+type fileCheckpoint struct {
+	path     string
+	mu       sync.Mutex
+	file     *os.File
+	finished []entity.BackfillOrder
+}
+
+// openCheckpoint opens the checkpoint at path, or starts one. A checkpoint of another run is
+// refused rather than mixed in.
+func openCheckpoint(path string, run backfillRun) (*fileCheckpoint, error) {
+	cp := &fileCheckpoint{path: path}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		header, err := json.Marshal(run)
+		if err != nil {
+			return nil, err
+		}
+		if err = os.WriteFile(path, append(header, '\n'), 0o644); err != nil {
+			return nil, fmt.Errorf("create checkpoint: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	default:
+		if err = cp.load(data, run); err != nil {
+			return nil, err
+		}
+	}
+
+	cp.file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+func (cp *fileCheckpoint) load(data []byte, run backfillRun) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	if !scanner.Scan() {
+		return fmt.Errorf("checkpoint %s is empty, delete it", cp.path)
+	}
+	var stored backfillRun
+	if err := json.Unmarshal(scanner.Bytes(), &stored); err != nil {
+		return fmt.Errorf("checkpoint %s: %w", cp.path, err)
+	}
+	if stored.From != run.From || stored.To != run.To || stored.Apply != run.Apply || !slices.Equal(stored.OrderIds, run.OrderIds) {
+		return fmt.Errorf("checkpoint %s belongs to another run, delete it or pick another --checkpoint", cp.path)
+	}
+	for scanner.Scan() {
+		var line entity.BackfillOrder
+		// A line cut short by a kill is the order being written; it is simply checked again.
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		cp.finished = append(cp.finished, line)
+	}
+	return scanner.Err()
+}
+
+func (cp *fileCheckpoint) Finished() ([]entity.BackfillOrder, error) {
+	return cp.finished, nil
+}
+
+func (cp *fileCheckpoint) Save(order entity.BackfillOrder) error {
+	line, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	_, err = cp.file.Write(append(line, '\n'))
+	return err
+}
+
+func (cp *fileCheckpoint) Close() error {
+	return cp.file.Close()
+}
+
+// Remove deletes the checkpoint of a run that needs no resuming.
+func (cp *fileCheckpoint) Remove() error {
+	_ = cp.file.Close()
+	return os.Remove(cp.path)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"zohoclient/entity"
+)
+
+// A checkpoint reopened for the same run returns what was saved, including a line cut short by
+// a kill, which is dropped; another run's parameters are refused.
+func TestFileCheckpoint_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	run := backfillRun{From: "2026-07-01", To: "2026-08-01", Apply: true}
+
+	cp, err := openCheckpoint(path, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines, _ := cp.Finished(); len(lines) != 0 {
+		t.Fatalf("new checkpoint holds %d orders", len(lines))
+	}
+	for _, line := range []entity.BackfillOrder{
+		{OrderId: 1, ZohoID: "ZO-1", Outcome: entity.BackfillCorrected, Rows: 2},
+		{OrderId: 2, ZohoID: "ZO-2", Outcome: entity.BackfillFailed, Reason: "timeout"},
+	} {
+		if err = cp.Save(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = cp.Close()
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = f.WriteString(`{"order_id":3,"zoho_`)
+	_ = f.Close()
+
+	cp, err = openCheckpoint(path, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, _ := cp.Finished()
+	if len(lines) != 2 || lines[0].OrderId != 1 || lines[0].Rows != 2 || lines[1].Outcome != entity.BackfillFailed {
+		t.Errorf("resumed lines = %+v", lines)
+	}
+	_ = cp.Close()
+
+	dryRun := run
+	dryRun.Apply = false
+	if _, err = openCheckpoint(path, dryRun); err == nil || !strings.Contains(err.Error(), "another run") {
+		t.Errorf("checkpoint of an applying run opened for a dry run: %v", err)
+	}
+}
+
+func TestCheckpointName(t *testing.T) {
+	byRange := checkpointName(backfillRun{From: "2026-07-01", To: "2026-08-01", Apply: true})
+	if byRange != "backfill-2026-07-01_2026-08-01-apply.checkpoint" {
+		t.Errorf("range checkpoint = %q", byRange)
+	}
+	a := checkpointName(backfillRun{OrderIds: []int64{1, 23}})
+	b := checkpointName(backfillRun{OrderIds: []int64{12, 3}})
+	if a == b || !strings.HasSuffix(a, "-dry-run.checkpoint") {
+		t.Errorf("id list checkpoints %q and %q must differ and name the mode", a, b)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/api/request"
+	"zohoclient/internal/lib/sl"
+)
+
+// runPush pushes the listed orders, or those placed in a range of days, to Zoho.
+func runPush(args []string) int {
+	fs, common := newFlagSet("push")
+	orders := fs.String("order", "", "comma-separated order ids")
+	from := fs.String("from", "", "first day of the orders to push, YYYY-MM-DD")
+	to := fs.String("to", "", "last day, inclusive; defaults to --from")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if (*orders == "") == (*from == "") {
+		fmt.Fprintln(os.Stderr, "push: give either --order or --from")
+		return exitUsage
+	}
+
+	var ids []int64
+	var first, end time.Time
+	if *orders != "" {
+		var err error
+		if ids, err = parseOrderIds(*orders); err != nil {
+			fmt.Fprintln(os.Stderr, "push:", err)
+			return exitUsage
+		}
+	} else {
+		var err error
+		if first, end, err = parseDays(*from, *to); err != nil {
+			fmt.Fprintln(os.Stderr, "push:", err)
+			return exitUsage
+		}
+	}
+
+	a := newApp(common)
+	defer a.close()
+	if !a.requireSync() {
+		return exitFailed
+	}
+
+	if ids == nil {
+		var err error
+		if ids, err = a.core.OrdersPlacedBetween(first, end); err != nil {
+			a.log.With(sl.Err(err)).Error("list orders to push")
+			return exitFailed
+		}
+	}
+
+	failed := 0
+	for _, id := range ids {
+		zohoId, err := a.core.PushOrderToZoho(id)
+		log := a.log.With(slog.Int64("order_id", id))
+		if err != nil {
+			failed++
+			log.With(sl.Err(err)).Error("push failed")
+			continue
+		}
+		log.With(slog.String("zoho_id", zohoId)).Info("order pushed")
+	}
+	a.log.With(slog.Int("orders", len(ids)), slog.Int("failed", failed)).Info("push finished")
+	if failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runReconcile compares a range of days' orders with Zoho; any issue found fails the command,
+// so it can run from cron.
+func runReconcile(args []string) int {
+	fs, common := newFlagSet("reconcile")
+	yesterday := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	from := fs.String("from", yesterday, "first day of the orders to compare, YYYY-MM-DD")
+	to := fs.String("to", "", "last day, inclusive; defaults to --from")
+	report := fs.String("report", "", "write the issues to this file, JSON for .json, CSV otherwise")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	first, end, err := parseDays(*from, *to)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile:", err)
+		return exitUsage
+	}
+
+	a := newApp(common)
+	defer a.close()
+	if !a.requireSync() {
+		return exitFailed
+	}
+
+	res, err := a.core.Reconcile(entity.ReconcileOptions{
+		From:          first,
+		To:            end,
+		UnsyncedAfter: time.Duration(a.conf.Telegram.Reconcile.UnsyncedHours) * time.Hour,
+	})
+	if err != nil {
+		a.log.With(sl.Err(err)).Error("reconciliation failed")
+		return exitFailed
+	}
+
+	if *report != "" {
+		if err = writeReport(*report, &res); err != nil {
+			a.log.With(sl.Err(err)).Error("write reconciliation report")
+			return exitFailed
+		}
+		a.log.With(slog.String("file", *report)).Info("reconciliation report written")
+	}
+
+	if len(res.Issues) > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// report is a result that can be saved for review.
+type report interface {
+	WriteJSON(w io.Writer) error
+	WriteCSV(w io.Writer) error
+}
+
+// writeReport saves a result as JSON when the file name ends in .json, as CSV otherwise.
+func writeReport(path string, r report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		err = r.WriteJSON(f)
+	} else {
+		err = r.WriteCSV(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// runCustomersResync syncs every customer still without a Zoho contact.
+func runCustomersResync(args []string) int {
+	fs, common := newFlagSet("customers resync")
+	retryFailed := fs.Bool("failed", false, "also retry the customers marked [ERR] by earlier syncs")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	a := newApp(common)
+	defer a.close()
+	if !a.requireSync() {
+		return exitFailed
+	}
+
+	synced, failed, err := a.core.ResyncCustomers(*retryFailed)
+	a.log.With(slog.Int("synced", synced), slog.Int("failed", failed)).Info("customer resync finished")
+	if err != nil {
+		a.log.With(sl.Err(err)).Error("customer resync stopped")
+		return exitFailed
+	}
+	if failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runReplayWebhook applies a saved webhook body, as the HTTP endpoint received it, without
+// going through the API. Order updates are applied in turn and stop at the first failure.
+func runReplayWebhook(args []string) int {
+	fs, common := newFlagSet("replay-webhook")
+	b2b := fs.Bool("b2b", false, "the body is a B2B portal webhook, not a Zoho order update")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "replay-webhook: give one file, or - for stdin")
+		return exitUsage
+	}
+	body, err := readInput(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "replay-webhook:", err)
+		return exitFailed
+	}
+
+	// Decode before connecting anything, so a bad file costs nothing.
+	var updates []entity.ApiOrder
+	var payload entity.B2BWebhookPayload
+	if *b2b {
+		err = decodeB2BWebhook(body, &payload)
+	} else {
+		updates, err = decodeOrderWebhook(body)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "replay-webhook:", err)
+		return exitFailed
+	}
+
+	a := newApp(common)
+	defer a.close()
+	if a.db == nil {
+		a.log.Error("the database is required, see the errors above")
+		return exitFailed
+	}
+
+	if *b2b {
+		result, err := a.core.ProcessB2BWebhook(&payload)
+		if err != nil {
+			a.log.With(sl.Err(err)).Error("replay b2b webhook")
+			return exitFailed
+		}
+		a.log.With(slog.String("result", result)).Info("b2b webhook replayed")
+		return exitOK
+	}
+
+	for i := range updates {
+		if err = a.core.UpdateOrder(&updates[i]); err != nil {
+			a.log.With(
+				slog.String("zoho_id", updates[i].ZohoID),
+				slog.Int("applied", i),
+				slog.Int("total", len(updates)),
+				sl.Err(err),
+			).Error("replay order update")
+			return exitFailed
+		}
+	}
+	a.log.With(slog.Int("updates", len(updates))).Info("order webhook replayed")
+	return exitOK
+}
+
+// decodeOrderWebhook reads the body of /zoho/webhook/order: a request whose data is one order
+// update or an array of them.
+func decodeOrderWebhook(body []byte) ([]entity.ApiOrder, error) {
+	var req request.Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("decode request: %w", err)
+	}
+	var updates []entity.ApiOrder
+	if err := request.DecodeAndValidateArrayData(&req, nil, &updates); err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		return nil, fmt.Errorf("no order updates in the request data")
+	}
+	return updates, nil
+}
+
+func decodeB2BWebhook(body []byte, payload *entity.B2BWebhookPayload) error {
+	if err := json.Unmarshal(body, payload); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	return payload.Bind(nil)
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// parseOrderIds reads a comma- or whitespace-separated list of order ids.
+func parseOrderIds(list string) ([]int64, error) {
+	var ids []int64
+	for _, field := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid order id %q", field)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no order ids given")
+	}
+	return ids, nil
+}
@@ -1,218 +1,113 @@
 package main
 
 import (
-	"context"
-	"errors"
 	"flag"
-	"log/slog"
-	"net/http"
+	"fmt"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
-	"zohoclient/bot"
-	"zohoclient/impl/core"
-	"zohoclient/internal/config"
-	repository "zohoclient/internal/database/mongo"
-	"zohoclient/internal/database/sql"
-	"zohoclient/internal/http-server/api"
-	"zohoclient/internal/lib/logger"
-	"zohoclient/internal/lib/sl"
-	"zohoclient/internal/services"
 )
 
-func main() {
-	configPath := flag.String("conf", "config.yml", "path to config file")
-	logPath := flag.String("log", "/var/log/", "path to log file directory")
-	backfillDay := flag.String("backfill", "", "repair the per-line discount of orders placed on this day (YYYY-MM-DD) and exit; reports only unless -apply is given")
-	backfillApply := flag.Bool("apply", false, "with -backfill: actually write the corrected rows to Zoho")
-	flag.Parse()
-
-	conf := config.MustLoad(*configPath)
-	lg := logger.SetupLogger(conf.Env, *logPath)
-
-	// Initialize Telegram bot if enabled
-	var tgBot *bot.TgBot
-	if conf.Telegram.Enabled {
-		var err error
-		tgBot, err = bot.NewTgBot(conf.Telegram.BotName, conf.Telegram.ApiKey, conf.Telegram.AdminId, lg)
-		if err != nil {
-			lg.Error("failed to initialize telegram bot", slog.String("error", err.Error()))
-		} else {
-			lg = logger.SetupTelegramHandler(lg, tgBot, slog.LevelDebug)
-			lg.With(
-				slog.String("bot", conf.Telegram.BotName),
-			).Info("telegram bot initialized")
-
-			go func() {
-				if err := tgBot.Start(); err != nil {
-					lg.Error("telegram bot error", slog.String("error", err.Error()))
-				}
-			}()
-		}
-	}
-
-	lg.Info("starting zohoclient", slog.String("config", *configPath), slog.String("env", conf.Env))
-	lg.Debug("debug messages enabled")
-
-	handler := core.New(lg, *conf)
-
-	db, err := sql.NewSQLClient(conf, lg)
-	if err != nil {
-		lg.With(sl.Err(err)).Error("mysql client")
-	}
-	if db != nil {
-		handler.SetRepository(db)
-		lg.With(
-			slog.String("host", conf.SQL.HostName),
-			slog.String("port", conf.SQL.Port),
-			slog.String("user", conf.SQL.UserName),
-			slog.String("database", conf.SQL.Database),
-		).Info("mysql client initialized")
-
-		lg.Debug("mysql stats", slog.String("connections", db.Stats()))
-		go func() {
-			ticker := time.NewTicker(1 * time.Hour)
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ticker.C:
-					stats := db.Stats()
-					if stats != "" {
-						lg.Info("mysql", slog.String("stats", stats))
-					}
-				}
-			}
-		}()
-	}
+// Exit codes shared by the commands.
+const (
+	exitOK     = 0 // done, nothing failed
+	exitFailed = 1 // the command ran, but some orders failed or issues were found; or it could not run
+	exitUsage  = 2 // bad arguments
+)
 
-	zoho, err := services.NewZohoService(conf, lg)
-	if err != nil {
-		lg.Error("zoho service", sl.Err(err))
-	}
+// command is one subcommand; name may be two words, as in "backfill discounts".
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) int
+}
 
-	prodRepo, err := services.NewProductRepo(conf, lg)
-	if err != nil {
-		lg.With(sl.Err(err)).Error("product repository")
-	} else {
-		handler.SetProductRepository(prodRepo)
-		lg.With(
-			slog.String("url", conf.ProdRepo.ProdUrl),
-		).Info("product repository initialized")
-	}
+var commands = []command{
+	{"serve", "", "run the service: pollers, webhooks API and the Telegram bot (the default)", runServe},
+	{"push", "--order ID[,ID...] | --from YYYY-MM-DD [--to YYYY-MM-DD]", "push orders to Zoho", runPush},
+	{"backfill discounts", "--from YYYY-MM-DD [--to YYYY-MM-DD] | --order ID[,ID...] | --orders-file FILE [--apply] [--report FILE]", "repair the per-line discount of synced orders, resumably", runBackfillDiscounts},
+	{"reconcile", "[--from YYYY-MM-DD] [--to YYYY-MM-DD] [--report FILE]", "compare orders with Zoho and report the differences", runReconcile},
+	{"customers resync", "[--failed]", "sync every customer without a Zoho contact", runCustomersResync},
+	{"config check", "", "validate the config and try every configured backend", runConfigCheck},
+	{"migrate status", "", "list the OpenCart schema migrations and whether they are applied", runMigrateStatus},
+	{"migrate up", "[--to VERSION]", "apply the pending schema migrations", runMigrateUp},
+	{"migrate down", "[--to VERSION] [--force]", "revert the last schema migration, or all above VERSION", runMigrateDown},
+	{"replay-webhook", "[--b2b] FILE", "apply a saved Zoho (or B2B) webhook body; - reads stdin", runReplayWebhook},
+}
 
-	if zoho != nil {
-		handler.SetZoho(zoho)
-	} else {
-		lg.Error("zoho service not initialized")
-	}
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	mongoClient, err := repository.NewMongoClient(conf, lg)
-	if err != nil {
-		lg.Error("failed to create mongo client", sl.Err(err))
+// run picks the command named by the leading arguments and runs it with the rest. Without a
+// command, or when the first argument is a flag, it serves, as the service always has.
+func run(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runServe(args)
 	}
-	if mongoClient != nil {
-		handler.SetMongoRepository(mongoClient)
+	if args[0] == "help" {
+		usage(os.Stdout)
+		return exitOK
 	}
-
-	// Initialize SmartSender integration if enabled
-	if conf.SmartSender.Enabled {
-		smartSenderSvc, err := services.NewSmartSenderService(conf, lg)
-		if err != nil {
-			lg.Error("failed to create smartsender service", sl.Err(err))
-		} else if smartSenderSvc != nil {
-			handler.SetSmartSenderService(smartSenderSvc)
-			lg.Info("SmartSender service initialized")
-		}
-
-		zohoFuncSvc, err := services.NewZohoFunctionsService(conf, lg)
-		if err != nil {
-			lg.Error("failed to create zoho functions service", sl.Err(err))
-		} else if zohoFuncSvc != nil {
-			handler.SetZohoFunctionsService(zohoFuncSvc)
-			lg.Info("Zoho Functions service initialized")
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd.run(args[len(words):])
 		}
-
-		handler.SetSmartSenderPollInterval(time.Duration(conf.SmartSender.PollInterval) * time.Second)
 	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", strings.Join(args, " "))
+	usage(os.Stderr)
+	return exitUsage
+}
 
-	// One-shot maintenance mode: repair a day's orders and exit without starting the service,
-	// so the poller cannot interleave with the rewrite.
-	if *backfillDay != "" {
-		day, err := time.ParseInLocation(time.DateOnly, *backfillDay, time.Local)
-		if err != nil {
-			lg.With(sl.Err(err)).Error("invalid -backfill date, want YYYY-MM-DD")
-			os.Exit(1)
-		}
-		res, err := handler.BackfillOrderDiscounts(day, day.AddDate(0, 0, 1), *backfillApply)
-		if err != nil {
-			lg.With(sl.Err(err)).Error("backfill failed")
-			if db != nil {
-				db.Close()
-			}
-			os.Exit(1)
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: zohoclient [command] [-conf config.yml] [-log /var/log/] [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-20s %s\n", cmd.name, cmd.summary)
+		if cmd.args != "" {
+			fmt.Fprintf(w, "  %-20s   %s\n", "", cmd.args)
 		}
-		if !*backfillApply {
-			lg.Info("dry run: nothing was written, re-run with -apply to correct these orders")
-		}
-		if db != nil {
-			db.Close()
-		}
-		if res.Failed > 0 {
-			os.Exit(1)
-		}
-		return
-	}
-
-	handler.SetAuthKey(conf.Listen.ApiKey)
-	handler.Start()
-
-	// Create an HTTP server
-	server, err := api.New(conf, lg, handler)
-	if err != nil {
-		lg.Error("server create", sl.Err(err))
-		return
 	}
+	fmt.Fprintln(w, "\nrun \"zohoclient <command> -h\" for the flags of a command")
+}
 
-	// Channel to listen for shutdown signals
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+// newFlagSet returns the flag set of a command, with the common flags registered.
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return fs, addCommonFlags(fs)
+}
 
-	// Start HTTP server in goroutine
-	go func() {
-		if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			lg.Error("server start", sl.Err(err))
+// parseFlags parses a command's arguments and returns the exit code when it must stop: 0 for
+// -h, exitUsage on bad flags.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK, false
 		}
-	}()
-
-	// Wait for a shutdown signal
-	sig := <-quit
-	lg.Info("shutdown signal received", slog.String("signal", sig.String()))
-
-	// Create a shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Graceful shutdown sequence
-	lg.Info("shutting down services...")
-
-	// 1. Stop accepting new HTTP requests
-	if err := server.Shutdown(ctx); err != nil {
-		lg.Error("http server shutdown", sl.Err(err))
+		return exitUsage, false
 	}
+	return exitOK, true
+}
 
-	// 2. Stop order processing
-	handler.Stop()
-
-	// 3. Stop Telegram bot
-	if tgBot != nil {
-		tgBot.Stop()
+// parseDays reads a --from/--to pair of days, in the server's local time, and returns the
+// range [from, to+1 day). An empty to means the same day as from.
+func parseDays(from, to string) (time.Time, time.Time, error) {
+	first, err := time.ParseInLocation(time.DateOnly, from, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid --from %q, want YYYY-MM-DD", from)
 	}
-
-	// 4. Close database connection
-	if db != nil {
-		db.Close()
+	last := first
+	if to != "" {
+		last, err = time.ParseInLocation(time.DateOnly, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to %q, want YYYY-MM-DD", to)
+		}
+		if last.Before(first) {
+			return time.Time{}, time.Time{}, fmt.Errorf("--to %s is before --from %s", to, from)
+		}
 	}
-
-	lg.Info("service stopped gracefully")
+	return first, last.AddDate(0, 0, 1), nil
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/internal/database/sql"
+)
+
+func TestRun_UnknownCommand(t *testing.T) {
+	if code := run([]string{"backfill", "prices"}); code != exitUsage {
+		t.Errorf("run(backfill prices) = %d, want %d", code, exitUsage)
+	}
+	if code := run([]string{"migrate", "sideways"}); code != exitUsage {
+		t.Errorf("run(migrate sideways) = %d, want %d", code, exitUsage)
+	}
+	if code := run([]string{"push", "--order", "12", "--from", "2026-03-01"}); code != exitUsage {
+		t.Errorf("push with both --order and --from = %d, want %d", code, exitUsage)
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.Local) }
+
+	from, to, err := parseDays("2026-03-01", "")
+	if err != nil || !from.Equal(day(1)) || !to.Equal(day(2)) {
+		t.Errorf("one day = %s - %s, %v; want 03-01 - 03-02", from, to, err)
+	}
+	from, to, err = parseDays("2026-03-01", "2026-03-07")
+	if err != nil || !from.Equal(day(1)) || !to.Equal(day(8)) {
+		t.Errorf("range = %s - %s, %v; want 03-01 - 03-08", from, to, err)
+	}
+	for _, tt := range [][2]string{{"", ""}, {"2026-03-07", "2026-03-01"}, {"2026-03-01", "7 March"}} {
+		if _, _, err = parseDays(tt[0], tt[1]); err == nil {
+			t.Errorf("parseDays(%q, %q) accepted", tt[0], tt[1])
+		}
+	}
+}
+
+func TestDecodeOrderWebhook(t *testing.T) {
+	single := `{"data": {"zoho_id": "ZO-1", "status": "Нове", "grand_total": 468,
+		"ordered_items": [{"zoho_id": "P-1", "price": 234, "total": 468, "quantity": 2}]}}`
+	updates, err := decodeOrderWebhook([]byte(single))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0].ZohoID != "ZO-1" || updates[0].OrderedItems[0].Quantity != 2 {
+		t.Errorf("updates = %+v", updates)
+	}
+
+	for name, body := range map[string]string{
+		"not json":     `data: 1`,
+		"no data":      `{}`,
+		"invalid item": `{"data": [{"zoho_id": "ZO-1", "status": "Нове", "grand_total": 0, "ordered_items": []}]}`,
+	} {
+		if _, err = decodeOrderWebhook([]byte(body)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestWriteMigrationStatus(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local)
+	var out strings.Builder
+	writeMigrationStatus(&out, []sql.MigrationStatus{
+		{Version: 1, Name: "zoho_columns", Applied: true, AppliedAt: at, Present: true},
+		{Version: 2, Name: "wfsync_columns", Present: true},
+		{Version: 3, Name: "zoho_id_indexes"},
+	})
+	want := "1  zoho_columns     applied 2026-10-18 09:30:00\n" +
+		"2  wfsync_columns   pending, already in the schema\n" +
+		"3  zoho_id_indexes  pending\n"
+	if out.String() != want {
+		t.Errorf("status =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+	"zohoclient/internal/config"
+	"zohoclient/internal/database/sql"
+	"zohoclient/internal/lib/logger"
+	"zohoclient/internal/lib/sl"
+)
+
+// connectSchema connects to the OpenCart database without migrating it, which the service and
+// the other commands do on start.
+func connectSchema(common *commonFlags) (*sql.MySql, bool) {
+	conf := config.MustLoad(common.configPath)
+	lg := logger.SetupLogger(conf.Env, common.logPath)
+	db, err := sql.Connect(conf, lg)
+	if err != nil {
+		lg.With(sl.Err(err)).Error("mysql client")
+		return nil, false
+	}
+	return db, true
+}
+
+// runMigrateStatus lists the schema migrations; it fails when the schema lacks any of them.
+func runMigrateStatus(args []string) int {
+	fs, common := newFlagSet("migrate status")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	db, ok := connectSchema(common)
+	if !ok {
+		return exitFailed
+	}
+	defer db.Close()
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate status:", err)
+		return exitFailed
+	}
+	writeMigrationStatus(os.Stdout, statuses)
+	for _, st := range statuses {
+		if !st.Present {
+			return exitFailed
+		}
+	}
+	return exitOK
+}
+
+// writeMigrationStatus prints one line per migration. A migration whose changes are in the
+// schema without a record was applied by the startup of an older version, or by wfsync.
+func writeMigrationStatus(w io.Writer, statuses []sql.MigrationStatus) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, st := range statuses {
+		state := "pending"
+		switch {
+		case st.Applied && st.Present:
+			state = "applied " + st.AppliedAt.Local().Format(time.DateTime)
+		case st.Applied:
+			state = "applied " + st.AppliedAt.Local().Format(time.DateTime) + ", but missing from the schema"
+		case st.Present:
+			state = "pending, already in the schema"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", st.Version, st.Name, state)
+	}
+	_ = tw.Flush()
+}
+
+// runMigrateUp applies the pending migrations, all of them or up to --to.
+func runMigrateUp(args []string) int {
+	fs, common := newFlagSet("migrate up")
+	to := fs.Int("to", 0, "last migration to apply; 0 applies all")
+	return runMigrate(fs, common, args, func(db *sql.MySql) (int, error) {
+		return db.MigrateUp(*to)
+	})
+}
+
+// runMigrateDown reverts the last migration, or every one above --to. It refuses to drop the
+// sync state, the Zoho ids above all, unless --force is given.
+func runMigrateDown(args []string) int {
+	fs, common := newFlagSet("migrate down")
+	to := fs.Int("to", -1, "migration to go back to; 0 reverts all (default: revert the last one)")
+	force := fs.Bool("force", false, "drop the columns even when they hold sync data, such as the Zoho ids")
+	return runMigrate(fs, common, args, func(db *sql.MySql) (int, error) {
+		target := *to
+		if target < 0 {
+			statuses, err := db.MigrationStatus()
+			if err != nil {
+				return 0, err
+			}
+			target = 0
+			for _, st := range statuses {
+				if st.Applied {
+					target = st.Version - 1
+				}
+			}
+		}
+		n, err := db.MigrateDown(target, *force)
+		if errors.Is(err, sql.ErrMigrationDataLoss) {
+			err = fmt.Errorf("%w; without the Zoho ids the sync creates the orders and customers again, rerun with --force to drop it anyway", err)
+		}
+		return n, err
+	})
+}
+
+func runMigrate(fs *flag.FlagSet, common *commonFlags, args []string, migrate func(db *sql.MySql) (int, error)) int {
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "%s: unexpected arguments %v\n", fs.Name(), fs.Args())
+		return exitUsage
+	}
+	db, ok := connectSchema(common)
+	if !ok {
+		return exitFailed
+	}
+	defer db.Close()
+
+	n, err := migrate(db)
+	fmt.Printf("%d migrations done\n", n)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Name(), err)
+		return exitFailed
+	}
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Name(), err)
+		return exitFailed
+	}
+	writeMigrationStatus(os.Stdout, statuses)
+	return exitOK
+}
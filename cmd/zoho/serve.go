@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"zohoclient/bot"
+	"zohoclient/internal/http-server/api"
+	"zohoclient/internal/lib/sl"
+)
+
+// runServe runs the service until SIGINT or SIGTERM.
+func runServe(args []string) int {
+	fs, common := newFlagSet("serve")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	a := newApp(common)
+	lg, conf, handler, tgBot, webhooks := a.log, a.conf, a.core, a.tgBot, a.webhooks
+
+	if a.db != nil {
+		lg.Debug("mysql stats", slog.String("connections", a.db.Stats()))
+		go func() {
+			ticker := time.NewTicker(1 * time.Hour)
+			defer ticker.Stop()
+			for range ticker.C {
+				if stats := a.db.Stats(); stats != "" {
+					lg.Info("mysql", slog.String("stats", stats))
+				}
+			}
+		}()
+	}
+
+	handler.SetAuthKey(conf.Listen.ApiKey)
+	handler.Start()
+	if webhooks != nil {
+		webhooks.Start()
+	}
+
+	// The bot is started once the core is wired, so admin commands never see a half-built core.
+	if tgBot != nil {
+		tgBot.SetCore(handler)
+		handler.SetStatusNotifier(tgBot)
+		if report := conf.Telegram.Report; report.Enabled {
+			schedule, err := bot.ParseReportSchedule(report.Time, report.Timezone, report.Recipients)
+			if err != nil {
+				lg.With(sl.Err(err)).Error("daily report not scheduled")
+			} else {
+				tgBot.StartDailyReport(schedule)
+			}
+		}
+		reconcile := conf.Telegram.Reconcile
+		tgBot.SetReconcileUnsyncedAfter(time.Duration(reconcile.UnsyncedHours) * time.Hour)
+		if reconcile.Enabled {
+			schedule, err := bot.ParseReportSchedule(reconcile.Time, reconcile.Timezone, reconcile.Recipients)
+			if err != nil {
+				lg.With(sl.Err(err)).Error("reconciliation not scheduled")
+			} else {
+				tgBot.StartReconcile(bot.ReconcileJob{
+					Schedule:      schedule,
+					Days:          reconcile.Days,
+					UnsyncedAfter: time.Duration(reconcile.UnsyncedHours) * time.Hour,
+					ReportDir:     reconcile.ReportDir,
+				})
+			}
+		}
+		go func() {
+			if err := tgBot.Start(); err != nil {
+				lg.Error("telegram bot error", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
+	// Create an HTTP server
+	server, err := api.New(conf, lg, handler)
+	if err != nil {
+		lg.Error("server create", sl.Err(err))
+		return exitFailed
+	}
+
+	// Channel to listen for shutdown signals
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start HTTP server in goroutine
+	go func() {
+		if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			lg.Error("server start", sl.Err(err))
+		}
+	}()
+
+	// Wait for a shutdown signal
+	sig := <-quit
+	lg.Info("shutdown signal received", slog.String("signal", sig.String()))
+
+	// Create a shutdown context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Graceful shutdown sequence
+	lg.Info("shutting down services...")
+
+	// 1. Stop accepting new HTTP requests
+	if err := server.Shutdown(ctx); err != nil {
+		lg.Error("http server shutdown", sl.Err(err))
+	}
+
+	// 2. Stop order processing; undelivered webhooks stay in the log for the next start
+	handler.Stop()
+	if webhooks != nil {
+		webhooks.Stop()
+	}
+
+	// 3. Stop Telegram bot
+	if tgBot != nil {
+		tgBot.Stop()
+	}
+
+	// 4. Close database connections
+	a.close()
+
+	lg.Info("service stopped gracefully")
+	return exitOK
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ApiCall is one authenticated request to the service's API, kept per client for the audit log.
+// Scope is the scope the route requires, empty for routes that require none; Denied is set
+// when the client lacked it and got 403.
+type ApiCall struct {
+	Time        time.Time `json:"time" bson:"time"`
+	Client      string    `json:"client" bson:"client"`
+	TokenSource string    `json:"token_source,omitempty" bson:"token_source,omitempty"`
+	Method      string    `json:"method" bson:"method"`
+	Path        string    `json:"path" bson:"path"`
+	RequestID   string    `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	RemoteAddr  string    `json:"remote_addr,omitempty" bson:"remote_addr,omitempty"`
+	Scope       string    `json:"scope,omitempty" bson:"scope,omitempty"`
+	Denied      bool      `json:"denied" bson:"denied"`
+	Status      int       `json:"status" bson:"status"`
+	DurationMs  int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// ApiCallFilter selects calls for the audit API; empty fields match all.
+type ApiCallFilter struct {
+	Client string
+	Path   string
+	Status int
+	Denied bool // only calls refused for a missing scope
+	Since  time.Time
+	Limit  int
+}
+
+var ErrApiAuditNotAvailable = errors.New("api audit log not available")
@@ -8,7 +8,7 @@ import (
 type ApiOrder struct {
 	ZohoID       string           `json:"zoho_id" validate:"required"`
 	Status       string           `json:"status" validate:"required"`
-	GrandTotal   float64          `json:"grand_total" validate:"gt=0"`
+	GrandTotal   Amount           `json:"grand_total" validate:"gt=0"`
 	Coupon       string           `json:"coupon"`
 	OrderedItems []ApiOrderedItem `json:"ordered_items" validate:"required,dive"`
 	// ModifiedTime is Zoho's Sales_Orders.Modified_Time for the version that
@@ -18,11 +18,11 @@ type ApiOrder struct {
 }
 
 type ApiOrderedItem struct {
-	ZohoID   string  `json:"zoho_id" validate:"required"`
-	Price    float64 `json:"price" validate:"gt=0"`
-	Total    float64 `json:"total" validate:"gt=0"`
-	Quantity int     `json:"quantity" validate:"gt=0"`
-	Shipping bool    `json:"is_shipping"`
+	ZohoID   string `json:"zoho_id" validate:"required"`
+	Price    Amount `json:"price" validate:"gt=0"`
+	Total    Amount `json:"total" validate:"gt=0"`
+	Quantity int    `json:"quantity" validate:"gt=0"`
+	Shipping bool   `json:"is_shipping"`
 }
 
 func (o *ApiOrder) Bind(_ *http.Request) error {
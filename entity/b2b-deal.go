@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrB2BOrderInProgress is returned when a B2B webhook arrives for an order whose Deal is still
+// being created by an earlier delivery. The portal should retry later instead of creating a
+// second Deal.
+var ErrB2BOrderInProgress = errors.New("b2b order is being processed")
+
+// ErrB2BOrderNotFound is returned when an order_updated, order_cancelled or order_paid event
+// arrives for an order that has no Deal: the portal never delivered its order_confirmed.
+var ErrB2BOrderNotFound = errors.New("b2b order not found")
+
+// B2BDeal maps a B2B portal order (order_uid) to the Zoho Deal created for it. It is the
+// idempotency key of the B2B webhook: a redelivered order_confirmed finds its record here and
+// gets the existing Deal id back. ZohoID is empty while the Deal is still being created.
+// PaymentClaimedAt is set while an order_paid delivery creates the payment, the same way for
+// the payment as the claim itself is for the Deal.
+type B2BDeal struct {
+	OrderUID    string    `json:"order_uid" bson:"order_uid"`
+	OrderNumber string    `json:"order_number" bson:"order_number"`
+	ClientUID   string    `json:"client_uid" bson:"client_uid"`
+	ZohoID      string    `json:"zoho_id" bson:"zoho_id"`
+	PaymentID   string    `json:"payment_id,omitempty" bson:"payment_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+
+	PaymentClaimedAt time.Time `json:"-" bson:"payment_claimed_at,omitempty"`
+}
@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Outcomes of one order in a discount backfill.
+const (
+	BackfillCorrected    = "corrected"
+	BackfillWouldCorrect = "would_correct"
+	BackfillUnchanged    = "unchanged"
+	BackfillSkipped      = "skipped"
+	BackfillFailed       = "failed"
+)
+
+// BackfillOptions selects the orders a discount backfill repairs and how it reports.
+type BackfillOptions struct {
+	From time.Time
+	To   time.Time
+	// OrderIds, when set, selects these orders instead of the From-To range.
+	OrderIds []int64
+	Apply    bool // write the corrections; without it the run only reports
+	// Workers is how many orders are checked at once; below 1 means one.
+	Workers int
+	// Rate caps the Zoho calls per second across all workers; 0 leaves them unlimited.
+	Rate float64
+	// Checkpoint, when set, records each finished order; the orders it already holds are
+	// not checked again, except the failed ones.
+	Checkpoint BackfillCheckpoint
+	// Progress, when set, is called after each order with the number done and the total.
+	Progress func(done, total int)
+}
+
+// BackfillCheckpoint keeps the orders a backfill has finished, so an interrupted run resumes
+// where it stopped.
+type BackfillCheckpoint interface {
+	Finished() ([]BackfillOrder, error)
+	Save(order BackfillOrder) error
+}
+
+// BackfillOrder is the outcome of a backfill run for one order.
+type BackfillOrder struct {
+	OrderId     int64   `json:"order_id"`
+	ZohoID      string  `json:"zoho_id"`
+	Outcome     string  `json:"outcome"`
+	Rows        int     `json:"rows,omitempty"` // subform rows rewritten (or to rewrite)
+	DiscountWas float64 `json:"discount_was,omitempty"`
+	DiscountNow float64 `json:"discount_now,omitempty"`
+	Reason      string  `json:"reason,omitempty"` // why the order was skipped or failed
+	// Changes are the subform rows that differ, with the figures Zoho held and the right ones.
+	Changes []BackfillRowChange `json:"changes,omitempty"`
+}
+
+// BackfillRowChange is one subform row a backfill rewrites (or would).
+type BackfillRowChange struct {
+	RowID        string  `json:"row_id"`
+	ProductID    string  `json:"product_id"`
+	ListPriceWas Amount  `json:"list_price_was"`
+	ListPriceNow Amount  `json:"list_price_now"`
+	DiscountWas  float64 `json:"discount_was"`
+	DiscountNow  float64 `json:"discount_now"`
+}
+
+// BackfillResult is what a backfill run did, for the closing report.
+type BackfillResult struct {
+	Scanned   int             `json:"scanned"`
+	Corrected int             `json:"corrected"` // orders whose rows differ and were (or would be) rewritten
+	Unchanged int             `json:"unchanged"` // already correct
+	Skipped   int             `json:"skipped"`   // subform no longer matches what OpenCart holds
+	Failed    int             `json:"failed"`
+	Resumed   int             `json:"resumed"` // of the scanned, finished by an earlier run
+	Orders    []BackfillOrder `json:"orders"`
+}
+
+// Add counts one order's outcome and keeps its line.
+func (r *BackfillResult) Add(line BackfillOrder) {
+	r.Scanned++
+	switch line.Outcome {
+	case BackfillCorrected, BackfillWouldCorrect:
+		r.Corrected++
+	case BackfillUnchanged:
+		r.Unchanged++
+	case BackfillSkipped:
+		r.Skipped++
+	case BackfillFailed:
+		r.Failed++
+	}
+	r.Orders = append(r.Orders, line)
+}
+
+// WriteJSON writes the whole result as indented JSON.
+func (r *BackfillResult) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes one row per changed subform row, with the figures before and after, and one
+// row for each order without changes, under a header row.
+func (r *BackfillResult) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"order_id", "zoho_id", "outcome", "row_id", "product_id",
+		"list_price_was", "list_price_now", "discount_was", "discount_now", "reason"})
+	if err != nil {
+		return err
+	}
+	number := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for _, o := range r.Orders {
+		orderId := strconv.FormatInt(o.OrderId, 10)
+		if len(o.Changes) == 0 {
+			err = cw.Write([]string{orderId, o.ZohoID, o.Outcome, "", "", "", "", "", "", o.Reason})
+		}
+		for _, c := range o.Changes {
+			err = cw.Write([]string{orderId, o.ZohoID, o.Outcome, c.RowID, c.ProductID,
+				number(c.ListPriceWas.Float64()), number(c.ListPriceNow.Float64()), number(c.DiscountWas), number(c.DiscountNow), o.Reason})
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
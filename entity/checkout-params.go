@@ -28,16 +28,16 @@ const (
 type CheckoutParams struct {
 	ClientDetails *ClientDetails `json:"client_details" bson:"client_details" validate:"required"`
 	LineItems     []*LineItem    `json:"line_items" bson:"line_items" validate:"required,min=1,dive"`
-	Total         float64        `json:"total" bson:"total" validate:"required,min=1"`
-	SubTotal      float64        `json:"sub_total" bson:"sub_total"`
+	Total         Amount         `json:"total" bson:"total" validate:"required,min=1"`
+	SubTotal      Amount         `json:"sub_total" bson:"sub_total"`
 	ShippingTitle string         `json:"shipping_title,omitempty" bson:"shipping_title,omitempty"`
-	Shipping      float64        `json:"shipping,omitempty" bson:"shipping,omitempty"`
+	Shipping      Amount         `json:"shipping,omitempty" bson:"shipping,omitempty"`
 	CouponTitle   string         `json:"coupon_title,omitempty" bson:"coupon_title,omitempty"`
-	Coupon        float64        `json:"coupon,omitempty" bson:"coupon,omitempty"`
+	Coupon        Amount         `json:"coupon,omitempty" bson:"coupon,omitempty"`
 	TaxTitle      string         `json:"tax_title" bson:"tax_title"`
-	TaxValue      float64        `json:"tax_value" bson:"tax_value"`
+	TaxValue      Amount         `json:"tax_value" bson:"tax_value"`
 	DiscountTitle string         `json:"discount_title,omitempty" bson:"discount_title,omitempty"`
-	Discount      float64        `json:"discount,omitempty" bson:"discount,omitempty"`
+	Discount      Amount         `json:"discount,omitempty" bson:"discount,omitempty"`
 	Currency      string         `json:"currency" bson:"currency" validate:"required,oneof=PLN EUR"`
 	CurrencyValue float64        `json:"currency_value,omitempty" bson:"currency_value,omitempty"`
 	OrderId       int64          `json:"order_id" bson:"order_id" validate:"required"`
@@ -91,7 +91,7 @@ func (c *CheckoutParams) VatRate() float64 {
 		return r
 	}
 	if c.TaxValue > 0 && c.SubTotal > 0 {
-		return c.TaxValue / c.SubTotal
+		return c.TaxValue.Float64() / c.SubTotal.Float64()
 	}
 	return 0
 }
@@ -104,12 +104,12 @@ func (c *CheckoutParams) VatRate() float64 {
 // art. 29a ust. 7 pkt 2), so on a correctly configured shop order_total.tax must equal this.
 // Where it does not, the shop is declaring VAT on money the customer never paid — see
 // docs/OPENCART_VAT_BUG_RU.md. Callers use the gap as a health check, not to alter the totals.
-func (c *CheckoutParams) LawfulTax() float64 {
+func (c *CheckoutParams) LawfulTax() Amount {
 	r := c.VatRate()
 	if r <= 0 {
 		return 0
 	}
-	return (c.Total - c.Shipping) * r / (1 + r)
+	return (c.Total - c.Shipping).Mul(r / (1 + r))
 }
 
 // nominalTaxRate returns the order's per-unit VAT rate as a decimal (e.g. 0.23), taken from
@@ -119,7 +119,7 @@ func (c *CheckoutParams) LawfulTax() float64 {
 func (c *CheckoutParams) nominalTaxRate() float64 {
 	for _, li := range c.LineItems {
 		if li != nil && li.Price > 0 && li.Tax > 0 {
-			return li.Tax / li.Price
+			return li.Tax.Float64() / li.Price.Float64()
 		}
 	}
 	return 0
@@ -129,8 +129,8 @@ func (c *CheckoutParams) nominalTaxRate() float64 {
 // Base total = sum of LineItem.Total (without tax).
 // Discount = Base total - (Total - TaxValue - Shipping).
 // Returns: discount value, discount percentage (e.g., 10.0 for 10%).
-func (c *CheckoutParams) GetDiscount() (float64, float64) {
-	var baseTotal float64
+func (c *CheckoutParams) GetDiscount() (Amount, float64) {
+	var baseTotal Amount
 	for _, item := range c.LineItems {
 		baseTotal += item.Total
 	}
@@ -139,7 +139,7 @@ func (c *CheckoutParams) GetDiscount() (float64, float64) {
 	}
 	actualTotal := c.Total - c.TaxValue - c.Shipping
 	discount := baseTotal - actualTotal
-	percent := (discount / baseTotal) * 100
+	percent := discount.Float64() / baseTotal.Float64() * 100
 	return discount, percent
 }
 
@@ -149,13 +149,13 @@ type LineItem struct {
 	Uid    string  `json:"uid,omitempty" bson:"uid"`
 	ZohoId string  `json:"zoho_id,omitempty" bson:"zoho_id"`
 	Qty    float64 `json:"qty" validate:"required,min=1"`
-	Price  float64 `json:"price" validate:"required,min=1"`
-	Tax    float64 `json:"tax" validate:"required,min=1"`
-	Total  float64 `json:"total" validate:"required,min=1"`
+	Price  Amount  `json:"price" validate:"required,min=1"`
+	Tax    Amount  `json:"tax" validate:"required,min=1"`
+	Total  Amount  `json:"total" validate:"required,min=1"`
 	Sku    string  `json:"sku,omitempty" bson:"sku"`
 	// MasterPrice is the product's ordinary catalogue price. When it exceeds Price,
 	// the line is treated as carrying a per-line "special price" discount on Zoho sync.
-	MasterPrice float64 `json:"master_price,omitempty" bson:"master_price,omitempty"`
+	MasterPrice Amount `json:"master_price,omitempty" bson:"master_price,omitempty"`
 }
 
 type ClientDetails struct {
@@ -71,10 +71,10 @@ func TestTaxRate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &CheckoutParams{
-				Total:    tt.total,
-				SubTotal: tt.subTotal,
-				TaxValue: tt.taxValue,
-				Shipping: tt.shipping,
+				Total:    AmountOf(tt.total),
+				SubTotal: AmountOf(tt.subTotal),
+				TaxValue: AmountOf(tt.taxValue),
+				Shipping: AmountOf(tt.shipping),
 			}
 			result := c.TaxRate()
 			if diff := result - tt.expected; diff > 0.01 || diff < -0.01 {
@@ -88,12 +88,12 @@ func TestTaxRate(t *testing.T) {
 // Both of these are 23% orders; only the totals differ.
 func TestVatRateIgnoresReductions(t *testing.T) {
 	legacy := &CheckoutParams{ // order 16939 as OpenCart charged it (tax on the full subtotal)
-		SubTotal: 422.764, TaxValue: 97.2357, Coupon: -42.2764, Total: 477.7233,
-		LineItems: []*LineItem{{Price: 52.8455, Tax: 12.1545, Qty: 8, Total: 422.764}},
+		SubTotal: AmountOf(422.764), TaxValue: AmountOf(97.2357), Coupon: AmountOf(-42.2764), Total: AmountOf(477.7233),
+		LineItems: []*LineItem{{Price: AmountOf(52.8455), Tax: AmountOf(12.1545), Qty: 8, Total: AmountOf(422.764)}},
 	}
 	fixed := &CheckoutParams{ // the same order once OpenCart reduces the taxable base
-		SubTotal: 422.764, TaxValue: 87.5121, Coupon: -42.2764, Total: 468.00,
-		LineItems: []*LineItem{{Price: 52.8455, Tax: 12.1545, Qty: 8, Total: 422.764}},
+		SubTotal: AmountOf(422.764), TaxValue: AmountOf(87.5121), Coupon: AmountOf(-42.2764), Total: AmountOf(468.00),
+		LineItems: []*LineItem{{Price: AmountOf(52.8455), Tax: AmountOf(12.1545), Qty: 8, Total: AmountOf(422.764)}},
 	}
 	for name, c := range map[string]*CheckoutParams{"legacy": legacy, "fixed": fixed} {
 		if got := c.TaxRate(); got < 22.99 || got > 23.01 {
@@ -116,25 +116,25 @@ func TestLawfulTax(t *testing.T) {
 	}{
 		{
 			name: "no reductions - tax_value already lawful", total: 1230, taxValue: 230,
-			lines:      []*LineItem{{Price: 100, Tax: 23, Qty: 10, Total: 1000}},
+			lines:      []*LineItem{{Price: AmountOf(100), Tax: AmountOf(23), Qty: 10, Total: AmountOf(1000)}},
 			wantLawful: 230, wantHealthy: true,
 		},
 		{
 			// Order 16939 as charged: 97.24 declared, but only 89.33 was collected as VAT.
 			name: "order 16939 - OpenCart over-declares", total: 477.7233, taxValue: 97.2357,
-			lines:      []*LineItem{{Price: 52.8455, Tax: 12.1545, Qty: 8, Total: 422.764}},
+			lines:      []*LineItem{{Price: AmountOf(52.8455), Tax: AmountOf(12.1545), Qty: 8, Total: AmountOf(422.764)}},
 			wantLawful: 89.3304, wantHealthy: false,
 		},
 		{
 			// Order 16942 as charged: 100.29 zl of VAT declared but never collected.
 			name: "order 16942 - OpenCart over-declares", total: 2560.6872, taxValue: 579.1131,
-			lines:      []*LineItem{{Price: 52.8455, Tax: 12.1545, Qty: 1, Total: 52.8455}},
+			lines:      []*LineItem{{Price: AmountOf(52.8455), Tax: AmountOf(12.1545), Qty: 1, Total: AmountOf(52.8455)}},
 			wantLawful: 478.8277, wantHealthy: false,
 		},
 		{
 			// The same order once OpenCart reduces the taxable base: the gap closes.
 			name: "order 16942 after the OpenCart fix", total: 2508.5670, taxValue: 469.0816,
-			lines:      []*LineItem{{Price: 52.8455, Tax: 12.1545, Qty: 1, Total: 52.8455}},
+			lines:      []*LineItem{{Price: AmountOf(52.8455), Tax: AmountOf(12.1545), Qty: 1, Total: AmountOf(52.8455)}},
 			wantLawful: 469.0816, wantHealthy: true,
 		},
 		{
@@ -142,28 +142,28 @@ func TestLawfulTax(t *testing.T) {
 			// 1000 net + 230 VAT + 39.90 carriage. Counting it would inflate the lawful VAT to
 			// 237.46 and make a healthy order look under-declared.
 			name: "shipping is outside the taxable base", total: 1269.90, taxValue: 230, shipping: 39.90,
-			lines:      []*LineItem{{Price: 100, Tax: 23, Qty: 10, Total: 1000}},
+			lines:      []*LineItem{{Price: AmountOf(100), Tax: AmountOf(23), Qty: 10, Total: AmountOf(1000)}},
 			wantLawful: 230, wantHealthy: true,
 		},
 		{
 			// Order 17103: 21% Spanish order, 10% coupon, DHL 39.90. Healthy — OpenCart taxed the
 			// discounted base — and it must stay healthy once shipping is excluded.
 			name: "order 17103 - shipping plus coupon, healthy", total: 996.0936, taxValue: 165.9510, shipping: 39.90,
-			lines:      []*LineItem{{Price: 24.3902, Tax: 5.1219, Qty: 30, Total: 731.706}},
+			lines:      []*LineItem{{Price: AmountOf(24.3902), Tax: AmountOf(5.1219), Qty: 30, Total: AmountOf(731.706)}},
 			wantLawful: 165.9510, wantHealthy: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &CheckoutParams{Total: tt.total, TaxValue: tt.taxValue, Shipping: tt.shipping, LineItems: tt.lines}
+			c := &CheckoutParams{Total: AmountOf(tt.total), TaxValue: AmountOf(tt.taxValue), Shipping: AmountOf(tt.shipping), LineItems: tt.lines}
 			got := c.LawfulTax()
-			if diff := got - tt.wantLawful; diff > 0.01 || diff < -0.01 {
-				t.Errorf("LawfulTax() = %.4f, want %.4f", got, tt.wantLawful)
+			if diff := got.Float64() - tt.wantLawful; diff > 0.01 || diff < -0.01 {
+				t.Errorf("LawfulTax() = %s, want %.4f", got, tt.wantLawful)
 			}
-			healthy := got-c.TaxValue < 0.01 && c.TaxValue-got < 0.01
+			healthy := (got - c.TaxValue).Abs() < Cent
 			if healthy != tt.wantHealthy {
-				t.Errorf("health check = %v, want %v (tax_value %.4f vs lawful %.4f)", healthy, tt.wantHealthy, c.TaxValue, got)
+				t.Errorf("health check = %v, want %v (tax_value %s vs lawful %s)", healthy, tt.wantHealthy, c.TaxValue, got)
 			}
 		})
 	}
@@ -184,7 +184,7 @@ func TestDiscountPercent(t *testing.T) {
 			total:           123.00, // 100 base + 23 tax
 			taxValue:        23.00,
 			shipping:        0,
-			lineItems:       []*LineItem{{Total: 100.00}},
+			lineItems:       []*LineItem{{Total: AmountOf(100.00)}},
 			expectedValue:   0,
 			expectedPercent: 0,
 		},
@@ -193,7 +193,7 @@ func TestDiscountPercent(t *testing.T) {
 			total:           113.00, // 90 after discount + 23 tax
 			taxValue:        23.00,
 			shipping:        0,
-			lineItems:       []*LineItem{{Total: 100.00}},
+			lineItems:       []*LineItem{{Total: AmountOf(100.00)}},
 			expectedValue:   10.0,
 			expectedPercent: 10.0,
 		},
@@ -202,7 +202,7 @@ func TestDiscountPercent(t *testing.T) {
 			total:           103.00, // 80 after discount + 23 tax + 0 shipping (shipping not in lineItems)
 			taxValue:        23.00,
 			shipping:        0,
-			lineItems:       []*LineItem{{Total: 100.00}},
+			lineItems:       []*LineItem{{Total: AmountOf(100.00)}},
 			expectedValue:   20.0,
 			expectedPercent: 20.0,
 		},
@@ -211,7 +211,7 @@ func TestDiscountPercent(t *testing.T) {
 			total:           123.00, // 90 after discount + 23 tax + 10 shipping
 			taxValue:        23.00,
 			shipping:        10.00,
-			lineItems:       []*LineItem{{Total: 100.00}},
+			lineItems:       []*LineItem{{Total: AmountOf(100.00)}},
 			expectedValue:   10.0,
 			expectedPercent: 10.0,
 		},
@@ -220,7 +220,7 @@ func TestDiscountPercent(t *testing.T) {
 			total:           223.00, // 180 after discount + 43 tax
 			taxValue:        43.00,
 			shipping:        0,
-			lineItems:       []*LineItem{{Total: 100.00}, {Total: 100.00}},
+			lineItems:       []*LineItem{{Total: AmountOf(100.00)}, {Total: AmountOf(100.00)}},
 			expectedValue:   20.0,
 			expectedPercent: 10.0, // (200 - 180) / 200 * 100 = 10%
 		},
@@ -247,13 +247,13 @@ func TestDiscountPercent(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &CheckoutParams{
-				Total:     tt.total,
-				TaxValue:  tt.taxValue,
-				Shipping:  tt.shipping,
+				Total:     AmountOf(tt.total),
+				TaxValue:  AmountOf(tt.taxValue),
+				Shipping:  AmountOf(tt.shipping),
 				LineItems: tt.lineItems,
 			}
 			discountValue, discountPercent := c.GetDiscount()
-			if diff := discountValue - tt.expectedValue; diff > 0.01 || diff < -0.01 {
+			if diff := discountValue.Float64() - tt.expectedValue; diff > 0.01 || diff < -0.01 {
 				t.Errorf("DiscountPercent() value = %v, want %v", discountValue, tt.expectedValue)
 			}
 			if diff := discountPercent - tt.expectedPercent; diff > 0.01 || diff < -0.01 {
@@ -513,7 +513,7 @@ func TestValidate(t *testing.T) {
 		{
 			name: "valid order",
 			params: CheckoutParams{
-				LineItems:     []*LineItem{{Name: "Product", Qty: 1, Price: 10.00}},
+				LineItems:     []*LineItem{{Name: "Product", Qty: 1, Price: AmountOf(10.00)}},
 				ClientDetails: &ClientDetails{FirstName: "John", LastName: "Doe"},
 			},
 			expectErr: false,
@@ -529,7 +529,7 @@ func TestValidate(t *testing.T) {
 		{
 			name: "nil client details",
 			params: CheckoutParams{
-				LineItems:     []*LineItem{{Name: "Product", Qty: 1, Price: 10.00}},
+				LineItems:     []*LineItem{{Name: "Product", Qty: 1, Price: AmountOf(10.00)}},
 				ClientDetails: nil,
 			},
 			expectErr: true,
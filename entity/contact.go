@@ -8,4 +8,6 @@ type Contact struct {
 	Country          string `json:"field,omitempty"`
 	Phone            string `json:"Phone,omitempty"`
 	CustomerCategory string `json:"customer_category,omitempty"`
+	// AccountName links a B2B contact to its company Account.
+	AccountName *ZohoAccountRef `json:"Account_Name,omitempty"`
 }
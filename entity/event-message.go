@@ -6,6 +6,14 @@ import (
 	"zohoclient/internal/lib/validate"
 )
 
+// Event types the sync core emits through the MessageService.
+const (
+	EventOrderCreated  = "order_created"
+	EventPaymentLinked = "payment_linked"
+	EventOrderUpdated  = "order_updated" // a Zoho webhook applied to an OpenCart order
+	EventSyncFailure   = "sync_failure"
+)
+
 type EventMessage struct {
 	Sender   *User       `json:"sender,omitempty" bson:"sender"`
 	Type     string      `json:"type" bson:"type" validate:"required,min=1"`
@@ -14,8 +22,23 @@ type EventMessage struct {
 	Username string      `json:"username,omitempty" bson:"username"`
 	Text     string      `json:"text,omitempty" bson:"text"`
 	Payload  interface{} `json:"payload,omitempty" bson:"payload"`
+	// Shadow marks events of Zoho shadow mode: the Zoho records they name were never created.
+	Shadow bool `json:"shadow,omitempty" bson:"shadow,omitempty"`
 }
 
 func (m *EventMessage) Bind(_ *http.Request) error {
 	return validate.Struct(m)
 }
+
+// OrderEvent is the payload of the order events.
+type OrderEvent struct {
+	OrderId    int64  `json:"order_id,omitempty"`
+	CustomerId int64  `json:"customer_id,omitempty"`
+	ZohoId     string `json:"zoho_id,omitempty"`
+	Email      string `json:"email,omitempty"`
+	PaymentId  string `json:"payment_id,omitempty"`
+	StatusId   int    `json:"status_id,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+	Total      Amount `json:"total,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,260 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"zohoclient/internal/lib/validate"
+)
+
+// Amount is a sum of money held exactly, as a whole number of ten-thousandths of the currency
+// unit. Four places is what both sides store: OpenCart keeps decimal(15,4) and Zoho takes
+// List_Price and the line Total to 4 decimals. Amounts add, subtract and compare as plain
+// integers; only multiplying by a rate or a quantity rounds, to the nearest ten-thousandth.
+//
+// In JSON an Amount is a plain number, as Zoho's currency and decimal fields expect.
+type Amount int64
+
+const (
+	// Cent is one hundredth of the currency unit.
+	Cent Amount = 100
+	// Unit is one whole unit of the currency: a zloty, a euro.
+	Unit Amount = 10000
+
+	amountPlaces = 4
+)
+
+func init() {
+	// Validation tags (min=1, gt=0) keep meaning whole currency units, not ten-thousandths.
+	validate.RegisterType(func(v reflect.Value) any {
+		return v.Interface().(Amount).Float64()
+	}, Amount(0))
+}
+
+// AmountOf converts a float to an Amount, rounding half away from zero to 4 places.
+func AmountOf(v float64) Amount {
+	return Amount(math.Round(v * float64(Unit)))
+}
+
+// ParseAmount reads a decimal number such as "125.70" or "-10". The text is taken digit by
+// digit, so no binary rounding is involved; digits past the fourth place round half away from
+// zero. Exponent notation, as JSON encoders print very small and very large floats, goes
+// through float64.
+func ParseAmount(s string) (Amount, error) {
+	text := strings.TrimSpace(s)
+	if strings.ContainsAny(text, "eE") {
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) || math.Abs(v) >= math.MaxInt64/float64(Unit) {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		return AmountOf(v), nil
+	}
+
+	negative := false
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) || len(whole) > 14 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	var v int64
+	for _, d := range whole {
+		v = v*10 + int64(d-'0')
+	}
+	for i := 0; i < amountPlaces; i++ {
+		v *= 10
+		if i < len(fraction) {
+			v += int64(fraction[i] - '0')
+		}
+	}
+	if len(fraction) > amountPlaces && fraction[amountPlaces] >= '5' {
+		v++
+	}
+	if negative {
+		v = -v
+	}
+	return Amount(v), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Float64 returns the amount as a float, for rates, percentages and the few consumers still
+// working in floats.
+func (a Amount) Float64() float64 {
+	return float64(a) / float64(Unit)
+}
+
+// Round rounds the amount half away from zero to the given number of decimal places, 0 to 4.
+func (a Amount) Round(places int) Amount {
+	if places >= amountPlaces {
+		return a
+	}
+	step := Amount(math.Pow10(amountPlaces - max(places, 0)))
+	half := step / 2
+	if a < 0 {
+		return -((-a + half) / step * step)
+	}
+	return (a + half) / step * step
+}
+
+// Abs returns the amount without its sign.
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Mul multiplies the amount by a quantity or a rate, rounding to 4 places.
+func (a Amount) Mul(f float64) Amount {
+	return Amount(math.Round(float64(a) * f))
+}
+
+// Div divides the amount by a quantity or a rate, rounding to 4 places. A zero divisor leaves
+// the amount as it is.
+func (a Amount) Div(f float64) Amount {
+	if f == 0 {
+		return a
+	}
+	return Amount(math.Round(float64(a) / f))
+}
+
+// Scale multiplies the amount by the ratio num/den of two amounts, such as the part of an order
+// left after its reductions, rounding once, half away from zero, to 4 places. The product is
+// taken in full, so no precision is lost on the way. A zero den leaves the amount as it is.
+func (a Amount) Scale(num, den Amount) Amount {
+	if den == 0 {
+		return a
+	}
+	product := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(num)))
+	divisor := big.NewInt(int64(den))
+	quotient, remainder := new(big.Int).QuoRem(product, divisor, new(big.Int))
+	if new(big.Int).Abs(remainder.Lsh(remainder, 1)).Cmp(new(big.Int).Abs(divisor)) >= 0 {
+		if product.Sign() != divisor.Sign() {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return Amount(quotient.Int64())
+}
+
+// String formats the amount with two decimals, or with as many as it needs up to four:
+// "125.70", "24.3902", "-10.00".
+func (a Amount) String() string {
+	return a.format(2)
+}
+
+// format prints the amount with at least minPlaces decimals, dropping the trailing zeros
+// beyond them.
+func (a Amount) format(minPlaces int) string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	fraction := fmt.Sprintf("%04d", v%int64(Unit))
+	for len(fraction) > minPlaces && fraction[len(fraction)-1] == '0' {
+		fraction = fraction[:len(fraction)-1]
+	}
+	if fraction == "" {
+		return fmt.Sprintf("%s%d", sign, v/int64(Unit))
+	}
+	return fmt.Sprintf("%s%d.%s", sign, v/int64(Unit), fraction)
+}
+
+// MarshalJSON writes the amount as a JSON number with no trailing zeros, the way a float of the
+// same value would be written.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.format(0)), nil
+}
+
+// UnmarshalJSON reads a JSON number, a number in a string, or null, which leaves the amount
+// zero — Zoho returns null for currency fields nobody has filled in.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" || text == "" {
+		*a = 0
+		return nil
+	}
+	v, err := ParseAmount(text)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Scan reads a DECIMAL column, which the MySQL driver returns as text, without passing it
+// through a float.
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		return a.scanText(string(v))
+	case string:
+		return a.scanText(v)
+	case int64:
+		*a = Amount(v) * Unit
+	case float64:
+		*a = AmountOf(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Amount", src)
+	}
+	return nil
+}
+
+func (a *Amount) scanText(s string) error {
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value writes the amount as decimal text, so a DECIMAL column stores it exactly.
+func (a Amount) Value() (driver.Value, error) {
+	return a.format(amountPlaces), nil
+}
+
+// Money is an amount in a named currency, for a sum handled on its own: a total in a message,
+// a report line, the currency totals worked out for a B2B order.
+//
+// The records exchanged with Zoho, OpenCart and the checkout API keep bare Amount fields next to
+// one Currency field instead. Their JSON and columns are set by the other side, with the
+// currency given once per record for all its sums; Money fields would change that layout into
+// an object per sum. Such a record is turned into Money where a sum leaves it, with NewMoney.
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewMoney returns the amount in the given currency.
+func NewMoney(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// String formats the money to the cent, as "125.70 PLN".
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.Round(2).String()
+	}
+	return m.Amount.Round(2).String() + " " + m.Currency
+}
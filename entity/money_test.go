@@ -0,0 +1,190 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"zohoclient/internal/lib/validate"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "125.70", want: 1257000},
+		{in: "24.3902", want: 243902},
+		{in: "-10", want: -100000},
+		{in: "+3.5", want: 35000},
+		{in: ".5", want: 5000},
+		{in: "0", want: 0},
+		// Past the fourth place the digit decides, not a binary approximation of it.
+		{in: "24.390243902439025", want: 243902},
+		{in: "0.00005", want: 1},
+		{in: "-0.00005", want: -1},
+		{in: "2.00004999", want: 20000},
+		{in: "1e-05", want: 0},
+		{in: "1.5E+3", want: 15000000},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "12,50", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "123456789012345", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAmount(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAmount(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAmount(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmount_Arithmetic(t *testing.T) {
+	// The sum a float gets wrong.
+	if sum := AmountOf(0.1) + AmountOf(0.2); sum != AmountOf(0.3) {
+		t.Errorf("0.1 + 0.2 = %s, want 0.30", sum)
+	}
+	if got := AmountOf(24.3902).Mul(30); got != AmountOf(731.706) {
+		t.Errorf("24.3902 x 30 = %s, want 731.706", got)
+	}
+	if got := AmountOf(100).Div(3); got != AmountOf(33.3333) {
+		t.Errorf("100 / 3 = %s, want 33.3333", got)
+	}
+	if got := AmountOf(100).Div(0); got != AmountOf(100) {
+		t.Errorf("100 / 0 = %s, want 100 unchanged", got)
+	}
+
+	// 24.3902 x 700 / 1000 in one step, without rounding the ratio first.
+	if got := AmountOf(24.3902).Scale(AmountOf(700), AmountOf(1000)); got != AmountOf(17.0731) {
+		t.Errorf("24.3902 x 0.7 = %s, want 17.0731", got)
+	}
+	if got := AmountOf(1).Scale(AmountOf(2), AmountOf(3)); got != AmountOf(0.6667) {
+		t.Errorf("1 x 2/3 = %s, want 0.6667", got)
+	}
+	if got := AmountOf(-1).Scale(AmountOf(2), AmountOf(3)); got != AmountOf(-0.6667) {
+		t.Errorf("-1 x 2/3 = %s, want -0.6667", got)
+	}
+	if got := AmountOf(0.0001).Scale(1, 2); got != AmountOf(0.0001) {
+		t.Errorf("half a ten-thousandth = %s, want it rounded away from zero", got)
+	}
+	if got := AmountOf(5).Scale(AmountOf(1), 0); got != AmountOf(5) {
+		t.Errorf("5 x 1/0 = %s, want 5 unchanged", got)
+	}
+
+	rounds := []struct {
+		in     Amount
+		places int
+		want   Amount
+	}{
+		{AmountOf(1.005), 2, AmountOf(1.01)},
+		{AmountOf(-1.005), 2, AmountOf(-1.01)},
+		{AmountOf(1.0049), 2, AmountOf(1)},
+		{AmountOf(2.5), 0, AmountOf(3)},
+		{AmountOf(24.3902), 4, AmountOf(24.3902)},
+	}
+	for _, tt := range rounds {
+		if got := tt.in.Round(tt.places); got != tt.want {
+			t.Errorf("%s.Round(%d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	tests := map[Amount]string{
+		AmountOf(125.7):    "125.70",
+		AmountOf(24.3902):  "24.3902",
+		AmountOf(-10):      "-10.00",
+		AmountOf(-0.05):    "-0.05",
+		AmountOf(731.706):  "731.706",
+		0:                  "0.00",
+		AmountOf(1000000):  "1000000.00",
+		AmountOf(0.0001):   "0.0001",
+		AmountOf(-87.8047): "-87.8047",
+	}
+	for in, want := range tests {
+		if got := in.String(); got != want {
+			t.Errorf("String(%d) = %q, want %q", int64(in), got, want)
+		}
+	}
+	if got := NewMoney(AmountOf(468.004), CurrencyPLN).String(); got != "468.00 PLN" {
+		t.Errorf("money = %q, want 468.00 PLN", got)
+	}
+}
+
+// Zoho's currency fields are JSON numbers: an Amount must go out as one, and come back from a
+// number, a numeric string, or null.
+func TestAmount_JSON(t *testing.T) {
+	item := OrderedItem{Quantity: 2, ListPrice: AmountOf(24.3902), DiscountP: 10, Total: AmountOf(40)}
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Product_Name":{"name":"","id":""},"Quantity":2,"GetDiscount":0,"DiscountP":10,"List_Price":24.3902,"Total":40}`
+	if string(data) != want {
+		t.Errorf("marshal = %s\nwant      %s", data, want)
+	}
+
+	var record ZohoOrderRecord
+	err = json.Unmarshal([]byte(`{"Grand_Total":"93.90","Ordered_Items":[{"List_Price":24.390243902439025,"Total":null}]}`), &record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.GrandTotal != AmountOf(93.9) || record.OrderedItems[0].ListPrice != AmountOf(24.3902) || record.OrderedItems[0].Total != 0 {
+		t.Errorf("unmarshal = %+v", record)
+	}
+
+	if err = json.Unmarshal([]byte(`{"grand_total":"ten"}`), &ApiOrder{}); err == nil {
+		t.Error("unmarshal of a non-number succeeded")
+	}
+}
+
+func TestAmount_SQL(t *testing.T) {
+	var a Amount
+	for src, want := range map[any]Amount{
+		"125.7000":      AmountOf(125.7),
+		int64(3):        AmountOf(3),
+		float64(1.2345): AmountOf(1.2345),
+		nil:             0,
+	} {
+		if err := a.Scan(src); err != nil || a != want {
+			t.Errorf("Scan(%v) = %s, %v; want %s", src, a, err, want)
+		}
+	}
+	if err := a.Scan([]byte("-42.3764")); err != nil || a != AmountOf(-42.3764) {
+		t.Errorf("Scan([]byte) = %s, %v", a, err)
+	}
+	if err := a.Scan(true); err == nil {
+		t.Error("Scan(bool) succeeded")
+	}
+
+	if v, err := AmountOf(-87.8).Value(); err != nil || v != "-87.8000" {
+		t.Errorf("Value() = %v, %v; want -87.8000", v, err)
+	}
+}
+
+// The validation tags keep their meaning in currency units.
+func TestAmount_Validate(t *testing.T) {
+	item := LineItem{Name: "P", Qty: 1, Price: AmountOf(0.5), Tax: AmountOf(1.15), Total: AmountOf(5)}
+	if err := validate.Struct(item); err == nil {
+		t.Error("a price of 0.50 passed min=1")
+	}
+	item.Price = AmountOf(5)
+	if err := validate.Struct(item); err != nil {
+		t.Errorf("valid line item: %v", err)
+	}
+
+	order := ApiOrder{ZohoID: "z", Status: "s", OrderedItems: []ApiOrderedItem{{ZohoID: "z1", Price: AmountOf(0.01), Total: AmountOf(0.01), Quantity: 1}}}
+	if err := validate.Struct(order); err == nil {
+		t.Error("a zero grand total passed gt=0")
+	}
+	order.GrandTotal = AmountOf(0.01)
+	if err := validate.Struct(order); err != nil {
+		t.Errorf("valid api order: %v", err)
+	}
+}
@@ -1,15 +1,100 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
 
-type MongoOrder struct {
-	CreationDate time.Time `json:"creation_date" bson:"creation_date"`
-	OrderID      int64     `json:"order_id" bson:"order_id"`
-	Versions     []Version `json:"versions" bson:"versions"`
-}
+// Version directions: which way the stored payload travelled.
+const (
+	VersionOutbound = "outbound" // CheckoutParams pushed to Zoho
+	VersionInbound  = "inbound"  // ApiOrder received from a Zoho webhook
+)
 
-type Version struct {
-	ID           string    `json:"id" bson:"id"`
+var (
+	ErrOrderVersionNotFound = errors.New("order version not found")
+	ErrVersionsNotAvailable = errors.New("order version store not available")
+)
+
+// OrderVersion is one saved state of an order. Each version is a document of its own, so the
+// TTL index expires them one by one.
+type OrderVersion struct {
+	OrderID      int64     `json:"order_id" bson:"order_id"`
+	Version      int64     `json:"version" bson:"version"`
+	Direction    string    `json:"direction" bson:"direction"`
 	CreationDate time.Time `json:"creation_date" bson:"creation_date"`
 	Payload      string    `json:"payload" bson:"payload"`
 }
+
+// OrderVersionInfo is a stored version as the API returns it: the raw payload next to its
+// normalized snapshot.
+type OrderVersionInfo struct {
+	Version      int64           `json:"version"`
+	Direction    string          `json:"direction"`
+	CreationDate time.Time       `json:"creation_date"`
+	Snapshot     *OrderSnapshot  `json:"snapshot,omitempty"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+// OrderSnapshot is the part of an order both directions carry, so an outbound CheckoutParams
+// and an inbound ApiOrder can be compared.
+type OrderSnapshot struct {
+	Status   string         `json:"status"`
+	Total    Amount         `json:"total"`
+	Shipping Amount         `json:"shipping"`
+	Currency string         `json:"currency,omitempty"`
+	Items    []SnapshotItem `json:"items"`
+}
+
+// SnapshotItem is a product line; Key is the Zoho product id, or the OpenCart uid or name of
+// a product not yet mapped.
+type SnapshotItem struct {
+	Key      string  `json:"key"`
+	Name     string  `json:"name,omitempty"`
+	Quantity float64 `json:"quantity"`
+	Price    Amount  `json:"price"`
+	Total    Amount  `json:"total"`
+}
+
+// Item change kinds.
+const (
+	ItemAdded   = "added"
+	ItemRemoved = "removed"
+	ItemChanged = "changed"
+)
+
+// OrderVersionDiff lists what changed between two versions of an order. Fields that did not
+// change are left out.
+type OrderVersionDiff struct {
+	OrderID  int64            `json:"order_id"`
+	From     OrderVersionRef  `json:"from"`
+	To       OrderVersionRef  `json:"to"`
+	Status   *ValueChange     `json:"status,omitempty"`
+	Total    *ValueChange     `json:"total,omitempty"`
+	Shipping *ValueChange     `json:"shipping,omitempty"`
+	Items    []ItemDifference `json:"items"`
+}
+
+// OrderVersionRef names one side of a diff.
+type OrderVersionRef struct {
+	Version      int64     `json:"version"`
+	Direction    string    `json:"direction"`
+	CreationDate time.Time `json:"creation_date"`
+}
+
+type ValueChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// ItemDifference is a product line added, removed, or with another quantity or price.
+type ItemDifference struct {
+	Key          string  `json:"key"`
+	Name         string  `json:"name,omitempty"`
+	Change       string  `json:"change"`
+	FromQuantity float64 `json:"from_quantity"`
+	ToQuantity   float64 `json:"to_quantity"`
+	FromPrice    Amount  `json:"from_price"`
+	ToPrice      Amount  `json:"to_price"`
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Kinds of disagreement a reconciliation reports.
+const (
+	ReconcileTotal     = "total"           // grand totals differ beyond the divergence tolerance
+	ReconcileItems     = "items"           // products or quantities differ
+	ReconcileStatus    = "status"          // Zoho holds another known status
+	ReconcilePayment   = "payment"         // payment missing, unlinked or duplicated
+	ReconcileMissing   = "missing_in_zoho" // the Sales Order named by zoho_id cannot be read
+	ReconcileUnsynced  = "unsynced"        // no zoho_id after the grace period
+	ReconcileOrphan    = "orphan_in_zoho"  // ID_site names no OpenCart order
+	ReconcileDuplicate = "duplicate"       // ID_site names an order synced to another record
+)
+
+// ReconcileOptions selects the orders a reconciliation checks.
+type ReconcileOptions struct {
+	From time.Time
+	To   time.Time
+	// UnsyncedAfter is how old an order without a zoho_id must be to be reported.
+	UnsyncedAfter time.Duration
+	// Progress, when set, is called after each synced order with the number done and the total.
+	Progress func(done, total int)
+}
+
+// ReconcileIssue is one disagreement between OpenCart and Zoho.
+type ReconcileIssue struct {
+	Kind     string `json:"kind"`
+	OrderId  int64  `json:"order_id,omitempty"`
+	ZohoID   string `json:"zoho_id,omitempty"`
+	OpenCart string `json:"opencart,omitempty"` // the OpenCart side, e.g. "468.00 PLN"
+	Zoho     string `json:"zoho,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// ReconcileResult is what a reconciliation found.
+type ReconcileResult struct {
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Checked    int              `json:"checked"`     // synced orders compared with their Sales Order
+	Matched    int              `json:"matched"`     // of those, orders without any issue
+	ZohoOrders int              `json:"zoho_orders"` // Sales Orders created in the range
+	Issues     []ReconcileIssue `json:"issues"`
+}
+
+// Count returns the number of issues of a kind.
+func (r *ReconcileResult) Count(kind string) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteJSON writes the whole result as indented JSON.
+func (r *ReconcileResult) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes one row per issue, under a header row.
+func (r *ReconcileResult) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"kind", "order_id", "zoho_id", "opencart", "zoho", "detail"}); err != nil {
+		return err
+	}
+	for _, issue := range r.Issues {
+		orderId := ""
+		if issue.OrderId != 0 {
+			orderId = strconv.FormatInt(issue.OrderId, 10)
+		}
+		if err := cw.Write([]string{issue.Kind, orderId, issue.ZohoID, issue.OpenCart, issue.Zoho, issue.Detail}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ZohoPaymentRecord is a payment linked to a Sales Order, as Zoho returns it on search.
+type ZohoPaymentRecord struct {
+	ID     string `json:"id"`
+	Name   string `json:"Name"`
+	Status string `json:"Status"`
+	Sum    Amount `json:"Sum"`
+}
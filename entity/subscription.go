@@ -1,11 +1,46 @@
 package entity
 
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// SubscriptionTypeStatus subscribes a Telegram user to order status changes.
+const SubscriptionTypeStatus = "status"
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
 type Subscription struct {
-	UserID           int    `json:"user_id" bson:"user_id"`
-	User             string `json:"user" bson:"user"`
-	Role             string `json:"role" bson:"role"`
-	State            string `json:"state" bson:"state"`
-	SubscriptionType string `json:"subscription_type" bson:"subscription_type"`
+	UserID             int    `json:"user_id" bson:"user_id"`
+	User               string `json:"user" bson:"user"`
+	Role               string `json:"role" bson:"role"`
+	State              string `json:"state" bson:"state"`
+	SubscriptionType   string `json:"subscription_type" bson:"subscription_type"`
+	SubscriptionFilter `bson:",inline"`
+	CreatedAt          time.Time `json:"created_at" bson:"created_at"`
+}
+
+// SubscriptionFilter narrows the status changes a subscriber is told about. An empty list
+// matches everything.
+type SubscriptionFilter struct {
+	Statuses       []int    `json:"statuses,omitempty" bson:"statuses,omitempty"`
+	Currencies     []string `json:"currencies,omitempty" bson:"currencies,omitempty"`
+	CustomerGroups []int64  `json:"customer_groups,omitempty" bson:"customer_groups,omitempty"`
+}
+
+// OrderStatusChange is an order moved to another status by a Zoho update.
+type OrderStatusChange struct {
+	OrderId         int64  `json:"order_id"`
+	ZohoId          string `json:"zoho_id"`
+	FromStatusId    int    `json:"from_status_id"`
+	StatusId        int    `json:"status_id"`
+	StatusName      string `json:"status_name"`
+	Currency        string `json:"currency"`
+	CustomerGroupId int64  `json:"customer_group_id"`
+	Customer        string `json:"customer"`
+	Total           Amount `json:"total"`
+	Shadow          bool   `json:"shadow,omitempty"` // seen in Zoho shadow mode
 }
 
 func NewSubscription(userId int, user string) Subscription {
@@ -14,7 +49,7 @@ func NewSubscription(userId int, user string) Subscription {
 		User:             user,
 		Role:             "guest",
 		State:            "await",
-		SubscriptionType: "status",
+		SubscriptionType: SubscriptionTypeStatus,
 	}
 }
 
@@ -29,3 +64,17 @@ func (s *Subscription) IsAdmin() bool {
 func (s *Subscription) IsActive() bool {
 	return s.State == "active"
 }
+
+// Matches reports whether the change passes every filter that is set.
+func (f SubscriptionFilter) Matches(change OrderStatusChange) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, change.StatusId) {
+		return false
+	}
+	if len(f.Currencies) > 0 && !slices.Contains(f.Currencies, change.Currency) {
+		return false
+	}
+	if len(f.CustomerGroups) > 0 && !slices.Contains(f.CustomerGroups, change.CustomerGroupId) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "testing"
+
+func TestSubscriptionFilterMatches(t *testing.T) {
+	change := OrderStatusChange{StatusId: 2, Currency: CurrencyPLN, CustomerGroupId: 1}
+
+	tests := []struct {
+		name   string
+		filter SubscriptionFilter
+		want   bool
+	}{
+		{name: "empty filter matches all", want: true},
+		{name: "status listed", filter: SubscriptionFilter{Statuses: []int{2, 5}}, want: true},
+		{name: "status not listed", filter: SubscriptionFilter{Statuses: []int{5}}, want: false},
+		{name: "currency listed", filter: SubscriptionFilter{Currencies: []string{CurrencyEUR, CurrencyPLN}}, want: true},
+		{name: "currency not listed", filter: SubscriptionFilter{Currencies: []string{CurrencyEUR}}, want: false},
+		{name: "group not listed", filter: SubscriptionFilter{CustomerGroups: []int64{6}}, want: false},
+		{
+			name:   "every filter must match",
+			filter: SubscriptionFilter{Statuses: []int{2}, Currencies: []string{CurrencyPLN}, CustomerGroups: []int64{6}},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(change); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
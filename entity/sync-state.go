@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+// OrderSyncState is the Zoho sync bookkeeping stored on an OpenCart order.
+type OrderSyncState struct {
+	OrderId           int64     `json:"order_id"`
+	StatusId          int64     `json:"status_id"`
+	ZohoId            string    `json:"zoho_id"`
+	ZohoPaymentId     string    `json:"zoho_payment_id"`
+	ZohoPaymentStatus string    `json:"zoho_payment_status"` // wf_payment_status last pushed to Zoho
+	PaymentStatus     string    `json:"payment_status"`      // wf_payment_status written by wfsync
+	ZohoModifiedTime  time.Time `json:"zoho_modified_time"`
+	DateModified      time.Time `json:"date_modified"`
+}
+
+// Sync failure kinds.
+const (
+	SyncFailureOrderPayment = "order_payment"
+	SyncFailureCustomer     = "customer"
+)
+
+// SyncFailure is a record the sync gave up on and marked with the error sentinel, so it is
+// skipped by the pollers until retried.
+type SyncFailure struct {
+	Kind         string    `json:"kind"`
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	DateModified time.Time `json:"date_modified"`
+}
+
+// SyncStats summarizes the state of the sync pollers.
+type SyncStats struct {
+	StartedAt       time.Time `json:"started_at"`
+	Paused          bool      `json:"paused"`
+	LastOrderRun    time.Time `json:"last_order_run"`
+	LastCustomerRun time.Time `json:"last_customer_run"`
+	CustomersTotal  int64     `json:"customers_total"`
+	CustomersSynced int64     `json:"customers_synced"`
+	FailedPayments  int64     `json:"failed_payments"`
+	FailedCustomers int64     `json:"failed_customers"`
+}
+
+// SyncReport counts what the sync did over a period, for the daily digest.
+type SyncReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	OrdersCreated int `json:"orders_created"`
+	OrdersUpdated int `json:"orders_updated"`
+
+	// Reverse sync: Zoho webhooks applied to OpenCart, and those skipped as echoes of our own writes.
+	WebhooksApplied        int `json:"webhooks_applied"`
+	WebhooksEchoSuppressed int `json:"webhooks_echo_suppressed"`
+
+	PaymentsCreated int `json:"payments_created"`
+	PaymentsUpdated int `json:"payments_updated"`
+	PaymentsErrored int `json:"payments_errored"`
+
+	CustomersSynced int   `json:"customers_synced"`
+	CustomersFailed int   `json:"customers_failed"`
+	CustomersTotal  int64 `json:"customers_total"`
+	CustomersInZoho int64 `json:"customers_in_zoho"`
+
+	// Distinct orders that could not be pushed because a product has no Zoho id, and orders
+	// whose declared VAT differs from the VAT they contain (taxHealthGap) beyond tolerance.
+	OrdersBlockedOnProducts []int64 `json:"orders_blocked_on_products"`
+	OrdersWithTaxGap        []int64 `json:"orders_with_tax_gap"`
+}
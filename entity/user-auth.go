@@ -2,14 +2,44 @@ package entity
 
 import (
 	"net/http"
+	"slices"
+	"time"
 	"zohoclient/internal/lib/validate"
 )
 
+// API token scopes. A route requires one scope; ScopeAdmin grants all of them.
+const (
+	ScopeWebhookOrder = "webhook:order"
+	ScopeWebhookB2B   = "webhook:b2b"
+	ScopePush         = "push"
+	ScopeCatalogWrite = "catalog:write"
+	ScopeAdmin        = "admin"
+)
+
+// Token sources, recorded on each authenticated call.
+const (
+	TokenSourceConfig = "config"
+	TokenSourceOcApi  = "oc_api"
+)
+
 type UserAuth struct {
-	Name  string `json:"name" bson:"name" validate:"omitempty"`
-	Token string `json:"token" bson:"token" validate:"required,min=1"`
+	Name      string    `json:"name" bson:"name" validate:"omitempty"`
+	Token     string    `json:"token" bson:"token" validate:"required,min=1"`
+	Scopes    []string  `json:"scopes,omitempty" bson:"scopes,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	Source    string    `json:"source,omitempty" bson:"source,omitempty"`
 }
 
 func (u *UserAuth) Bind(_ *http.Request) error {
 	return validate.Struct(u)
 }
+
+// HasScope reports whether the client may call routes requiring scope.
+func (u *UserAuth) HasScope(scope string) bool {
+	return slices.Contains(u.Scopes, ScopeAdmin) || slices.Contains(u.Scopes, scope)
+}
+
+// Expired reports whether the token has an expiry that has passed.
+func (u *UserAuth) Expired(now time.Time) bool {
+	return !u.ExpiresAt.IsZero() && !now.Before(u.ExpiresAt)
+}
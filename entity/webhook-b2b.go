@@ -6,9 +6,18 @@ import (
 	"zohoclient/internal/lib/validate"
 )
 
+// B2B portal webhook events. order_confirmed creates the Deal; every later event targets the
+// Deal found through the order_uid mapping (see B2BDeal).
+const (
+	B2BEventOrderConfirmed = "order_confirmed"
+	B2BEventOrderUpdated   = "order_updated"
+	B2BEventOrderCancelled = "order_cancelled"
+	B2BEventOrderPaid      = "order_paid"
+)
+
 // B2BWebhookPayload represents the incoming webhook from B2B portal
 type B2BWebhookPayload struct {
-	Event     string          `json:"event" validate:"required,eq=order_confirmed"`
+	Event     string          `json:"event" validate:"required,oneof=order_confirmed order_updated order_cancelled order_paid"`
 	Timestamp time.Time       `json:"timestamp"`
 	Data      B2BWebhookOrder `json:"data" validate:"required"`
 }
@@ -32,26 +41,37 @@ type B2BWebhookOrder struct {
 	ClientTaxID     string           `json:"client_tax_id"`
 	StoreUID        string           `json:"store_uid"`
 	Status          string           `json:"status"`
-	Total           float64          `json:"total" validate:"gt=0"`
-	Subtotal        float64          `json:"subtotal"`
-	TotalVAT        float64          `json:"total_vat"`
+	Total           Amount           `json:"total" validate:"gt=0"`
+	Subtotal        Amount           `json:"subtotal"`
+	TotalVAT        Amount           `json:"total_vat"`
 	DiscountPercent float64          `json:"discount_percent"`
-	DiscountAmount  float64          `json:"discount_amount"`
+	DiscountAmount  Amount           `json:"discount_amount"`
 	CurrencyCode    string           `json:"currency_code" validate:"required,oneof=USD EUR PLN UAH"`
 	ShippingAddress string           `json:"shipping_address"`
 	Comment         string           `json:"comment"`
 	CreatedAt       time.Time        `json:"created_at"`
 	Items           []B2BWebhookItem `json:"items" validate:"required,min=1,dive"`
+	// Payment is sent with order_paid only.
+	Payment *B2BWebhookPayment `json:"payment,omitempty"`
+}
+
+// B2BWebhookPayment describes the payment reported by an order_paid event. Amount defaults to
+// the order total when the portal leaves it out.
+type B2BWebhookPayment struct {
+	Amount        Amount    `json:"amount" validate:"gte=0"`
+	Method        string    `json:"method"`
+	TransactionID string    `json:"transaction_id"`
+	PaidAt        time.Time `json:"paid_at"`
 }
 
 // B2BWebhookItem represents a single line item in the webhook
 type B2BWebhookItem struct {
-	ProductUID    string  `json:"product_uid" validate:"required"`
-	ProductSKU    string  `json:"product_sku"`
-	Quantity      int     `json:"quantity" validate:"required,gt=0"`
-	Price         float64 `json:"price" validate:"gt=0"`
-	Discount      float64 `json:"discount"`
-	PriceDiscount float64 `json:"price_discount"`
-	Tax           float64 `json:"tax"`
-	Total         float64 `json:"total" validate:"gt=0"`
+	ProductUID    string `json:"product_uid" validate:"required"`
+	ProductSKU    string `json:"product_sku"`
+	Quantity      int    `json:"quantity" validate:"required,gt=0"`
+	Price         Amount `json:"price" validate:"gt=0"`
+	Discount      Amount `json:"discount"`
+	PriceDiscount Amount `json:"price_discount"`
+	Tax           Amount `json:"tax"`
+	Total         Amount `json:"total" validate:"gt=0"`
 }
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Outbound webhook events, posted to the downstream systems subscribed to them.
+const (
+	WebhookOrderSynced          = "order.synced"
+	WebhookOrderUpdatedFromZoho = "order.updated_from_zoho"
+	WebhookPaymentLinked        = "payment.linked"
+	WebhookCustomerSynced       = "customer.synced"
+)
+
+// Delivery states of an outbound webhook.
+const (
+	DeliveryPending   = "pending"   // waiting for its first or next attempt
+	DeliveryDelivered = "delivered" // the subscriber answered 2xx
+	DeliveryFailed    = "failed"    // out of attempts; redeliver to try again
+)
+
+// WebhookEnvelope is the JSON body posted to a subscriber.
+type WebhookEnvelope struct {
+	ID    string    `json:"id"`
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data"`
+}
+
+// WebhookDelivery is one event for one subscriber, with its delivery history. Body is kept
+// verbatim so every attempt sends, and signs, the same bytes.
+type WebhookDelivery struct {
+	ID           string    `json:"id" bson:"_id"`
+	Subscriber   string    `json:"subscriber" bson:"subscriber"`
+	URL          string    `json:"url" bson:"url"`
+	Event        string    `json:"event" bson:"event"`
+	Body         string    `json:"body" bson:"body"`
+	Status       string    `json:"status" bson:"status"`
+	Attempts     int       `json:"attempts" bson:"attempts"`
+	ResponseCode int       `json:"response_code,omitempty" bson:"response_code,omitempty"`
+	LastError    string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	NextAttempt  time.Time `json:"next_attempt,omitzero" bson:"next_attempt,omitempty"`
+	DeliveredAt  time.Time `json:"delivered_at,omitzero" bson:"delivered_at,omitempty"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at"`
+	// LeasedUntil is set while a worker is attempting the delivery; no other worker takes it up
+	// before then, even when it is due again.
+	LeasedUntil time.Time `json:"-" bson:"leased_until,omitempty"`
+}
+
+// WebhookDeliveryFilter selects deliveries for the delivery log API; empty fields match all.
+type WebhookDeliveryFilter struct {
+	Status     string
+	Event      string
+	Subscriber string
+	Limit      int
+}
+
+var (
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrWebhooksNotConfigured = errors.New("outbound webhooks not configured")
+)
@@ -0,0 +1,22 @@
+package entity
+
+// ZohoAccount is a company record in the Zoho CRM Accounts module. B2B portal clients are kept
+// as Accounts, identified by the portal client UID (B2B_Client_UID) and tax id (NIP); their
+// people are Contacts linked through Account_Name. Empty fields are omitted, so an update never
+// blanks a value already stored in Zoho.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/insert-records.html
+type ZohoAccount struct {
+	AccountName    string `json:"Account_Name,omitempty"`
+	NIP            string `json:"NIP,omitempty"`
+	ClientUID      string `json:"B2B_Client_UID,omitempty"`
+	Phone          string `json:"Phone,omitempty"`
+	BillingStreet  string `json:"Billing_Street,omitempty"`
+	BillingCity    string `json:"Billing_City,omitempty"`
+	BillingCode    string `json:"Billing_Code,omitempty"`
+	BillingCountry string `json:"Billing_Country,omitempty"`
+}
+
+// ZohoAccountRef is a lookup reference to an Accounts record.
+type ZohoAccountRef struct {
+	ID string `json:"id"`
+}
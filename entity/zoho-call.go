@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ZohoCall is one request to the Zoho CRM API with its outcome, kept for the audit log.
+// Bodies are stored with personal data redacted and cut to the configured size.
+type ZohoCall struct {
+	Time         time.Time `json:"time" bson:"time"`
+	Method       string    `json:"method" bson:"method"`
+	Path         string    `json:"path" bson:"path"`
+	OrderID      int64     `json:"order_id,omitempty" bson:"order_id,omitempty"`   // OpenCart order, when the request names it
+	RecordID     string    `json:"record_id,omitempty" bson:"record_id,omitempty"` // Zoho record addressed or returned
+	RequestBody  string    `json:"request_body,omitempty" bson:"request_body,omitempty"`
+	Status       int       `json:"status" bson:"status"`                 // HTTP status; 0 when no response arrived
+	Code         string    `json:"code,omitempty" bson:"code,omitempty"` // Zoho code, e.g. SUCCESS, INVALID_DATA
+	Message      string    `json:"message,omitempty" bson:"message,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty" bson:"response_body,omitempty"`
+	Error        string    `json:"error,omitempty" bson:"error,omitempty"`
+	Failed       bool      `json:"failed" bson:"failed"` // no response, a non-2xx status or a Zoho error code
+	DurationMs   int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// ZohoCallShadow is the code of a write recorded in shadow mode instead of being sent.
+const ZohoCallShadow = "SHADOW"
+
+// ZohoCallFilter selects calls for the audit API; empty fields match all.
+type ZohoCallFilter struct {
+	OrderID  int64
+	RecordID string
+	Path     string
+	Code     string
+	Failed   bool // only calls that did not succeed
+	Since    time.Time
+	Limit    int
+}
+
+var ErrAuditNotAvailable = errors.New("zoho audit log not available")
@@ -3,23 +3,25 @@ package entity
 // ZohoOrderB2B represents a Deal record in the Zoho CRM Deals module for B2B orders.
 // Uses currency-specific total fields (Grand_Total_UAH, etc.) because B2B deals
 // may be denominated in different currencies.
-// JSON field names map to Zoho CRM Deals module API names.
+// JSON field names map to Zoho CRM Deals module API names. Deals created from the B2B portal
+// carry the portal order UID (B2B_Order_UID), by which they are found again.
 type ZohoOrderB2B struct {
-	ContactName ContactName `json:"Contact_Name"`
-	Goods       []Good      `json:"Products"`
-	DiscountP   float64     `json:"total_discount"`
-	Description string      `json:"Description"`
+	ContactName ContactName     `json:"Contact_Name"`
+	AccountName *ZohoAccountRef `json:"Account_Name,omitempty"`
+	Goods       []Good          `json:"Products"`
+	DiscountP   float64         `json:"total_discount"`
+	Description string          `json:"Description"`
 	//CustomerNo  string      `json:"Customer_No"`
 	//ShippingState      string          `json:"Shipping_State"`
 	VAT            float64 `json:"VAT"`
-	GrandTotalUAH  float64 `json:"Grand_Total_UAH,omitempty"`
-	GrandTotalUSD  float64 `json:"Grand_Total_USD,omitempty"`
-	GrandTotalEUR  float64 `json:"Grand_Total_EUR,omitempty"`
-	GrandTotalPLN  float64 `json:"Grand_Total_PLN,omitempty"`
-	SubTotalUAH    float64 `json:"Total_UAH,omitempty"`
-	SubTotalUSD    float64 `json:"Total_USD,omitempty"`
-	SubTotalEUR    float64 `json:"Total_EUR,omitempty"`
-	SubTotalPLN    float64 `json:"Total_PLN,omitempty"`
+	GrandTotalUAH  Amount  `json:"Grand_Total_UAH,omitempty"`
+	GrandTotalUSD  Amount  `json:"Grand_Total_USD,omitempty"`
+	GrandTotalEUR  Amount  `json:"Grand_Total_EUR,omitempty"`
+	GrandTotalPLN  Amount  `json:"Grand_Total_PLN,omitempty"`
+	SubTotalUAH    Amount  `json:"Total_UAH,omitempty"`
+	SubTotalUSD    Amount  `json:"Total_USD,omitempty"`
+	SubTotalEUR    Amount  `json:"Total_EUR,omitempty"`
+	SubTotalPLN    Amount  `json:"Total_PLN,omitempty"`
 	Currency       string  `json:"Currency"`
 	BillingCountry string  `json:"Country"`
 	Status         string  `json:"Stage"`
@@ -31,24 +33,47 @@ type ZohoOrderB2B struct {
 	NIP         string `json:"NIP,omitempty"`
 	Location    string `json:"Location"`
 	OrderSource string `json:"Order_Source"`
+	OrderUID    string `json:"B2B_Order_UID,omitempty"`
+}
+
+// ZohoOrderB2BUpdate patches an existing B2B Deal. Only the fields that are set are written, so
+// the Deal's contact, pipeline and any field edited by a manager in Zoho are left untouched. The
+// fields are pointers so that a discount of zero or a cleared comment is written too.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/update-specific-record.html
+type ZohoOrderB2BUpdate struct {
+	DiscountP     *float64 `json:"total_discount,omitempty"`
+	Description   *string  `json:"Description,omitempty"`
+	VAT           *float64 `json:"VAT,omitempty"`
+	GrandTotalUAH *Amount  `json:"Grand_Total_UAH,omitempty"`
+	GrandTotalUSD *Amount  `json:"Grand_Total_USD,omitempty"`
+	GrandTotalEUR *Amount  `json:"Grand_Total_EUR,omitempty"`
+	GrandTotalPLN *Amount  `json:"Grand_Total_PLN,omitempty"`
+	SubTotalUAH   *Amount  `json:"Total_UAH,omitempty"`
+	SubTotalUSD   *Amount  `json:"Total_USD,omitempty"`
+	SubTotalEUR   *Amount  `json:"Total_EUR,omitempty"`
+	SubTotalPLN   *Amount  `json:"Total_PLN,omitempty"`
+	Status        string   `json:"Stage,omitempty"`
+	BillingStreet *string  `json:"delivery_street,omitempty"`
 }
 
 // Good represents a record in the Zoho CRM custom "Goods" module, linked to a Deal.
 // Currency-specific price/total fields are used instead of a single amount field.
+// ID is set only on records read back from Zoho, and on updates of those records.
 type Good struct {
+	ID        string      `json:"id,omitempty"`
 	Product   ZohoProduct `json:"Product"`
 	Deal      ZohoDeal    `json:"Deal"`
 	Name      string      `json:"Name"`
 	Quantity  int64       `json:"Goods_quantity"`
 	DiscountP float64     `json:"Discount"`
-	PriceUAH  float64     `json:"Good_price,omitempty"`
-	PriceUSD  float64     `json:"Price_USD,omitempty"`
-	PriceEUR  float64     `json:"Price_EUR,omitempty"`
-	PricePLN  float64     `json:"Price_PLN,omitempty"`
-	TotalUAH  float64     `json:"Total,omitempty"`
-	TotalUSD  float64     `json:"Total_USD,omitempty"`
-	TotalEUR  float64     `json:"Total_EUR,omitempty"`
-	TotalPLN  float64     `json:"Total_PLN,omitempty"`
+	PriceUAH  Amount      `json:"Good_price,omitempty"`
+	PriceUSD  Amount      `json:"Price_USD,omitempty"`
+	PriceEUR  Amount      `json:"Price_EUR,omitempty"`
+	PricePLN  Amount      `json:"Price_PLN,omitempty"`
+	TotalUAH  Amount      `json:"Total,omitempty"`
+	TotalUSD  Amount      `json:"Total_USD,omitempty"`
+	TotalEUR  Amount      `json:"Total_EUR,omitempty"`
+	TotalPLN  Amount      `json:"Total_PLN,omitempty"`
 }
 
 type ZohoDeal struct {
@@ -6,25 +6,25 @@ package entity
 type ZohoOrder struct {
 	ContactName        ContactName     `json:"Contact_Name"`
 	OrderedItems       []OrderedItem   `json:"Ordered_Items"`
-	Discount           float64         `json:"GetDiscount"`
+	Discount           Amount          `json:"GetDiscount"`
 	DiscountP          float64         `json:"DiscountP"`
 	CouponTitle        string          `json:"Promocode"`
-	CouponValue        float64         `json:"Promocode_discount"`
+	CouponValue        Amount          `json:"Promocode_discount"`
 	Description        string          `json:"Description"`
 	CustomerNo         string          `json:"Customer_No"`
 	ShippingState      string          `json:"Shipping_State"`
-	Tax                float64         `json:"Tax"`
+	Tax                Amount          `json:"Tax"`
 	VAT                float64         `json:"VAT"`
-	GrandTotal         float64         `json:"Grand_Total"`
-	SubTotal           float64         `json:"Sub_Total"`
+	GrandTotal         Amount          `json:"Grand_Total"`
+	SubTotal           Amount          `json:"Sub_Total"`
 	Currency           string          `json:"Currency"`
 	BillingCountry     string          `json:"Billing_Country"`
 	Carrier            string          `json:"Carrier"`
 	Status             string          `json:"Status"`
-	SalesCommission    float64         `json:"Sales_Commission"`
+	SalesCommission    Amount          `json:"Sales_Commission"`
 	DueDate            string          `json:"Due_Date"`
 	BillingStreet      string          `json:"Billing_Street"`
-	Adjustment         float64         `json:"Adjustment"`
+	Adjustment         Amount          `json:"Adjustment"`
 	TermsAndConditions string          `json:"Terms_and_Conditions"`
 	BillingCode        string          `json:"Billing_Code"`
 	ProductDetails     []ProductDetail `json:"Product_Details,omitempty"`
@@ -50,10 +50,10 @@ type ContactName struct {
 type OrderedItem struct {
 	Product   ZohoProduct `json:"Product_Name"`
 	Quantity  int64       `json:"Quantity"`
-	Discount  float64     `json:"GetDiscount"`
+	Discount  Amount      `json:"GetDiscount"`
 	DiscountP float64     `json:"DiscountP"`
-	ListPrice float64     `json:"List_Price"`
-	Total     float64     `json:"Total"`
+	ListPrice Amount      `json:"List_Price"`
+	Total     Amount      `json:"Total"`
 }
 
 type ZohoProduct struct {
@@ -68,8 +68,9 @@ type ZohoOrderRecord struct {
 	ID           string               `json:"id"`
 	Subject      string               `json:"Subject"`
 	IDsite       string               `json:"ID_site"`
+	Status       string               `json:"Status"`
 	ModifiedTime string               `json:"Modified_Time"`
-	GrandTotal   float64              `json:"Grand_Total"`
+	GrandTotal   Amount               `json:"Grand_Total"`
 	OrderedItems []ZohoOrderedItemRow `json:"Ordered_Items"`
 }
 
@@ -78,9 +79,9 @@ type ZohoOrderedItemRow struct {
 	ID        string      `json:"id"`
 	Product   ZohoProduct `json:"Product_Name"`
 	Quantity  float64     `json:"Quantity"`
-	ListPrice float64     `json:"List_Price"`
+	ListPrice Amount      `json:"List_Price"`
 	DiscountP float64     `json:"DiscountP"`
-	Total     float64     `json:"Total"`
+	Total     Amount      `json:"Total"`
 }
 
 // OrderedItemPatch updates an existing subform row in place. The row id is mandatory: a row sent
@@ -91,17 +92,17 @@ type OrderedItemPatch struct {
 	ID        string      `json:"id"`
 	Product   ZohoProduct `json:"Product_Name"`
 	Quantity  int64       `json:"Quantity"`
-	ListPrice float64     `json:"List_Price"`
+	ListPrice Amount      `json:"List_Price"`
 	DiscountP float64     `json:"DiscountP"`
-	Total     float64     `json:"Total"`
+	Total     Amount      `json:"Total"`
 }
 
 type ProductDetail struct {
 	Product     ProductID `json:"product"`
 	Quantity    int       `json:"quantity"`
-	Discount    float64   `json:"GetDiscount"`
+	Discount    Amount    `json:"GetDiscount"`
 	ProductDesc string    `json:"product_description"`
-	UnitPrice   float64   `json:"Unit Price"`
+	UnitPrice   Amount    `json:"Unit Price"`
 	LineTax     []LineTax `json:"line_tax"`
 }
 
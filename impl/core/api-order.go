@@ -70,6 +70,7 @@ func (c *Core) UpdateOrder(orderDetails *entity.ApiOrder) error {
 				slog.Time("incoming", incomingModified),
 				slog.Time("stored", storedModified),
 			).Debug("skipping echo webhook")
+			c.countReport(func(r *entity.SyncReport) { r.WebhooksEchoSuppressed++ })
 			return nil
 		}
 	} else {
@@ -131,9 +132,9 @@ func (c *Core) UpdateOrder(orderDetails *entity.ApiOrder) error {
 		// silently ends up worth two different amounts in two systems. Report it.
 		if diff, diverged := totalsDiverged(orderParams.Total, orderDetails.GrandTotal); diverged {
 			log.With(
-				slog.Float64("oc_total", round2(orderParams.Total)),
-				slog.Float64("zoho_total", round2(orderDetails.GrandTotal)),
-				slog.Float64("diff", round2(diff)),
+				slog.Float64("oc_total", orderParams.Total.Round(2).Float64()),
+				slog.Float64("zoho_total", orderDetails.GrandTotal.Round(2).Float64()),
+				slog.Float64("diff", diff.Round(2).Float64()),
 				slog.String("currency", orderParams.Currency),
 			).Warn("Zoho grand total diverges from OpenCart while items are unchanged; totals left untouched")
 		}
@@ -148,7 +149,16 @@ func (c *Core) UpdateOrder(orderDetails *entity.ApiOrder) error {
 				log.With(sl.Err(err)).Warn("store zoho_modified_time failed")
 			}
 		}
-		c.saveOrderVersionToMongo(orderId, orderDetails)
+		c.saveOrderVersionToMongo(orderId, entity.VersionInbound, orderDetails)
+		c.notifyStatusChange(statusChange(orderId, orderDetails.ZohoID, orderParams,
+			previousStatusId, newStatusId, orderDetails.Status))
+		updated := entity.OrderEvent{OrderId: orderId, ZohoId: orderDetails.ZohoID, StatusId: newStatusId,
+			Currency: orderParams.Currency, Total: orderParams.Total.Round(2)}
+		c.emitEvent(entity.EventOrderUpdated,
+			fmt.Sprintf("Order %d updated from Zoho", orderId),
+			fmt.Sprintf("status %d → %d, items and totals untouched", previousStatusId, newStatusId),
+			updated)
+		c.dispatchWebhook(entity.WebhookOrderUpdatedFromZoho, updated)
 		log.With(
 			slog.Int("status_from", previousStatusId),
 			slog.Int("status_to", newStatusId),
@@ -182,6 +192,7 @@ func (c *Core) UpdateOrder(orderDetails *entity.ApiOrder) error {
 		log.With(sl.Err(err)).Error("failed to update order")
 		return fmt.Errorf("failed to update order: %w", err)
 	}
+	c.countReport(func(r *entity.SyncReport) { r.WebhooksApplied++ })
 
 	// Record the version we just applied so a future echo for this same change is
 	// recognised and suppressed. Failure is non-fatal — worst case the next echo
@@ -193,39 +204,50 @@ func (c *Core) UpdateOrder(orderDetails *entity.ApiOrder) error {
 	}
 
 	// Audit what the webhook actually changed.
-	newTotalDisplay := (float64(totals.Total) / 100) / currencyValue
+	newTotal := entity.NewMoney(totals.Total.Div(currencyValue), orderParams.Currency)
 	logOrderDiff(log, previousItems, mergedItems, c.shippingItemZohoId,
 		previousStatusId, newStatusId,
-		previousTotal, newTotalDisplay, orderParams.Currency)
+		entity.NewMoney(previousTotal, orderParams.Currency), newTotal)
 
 	// Save order version to MongoDB
-	c.saveOrderVersionToMongo(orderId, orderDetails)
+	c.saveOrderVersionToMongo(orderId, entity.VersionInbound, orderDetails)
+	change := statusChange(orderId, orderDetails.ZohoID, orderParams, previousStatusId, newStatusId, orderDetails.Status)
+	change.Total = newTotal.Amount.Round(2)
+	c.notifyStatusChange(change)
+	updated := entity.OrderEvent{OrderId: orderId, ZohoId: orderDetails.ZohoID, StatusId: newStatusId,
+		Currency: orderParams.Currency, Total: newTotal.Amount.Round(2)}
+	c.emitEvent(entity.EventOrderUpdated,
+		fmt.Sprintf("Order %d updated from Zoho", orderId),
+		fmt.Sprintf("status %d → %d, total %s → %s", previousStatusId, newStatusId,
+			previousTotal.Round(2), newTotal),
+		updated)
+	c.dispatchWebhook(entity.WebhookOrderUpdatedFromZoho, updated)
 
 	log.With(
-		slog.String("sub_total", fmtCents(totals.ItemsTotal)),
-		slog.String("shipping", fmtCents(totals.Shipping)),
-		slog.String("discount", fmtCents(totals.Discount)),
-		slog.String("coupon", fmtCents(totals.Coupon)),
-		slog.String("tax_total", fmtCents(totals.Tax)),
-		slog.Float64("tax_rate", round4(taxRate)),
-		slog.String("total", fmtCents(totals.Total)),
-		slog.String("zoho_total", fmtCents(int64(math.Round(orderDetails.GrandTotal*100)))),
+		slog.String("sub_total", totals.ItemsTotal.String()),
+		slog.String("shipping", totals.Shipping.String()),
+		slog.String("discount", totals.Discount.String()),
+		slog.String("coupon", totals.Coupon.String()),
+		slog.String("tax_total", totals.Tax.String()),
+		slog.Float64("tax_rate", taxRate),
+		slog.String("total", totals.Total.String()),
+		slog.String("zoho_total", orderDetails.GrandTotal.String()),
 	).Debug("order updated")
 
 	return nil
 }
 
-// reverseTotals is the OpenCart order_total breakdown (in cents) plus the per-line
+// reverseTotals is the OpenCart order_total breakdown (to the cent) plus the per-line
 // order_product rows reconstructed from a Zoho reverse-sync payload. Discount and Coupon
 // follow OpenCart's negative-sign convention.
 type reverseTotals struct {
 	Products   []sql.OrderProductData
-	ItemsTotal int64 // sub_total
-	Shipping   int64
-	Tax        int64
-	Discount   int64 // post-tax discount, negative
-	Coupon     int64 // pre-tax coupon, negative
-	Total      int64
+	ItemsTotal entity.Amount // sub_total
+	Shipping   entity.Amount
+	Tax        entity.Amount
+	Discount   entity.Amount // post-tax discount, negative
+	Coupon     entity.Amount // pre-tax coupon, negative
+	Total      entity.Amount
 }
 
 // computeReverseTotals rebuilds OpenCart's order_total rows and order_product data from a Zoho
@@ -247,26 +269,26 @@ type reverseTotals struct {
 // grand total and OpenCart stays internally consistent even after a manager edits the subform.
 // The payload cannot say how that reduction divides between the coupon and discount rows, so
 // the split comes from the order's stored totals.
-func (c *Core) computeReverseTotals(items []entity.ApiOrderedItem, grandTotalFloat float64, oc *entity.CheckoutParams) reverseTotals {
+func (c *Core) computeReverseTotals(items []entity.ApiOrderedItem, grandTotal entity.Amount, oc *entity.CheckoutParams) reverseTotals {
 	rate := oc.VatRate()
 
 	// Zoho's ListPrice may be the catalogue (master) price, while OpenCart's sub_total is built
 	// from the price actually paid. Recover the paid price per product from the stored order.
-	paidPrices := make(map[string]float64, len(oc.LineItems))
+	paidPrices := make(map[string]entity.Amount, len(oc.LineItems))
 	for _, li := range oc.LineItems {
 		if li != nil && li.ZohoId != "" && li.Price > 0 {
 			paidPrices[li.ZohoId] = li.Price
 		}
 	}
 
-	var itemsTotalF, netProductsF, shippingF float64
+	var itemsTotal, netProducts, shipping entity.Amount
 	products := make([]sql.OrderProductData, 0, len(items))
 	for _, item := range items {
 		if item.ZohoID == c.shippingItemZohoId {
 			// The line total, not the unit price: it is the amount the non-taxable line
 			// contributes to Zoho's grand total, so the order_total rows still reconcile if a
 			// manager discounts the carriage or splits it across several lines.
-			shippingF += item.Total
+			shipping += item.Total
 			continue
 		}
 
@@ -277,29 +299,30 @@ func (c *Core) computeReverseTotals(items []entity.ApiOrderedItem, grandTotalFlo
 			paidUnit = item.Price
 		}
 
-		lineTotal := paidUnit * float64(item.Quantity)
-		itemsTotalF += lineTotal
-		netProductsF += item.Total
+		lineTotal := paidUnit.Mul(float64(item.Quantity))
+		itemsTotal += lineTotal
+		netProducts += item.Total
 
 		products = append(products, sql.OrderProductData{
-			ZohoID:       item.ZohoID,
-			Quantity:     item.Quantity,
-			PriceInCents: cents(paidUnit),
-			TotalInCents: cents(lineTotal),
-			TaxInCents:   cents(paidUnit * rate),
+			ZohoID:   item.ZohoID,
+			Quantity: item.Quantity,
+			Price:    paidUnit.Round(2),
+			Total:    lineTotal.Round(2),
+			Tax:      paidUnit.Mul(rate).Round(2),
 		})
 	}
 
-	grandTotal := cents(grandTotalFloat)
-	itemsTotal := cents(itemsTotalF)
-	shipping := cents(shippingF)
-	taxTotal := cents(netProductsF * rate)
+	// The rows are kept to the cent, as Zoho's grand total is.
+	grandTotal = grandTotal.Round(2)
+	itemsTotal = itemsTotal.Round(2)
+	shipping = shipping.Round(2)
+	taxTotal := netProducts.Mul(rate).Round(2)
 
 	// Everything the lines gave up. Derived so the rows always sum to the grand total; a
 	// non-positive result means there was no reduction, and any remainder folds into tax so the
 	// rows stay exact.
 	reduction := itemsTotal + shipping + taxTotal - grandTotal
-	if reduction <= 1 {
+	if reduction <= entity.Cent {
 		reduction = 0
 		taxTotal = grandTotal - itemsTotal - shipping
 	}
@@ -345,7 +368,7 @@ func itemsUnchanged(items []entity.ApiOrderedItem, oc *entity.CheckoutParams, sh
 const (
 	// totalsDivergenceFloor is the smallest gap worth reporting, in order currency. Below this a
 	// difference cannot be anything but rounding, whatever the order's size.
-	totalsDivergenceFloor = 1.0
+	totalsDivergenceFloor = entity.Unit
 	// totalsDivergenceRate scales the tolerance with the order so long subforms, where the drift
 	// accumulates line by line, are not reported over and over.
 	totalsDivergenceRate = 0.001
@@ -357,22 +380,22 @@ const (
 // expected and meaningless; past max(1.00, 0.1% of the order) the money itself has changed.
 // Returns the signed difference (Zoho minus OpenCart) and whether it exceeds the tolerance.
 // A payload carrying no usable grand total never diverges — there is nothing to compare against.
-func totalsDiverged(ocTotal, zohoTotal float64) (float64, bool) {
+func totalsDiverged(ocTotal, zohoTotal entity.Amount) (entity.Amount, bool) {
 	if ocTotal <= 0 || zohoTotal <= 0 {
 		return 0, false
 	}
 	diff := zohoTotal - ocTotal
-	tolerance := math.Max(totalsDivergenceFloor, ocTotal*totalsDivergenceRate)
-	return diff, math.Abs(diff) > tolerance
+	tolerance := max(totalsDivergenceFloor, ocTotal.Mul(totalsDivergenceRate))
+	return diff, diff.Abs() > tolerance
 }
 
 // splitReductions divides the reduction recovered from a Zoho payload between OpenCart's coupon
 // and discount rows (both stored negative). The lines carry the two blended into one figure and
 // the payload cannot separate them, so when an order has both they are split in the same
 // proportion the stored order used.
-func (c *Core) splitReductions(oc *entity.CheckoutParams, reduction int64) (coupon, discount int64) {
-	couponAmt := math.Abs(oc.Coupon)
-	discountAmt := math.Abs(oc.Discount)
+func (c *Core) splitReductions(oc *entity.CheckoutParams, reduction entity.Amount) (coupon, discount entity.Amount) {
+	couponAmt := oc.Coupon.Abs()
+	discountAmt := oc.Discount.Abs()
 
 	switch {
 	case reduction <= 0 || couponAmt+discountAmt == 0:
@@ -382,17 +405,12 @@ func (c *Core) splitReductions(oc *entity.CheckoutParams, reduction int64) (coup
 	case couponAmt == 0:
 		return 0, -reduction
 	default:
-		share := couponAmt / (couponAmt + discountAmt)
-		couponPart := int64(math.Round(float64(reduction) * share))
+		share := couponAmt.Float64() / (couponAmt + discountAmt).Float64()
+		couponPart := reduction.Mul(share).Round(2)
 		return -couponPart, -(reduction - couponPart)
 	}
 }
 
-// cents converts a currency amount to integer cents.
-func cents(v float64) int64 {
-	return int64(math.Round(v * 100))
-}
-
 // calculateTaxRate calculates the tax rate from existing order_total data.
 // Returns tax rate as a decimal (e.g., 0.23 for 23% VAT), rounded to 4 decimal places.
 func (c *Core) calculateTaxRate(orderId int64) (float64, error) {
@@ -412,7 +430,7 @@ func (c *Core) calculateTaxRate(orderId int64) (float64, error) {
 	}
 
 	// Calculate rate and round to 4 decimals
-	rate := tax / subTotal
+	rate := tax.Float64() / subTotal.Float64()
 	return math.Round(rate*10000) / 10000, nil
 }
 
@@ -449,7 +467,7 @@ func mergeItemsByZohoID(items []entity.ApiOrderedItem) []entity.ApiOrderedItem {
 			merged[pos].Quantity += item.Quantity
 			merged[pos].Total += item.Total
 			if merged[pos].Quantity > 0 {
-				merged[pos].Price = merged[pos].Total / float64(merged[pos].Quantity)
+				merged[pos].Price = merged[pos].Total.Div(float64(merged[pos].Quantity))
 			}
 			continue
 		}
@@ -464,22 +482,21 @@ func mergeItemsByZohoID(items []entity.ApiOrderedItem) []entity.ApiOrderedItem {
 // Compares API totals (discounted) vs full totals (price × quantity).
 // Returns discount as a decimal (e.g., 0.15 for 15% discount).
 func (c *Core) calculateDiscountPercent(items []entity.ApiOrderedItem) float64 {
-	var sumApiTotals float64 = 0
-	var sumFullTotals float64 = 0
+	var sumApiTotals, sumFullTotals entity.Amount
 
 	for _, item := range items {
 		if c.shippingItemZohoId != "" && item.ZohoID == c.shippingItemZohoId {
 			continue
 		}
-		sumApiTotals += item.Total                           // Discounted total from API
-		sumFullTotals += item.Price * float64(item.Quantity) // Full price
+		sumApiTotals += item.Total                              // Discounted total from API
+		sumFullTotals += item.Price.Mul(float64(item.Quantity)) // Full price
 	}
 
 	if sumFullTotals == 0 {
 		return 0
 	}
 
-	return 1.0 - (sumApiTotals / sumFullTotals)
+	return 1.0 - sumApiTotals.Float64()/sumFullTotals.Float64()
 }
 
 // itemDiffEntry is one aggregated line used for before/after comparison.
@@ -497,8 +514,7 @@ func logOrderDiff(
 	incoming []entity.ApiOrderedItem,
 	shippingZohoID string,
 	previousStatusId, newStatusId int,
-	previousTotal, newTotal float64,
-	currency string,
+	previousTotal, newTotal entity.Money,
 ) {
 	prevMap := make(map[string]itemDiffEntry, len(previous))
 	for _, it := range previous {
@@ -557,8 +573,8 @@ func logOrderDiff(
 	sort.Strings(removed)
 	sort.Strings(changed)
 
-	totalDelta := newTotal - previousTotal
-	totalChanged := math.Abs(totalDelta) >= 0.005 // ignore sub-cent float jitter
+	totalDelta := entity.NewMoney(newTotal.Amount-previousTotal.Amount, newTotal.Currency)
+	totalChanged := totalDelta.Amount.Round(2) != 0 // ignore sub-cent differences
 	statusChanged := previousStatusId != newStatusId
 
 	if !totalChanged && !statusChanged && len(added) == 0 && len(removed) == 0 && len(changed) == 0 {
@@ -575,9 +591,9 @@ func logOrderDiff(
 	}
 	if totalChanged {
 		attrs = append(attrs,
-			slog.String("total_from", fmt.Sprintf("%.2f %s", previousTotal, currency)),
-			slog.String("total_to", fmt.Sprintf("%.2f %s", newTotal, currency)),
-			slog.String("total_delta", fmt.Sprintf("%+.2f %s", totalDelta, currency)),
+			slog.String("total_from", previousTotal.String()),
+			slog.String("total_to", newTotal.String()),
+			slog.String("total_delta", signedMoney(totalDelta)),
 		)
 	}
 	if len(added) > 0 {
@@ -592,3 +608,11 @@ func logOrderDiff(
 
 	log.With(attrs...).Info("order update applied")
 }
+
+// signedMoney formats money with an explicit sign, "+12.50 PLN" or "-3.00 PLN".
+func signedMoney(m entity.Money) string {
+	if m.Amount.Round(2) < 0 {
+		return m.String()
+	}
+	return "+" + m.String()
+}
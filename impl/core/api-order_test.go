@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"testing"
+	"zohoclient/entity"
+)
 
 // TestTotalsDiverged pins the tolerance against the case that motivated it: order 17134, where
 // Zoho's subform was repriced from the 8.40% discount we sent to a flat 10% while every product
@@ -73,13 +76,13 @@ func TestTotalsDiverged(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			diff, got := totalsDiverged(tt.ocTotal, tt.zohoTot)
+			diff, got := totalsDiverged(entity.AmountOf(tt.ocTotal), entity.AmountOf(tt.zohoTot))
 			if got != tt.want {
-				t.Errorf("totalsDiverged(%.2f, %.2f) = %v, want %v (diff %.2f)",
+				t.Errorf("totalsDiverged(%.2f, %.2f) = %v, want %v (diff %s)",
 					tt.ocTotal, tt.zohoTot, got, tt.want, diff)
 			}
-			if tt.want && !approx(diff, tt.wantDiff, 0.005) {
-				t.Errorf("diff = %.2f, want %.2f", diff, tt.wantDiff)
+			if tt.want && diff != entity.AmountOf(tt.wantDiff) {
+				t.Errorf("diff = %s, want %.2f", diff, tt.wantDiff)
 			}
 		})
 	}
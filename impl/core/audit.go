@@ -0,0 +1,28 @@
+package core
+
+import "zohoclient/entity"
+
+// ZohoCalls returns the newest audited Zoho API calls matching the filter.
+func (c *Core) ZohoCalls(filter entity.ZohoCallFilter) ([]entity.ZohoCall, error) {
+	if c.mongoRepo == nil {
+		return nil, entity.ErrAuditNotAvailable
+	}
+	return c.mongoRepo.GetZohoCalls(filter)
+}
+
+// SaveApiCall stores the audit record of an authenticated API call; without MongoDB calls are
+// only logged.
+func (c *Core) SaveApiCall(call entity.ApiCall) error {
+	if c.mongoRepo == nil {
+		return nil
+	}
+	return c.mongoRepo.SaveApiCall(call)
+}
+
+// ApiCalls returns the newest audited API calls matching the filter.
+func (c *Core) ApiCalls(filter entity.ApiCallFilter) ([]entity.ApiCall, error) {
+	if c.mongoRepo == nil {
+		return nil, entity.ErrApiAuditNotAvailable
+	}
+	return c.mongoRepo.GetApiCalls(filter)
+}
@@ -0,0 +1,292 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+const (
+	// B2BWebhookCancelledStatus is the Deal stage an order_cancelled event moves the Deal to.
+	B2BWebhookCancelledStatus = "Скасовано"
+
+	// zohoBulkLimit is the maximum number of records Zoho accepts in one update or delete call.
+	zohoBulkLimit = 100
+)
+
+// updateB2BOrder patches the mapped Deal to match the order: Goods whose product is still
+// ordered are updated in place, new products are added, removed products are deleted, and the
+// Deal totals are rewritten.
+func (c *Core) updateB2BOrder(log *slog.Logger, payload *entity.B2BWebhookPayload) (string, error) {
+	deal, err := c.findB2BDeal(payload.Data.OrderUID)
+	if err != nil {
+		return "", err
+	}
+	log = log.With(slog.String("zoho_id", deal.ZohoID))
+
+	lineItems, err := c.resolveB2BWebhookProducts(payload.Data.Items)
+	if err != nil {
+		log.With(sl.Err(err)).Error("failed to resolve product Zoho IDs")
+		return "", fmt.Errorf("resolve product Zoho IDs: %w", err)
+	}
+
+	existing, err := c.zoho.GetB2BOrderGoods(deal.ZohoID)
+	if err != nil {
+		log.With(sl.Err(err)).Error("failed to read Deal goods")
+		return "", fmt.Errorf("get Deal goods: %w", err)
+	}
+
+	updated, created, deleted := planB2BGoodsPatch(existing, buildB2BGoods(&payload.Data, lineItems), deal.ZohoID)
+
+	for _, chunk := range chunkSlice(updated, zohoBulkLimit) {
+		if err = c.zoho.UpdateB2BOrderGoods(chunk); err != nil {
+			log.With(sl.Err(err)).Error("failed to update Deal goods")
+			return deal.ZohoID, fmt.Errorf("update Deal goods: %w", err)
+		}
+	}
+
+	if err = addChunkedItems(chunkSlice(created, ChunkSize), func(chunk []*entity.Good) (string, error) {
+		return c.zoho.AddItemsToOrderB2B(deal.ZohoID, chunk)
+	}); err != nil {
+		log.With(sl.Err(err)).Error("failed to add Deal goods")
+		return deal.ZohoID, err
+	}
+
+	for i := 0; i < len(deleted); i += zohoBulkLimit {
+		end := min(i+zohoBulkLimit, len(deleted))
+		if err = c.zoho.DeleteB2BOrderGoods(deleted[i:end]); err != nil {
+			log.With(sl.Err(err)).Error("failed to delete Deal goods")
+			return deal.ZohoID, fmt.Errorf("delete Deal goods: %w", err)
+		}
+	}
+
+	zohoOrder, _ := c.buildZohoOrderFromWebhook(&payload.Data, "", "", lineItems)
+	update := b2bDealUpdate(zohoOrder)
+	if err = c.zoho.UpdateB2BOrder(deal.ZohoID, update); err != nil {
+		log.With(sl.Err(err)).Error("failed to update Deal totals")
+		return deal.ZohoID, fmt.Errorf("update Deal: %w", err)
+	}
+
+	log.With(
+		slog.Int("goods_updated", len(updated)),
+		slog.Int("goods_added", len(created)),
+		slog.Int("goods_deleted", len(deleted)),
+	).Info("B2B Deal updated")
+	return deal.ZohoID, nil
+}
+
+// b2bDealUpdate builds the patch rewriting a Deal's totals, discount, VAT rate, comment and
+// delivery address from the order. All of them are sent, zero and empty included, so that a
+// discount taken off or a comment cleared on the portal is cleared on the Deal as well. Of the
+// currency totals only those of the order's currency are written.
+func b2bDealUpdate(order entity.ZohoOrderB2B) entity.ZohoOrderB2BUpdate {
+	update := entity.ZohoOrderB2BUpdate{
+		DiscountP:     &order.DiscountP,
+		Description:   &order.Description,
+		VAT:           &order.VAT,
+		BillingStreet: &order.BillingStreet,
+	}
+	switch order.Currency {
+	case entity.CurrencyUAH:
+		update.GrandTotalUAH, update.SubTotalUAH = &order.GrandTotalUAH, &order.SubTotalUAH
+	case entity.CurrencyPLN:
+		update.GrandTotalPLN, update.SubTotalPLN = &order.GrandTotalPLN, &order.SubTotalPLN
+	case entity.CurrencyUSD:
+		update.GrandTotalUSD, update.SubTotalUSD = &order.GrandTotalUSD, &order.SubTotalUSD
+	case entity.CurrencyEUR:
+		update.GrandTotalEUR, update.SubTotalEUR = &order.GrandTotalEUR, &order.SubTotalEUR
+	}
+	return update
+}
+
+// cancelB2BOrder moves the mapped Deal to the cancelled stage. Its Goods are kept so the
+// cancelled order stays readable in Zoho.
+func (c *Core) cancelB2BOrder(log *slog.Logger, payload *entity.B2BWebhookPayload) (string, error) {
+	deal, err := c.findB2BDeal(payload.Data.OrderUID)
+	if err != nil {
+		return "", err
+	}
+	log = log.With(slog.String("zoho_id", deal.ZohoID))
+
+	err = c.zoho.UpdateB2BOrder(deal.ZohoID, entity.ZohoOrderB2BUpdate{Status: B2BWebhookCancelledStatus})
+	if err != nil {
+		log.With(sl.Err(err)).Error("failed to cancel Deal")
+		return deal.ZohoID, fmt.Errorf("cancel Deal: %w", err)
+	}
+
+	log.Info("B2B Deal cancelled")
+	return deal.ZohoID, nil
+}
+
+// payB2BOrder creates a payment linked to the mapped Deal. The payment is claimed on the mapping
+// before it is created, as confirmB2BOrder claims the Deal: a redelivered order_paid finds the
+// payment id, or the claim of a delivery still creating it, and does not create a second one.
+func (c *Core) payB2BOrder(log *slog.Logger, payload *entity.B2BWebhookPayload) (string, error) {
+	deal, err := c.findB2BDeal(payload.Data.OrderUID)
+	if err != nil {
+		return "", err
+	}
+	orderUID := payload.Data.OrderUID
+	log = log.With(slog.String("zoho_id", deal.ZohoID))
+	if payload.Data.Payment != nil {
+		log = log.With(
+			slog.String("payment_method", payload.Data.Payment.Method),
+			slog.String("transaction_id", payload.Data.Payment.TransactionID),
+		)
+	}
+
+	if deal.PaymentID != "" {
+		log.With(slog.String("zoho_payment_id", deal.PaymentID)).Info("B2B payment redelivered, payment already exists")
+		return deal.ZohoID, nil
+	}
+	if unsaved, ok := c.b2bUnsavedPayments.Load(orderUID); ok {
+		// This process created the payment but could not record it; try again.
+		paymentId := unsaved.(string)
+		log.With(slog.String("zoho_payment_id", paymentId)).Info("B2B payment redelivered, payment created but not recorded")
+		return deal.ZohoID, c.saveB2BDealPaymentId(log, orderUID, paymentId)
+	}
+
+	claimedAt := deal.PaymentClaimedAt
+	takeover := !claimedAt.IsZero()
+	if takeover && time.Since(claimedAt) < b2bClaimTimeout {
+		return "", entity.ErrB2BOrderInProgress
+	}
+	claimed, err := c.mongoRepo.ClaimB2BDealPayment(orderUID, claimedAt)
+	if err != nil {
+		log.With(sl.Err(err)).Error("failed to claim B2B payment")
+		return "", fmt.Errorf("claim B2B payment: %w", err)
+	}
+	if !claimed {
+		return "", entity.ErrB2BOrderInProgress
+	}
+
+	if takeover {
+		log.With(slog.Time("claimed_at", claimedAt)).Warn("took over stale B2B payment claim")
+		// The delivery that left the claim may have created the payment without recording it.
+		paymentId, err := c.zoho.FindPaymentID("Deal", deal.ZohoID)
+		if err != nil {
+			log.With(sl.Err(err)).Error("failed to look up B2B payment")
+			return deal.ZohoID, fmt.Errorf("find B2B payment: %w", err)
+		}
+		if paymentId != "" {
+			log.With(slog.String("zoho_payment_id", paymentId)).Info("B2B payment of the stale claim found in Zoho")
+			return deal.ZohoID, c.saveB2BDealPaymentId(log, orderUID, paymentId)
+		}
+	}
+
+	payment := buildB2BPayment(&payload.Data, deal.ZohoID)
+	paymentId, err := c.zoho.CreatePayment(payment)
+	if err != nil {
+		log.With(sl.Err(err)).Error("failed to create B2B payment")
+		// Nothing was created in Zoho: free the payment so the portal's retry can create it.
+		if relErr := c.mongoRepo.ReleaseB2BDealPayment(orderUID); relErr != nil {
+			log.With(sl.Err(relErr)).Error("failed to release B2B payment claim")
+		}
+		return deal.ZohoID, fmt.Errorf("create payment: %w", err)
+	}
+
+	if err = c.saveB2BDealPaymentId(log, orderUID, paymentId); err != nil {
+		return deal.ZohoID, err
+	}
+
+	log.With(slog.String("zoho_payment_id", paymentId)).Info("B2B payment created")
+	return deal.ZohoID, nil
+}
+
+// saveB2BDealPaymentId records the payment created for a paid order, retrying a failing store
+// like saveB2BDealZohoId. If the store keeps failing the id is kept in memory for the portal's
+// retry of the event, and the error is returned so that the portal does retry.
+func (c *Core) saveB2BDealPaymentId(log *slog.Logger, orderUID, paymentId string) error {
+	var err error
+	for attempt := 0; attempt < b2bSaveAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * b2bSaveRetryDelay)
+		}
+		if err = c.mongoRepo.SetB2BDealPaymentId(orderUID, paymentId); err == nil {
+			c.b2bUnsavedPayments.Delete(orderUID)
+			return nil
+		}
+	}
+	c.b2bUnsavedPayments.Store(orderUID, paymentId)
+	log.With(sl.Err(err), slog.String("zoho_payment_id", paymentId), slog.Int("attempts", b2bSaveAttempts)).
+		Error("failed to store B2B payment id")
+	return fmt.Errorf("store payment id: %w", err)
+}
+
+// findB2BDeal returns the Deal mapping an event applies to. An order whose Deal is still being
+// created is reported as in progress, so the portal retries the event later.
+func (c *Core) findB2BDeal(orderUID string) (*entity.B2BDeal, error) {
+	if c.mongoRepo == nil {
+		return nil, fmt.Errorf("B2B deal store not available")
+	}
+	deal, err := c.mongoRepo.GetB2BDeal(orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("get B2B deal: %w", err)
+	}
+	if deal == nil {
+		return nil, entity.ErrB2BOrderNotFound
+	}
+	if deal.ZohoID == "" {
+		return nil, entity.ErrB2BOrderInProgress
+	}
+	return deal, nil
+}
+
+// buildB2BPayment builds the Zoho payment for an order_paid event, linked to the Deal.
+func buildB2BPayment(order *entity.B2BWebhookOrder, dealID string) entity.ZohoPayment {
+	amount := order.Total
+	paidAt := time.Now()
+	if order.Payment != nil {
+		if order.Payment.Amount > 0 {
+			amount = order.Payment.Amount
+		}
+		if !order.Payment.PaidAt.IsZero() {
+			paidAt = order.Payment.PaidAt
+		}
+	}
+
+	return entity.ZohoPayment{
+		Name:        fmt.Sprintf("B2B Payment %s", order.OrderNumber),
+		Deal:        &entity.ZohoDeal{ID: dealID},
+		Status:      entity.ZohoPaymentPaid,
+		Sum:         amount.Round(2),
+		Currency:    order.CurrencyCode,
+		PaymentTime: paidAt.Format(time.RFC3339),
+		Email:       order.ClientEmail,
+	}
+}
+
+// planB2BGoodsPatch matches the Deal's existing Goods to the wanted ones by product. A matched
+// record is updated in place (keeping its id), a wanted product without a record is created
+// and linked to the Deal, and a record whose product is no longer ordered is deleted.
+func planB2BGoodsPatch(existing, wanted []entity.Good, dealID string) (updated, created []entity.Good, deleted []string) {
+	byProduct := make(map[string][]entity.Good, len(existing))
+	for _, good := range existing {
+		byProduct[good.Product.ID] = append(byProduct[good.Product.ID], good)
+	}
+
+	for _, good := range wanted {
+		good.Deal = entity.ZohoDeal{ID: dealID}
+		records := byProduct[good.Product.ID]
+		if len(records) == 0 {
+			created = append(created, good)
+			continue
+		}
+		good.ID = records[0].ID
+		byProduct[good.Product.ID] = records[1:]
+		updated = append(updated, good)
+	}
+
+	// Walk existing in order so deletions are deterministic.
+	for _, good := range existing {
+		records := byProduct[good.Product.ID]
+		if len(records) > 0 && records[0].ID == good.ID {
+			deleted = append(deleted, good.ID)
+			byProduct[good.Product.ID] = records[1:]
+		}
+	}
+
+	return updated, created, deleted
+}
@@ -3,6 +3,8 @@ package core
 import (
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 	"zohoclient/entity"
 	"zohoclient/internal/lib/sl"
 )
@@ -11,17 +13,152 @@ const (
 	B2BWebhookPipeline    = "B2B"
 	B2BWebhookOrderSource = "B2B Portal"
 	B2BWebhookStatus      = "Нове замовлення"
+
+	// b2bClaimTimeout is how long a claimed order may go without a Deal id before another
+	// delivery is allowed to take it over. It only matters when a process died between
+	// claiming an order and recording the Deal it created.
+	b2bClaimTimeout = 5 * time.Minute
+
+	// b2bSaveAttempts is how many times the Deal id of a created Deal is written to the store
+	// before giving up on it.
+	b2bSaveAttempts   = 3
+	b2bSaveRetryDelay = 200 * time.Millisecond
 )
 
-// ProcessB2BWebhook handles incoming B2B webhook and creates a Zoho Deal
+// ProcessB2BWebhook dispatches an incoming B2B webhook by event and returns the id of the Zoho
+// Deal it was applied to. order_confirmed creates the Deal; order_updated, order_cancelled and
+// order_paid act on the Deal mapped to the order's order_uid.
 func (c *Core) ProcessB2BWebhook(payload *entity.B2BWebhookPayload) (string, error) {
 	log := c.log.With(
+		slog.String("event", payload.Event),
 		slog.String("order_uid", payload.Data.OrderUID),
 		slog.String("order_number", payload.Data.OrderNumber),
 		slog.String("currency", payload.Data.CurrencyCode),
-		slog.Float64("total", payload.Data.Total),
+		slog.String("total", payload.Data.Total.String()),
 	)
 
+	switch payload.Event {
+	case entity.B2BEventOrderConfirmed:
+		return c.confirmB2BOrder(log, payload)
+	case entity.B2BEventOrderUpdated:
+		return c.updateB2BOrder(log, payload)
+	case entity.B2BEventOrderCancelled:
+		return c.cancelB2BOrder(log, payload)
+	case entity.B2BEventOrderPaid:
+		return c.payB2BOrder(log, payload)
+	default:
+		return "", fmt.Errorf("unsupported B2B event %q", payload.Event)
+	}
+}
+
+// confirmB2BOrder creates the Zoho Deal for a newly confirmed order. Deliveries are idempotent
+// on order_uid: a redelivered order gets the Deal created for it the first time.
+func (c *Core) confirmB2BOrder(log *slog.Logger, payload *entity.B2BWebhookPayload) (string, error) {
+	if c.mongoRepo == nil {
+		log.Warn("no B2B deal store, redelivered orders will not be detected")
+		return c.createB2BDealFromWebhook(log, payload)
+	}
+
+	existing, claimed, err := c.mongoRepo.ClaimB2BDeal(entity.B2BDeal{
+		OrderUID:    payload.Data.OrderUID,
+		OrderNumber: payload.Data.OrderNumber,
+		ClientUID:   payload.Data.ClientUID,
+	})
+	if err != nil {
+		log.With(sl.Err(err)).Error("failed to claim B2B order")
+		return "", fmt.Errorf("claim B2B order: %w", err)
+	}
+	if !claimed {
+		if existing.ZohoID != "" {
+			log.With(slog.String("zoho_id", existing.ZohoID)).Info("B2B order redelivered, Deal already exists")
+			return existing.ZohoID, nil
+		}
+		if unsaved, ok := c.b2bUnsavedDeals.Load(payload.Data.OrderUID); ok {
+			// This process created the Deal but could not record it; try again rather than
+			// letting the claim go stale and a second Deal be created.
+			zohoId := unsaved.(string)
+			log.With(slog.String("zoho_id", zohoId)).Info("B2B order redelivered, Deal created but not recorded")
+			c.saveB2BDealZohoId(log, payload.Data.OrderUID, zohoId)
+			return zohoId, nil
+		}
+		if time.Since(existing.CreatedAt) < b2bClaimTimeout {
+			return "", entity.ErrB2BOrderInProgress
+		}
+		// Renew the claim so that deliveries arriving meanwhile wait for this one; of several
+		// deliveries finding the same stale claim only one gets it.
+		taken, err := c.mongoRepo.TakeOverB2BDeal(payload.Data.OrderUID, existing.CreatedAt)
+		if err != nil {
+			log.With(sl.Err(err)).Error("failed to take over B2B order claim")
+			return "", fmt.Errorf("take over B2B order: %w", err)
+		}
+		if !taken {
+			return "", entity.ErrB2BOrderInProgress
+		}
+		log.With(slog.Time("claimed_at", existing.CreatedAt)).Warn("took over stale B2B order claim")
+
+		// The delivery that left the claim may have created the Deal and failed to record it
+		// in a process that has restarted since, or on another replica.
+		zohoId, err := c.zoho.FindDealID("B2B_Order_UID", payload.Data.OrderUID)
+		if err != nil {
+			log.With(sl.Err(err)).Error("failed to look up B2B Deal")
+			return "", fmt.Errorf("find B2B Deal: %w", err)
+		}
+		if zohoId != "" {
+			log.With(slog.String("zoho_id", zohoId)).Info("B2B Deal of the stale claim found in Zoho")
+			c.saveB2BDealZohoId(log, payload.Data.OrderUID, zohoId)
+			return zohoId, nil
+		}
+	}
+
+	zohoId, err := c.createB2BDealFromWebhook(log, payload)
+	if zohoId == "" {
+		// Nothing was created in Zoho: free the order so the portal's retry can create it.
+		if relErr := c.mongoRepo.ReleaseB2BDeal(payload.Data.OrderUID); relErr != nil {
+			log.With(sl.Err(relErr)).Error("failed to release B2B order claim")
+		}
+		return "", err
+	}
+
+	// The Deal exists even if adding its items failed; record it so a retry does not create a
+	// second one.
+	c.saveB2BDealZohoId(log, payload.Data.OrderUID, zohoId)
+
+	return zohoId, err
+}
+
+// saveB2BDealZohoId records the Deal created for a claimed order, retrying a failing store. If
+// the store keeps failing the id is kept in memory, where a redelivery of the order finds it
+// instead of taking the claim over. A redelivery reaching another process takes the claim over
+// once stale and finds the Deal in Zoho by its B2B_Order_UID.
+func (c *Core) saveB2BDealZohoId(log *slog.Logger, orderUID, zohoId string) {
+	var err error
+	for attempt := 0; attempt < b2bSaveAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * b2bSaveRetryDelay)
+		}
+		if err = c.mongoRepo.SetB2BDealZohoId(orderUID, zohoId); err == nil {
+			c.b2bUnsavedDeals.Delete(orderUID)
+			return
+		}
+	}
+	c.b2bUnsavedDeals.Store(orderUID, zohoId)
+	log.With(sl.Err(err), slog.String("zoho_id", zohoId), slog.Int("attempts", b2bSaveAttempts)).
+		Error("failed to store B2B Deal id")
+}
+
+// GetB2BDeal returns the Zoho Deal mapping for a B2B portal order, or nil when the order has
+// never been received.
+func (c *Core) GetB2BDeal(orderUID string) (*entity.B2BDeal, error) {
+	if c.mongoRepo == nil {
+		return nil, fmt.Errorf("B2B deal store not available")
+	}
+	return c.mongoRepo.GetB2BDeal(orderUID)
+}
+
+// createB2BDealFromWebhook resolves products and contact and creates the Deal with its items.
+// The returned id is non-empty whenever the Deal itself was created, even if err is set.
+func (c *Core) createB2BDealFromWebhook(log *slog.Logger, payload *entity.B2BWebhookPayload) (string, error) {
+
 	// Step 1: Resolve Zoho product IDs for all items
 	lineItems, err := c.resolveB2BWebhookProducts(payload.Data.Items)
 	if err != nil {
@@ -29,21 +166,21 @@ func (c *Core) ProcessB2BWebhook(payload *entity.B2BWebhookPayload) (string, err
 		return "", fmt.Errorf("resolve product Zoho IDs: %w", err)
 	}
 
-	// Step 2: Create/find contact (placeholder with client_uid for now)
-	contactID, err := c.resolveB2BWebhookContact(&payload.Data)
+	// Step 2: Resolve the client's Account and contact person
+	contactID, accountID, err := c.resolveB2BWebhookClient(&payload.Data)
 	if err != nil {
-		log.With(sl.Err(err)).Error("failed to resolve contact")
-		return "", fmt.Errorf("resolve contact: %w", err)
+		log.With(sl.Err(err)).Error("failed to resolve client")
+		return "", fmt.Errorf("resolve client: %w", err)
 	}
 
 	// Step 3: Build Zoho B2B order
-	zohoOrder, chunkedItems := c.buildZohoOrderFromWebhook(&payload.Data, contactID, lineItems)
+	zohoOrder, chunkedItems := c.buildZohoOrderFromWebhook(&payload.Data, contactID, accountID, lineItems)
 
 	// Step 4: Create Deal in Zoho with items
 	zohoId, err := c.createB2BDealWithItems(zohoOrder, chunkedItems)
 	if err != nil {
-		log.With(sl.Err(err)).Error("failed to create Zoho Deal")
-		return "", err
+		log.With(sl.Err(err), slog.String("zoho_id", zohoId)).Error("failed to create Zoho Deal")
+		return zohoId, err
 	}
 
 	//log.With(slog.String("zoho_id", zohoId)).Info("B2B Deal created from webhook")
@@ -106,71 +243,137 @@ func (c *Core) resolveB2BWebhookProducts(items []entity.B2BWebhookItem) ([]*enti
 	return lineItems, nil
 }
 
-// resolveB2BWebhookContact creates or finds a contact for the B2B order.
-// Uses placeholder fields until client data is added to webhook payload.
-func (c *Core) resolveB2BWebhookContact(order *entity.B2BWebhookOrder) (string, error) {
-	clientDetails := &entity.ClientDetails{
-		FirstName: order.ClientName,
-		LastName:  "",
-		Email:     order.ClientEmail,
-		Phone:     order.ClientPhone,
-		Country:   order.ClientCountry,
-		City:      order.ClientCity,
-		Street:    order.ClientStreet,
-		ZipCode:   order.ClientZipCode,
-		TaxId:     order.ClientTaxID,
+// resolveB2BWebhookClient resolves the B2B client into a Zoho Account and its contact person.
+// The Account is matched by the portal client UID stored on it, then by tax id (NIP), then by
+// the Account of an existing Contact with the client's email; a new Account is created when
+// nothing matches. The matched Account gets the client UID, so later orders of the same client
+// attach to it directly. Its name is left as it is: the portal only knows the contact person.
+func (c *Core) resolveB2BWebhookClient(order *entity.B2BWebhookOrder) (contactID, accountID string, err error) {
+	accountID, err = c.findB2BAccount(order)
+	if err != nil {
+		return "", "", fmt.Errorf("find account: %w", err)
 	}
 
-	// Use placeholder if client name is empty
-	if clientDetails.FirstName == "" {
-		clientDetails.FirstName = "B2B Client"
-		clientDetails.LastName = order.ClientUID
+	if accountID == "" {
+		accountID, err = c.zoho.CreateAccount(buildB2BAccount(order))
+		if err != nil {
+			return "", "", fmt.Errorf("create account: %w", err)
+		}
+	} else if err = c.zoho.UpdateAccount(accountID, buildB2BAccountUpdate(order)); err != nil {
+		return "", "", fmt.Errorf("update account %s: %w", accountID, err)
 	}
 
-	// If no email and no phone, use placeholder email
-	if clientDetails.Email == "" && clientDetails.Phone == "" {
-		clientDetails.Email = fmt.Sprintf("%s@b2b.placeholder.local", order.ClientUID)
+	contactID, err = c.zoho.CreateB2BContact(buildB2BContact(order), accountID)
+	if err != nil {
+		return "", "", fmt.Errorf("create contact: %w", err)
 	}
 
-	contactID, err := c.zoho.CreateContact(clientDetails)
-	if err != nil {
-		return "", fmt.Errorf("create contact: %w", err)
+	return contactID, accountID, nil
+}
+
+// findB2BAccount returns the id of the Account the client belongs to, or "" when it is unknown.
+func (c *Core) findB2BAccount(order *entity.B2BWebhookOrder) (string, error) {
+	accountID, err := c.zoho.FindAccountID("B2B_Client_UID", order.ClientUID)
+	if err != nil || accountID != "" {
+		return accountID, err
+	}
+
+	if taxID := strings.TrimSpace(order.ClientTaxID); taxID != "" {
+		accountID, err = c.zoho.FindAccountID("NIP", taxID)
+		if err != nil || accountID != "" {
+			return accountID, err
+		}
+	}
+
+	if email := strings.TrimSpace(order.ClientEmail); email != "" {
+		return c.zoho.FindContactAccountID(email)
+	}
+
+	return "", nil
+}
+
+// buildB2BAccount maps the client fields of the order to a new Zoho Account, named after the
+// client.
+func buildB2BAccount(order *entity.B2BWebhookOrder) entity.ZohoAccount {
+	name := strings.TrimSpace(order.ClientName)
+	if name == "" {
+		name = fmt.Sprintf("B2B Client %s", order.ClientUID)
+	}
+
+	return entity.ZohoAccount{
+		AccountName:    name,
+		NIP:            strings.TrimSpace(order.ClientTaxID),
+		ClientUID:      order.ClientUID,
+		Phone:          order.ClientPhone,
+		BillingStreet:  order.ClientStreet,
+		BillingCity:    order.ClientCity,
+		BillingCode:    order.ClientZipCode,
+		BillingCountry: order.ClientCountry,
+	}
+}
+
+// buildB2BAccountUpdate maps the client fields of the order onto an existing Account. The
+// Account_Name is not sent: it is the company's name, which a manager may have set, while the
+// order only carries the name of the person who placed it.
+func buildB2BAccountUpdate(order *entity.B2BWebhookOrder) entity.ZohoAccount {
+	account := buildB2BAccount(order)
+	account.AccountName = ""
+	return account
+}
+
+// buildB2BContact maps the client fields of the order to the Account's contact person.
+func buildB2BContact(order *entity.B2BWebhookOrder) *entity.ClientDetails {
+	contact := &entity.ClientDetails{
+		Email:   order.ClientEmail,
+		Phone:   order.ClientPhone,
+		Country: order.ClientCountry,
+		City:    order.ClientCity,
+		Street:  order.ClientStreet,
+		ZipCode: order.ClientZipCode,
+		TaxId:   order.ClientTaxID,
+	}
+
+	names := strings.Fields(order.ClientName)
+	switch len(names) {
+	case 0:
+		contact.FirstName = "B2B Client"
+		contact.LastName = order.ClientUID
+	case 1:
+		contact.LastName = names[0]
+	default:
+		contact.FirstName = strings.Join(names[:len(names)-1], " ")
+		contact.LastName = names[len(names)-1]
+	}
+
+	// If no email and no phone, use placeholder email
+	if contact.Email == "" && contact.Phone == "" {
+		contact.Email = fmt.Sprintf("%s@b2b.placeholder.local", order.ClientUID)
 	}
 
-	return contactID, nil
+	return contact
 }
 
 // buildZohoOrderFromWebhook converts webhook data to ZohoOrderB2B
 func (c *Core) buildZohoOrderFromWebhook(
 	order *entity.B2BWebhookOrder,
-	contactID string,
+	contactID, accountID string,
 	lineItems []*entity.LineItem,
 ) (entity.ZohoOrderB2B, [][]*entity.Good) {
 
 	discountP := round0(order.DiscountPercent)
 
-	orderCurrency := Currency{
-		Code: order.CurrencyCode,
-		Rate: 1.0, // B2B portal sends values in target currency
-	}
-
-	// Build all goods items
-	allItems := make([]entity.Good, 0, len(lineItems))
-	for _, item := range lineItems {
-		allItems = append(allItems, buildGood(item, orderCurrency, discountP))
-	}
-
 	// Chunk items for Zoho API (max 100 per call)
-	chunkedItems := chunkSlice(allItems, ChunkSize)
+	chunkedItems := chunkSlice(buildB2BGoods(order, lineItems), ChunkSize)
 
 	// Calculate VAT rate from totals
 	vatRate := 0.0
 	if order.Subtotal > 0 && order.TotalVAT > 0 {
-		vatRate = round0(order.TotalVAT * 100 / order.Subtotal)
+		vatRate = round0(order.TotalVAT.Float64() * 100 / order.Subtotal.Float64())
 	}
 
 	zohoOrder := entity.ZohoOrderB2B{
 		ContactName:    entity.ContactName{ID: contactID},
+		NIP:            strings.TrimSpace(order.ClientTaxID),
 		DiscountP:      discountP,
 		Description:    order.Comment,
 		VAT:            vatRate,
@@ -182,10 +385,34 @@ func (c *Core) buildZohoOrderFromWebhook(
 		Subject:        fmt.Sprintf("B2B Order %s", order.OrderNumber),
 		Location:       ZohoLocation,
 		OrderSource:    B2BWebhookOrderSource,
+		OrderUID:       order.OrderUID,
+	}
+
+	if accountID != "" {
+		zohoOrder.AccountName = &entity.ZohoAccountRef{ID: accountID}
 	}
 
 	// Set currency-specific totals
-	setCurrencyTotals(&zohoOrder, orderCurrency.Code, order.Total, order.Subtotal)
+	setCurrencyTotals(&zohoOrder,
+		entity.NewMoney(order.Total, order.CurrencyCode),
+		entity.NewMoney(order.Subtotal, order.CurrencyCode),
+	)
 
 	return zohoOrder, chunkedItems
 }
+
+// buildB2BGoods builds the Goods records for the order's line items.
+func buildB2BGoods(order *entity.B2BWebhookOrder, lineItems []*entity.LineItem) []entity.Good {
+	discountP := round0(order.DiscountPercent)
+
+	orderCurrency := Currency{
+		Code: order.CurrencyCode,
+		Rate: 1.0, // B2B portal sends values in target currency
+	}
+
+	goods := make([]entity.Good, 0, len(lineItems))
+	for _, item := range lineItems {
+		goods = append(goods, buildGood(item, orderCurrency, discountP))
+	}
+	return goods
+}
@@ -0,0 +1,743 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+type b2bRepo struct {
+	Repository
+}
+
+func (r *b2bRepo) GetProductByUid(uid string) (string, string, error) {
+	return "Product " + uid, "Z-" + uid, nil
+}
+
+type b2bZoho struct {
+	Zoho
+	createDealCalls int
+	createDealErr   error
+	createdDeal     entity.ZohoOrderB2B
+	// dealsByOrder maps a B2B_Order_UID to a Deal already in Zoho.
+	dealsByOrder map[string]string
+
+	// accountsBy maps "field=value" (or "email=address") to an existing Account id.
+	accountsBy      map[string]string
+	createdAccounts []entity.ZohoAccount
+	updatedAccounts []string
+	accountUpdates  []entity.ZohoAccount
+	contact         *entity.ClientDetails
+	contactAccount  string
+
+	goods        []entity.Good
+	addedGoods   []*entity.Good
+	updatedGoods []*entity.Good
+	deletedGoods []string
+	dealUpdates  []entity.ZohoOrderB2BUpdate
+	payments     []entity.ZohoPayment
+	// paymentsByDeal maps a Deal id to a payment already linked to it in Zoho.
+	paymentsByDeal map[string]string
+}
+
+func (z *b2bZoho) FindAccountID(field, value string) (string, error) {
+	return z.accountsBy[field+"="+value], nil
+}
+
+func (z *b2bZoho) FindDealID(field, value string) (string, error) {
+	if field != "B2B_Order_UID" {
+		return "", errors.New("unexpected Deal search field " + field)
+	}
+	return z.dealsByOrder[value], nil
+}
+
+func (z *b2bZoho) FindPaymentID(field, value string) (string, error) {
+	if field != "Deal" {
+		return "", errors.New("unexpected payment search field " + field)
+	}
+	return z.paymentsByDeal[value], nil
+}
+
+func (z *b2bZoho) FindContactAccountID(email string) (string, error) {
+	return z.accountsBy["email="+email], nil
+}
+
+func (z *b2bZoho) CreateAccount(account entity.ZohoAccount) (string, error) {
+	z.createdAccounts = append(z.createdAccounts, account)
+	return "ACC-NEW", nil
+}
+
+func (z *b2bZoho) UpdateAccount(id string, account entity.ZohoAccount) error {
+	z.updatedAccounts = append(z.updatedAccounts, id)
+	z.accountUpdates = append(z.accountUpdates, account)
+	return nil
+}
+
+func (z *b2bZoho) CreateB2BContact(contact *entity.ClientDetails, accountID string) (string, error) {
+	z.contact = contact
+	z.contactAccount = accountID
+	return "contact-1", nil
+}
+
+func (z *b2bZoho) CreateB2BOrder(order entity.ZohoOrderB2B) (string, error) {
+	z.createDealCalls++
+	z.createdDeal = order
+	if z.createDealErr != nil {
+		return "", z.createDealErr
+	}
+	return "DEAL-1", nil
+}
+
+func (z *b2bZoho) AddItemsToOrderB2B(_ string, items []*entity.Good) (string, error) {
+	z.addedGoods = append(z.addedGoods, items...)
+	return "GOOD-1", nil
+}
+
+func (z *b2bZoho) GetB2BOrderGoods(string) ([]entity.Good, error) { return z.goods, nil }
+
+func (z *b2bZoho) UpdateB2BOrderGoods(items []*entity.Good) error {
+	z.updatedGoods = append(z.updatedGoods, items...)
+	return nil
+}
+
+func (z *b2bZoho) DeleteB2BOrderGoods(ids []string) error {
+	z.deletedGoods = append(z.deletedGoods, ids...)
+	return nil
+}
+
+func (z *b2bZoho) UpdateB2BOrder(_ string, update entity.ZohoOrderB2BUpdate) error {
+	z.dealUpdates = append(z.dealUpdates, update)
+	return nil
+}
+
+func (z *b2bZoho) CreatePayment(payment entity.ZohoPayment) (string, error) {
+	z.payments = append(z.payments, payment)
+	return "PAY-1", nil
+}
+
+// b2bDealStore keeps the order_uid -> Deal mapping in memory with the same claim semantics as
+// the Mongo store.
+type b2bDealStore struct {
+	MongoRepository
+	deals map[string]*entity.B2BDeal
+	// saveErr fails as many SetB2BDealZohoId calls as saveFailures.
+	saveErr      error
+	saveFailures int
+	// paymentSaveFailures fails as many SetB2BDealPaymentId calls with saveErr.
+	paymentSaveFailures int
+}
+
+func (s *b2bDealStore) ClaimB2BDeal(deal entity.B2BDeal) (*entity.B2BDeal, bool, error) {
+	if existing, ok := s.deals[deal.OrderUID]; ok {
+		return existing, false, nil
+	}
+	deal.CreatedAt = time.Now()
+	s.deals[deal.OrderUID] = &deal
+	return &deal, true, nil
+}
+
+func (s *b2bDealStore) SetB2BDealZohoId(orderUID, zohoID string) error {
+	if s.saveFailures > 0 {
+		s.saveFailures--
+		return s.saveErr
+	}
+	s.deals[orderUID].ZohoID = zohoID
+	return nil
+}
+
+func (s *b2bDealStore) TakeOverB2BDeal(orderUID string, claimedAt time.Time) (bool, error) {
+	d, ok := s.deals[orderUID]
+	if !ok || d.ZohoID != "" || !d.CreatedAt.Equal(claimedAt) {
+		return false, nil
+	}
+	d.CreatedAt = time.Now()
+	return true, nil
+}
+
+func (s *b2bDealStore) SetB2BDealPaymentId(orderUID, paymentID string) error {
+	if s.paymentSaveFailures > 0 {
+		s.paymentSaveFailures--
+		return s.saveErr
+	}
+	s.deals[orderUID].PaymentID = paymentID
+	return nil
+}
+
+func (s *b2bDealStore) ClaimB2BDealPayment(orderUID string, claimedAt time.Time) (bool, error) {
+	d, ok := s.deals[orderUID]
+	if !ok || d.PaymentID != "" || !d.PaymentClaimedAt.Equal(claimedAt) {
+		return false, nil
+	}
+	d.PaymentClaimedAt = time.Now()
+	return true, nil
+}
+
+func (s *b2bDealStore) ReleaseB2BDealPayment(orderUID string) error {
+	if d, ok := s.deals[orderUID]; ok && d.PaymentID == "" {
+		d.PaymentClaimedAt = time.Time{}
+	}
+	return nil
+}
+
+func (s *b2bDealStore) GetB2BDeal(orderUID string) (*entity.B2BDeal, error) {
+	d, ok := s.deals[orderUID]
+	if !ok {
+		return nil, nil
+	}
+	deal := *d
+	return &deal, nil
+}
+
+func (s *b2bDealStore) ReleaseB2BDeal(orderUID string) error {
+	if d, ok := s.deals[orderUID]; ok && d.ZohoID == "" {
+		delete(s.deals, orderUID)
+	}
+	return nil
+}
+
+func b2bTestCore(zoho *b2bZoho, store *b2bDealStore) *Core {
+	return &Core{
+		log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		repo:      &b2bRepo{},
+		zoho:      zoho,
+		mongoRepo: store,
+	}
+}
+
+func b2bPayload() *entity.B2BWebhookPayload {
+	return &entity.B2BWebhookPayload{
+		Event: "order_confirmed",
+		Data: entity.B2BWebhookOrder{
+			OrderUID:     "ord_abc123",
+			OrderNumber:  "1-1234",
+			ClientUID:    "cli_xyz789",
+			ClientName:   "John Doe",
+			ClientEmail:  "john@example.com",
+			CurrencyCode: "EUR",
+			Total:        entity.AmountOf(123),
+			Subtotal:     entity.AmountOf(100),
+			TotalVAT:     entity.AmountOf(23),
+			Items: []entity.B2BWebhookItem{
+				{ProductUID: "p1", Quantity: 2, Price: entity.AmountOf(50), Total: entity.AmountOf(100)},
+			},
+		},
+	}
+}
+
+// A retry from the portal must get the Deal created by the first delivery, not a second Deal.
+func TestProcessB2BWebhook_RedeliveryReturnsExistingDeal(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{}}
+	core := b2bTestCore(zoho, store)
+
+	first, err := core.ProcessB2BWebhook(b2bPayload())
+	if err != nil {
+		t.Fatalf("first delivery error = %v", err)
+	}
+	second, err := core.ProcessB2BWebhook(b2bPayload())
+	if err != nil {
+		t.Fatalf("second delivery error = %v", err)
+	}
+
+	if first != "DEAL-1" || second != first {
+		t.Errorf("deal ids = %q, %q, want both DEAL-1", first, second)
+	}
+	if zoho.createDealCalls != 1 {
+		t.Errorf("CreateB2BOrder calls = %d, want 1", zoho.createDealCalls)
+	}
+	if zoho.createdDeal.OrderUID != "ord_abc123" {
+		t.Errorf("Deal B2B_Order_UID = %q, want ord_abc123", zoho.createdDeal.OrderUID)
+	}
+	if store.deals["ord_abc123"].ZohoID != "DEAL-1" {
+		t.Errorf("stored zoho_id = %q, want DEAL-1", store.deals["ord_abc123"].ZohoID)
+	}
+}
+
+// A delivery arriving while the first one is still creating the Deal must not create another.
+func TestProcessB2BWebhook_ConcurrentDeliveryInProgress(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{
+		"ord_abc123": {OrderUID: "ord_abc123", CreatedAt: time.Now()},
+	}}
+	core := b2bTestCore(zoho, store)
+
+	_, err := core.ProcessB2BWebhook(b2bPayload())
+	if !errors.Is(err, entity.ErrB2BOrderInProgress) {
+		t.Fatalf("error = %v, want ErrB2BOrderInProgress", err)
+	}
+	if zoho.createDealCalls != 0 {
+		t.Errorf("CreateB2BOrder calls = %d, want 0", zoho.createDealCalls)
+	}
+}
+
+// A claim left behind by a delivery that never recorded its Deal is taken over once stale.
+func TestProcessB2BWebhook_StaleClaimTakenOver(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{
+		"ord_abc123": {OrderUID: "ord_abc123", CreatedAt: time.Now().Add(-2 * b2bClaimTimeout)},
+	}}
+	core := b2bTestCore(zoho, store)
+
+	zohoId, err := core.ProcessB2BWebhook(b2bPayload())
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if zohoId != "DEAL-1" || zoho.createDealCalls != 1 {
+		t.Errorf("zohoId = %q after %d create(s), want DEAL-1 after 1", zohoId, zoho.createDealCalls)
+	}
+	if age := time.Since(store.deals["ord_abc123"].CreatedAt); age > time.Minute {
+		t.Errorf("claim is %v old after the takeover, want renewed", age)
+	}
+}
+
+// A delivery that read a stale claim which another delivery has since taken over waits for it.
+func TestProcessB2BWebhook_StaleClaimTakenOverOnce(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{
+		"ord_abc123": {OrderUID: "ord_abc123", CreatedAt: time.Now()},
+	}}
+	core := b2bTestCore(zoho, store)
+	core.mongoRepo = &staleClaimStore{b2bDealStore: store, claimedAt: time.Now().Add(-2 * b2bClaimTimeout)}
+
+	_, err := core.ProcessB2BWebhook(b2bPayload())
+	if !errors.Is(err, entity.ErrB2BOrderInProgress) {
+		t.Fatalf("error = %v, want ErrB2BOrderInProgress", err)
+	}
+	if zoho.createDealCalls != 0 {
+		t.Errorf("CreateB2BOrder calls = %d, want 0", zoho.createDealCalls)
+	}
+}
+
+// A Deal created under a claim whose id was never recorded, by a process that has restarted
+// since, is found in Zoho after the takeover instead of being created again.
+func TestProcessB2BWebhook_StaleClaimDealFoundInZoho(t *testing.T) {
+	zoho := &b2bZoho{dealsByOrder: map[string]string{"ord_abc123": "DEAL-0"}}
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{
+		"ord_abc123": {OrderUID: "ord_abc123", CreatedAt: time.Now().Add(-2 * b2bClaimTimeout)},
+	}}
+	core := b2bTestCore(zoho, store)
+
+	zohoId, err := core.ProcessB2BWebhook(b2bPayload())
+	if err != nil || zohoId != "DEAL-0" {
+		t.Fatalf("delivery = %q, %v, want DEAL-0, nil", zohoId, err)
+	}
+	if zoho.createDealCalls != 0 {
+		t.Errorf("CreateB2BOrder calls = %d, want 0", zoho.createDealCalls)
+	}
+	if store.deals["ord_abc123"].ZohoID != "DEAL-0" {
+		t.Errorf("stored zoho_id = %q, want DEAL-0", store.deals["ord_abc123"].ZohoID)
+	}
+}
+
+// staleClaimStore returns claims as they were at claimedAt, before another delivery renewed them.
+type staleClaimStore struct {
+	*b2bDealStore
+	claimedAt time.Time
+}
+
+func (s *staleClaimStore) ClaimB2BDeal(deal entity.B2BDeal) (*entity.B2BDeal, bool, error) {
+	existing, claimed, err := s.b2bDealStore.ClaimB2BDeal(deal)
+	if err != nil || claimed {
+		return existing, claimed, err
+	}
+	seen := *existing
+	seen.CreatedAt = s.claimedAt
+	return &seen, false, nil
+}
+
+// A Deal whose id could not be stored is not created again by the next delivery.
+func TestProcessB2BWebhook_UnsavedDealNotRecreated(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{}, saveErr: errors.New("mongo down"), saveFailures: b2bSaveAttempts}
+	core := b2bTestCore(zoho, store)
+
+	if zohoId, err := core.ProcessB2BWebhook(b2bPayload()); err != nil || zohoId != "DEAL-1" {
+		t.Fatalf("first delivery = %q, %v, want DEAL-1, nil", zohoId, err)
+	}
+	if store.deals["ord_abc123"].ZohoID != "" {
+		t.Fatal("the store was expected to fail")
+	}
+
+	// Long after: the claim would be stale.
+	store.deals["ord_abc123"].CreatedAt = time.Now().Add(-2 * b2bClaimTimeout)
+	zohoId, err := core.ProcessB2BWebhook(b2bPayload())
+	if err != nil || zohoId != "DEAL-1" {
+		t.Fatalf("redelivery = %q, %v, want DEAL-1, nil", zohoId, err)
+	}
+	if zoho.createDealCalls != 1 {
+		t.Errorf("CreateB2BOrder calls = %d, want 1", zoho.createDealCalls)
+	}
+	if store.deals["ord_abc123"].ZohoID != "DEAL-1" {
+		t.Errorf("stored zoho_id = %q, want DEAL-1 once the store is back", store.deals["ord_abc123"].ZohoID)
+	}
+}
+
+// A store failing once is retried.
+func TestProcessB2BWebhook_DealIdSaveRetried(t *testing.T) {
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{}, saveErr: errors.New("timeout"), saveFailures: 1}
+	core := b2bTestCore(&b2bZoho{}, store)
+
+	if _, err := core.ProcessB2BWebhook(b2bPayload()); err != nil {
+		t.Fatal(err)
+	}
+	if store.deals["ord_abc123"].ZohoID != "DEAL-1" {
+		t.Errorf("stored zoho_id = %q, want DEAL-1", store.deals["ord_abc123"].ZohoID)
+	}
+}
+
+// When Zoho rejects the Deal the claim is released, so the portal's retry can create it.
+func TestProcessB2BWebhook_FailedCreateReleasesClaim(t *testing.T) {
+	zoho := &b2bZoho{createDealErr: errors.New("zoho down")}
+	store := &b2bDealStore{deals: map[string]*entity.B2BDeal{}}
+	core := b2bTestCore(zoho, store)
+
+	if _, err := core.ProcessB2BWebhook(b2bPayload()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, ok := store.deals["ord_abc123"]; ok {
+		t.Error("claim kept after failed create, retry would be blocked")
+	}
+
+	zoho.createDealErr = nil
+	zohoId, err := core.ProcessB2BWebhook(b2bPayload())
+	if err != nil || zohoId != "DEAL-1" {
+		t.Errorf("retry = %q, %v, want DEAL-1, nil", zohoId, err)
+	}
+}
+
+func confirmedB2BStore() *b2bDealStore {
+	return &b2bDealStore{deals: map[string]*entity.B2BDeal{
+		"ord_abc123": {OrderUID: "ord_abc123", ZohoID: "DEAL-1", CreatedAt: time.Now()},
+	}}
+}
+
+func TestPlanB2BGoodsPatch(t *testing.T) {
+	good := func(id, product string, qty int64) entity.Good {
+		return entity.Good{ID: id, Product: entity.ZohoProduct{ID: product}, Quantity: qty}
+	}
+	existing := []entity.Good{good("g1", "A", 1), good("g2", "B", 1), good("g3", "A", 1)}
+	wanted := []entity.Good{good("", "A", 5), good("", "C", 2)}
+
+	updated, created, deleted := planB2BGoodsPatch(existing, wanted, "DEAL-1")
+
+	if len(updated) != 1 || updated[0].ID != "g1" || updated[0].Quantity != 5 {
+		t.Errorf("updated = %+v, want g1 with quantity 5", updated)
+	}
+	if len(created) != 1 || created[0].Product.ID != "C" || created[0].Deal.ID != "DEAL-1" {
+		t.Errorf("created = %+v, want product C linked to DEAL-1", created)
+	}
+	if len(deleted) != 2 || deleted[0] != "g2" || deleted[1] != "g3" {
+		t.Errorf("deleted = %v, want [g2 g3]", deleted)
+	}
+}
+
+func TestProcessB2BWebhook_UpdatePatchesGoods(t *testing.T) {
+	zoho := &b2bZoho{goods: []entity.Good{
+		{ID: "g1", Product: entity.ZohoProduct{ID: "Z-p1"}, Quantity: 2},
+		{ID: "g2", Product: entity.ZohoProduct{ID: "Z-gone"}, Quantity: 1},
+	}}
+	core := b2bTestCore(zoho, confirmedB2BStore())
+
+	payload := b2bPayload()
+	payload.Event = entity.B2BEventOrderUpdated
+	payload.Data.Items = append(payload.Data.Items, entity.B2BWebhookItem{ProductUID: "p2", Quantity: 1, Price: entity.AmountOf(10), Total: entity.AmountOf(10)})
+
+	zohoId, err := core.ProcessB2BWebhook(payload)
+	if err != nil || zohoId != "DEAL-1" {
+		t.Fatalf("ProcessB2BWebhook() = %q, %v, want DEAL-1, nil", zohoId, err)
+	}
+	if zoho.createDealCalls != 0 {
+		t.Errorf("CreateB2BOrder calls = %d, want 0", zoho.createDealCalls)
+	}
+	if len(zoho.updatedGoods) != 1 || zoho.updatedGoods[0].ID != "g1" {
+		t.Errorf("updated goods = %+v, want g1", zoho.updatedGoods)
+	}
+	if len(zoho.addedGoods) != 1 || zoho.addedGoods[0].Product.ID != "Z-p2" {
+		t.Errorf("added goods = %+v, want Z-p2", zoho.addedGoods)
+	}
+	if len(zoho.deletedGoods) != 1 || zoho.deletedGoods[0] != "g2" {
+		t.Errorf("deleted goods = %v, want [g2]", zoho.deletedGoods)
+	}
+	if len(zoho.dealUpdates) != 1 || zoho.dealUpdates[0].GrandTotalEUR == nil || *zoho.dealUpdates[0].GrandTotalEUR != entity.AmountOf(123) || zoho.dealUpdates[0].Status != "" {
+		t.Errorf("deal updates = %+v, want totals without a stage change", zoho.dealUpdates)
+	}
+}
+
+// An update taking the discount off and clearing the comment must write the zero and the empty
+// comment to the Deal, not leave the old values in place.
+func TestProcessB2BWebhook_UpdateClearsDiscount(t *testing.T) {
+	zoho := &b2bZoho{goods: []entity.Good{
+		{ID: "g1", Product: entity.ZohoProduct{ID: "Z-p1"}, Quantity: 2, DiscountP: 10},
+	}}
+	core := b2bTestCore(zoho, confirmedB2BStore())
+
+	payload := b2bPayload()
+	payload.Event = entity.B2BEventOrderUpdated
+	payload.Data.DiscountPercent = 0
+	payload.Data.Comment = ""
+
+	if _, err := core.ProcessB2BWebhook(payload); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(zoho.dealUpdates) != 1 {
+		t.Fatalf("deal updates = %d, want 1", len(zoho.dealUpdates))
+	}
+	body, err := json.Marshal(zoho.dealUpdates[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"total_discount":0,"Description":"","VAT":23,"Grand_Total_EUR":123,"Total_EUR":100,"delivery_street":""}`
+	if string(body) != want {
+		t.Errorf("deal update = %s\nwant          %s", body, want)
+	}
+}
+
+func TestProcessB2BWebhook_CancelMovesStage(t *testing.T) {
+	zoho := &b2bZoho{}
+	core := b2bTestCore(zoho, confirmedB2BStore())
+
+	payload := b2bPayload()
+	payload.Event = entity.B2BEventOrderCancelled
+
+	if _, err := core.ProcessB2BWebhook(payload); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(zoho.dealUpdates) != 1 || zoho.dealUpdates[0].Status != B2BWebhookCancelledStatus {
+		t.Errorf("deal updates = %+v, want stage %q", zoho.dealUpdates, B2BWebhookCancelledStatus)
+	}
+	if len(zoho.deletedGoods) != 0 {
+		t.Errorf("deleted goods = %v, cancellation must keep items", zoho.deletedGoods)
+	}
+}
+
+func TestProcessB2BWebhook_PaidCreatesPaymentOnce(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := confirmedB2BStore()
+	core := b2bTestCore(zoho, store)
+
+	payload := b2bPayload()
+	payload.Event = entity.B2BEventOrderPaid
+	payload.Data.Payment = &entity.B2BWebhookPayment{Amount: entity.AmountOf(100), TransactionID: "tx-1"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := core.ProcessB2BWebhook(payload); err != nil {
+			t.Fatalf("delivery %d error = %v", i+1, err)
+		}
+	}
+
+	if len(zoho.payments) != 1 {
+		t.Fatalf("CreatePayment calls = %d, want 1", len(zoho.payments))
+	}
+	p := zoho.payments[0]
+	if p.Deal == nil || p.Deal.ID != "DEAL-1" || p.Sells != nil || p.Sum != entity.AmountOf(100) || p.Status != entity.ZohoPaymentPaid {
+		t.Errorf("payment = %+v, want 100 paid on DEAL-1", p)
+	}
+	if store.deals["ord_abc123"].PaymentID != "PAY-1" {
+		t.Errorf("stored payment_id = %q, want PAY-1", store.deals["ord_abc123"].PaymentID)
+	}
+}
+
+func b2bPaidPayload() *entity.B2BWebhookPayload {
+	payload := b2bPayload()
+	payload.Event = entity.B2BEventOrderPaid
+	return payload
+}
+
+// An order_paid arriving while another delivery is creating the payment must not create another.
+func TestProcessB2BWebhook_PaidConcurrentDeliveryInProgress(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := confirmedB2BStore()
+	store.deals["ord_abc123"].PaymentClaimedAt = time.Now()
+	core := b2bTestCore(zoho, store)
+
+	if _, err := core.ProcessB2BWebhook(b2bPaidPayload()); !errors.Is(err, entity.ErrB2BOrderInProgress) {
+		t.Fatalf("error = %v, want ErrB2BOrderInProgress", err)
+	}
+	if len(zoho.payments) != 0 {
+		t.Errorf("CreatePayment calls = %d, want 0", len(zoho.payments))
+	}
+}
+
+// A payment whose id could not be stored fails the delivery, and the portal's retry records it
+// instead of creating a second payment.
+func TestProcessB2BWebhook_PaidUnsavedPaymentNotRecreated(t *testing.T) {
+	zoho := &b2bZoho{}
+	store := confirmedB2BStore()
+	store.saveErr, store.paymentSaveFailures = errors.New("mongo down"), b2bSaveAttempts
+	core := b2bTestCore(zoho, store)
+
+	if _, err := core.ProcessB2BWebhook(b2bPaidPayload()); err == nil {
+		t.Fatal("first delivery succeeded, want the store error")
+	}
+	if _, err := core.ProcessB2BWebhook(b2bPaidPayload()); err != nil {
+		t.Fatalf("redelivery error = %v", err)
+	}
+	if len(zoho.payments) != 1 {
+		t.Errorf("CreatePayment calls = %d, want 1", len(zoho.payments))
+	}
+	if store.deals["ord_abc123"].PaymentID != "PAY-1" {
+		t.Errorf("stored payment_id = %q, want PAY-1", store.deals["ord_abc123"].PaymentID)
+	}
+}
+
+// A payment created under a stale claim but never recorded is found in Zoho by its Deal.
+func TestProcessB2BWebhook_PaidStaleClaimPaymentFoundInZoho(t *testing.T) {
+	zoho := &b2bZoho{paymentsByDeal: map[string]string{"DEAL-1": "PAY-0"}}
+	store := confirmedB2BStore()
+	store.deals["ord_abc123"].PaymentClaimedAt = time.Now().Add(-2 * b2bClaimTimeout)
+	core := b2bTestCore(zoho, store)
+
+	if _, err := core.ProcessB2BWebhook(b2bPaidPayload()); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(zoho.payments) != 0 {
+		t.Errorf("CreatePayment calls = %d, want 0", len(zoho.payments))
+	}
+	if store.deals["ord_abc123"].PaymentID != "PAY-0" {
+		t.Errorf("stored payment_id = %q, want PAY-0", store.deals["ord_abc123"].PaymentID)
+	}
+}
+
+func TestProcessB2BWebhook_EventWithoutDeal(t *testing.T) {
+	tests := []struct {
+		name  string
+		deals map[string]*entity.B2BDeal
+		want  error
+	}{
+		{"never confirmed", map[string]*entity.B2BDeal{}, entity.ErrB2BOrderNotFound},
+		{"deal being created", map[string]*entity.B2BDeal{"ord_abc123": {OrderUID: "ord_abc123"}}, entity.ErrB2BOrderInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := b2bTestCore(&b2bZoho{}, &b2bDealStore{deals: tt.deals})
+			payload := b2bPayload()
+			payload.Event = entity.B2BEventOrderCancelled
+
+			if _, err := core.ProcessB2BWebhook(payload); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessB2BWebhook_AccountResolution(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountsBy  map[string]string
+		wantAccount string
+		wantCreated bool
+	}{
+		{"matched by client uid", map[string]string{
+			"B2B_Client_UID=cli_xyz789": "ACC-UID",
+			"NIP=PL123":                 "ACC-NIP",
+		}, "ACC-UID", false},
+		{"matched by tax id", map[string]string{
+			"NIP=PL123":              "ACC-NIP",
+			"email=john@example.com": "ACC-MAIL",
+		}, "ACC-NIP", false},
+		{"matched by contact email", map[string]string{
+			"email=john@example.com": "ACC-MAIL",
+		}, "ACC-MAIL", false},
+		{"unknown client", map[string]string{}, "ACC-NEW", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zoho := &b2bZoho{accountsBy: tt.accountsBy}
+			core := b2bTestCore(zoho, &b2bDealStore{deals: map[string]*entity.B2BDeal{}})
+			payload := b2bPayload()
+			payload.Data.ClientTaxID = "PL123"
+			payload.Data.ClientStreet = "ul. Prosta 1"
+
+			if _, err := core.ProcessB2BWebhook(payload); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if (len(zoho.createdAccounts) == 1) != tt.wantCreated {
+				t.Errorf("created accounts = %+v, want created=%v", zoho.createdAccounts, tt.wantCreated)
+			}
+			if !tt.wantCreated && (len(zoho.updatedAccounts) != 1 || zoho.updatedAccounts[0] != tt.wantAccount) {
+				t.Errorf("updated accounts = %v, want [%s]", zoho.updatedAccounts, tt.wantAccount)
+			}
+			// A matched company Account keeps its name; the order's client name is a person's.
+			for _, update := range zoho.accountUpdates {
+				if update.AccountName != "" || update.ClientUID != "cli_xyz789" {
+					t.Errorf("account update = %+v, want the client uid and no name", update)
+				}
+			}
+			if tt.wantCreated && zoho.createdAccounts[0].AccountName != "John Doe" {
+				t.Errorf("created account name = %q, want John Doe", zoho.createdAccounts[0].AccountName)
+			}
+			if zoho.contactAccount != tt.wantAccount {
+				t.Errorf("contact linked to %q, want %q", zoho.contactAccount, tt.wantAccount)
+			}
+			deal := zoho.createdDeal
+			if deal.AccountName == nil || deal.AccountName.ID != tt.wantAccount || deal.NIP != "PL123" {
+				t.Errorf("deal account = %+v, NIP = %q, want %s and PL123", deal.AccountName, deal.NIP, tt.wantAccount)
+			}
+		})
+	}
+}
+
+func TestBuildB2BAccount(t *testing.T) {
+	order := &entity.B2BWebhookOrder{
+		ClientUID:     "cli_1",
+		ClientName:    "Acme Sp. z o.o.",
+		ClientTaxID:   " PL123 ",
+		ClientStreet:  "ul. Prosta 1",
+		ClientCity:    "Warszawa",
+		ClientZipCode: "00-001",
+		ClientCountry: "Poland",
+	}
+
+	account := buildB2BAccount(order)
+	want := entity.ZohoAccount{
+		AccountName:    "Acme Sp. z o.o.",
+		NIP:            "PL123",
+		ClientUID:      "cli_1",
+		BillingStreet:  "ul. Prosta 1",
+		BillingCity:    "Warszawa",
+		BillingCode:    "00-001",
+		BillingCountry: "Poland",
+	}
+	if account != want {
+		t.Errorf("buildB2BAccount() = %+v, want %+v", account, want)
+	}
+
+	want.AccountName = ""
+	if update := buildB2BAccountUpdate(order); update != want {
+		t.Errorf("buildB2BAccountUpdate() = %+v, want %+v", update, want)
+	}
+
+	order.ClientName = ""
+	if got := buildB2BAccount(order).AccountName; got != "B2B Client cli_1" {
+		t.Errorf("AccountName without client name = %q, want placeholder", got)
+	}
+}
+
+func TestBuildB2BContact_Names(t *testing.T) {
+	tests := []struct {
+		clientName string
+		first      string
+		last       string
+	}{
+		{"John Doe", "John", "Doe"},
+		{"Anna Maria Nowak", "Anna Maria", "Nowak"},
+		{"Acme", "", "Acme"},
+		{"", "B2B Client", "cli_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clientName, func(t *testing.T) {
+			contact := buildB2BContact(&entity.B2BWebhookOrder{ClientUID: "cli_1", ClientName: tt.clientName, ClientPhone: "+48"})
+			if contact.FirstName != tt.first || contact.LastName != tt.last {
+				t.Errorf("name = %q %q, want %q %q", contact.FirstName, contact.LastName, tt.first, tt.last)
+			}
+		})
+	}
+}
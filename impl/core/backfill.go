@@ -1,30 +1,20 @@
 package core
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"log/slog"
-	"math"
+	"slices"
+	"sync"
 	"time"
 	"zohoclient/entity"
+	"zohoclient/internal/database/sql"
 	"zohoclient/internal/lib/sl"
-)
 
-// discountEpsilon is the smallest difference worth an API call. Zoho stores DiscountP to 2
-// decimals and List_Price to 4, so anything below half a unit of the last digit is noise.
-const (
-	discountEpsilon  = 0.005
-	listPriceEpsilon = 0.00005
+	"golang.org/x/time/rate"
 )
 
-// BackfillResult is what a backfill run did, for the closing report.
-type BackfillResult struct {
-	Scanned   int
-	Corrected int // orders whose rows differ and were (or would be) rewritten
-	Unchanged int // already correct
-	Skipped   int // subform no longer matches what OpenCart holds
-	Failed    int
-}
-
 // BackfillOrderDiscounts repairs orders synced with the shipping-VAT bug, which inflated the
 // per-line discount by the VAT on carriage: the lines were discounted to pay for a VAT Zoho never
 // charges on the non-taxable shipping item, leaving Zoho's grand total short by Shipping x rate.
@@ -39,74 +29,122 @@ type BackfillResult struct {
 // order (see ZohoService.UpdateOrderItemRows).
 //
 // With apply=false nothing is written: the run reports what it would change.
-func (c *Core) BackfillOrderDiscounts(from, to time.Time, apply bool) (BackfillResult, error) {
-	var res BackfillResult
+func (c *Core) BackfillOrderDiscounts(from, to time.Time, apply bool) (entity.BackfillResult, error) {
+	return c.RunBackfill(entity.BackfillOptions{From: from, To: to, Apply: apply})
+}
+
+// RunBackfill is BackfillOrderDiscounts with the options of a long run: an id list instead of
+// the range, several workers within a Zoho call budget, a checkpoint to resume from, and
+// progress reporting. It may run inside the live service: an applying run holds the pollers for
+// its duration, so no new order is pushed while the rewrite is under way. The hold is its own,
+// apart from the pause an admin sets: pollers paused before or during the run stay paused.
+func (c *Core) RunBackfill(opts entity.BackfillOptions) (entity.BackfillResult, error) {
+	var res entity.BackfillResult
+	apply := opts.Apply
 
 	if c.repo == nil || c.zoho == nil {
 		return res, fmt.Errorf("backfill needs both the database and the Zoho service")
 	}
 
-	log := c.log.With(
-		sl.Module("backfill"),
-		slog.String("from", from.Format(time.DateOnly)),
-		slog.String("to", to.Format(time.DateOnly)),
-		slog.Bool("apply", apply),
-	)
+	log := c.log.With(sl.Module("backfill"), slog.Bool("apply", apply))
+	if len(opts.OrderIds) > 0 {
+		log = log.With(slog.Int("order_ids", len(opts.OrderIds)))
+	} else {
+		log = log.With(
+			slog.String("from", opts.From.Format(time.DateOnly)),
+			slog.String("to", opts.To.Format(time.DateOnly)),
+		)
+	}
 
-	orders, err := c.repo.OrdersSyncedBetween(from, to)
+	orders, lines, err := c.backfillOrders(opts)
 	if err != nil {
-		return res, fmt.Errorf("load synced orders: %w", err)
+		return res, err
+	}
+	for _, line := range lines {
+		res.Add(line)
 	}
 
-	log.With(slog.Int("orders", len(orders))).Info("backfill started")
-
-	for _, synced := range orders {
-		res.Scanned++
-		oc := synced.Order
-		olog := log.With(
-			slog.Int64("order_id", oc.OrderId),
-			slog.String("zoho_id", synced.ZohoID),
-			slog.Float64("shipping", round2(oc.Shipping)),
-		)
-
-		patches, err := c.backfillPatches(synced.ZohoID, oc)
+	// Orders an earlier run finished are reported as they were; failures are tried again.
+	if opts.Checkpoint != nil {
+		finished, err := opts.Checkpoint.Finished()
 		if err != nil {
-			res.Skipped++
-			olog.With(sl.Err(err)).Warn("order skipped")
-			continue
+			return res, fmt.Errorf("read checkpoint: %w", err)
 		}
-		if len(patches) == 0 {
-			res.Unchanged++
-			olog.Debug("order already correct")
-			continue
+		done := make(map[int64]entity.BackfillOrder, len(finished))
+		for _, line := range finished {
+			if line.Outcome != entity.BackfillFailed {
+				done[line.OrderId] = line
+			}
 		}
+		pending := orders[:0]
+		for _, o := range orders {
+			if line, ok := done[o.Order.OrderId]; ok {
+				res.Add(line)
+				res.Resumed++
+				continue
+			}
+			pending = append(pending, o)
+		}
+		orders = pending
+	}
 
-		res.Corrected++
-		olog = olog.With(slog.Int("rows", len(patches)))
+	log.With(slog.Int("orders", len(orders)), slog.Int("resumed", res.Resumed)).Info("backfill started")
 
-		if !apply {
-			olog.Info("would correct order (dry run)")
-			continue
-		}
+	if apply {
+		c.backfillPauses.Add(1)
+		log.Info("pollers paused for the backfill")
+		defer func() {
+			if c.backfillPauses.Add(-1) == 0 && !c.paused.Load() {
+				log.Info("pollers resumed after the backfill")
+			}
+		}()
+	}
 
-		modified, err := c.zoho.UpdateOrderItemRows(synced.ZohoID, patches)
-		if err != nil {
-			res.Corrected--
-			res.Failed++
-			olog.With(sl.Err(err)).Error("order not corrected")
-			continue
-		}
+	var limiter *rate.Limiter
+	if opts.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.Rate), 1)
+	}
+	workers := min(max(opts.Workers, 1), max(len(orders), 1))
 
-		// Our own write comes back as a webhook; record the version so it is recognised as an
-		// echo rather than reverse-synced into OpenCart.
-		if t, err := time.Parse(time.RFC3339, modified); err == nil {
-			if err = c.repo.SetOrderZohoModifiedTime(oc.OrderId, t); err != nil {
-				olog.With(sl.Err(err)).Warn("store zoho_modified_time failed")
+	// Lines are kept in the order the orders were listed, whichever worker finishes first.
+	results := make([]entity.BackfillOrder, len(orders))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				line := c.backfillOrder(log, orders[i], apply, limiter)
+				results[i] = line
+
+				mu.Lock()
+				if opts.Checkpoint != nil {
+					if err := opts.Checkpoint.Save(line); err != nil {
+						log.With(sl.Err(err), slog.Int64("order_id", line.OrderId)).Warn("checkpoint not saved")
+					}
+				}
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, len(orders))
+				}
+				mu.Unlock()
 			}
-		}
+		}()
+	}
+	for i := range orders {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-		olog.Info("order corrected")
+	for _, line := range results {
+		res.Add(line)
 	}
+	// Skipped, resumed and checked orders read as one list.
+	slices.SortStableFunc(res.Orders, func(a, b entity.BackfillOrder) int { return cmp.Compare(a.OrderId, b.OrderId) })
 
 	log.With(
 		slog.Int("scanned", res.Scanned),
@@ -114,15 +152,114 @@ func (c *Core) BackfillOrderDiscounts(from, to time.Time, apply bool) (BackfillR
 		slog.Int("unchanged", res.Unchanged),
 		slog.Int("skipped", res.Skipped),
 		slog.Int("failed", res.Failed),
+		slog.Int("resumed", res.Resumed),
 	).Info("backfill finished")
 
 	return res, nil
 }
 
+// backfillOrders loads the orders a run checks: those placed in the range, or the listed ones.
+// A listed order that cannot be checked is returned as a skipped line instead.
+func (c *Core) backfillOrders(opts entity.BackfillOptions) ([]sql.SyncedOrder, []entity.BackfillOrder, error) {
+	if len(opts.OrderIds) == 0 {
+		orders, err := c.repo.OrdersSyncedBetween(opts.From, opts.To)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load synced orders: %w", err)
+		}
+		return orders, nil, nil
+	}
+
+	var orders []sql.SyncedOrder
+	var skipped []entity.BackfillOrder
+	seen := make(map[int64]bool, len(opts.OrderIds))
+	for _, id := range opts.OrderIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		zohoId, oc, err := c.repo.OrderSearchId(id)
+		switch {
+		case err != nil:
+			skipped = append(skipped, entity.BackfillOrder{OrderId: id, Outcome: entity.BackfillSkipped, Reason: err.Error()})
+		case oc == nil || !zohoOrderExists(zohoId):
+			skipped = append(skipped, entity.BackfillOrder{OrderId: id, ZohoID: zohoId, Outcome: entity.BackfillSkipped,
+				Reason: "not synced to a Zoho Sales Order"})
+		default:
+			orders = append(orders, sql.SyncedOrder{ZohoID: zohoId, Order: oc})
+		}
+	}
+	return orders, skipped, nil
+}
+
+// waitBudget blocks until the Zoho call budget allows one more call; a nil limiter never waits.
+func waitBudget(limiter *rate.Limiter) {
+	if limiter != nil {
+		_ = limiter.Wait(context.Background())
+	}
+}
+
+// backfillOrder checks one synced order and, when apply is set, rewrites its drifted rows.
+func (c *Core) backfillOrder(log *slog.Logger, synced sql.SyncedOrder, apply bool, limiter *rate.Limiter) entity.BackfillOrder {
+	oc := synced.Order
+	line := entity.BackfillOrder{OrderId: oc.OrderId, ZohoID: synced.ZohoID}
+	olog := log.With(
+		slog.Int64("order_id", oc.OrderId),
+		slog.String("zoho_id", synced.ZohoID),
+		slog.Float64("shipping", oc.Shipping.Round(2).Float64()),
+	)
+
+	waitBudget(limiter)
+	patches, err := c.backfillPatches(synced.ZohoID, oc, &line)
+	if err != nil {
+		line.Outcome = entity.BackfillSkipped
+		line.Reason = err.Error()
+		line.Changes = nil
+		olog.With(sl.Err(err)).Warn("order skipped")
+		return line
+	}
+	if len(patches) == 0 {
+		line.Outcome = entity.BackfillUnchanged
+		olog.Debug("order already correct")
+		return line
+	}
+
+	line.Rows = len(patches)
+	olog = olog.With(slog.Int("rows", len(patches)))
+
+	if !apply {
+		line.Outcome = entity.BackfillWouldCorrect
+		olog.Info("would correct order (dry run)")
+		return line
+	}
+
+	waitBudget(limiter)
+	modified, err := c.zoho.UpdateOrderItemRows(synced.ZohoID, patches)
+	if err != nil {
+		line.Outcome = entity.BackfillFailed
+		line.Reason = err.Error()
+		olog.With(sl.Err(err)).Error("order not corrected")
+		return line
+	}
+
+	// Our own write comes back as a webhook; record the version so it is recognised as an
+	// echo rather than reverse-synced into OpenCart.
+	if t, err := time.Parse(time.RFC3339, modified); err == nil {
+		if err = c.repo.SetOrderZohoModifiedTime(oc.OrderId, t); err != nil {
+			olog.With(sl.Err(err)).Warn("store zoho_modified_time failed")
+		}
+	}
+
+	line.Outcome = entity.BackfillCorrected
+	olog.Info("order corrected")
+	return line
+}
+
 // backfillPatches returns the subform rows that need rewriting for one order, or an empty slice
 // when Zoho already holds the right figures. It errors when the Zoho subform no longer lines up
-// with the OpenCart order, which means the record was edited and must be left to a human.
-func (c *Core) backfillPatches(zohoID string, oc *entity.CheckoutParams) ([]entity.OrderedItemPatch, error) {
+// with the OpenCart order, which means the record was edited and must be left to a human. The
+// discount drift is recorded on line for the report.
+func (c *Core) backfillPatches(zohoID string, oc *entity.CheckoutParams, line *entity.BackfillOrder) ([]entity.OrderedItemPatch, error) {
 	record, err := c.zoho.GetOrder(zohoID)
 	if err != nil {
 		return nil, fmt.Errorf("read zoho order: %w", err)
@@ -149,11 +286,19 @@ func (c *Core) backfillPatches(zohoID string, oc *entity.CheckoutParams) ([]enti
 				i+1, row.Quantity, expected.Quantity)
 		}
 
-		if math.Abs(row.DiscountP-expected.DiscountP) < discountEpsilon &&
-			math.Abs(row.ListPrice-expected.ListPrice) < listPriceEpsilon {
+		// Both sides hold the percentage to 2 decimals and the price as an exact amount.
+		if row.DiscountP == expected.DiscountP && row.ListPrice == expected.ListPrice {
 			continue
 		}
 
+		line.Changes = append(line.Changes, entity.BackfillRowChange{
+			RowID:        row.ID,
+			ProductID:    expected.Product.ID,
+			ListPriceWas: row.ListPrice,
+			ListPriceNow: expected.ListPrice,
+			DiscountWas:  row.DiscountP,
+			DiscountNow:  expected.DiscountP,
+		})
 		patches = append(patches, entity.OrderedItemPatch{
 			ID:        row.ID,
 			Product:   entity.ZohoProduct{ID: expected.Product.ID},
@@ -166,17 +311,21 @@ func (c *Core) backfillPatches(zohoID string, oc *entity.CheckoutParams) ([]enti
 
 	// Nothing is corrected piecemeal: either every drifted row goes, or none does.
 	if len(patches) == 0 {
+		line.Changes = nil
 		return nil, nil
 	}
 
+	line.DiscountWas = record.OrderedItems[0].DiscountP
+	line.DiscountNow = want[0].DiscountP
+
 	c.log.With(
 		sl.Module("backfill"),
 		slog.Int64("order_id", oc.OrderId),
 		slog.String("zoho_id", zohoID),
 		slog.Float64("discount_was", record.OrderedItems[0].DiscountP),
 		slog.Float64("discount_now", want[0].DiscountP),
-		slog.Float64("zoho_total_was", round2(record.GrandTotal)),
-		slog.Float64("charged", round2(oc.Total)),
+		slog.Float64("zoho_total_was", record.GrandTotal.Round(2).Float64()),
+		slog.Float64("charged", oc.Total.Round(2).Float64()),
 	).Info("discount drift")
 
 	return patches, nil
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 	"zohoclient/entity"
@@ -13,7 +15,9 @@ import (
 type backfillRepo struct {
 	Repository
 	orders []sql.SyncedOrder
+	byId   map[int64]sql.SyncedOrder
 
+	mu                sync.Mutex
 	modifiedTimeCalls int
 }
 
@@ -21,23 +25,42 @@ func (r *backfillRepo) OrdersSyncedBetween(time.Time, time.Time) ([]sql.SyncedOr
 	return r.orders, nil
 }
 
+func (r *backfillRepo) OrderSearchId(orderId int64) (string, *entity.CheckoutParams, error) {
+	o, ok := r.byId[orderId]
+	if !ok {
+		return "", nil, fmt.Errorf("order with id %d not found", orderId)
+	}
+	return o.ZohoID, o.Order, nil
+}
+
 func (r *backfillRepo) SetOrderZohoModifiedTime(int64, time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.modifiedTimeCalls++
 	return nil
 }
 
 type backfillZoho struct {
 	Zoho
-	record *entity.ZohoOrderRecord
+	record  *entity.ZohoOrderRecord
+	records map[string]*entity.ZohoOrderRecord // by id, for runs over several orders
 
+	mu          sync.Mutex
 	updateCalls int
 	updatedID   string
 	updatedRows []entity.OrderedItemPatch
 }
 
-func (z *backfillZoho) GetOrder(string) (*entity.ZohoOrderRecord, error) { return z.record, nil }
+func (z *backfillZoho) GetOrder(id string) (*entity.ZohoOrderRecord, error) {
+	if rec, ok := z.records[id]; ok {
+		return rec, nil
+	}
+	return z.record, nil
+}
 
 func (z *backfillZoho) UpdateOrderItemRows(orderID string, rows []entity.OrderedItemPatch) (string, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
 	z.updateCalls++
 	z.updatedID = orderID
 	z.updatedRows = rows
@@ -58,15 +81,15 @@ func backfillCore(repo *backfillRepo, zoho *backfillZoho) *Core {
 func order17103() *entity.CheckoutParams {
 	return &entity.CheckoutParams{
 		OrderId: 17103, Currency: "PLN",
-		SubTotal: 878.0474, TaxValue: 165.9510,
-		Coupon: -87.8047, CouponTitle: "Kupon (dark-6B89EB81)",
-		Shipping: 39.90, Total: 996.0936,
+		SubTotal: entity.AmountOf(878.0474), TaxValue: entity.AmountOf(165.9510),
+		Coupon: entity.AmountOf(-87.8047), CouponTitle: "Kupon (dark-6B89EB81)",
+		Shipping: entity.AmountOf(39.90), Total: entity.AmountOf(996.0936),
 		ClientDetails: minimalClient(),
 		LineItems: []*entity.LineItem{
-			{Name: "Gel Polish", ZohoId: "Z1", Price: 24.3902, Qty: 30, Tax: 5.1219, Total: 731.706},
-			{Name: "Builder Gel", ZohoId: "Z2", Price: 52.8455, Qty: 1, Tax: 11.0976, Total: 52.8455},
-			{Name: "Flash", ZohoId: "Z3", Price: 48.7805, Qty: 1, Tax: 10.2439, Total: 48.7805},
-			{Name: "Cat Eye", ZohoId: "Z4", Price: 44.7154, Qty: 1, Tax: 9.3902, Total: 44.7154},
+			{Name: "Gel Polish", ZohoId: "Z1", Price: entity.AmountOf(24.3902), Qty: 30, Tax: entity.AmountOf(5.1219), Total: entity.AmountOf(731.706)},
+			{Name: "Builder Gel", ZohoId: "Z2", Price: entity.AmountOf(52.8455), Qty: 1, Tax: entity.AmountOf(11.0976), Total: entity.AmountOf(52.8455)},
+			{Name: "Flash", ZohoId: "Z3", Price: entity.AmountOf(48.7805), Qty: 1, Tax: entity.AmountOf(10.2439), Total: entity.AmountOf(48.7805)},
+			{Name: "Cat Eye", ZohoId: "Z4", Price: entity.AmountOf(44.7154), Qty: 1, Tax: entity.AmountOf(9.3902), Total: entity.AmountOf(44.7154)},
 		},
 	}
 }
@@ -75,16 +98,13 @@ func order17103() *entity.CheckoutParams {
 // shipping VAT into the product lines: discountP = 1 - (Total/(1+rate) - Shipping)/SubTotal.
 func syncedWithBug(oc *entity.CheckoutParams, zohoID string) *entity.ZohoOrderRecord {
 	rate := oc.VatRate()
-	var discountP float64
-	if oc.SubTotal > 0 {
-		discountP = round4((1 - (oc.Total/(1+rate)-oc.Shipping)/oc.SubTotal) * 100)
-	}
+	bugNet := oc.Total.Div(1+rate) - oc.Shipping
 
 	rec := &entity.ZohoOrderRecord{ID: zohoID, Subject: fmt.Sprintf("Order #%d", oc.OrderId)}
 	var net float64
 	for i, li := range oc.LineItems {
-		it := buildOrderedItem(li, discountP)
-		net += it.ListPrice * float64(it.Quantity) * (1 - it.DiscountP/100)
+		it := buildOrderedItem(li, bugNet, oc.SubTotal)
+		net += zohoLineTotal(it)
 		rec.OrderedItems = append(rec.OrderedItems, entity.ZohoOrderedItemRow{
 			ID:        fmt.Sprintf("row-%d", i+1),
 			Product:   it.Product,
@@ -103,7 +123,7 @@ func syncedWithBug(oc *entity.CheckoutParams, zohoID string) *entity.ZohoOrderRe
 			Total:     oc.Shipping,
 		})
 	}
-	rec.GrandTotal = r2(net*(1+rate) + oc.Shipping)
+	rec.GrandTotal = entity.AmountOf(net*(1+rate) + oc.Shipping.Float64()).Round(2)
 
 	return rec
 }
@@ -121,7 +141,7 @@ func syncedCorrectly(core *Core, oc *entity.CheckoutParams, zohoID string) *enti
 			Total:     it.Total,
 		})
 	}
-	rec.GrandTotal = round2(oc.Total)
+	rec.GrandTotal = oc.Total.Round(2)
 
 	return rec
 }
@@ -143,8 +163,8 @@ func TestBackfill_CorrectsShippingVatDrift(t *testing.T) {
 	if got := zoho.record.OrderedItems[0].DiscountP; !approx(got, 10.79, 0.001) {
 		t.Fatalf("fixture DiscountP = %v, want the buggy 10.79", got)
 	}
-	if drift := round2(oc.Total) - zoho.record.GrandTotal; !approx(drift, oc.Shipping*oc.VatRate(), 0.01) {
-		t.Fatalf("fixture grand total drift = %.2f, want Shipping x rate = %.2f", drift, oc.Shipping*oc.VatRate())
+	if drift := oc.Total.Round(2) - zoho.record.GrandTotal; !approx(drift.Float64(), oc.Shipping.Mul(oc.VatRate()).Float64(), 0.01) {
+		t.Fatalf("fixture grand total drift = %s, want Shipping x rate = %s", drift, oc.Shipping.Mul(oc.VatRate()))
 	}
 
 	from, to := backfillDay()
@@ -171,7 +191,7 @@ func TestBackfill_CorrectsShippingVatDrift(t *testing.T) {
 			t.Errorf("row %d DiscountP = %v, want 10", i, row.DiscountP)
 		}
 	}
-	if got := zoho.updatedRows[0].ListPrice; !approx(got, 24.3902, 0.0001) {
+	if got := zoho.updatedRows[0].ListPrice; got != entity.AmountOf(24.3902) {
 		t.Errorf("row 1 List_Price = %v, want the catalogue 24.3902", got)
 	}
 	// Our own write must be recorded so the resulting webhook is recognised as an echo.
@@ -202,6 +222,89 @@ func TestBackfill_DryRunWritesNothing(t *testing.T) {
 	}
 }
 
+// The report carries a line per order with the drift, progress is reported per order, and an
+// applying run inside the live service holds the pollers only while it runs.
+func TestRunBackfill_ReportsOrdersAndProgress(t *testing.T) {
+	oc := order17103()
+	repo := &backfillRepo{orders: []sql.SyncedOrder{{ZohoID: "ZO-1", Order: oc}}}
+	zoho := &backfillZoho{record: syncedWithBug(oc, "ZO-1")}
+	core := backfillCore(repo, zoho)
+
+	var progress [][2]int
+	from, to := backfillDay()
+	res, err := core.RunBackfill(entity.BackfillOptions{
+		From:  from,
+		To:    to,
+		Apply: true,
+		Progress: func(done, total int) {
+			if !core.PollersPaused() {
+				t.Error("pollers not paused during an applying backfill")
+			}
+			progress = append(progress, [2]int{done, total})
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunBackfill() error = %v", err)
+	}
+
+	if len(progress) != 1 || progress[0] != [2]int{1, 1} {
+		t.Errorf("progress = %v, want [[1 1]]", progress)
+	}
+	if core.PollersPaused() {
+		t.Error("pollers still paused after the backfill")
+	}
+	if len(res.Orders) != 1 {
+		t.Fatalf("report lines = %d, want 1", len(res.Orders))
+	}
+	line := res.Orders[0]
+	if line.OrderId != oc.OrderId || line.Outcome != entity.BackfillCorrected || line.Rows != 4 {
+		t.Errorf("report line = %+v, want order %d corrected on 4 rows", line, oc.OrderId)
+	}
+	if !approx(line.DiscountWas, 10.79, 0.001) || !approx(line.DiscountNow, 10, 0.001) {
+		t.Errorf("discount %v -> %v, want 10.79 -> 10", line.DiscountWas, line.DiscountNow)
+	}
+}
+
+// A pause an admin sets while a backfill runs outlasts it, and a resume while it runs does not
+// release the backfill's own hold.
+func TestRunBackfill_KeepsAdminPause(t *testing.T) {
+	oc := order17103()
+	from, to := backfillDay()
+
+	tests := []struct {
+		name       string
+		during     func(core *Core)
+		wantPaused bool
+	}{
+		{"paused during the run", func(core *Core) { core.PausePollers() }, true},
+		{"resumed during the run", func(core *Core) {
+			core.ResumePollers()
+			if !core.PollersPaused() {
+				t.Error("pollers released by a resume while the backfill runs")
+			}
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &backfillRepo{orders: []sql.SyncedOrder{{ZohoID: "ZO-1", Order: oc}}}
+			core := backfillCore(repo, &backfillZoho{record: syncedWithBug(oc, "ZO-1")})
+
+			_, err := core.RunBackfill(entity.BackfillOptions{
+				From:     from,
+				To:       to,
+				Apply:    true,
+				Progress: func(int, int) { tt.during(core) },
+			})
+			if err != nil {
+				t.Fatalf("RunBackfill() error = %v", err)
+			}
+			if core.PollersPaused() != tt.wantPaused {
+				t.Errorf("paused after the backfill = %v, want %v", core.PollersPaused(), tt.wantPaused)
+			}
+		})
+	}
+}
+
 // Running it twice must be a no-op the second time.
 func TestBackfill_LeavesCorrectOrdersAlone(t *testing.T) {
 	oc := order17103()
@@ -235,7 +338,7 @@ func TestBackfill_SkipsEditedOrders(t *testing.T) {
 	}{
 		{"row added in Zoho", func(rec *entity.ZohoOrderRecord) {
 			rec.OrderedItems = append(rec.OrderedItems, entity.ZohoOrderedItemRow{
-				ID: "row-extra", Product: entity.ZohoProduct{ID: "GIFT"}, Quantity: 1, ListPrice: 10, Total: 10,
+				ID: "row-extra", Product: entity.ZohoProduct{ID: "GIFT"}, Quantity: 1, ListPrice: entity.AmountOf(10), Total: entity.AmountOf(10),
 			})
 		}},
 		{"row removed in Zoho", func(rec *entity.ZohoOrderRecord) {
@@ -278,7 +381,7 @@ func TestBackfill_SkipsEditedOrders(t *testing.T) {
 func TestBackfill_NoShippingIsAlreadyCorrect(t *testing.T) {
 	oc := order17103()
 	oc.Shipping = 0
-	oc.Total = 956.1936 // the same order collected in person
+	oc.Total = entity.AmountOf(956.1936) // the same order collected in person
 
 	repo := &backfillRepo{orders: []sql.SyncedOrder{{ZohoID: "ZO-1", Order: oc}}}
 	zoho := &backfillZoho{record: syncedWithBug(oc, "ZO-1")}
@@ -294,3 +397,95 @@ func TestBackfill_NoShippingIsAlreadyCorrect(t *testing.T) {
 		t.Errorf("result = %+v with %d update(s), want it left alone", res, zoho.updateCalls)
 	}
 }
+
+// memCheckpoint is a checkpoint held in memory.
+type memCheckpoint struct {
+	finished []entity.BackfillOrder
+	saved    []entity.BackfillOrder
+}
+
+func (m *memCheckpoint) Finished() ([]entity.BackfillOrder, error) { return m.finished, nil }
+
+func (m *memCheckpoint) Save(line entity.BackfillOrder) error {
+	m.saved = append(m.saved, line)
+	return nil
+}
+
+// A resumed run over an id list checks only what the checkpoint does not hold as finished,
+// reports listed orders it cannot check, and records the row figures before and after.
+func TestRunBackfill_OrderIdsResumeFromCheckpoint(t *testing.T) {
+	done, retried, fresh := order17103(), order17103(), order17103()
+	retried.OrderId, fresh.OrderId = 17104, 17105
+	b2b := order17103()
+	b2b.OrderId = 17106
+
+	repo := &backfillRepo{byId: map[int64]sql.SyncedOrder{
+		17103: {ZohoID: "ZO-1", Order: done},
+		17104: {ZohoID: "ZO-2", Order: retried},
+		17105: {ZohoID: "ZO-3", Order: fresh},
+		17106: {ZohoID: b2bZohoId, Order: b2b},
+	}}
+	zoho := &backfillZoho{records: map[string]*entity.ZohoOrderRecord{
+		"ZO-2": syncedWithBug(retried, "ZO-2"),
+		"ZO-3": syncedWithBug(fresh, "ZO-3"),
+	}}
+	core := backfillCore(repo, zoho)
+	checkpoint := &memCheckpoint{finished: []entity.BackfillOrder{
+		{OrderId: 17103, ZohoID: "ZO-1", Outcome: entity.BackfillCorrected, Rows: 4},
+		{OrderId: 17104, ZohoID: "ZO-2", Outcome: entity.BackfillFailed, Reason: "zoho api: 500"},
+	}}
+
+	res, err := core.RunBackfill(entity.BackfillOptions{
+		OrderIds:   []int64{17103, 17104, 17105, 17106, 17107, 17105},
+		Apply:      true,
+		Workers:    4,
+		Rate:       1000,
+		Checkpoint: checkpoint,
+	})
+	if err != nil {
+		t.Fatalf("RunBackfill() error = %v", err)
+	}
+
+	if res.Scanned != 5 || res.Corrected != 3 || res.Skipped != 2 || res.Failed != 0 || res.Resumed != 1 {
+		t.Errorf("result = %+v, want 5 scanned: 3 corrected (1 resumed), 2 skipped", res)
+	}
+	if zoho.updateCalls != 2 {
+		t.Errorf("update calls = %d, want 2: the failed order again and the new one", zoho.updateCalls)
+	}
+	if len(checkpoint.saved) != 2 {
+		t.Errorf("checkpoint saved %d orders, want 2", len(checkpoint.saved))
+	}
+
+	var line entity.BackfillOrder
+	for _, o := range res.Orders {
+		if o.OrderId == 17104 {
+			line = o
+		}
+	}
+	if len(line.Changes) != 4 {
+		t.Fatalf("order 17104 changes = %+v, want the 4 product rows", line.Changes)
+	}
+	change := line.Changes[0]
+	if change.RowID != "row-1" || change.ProductID != "Z1" || !approx(change.DiscountWas, 10.79, 0.001) ||
+		!approx(change.DiscountNow, 10, 0.001) || change.ListPriceNow != entity.AmountOf(24.3902) {
+		t.Errorf("row 1 change = %+v, want Z1 to 24.3902 and from 10.79%% to 10%%", change)
+	}
+
+	var csv strings.Builder
+	if err = res.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(csv.String(), "reason\n17103,ZO-1,corrected,,,,,,,\n17104,") {
+		t.Errorf("report is not in order id order:\n%s", csv.String())
+	}
+	for _, want := range []string{
+		"order_id,zoho_id,outcome,row_id,product_id,list_price_was,list_price_now,discount_was,discount_now,reason\n",
+		"17104,ZO-2,corrected,row-1,Z1,24.3907,24.3902,10.79,10,\n",
+		"17106,[B2B],skipped,,,,,,,not synced to a Zoho Sales Order\n",
+		"17107,,skipped,,,,,,,order with id 17107 not found\n",
+	} {
+		if !strings.Contains(csv.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, csv.String())
+		}
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 	"zohoclient/entity"
 	"zohoclient/internal/config"
 	"zohoclient/internal/database/sql"
 	"zohoclient/internal/lib/sl"
+
+	"golang.org/x/time/rate"
 )
 
 type Repository interface {
@@ -16,9 +19,11 @@ type Repository interface {
 	OrderSearchId(orderId int64) (string, *entity.CheckoutParams, error)
 	OrderSearchByZohoId(zohoId string) (int64, *entity.CheckoutParams, error)
 	OrdersSyncedBetween(from, to time.Time) ([]sql.SyncedOrder, error)
+	OrdersUnsyncedBetween(from, to time.Time) ([]sql.UnsyncedOrder, error)
+	OrderZohoIds(orderIds []int64) (map[int64]string, error)
 	ChangeOrderStatus(orderId, orderStatusId int64, comment string) error
 	ChangeOrderZohoId(orderId int64, zohoId string) error
-	OrderTotal(orderId int64, code string) (string, float64, error)
+	OrderTotal(orderId int64, code string) (string, entity.Amount, error)
 
 	// UpdateOrderWithTransaction Transaction-based order update
 	UpdateOrderWithTransaction(data sql.OrderUpdateTransaction) error
@@ -42,10 +47,16 @@ type Repository interface {
 	GetOrdersPendingPayment() ([]*entity.CheckoutParams, error)
 	GetOrdersPendingPaymentUpdate() ([]*entity.CheckoutParams, error)
 	GetOrderZohoId(orderId int64) (string, error)
+	GetOrderSyncState(orderId int64) (*entity.OrderSyncState, error)
+	GetSyncFailures(limit int) ([]entity.SyncFailure, error)
+	CountSyncFailures() (orders int64, customers int64, err error)
+
+	GetApiToken(key string) (*entity.UserAuth, error)
 
 	GetNewCustomers() ([]*sql.CustomerRow, error)
 	ChangeCustomerZohoId(customerId int64, zohoId string) error
 	CountCustomers() (total int64, synced int64, err error)
+	ResetCustomerErrors() (int64, error)
 }
 
 type ProductRepository interface {
@@ -56,12 +67,25 @@ type Zoho interface {
 	RefreshToken() error
 	CreateContact(contactData *entity.ClientDetails) (string, error)
 	UpsertContact(contactData *entity.ClientDetails) (string, error)
+	CreateB2BContact(contactData *entity.ClientDetails, accountID string) (string, error)
+	FindAccountID(field, value string) (string, error)
+	FindContactAccountID(email string) (string, error)
+	CreateAccount(account entity.ZohoAccount) (string, error)
+	UpdateAccount(id string, account entity.ZohoAccount) error
 	CreateOrder(orderData entity.ZohoOrder) (id string, modifiedTime string, err error)
 	CreateB2BOrder(orderData entity.ZohoOrderB2B) (string, error)
+	FindDealID(field, value string) (string, error)
+	FindPaymentID(field, value string) (string, error)
+	UpdateB2BOrder(dealID string, update entity.ZohoOrderB2BUpdate) error
+	GetB2BOrderGoods(dealID string) ([]entity.Good, error)
+	UpdateB2BOrderGoods(items []*entity.Good) error
+	DeleteB2BOrderGoods(ids []string) error
 	AddItemsToOrder(orderID string, items []*entity.OrderedItem) (string, error)
 	AddItemsToOrderB2B(orderID string, items []*entity.Good) (string, error)
 	UpdateOrder(orderData entity.ZohoOrder, id string) (modifiedTime string, err error)
 	GetOrder(orderID string) (*entity.ZohoOrderRecord, error)
+	SalesOrdersCreatedBetween(from, to time.Time) ([]entity.ZohoOrderRecord, error)
+	GetOrderPayments(zohoOrderID string) ([]entity.ZohoPaymentRecord, error)
 	UpdateOrderItemRows(orderID string, rows []entity.OrderedItemPatch) (modifiedTime string, err error)
 	CreatePayment(payment entity.ZohoPayment) (string, error)
 	UpdatePaymentStatus(id, status string) error
@@ -72,11 +96,42 @@ type MessageService interface {
 }
 
 type MongoRepository interface {
-	SaveOrderVersion(orderID int64, payload string) error
-	DeleteExpired() (int64, error)
+	SaveOrderVersion(orderID int64, direction, payload string) error
+	GetOrderVersions(orderID int64) ([]entity.OrderVersion, error)
+	GetOrderVersion(orderID, version int64) (*entity.OrderVersion, error)
+	GetZohoCalls(filter entity.ZohoCallFilter) ([]entity.ZohoCall, error)
+	SaveApiCall(call entity.ApiCall) error
+	GetApiCalls(filter entity.ApiCallFilter) ([]entity.ApiCall, error)
 	GetSSLastProcessedTime(chatID string) (time.Time, error)
 	SetSSLastProcessedTime(chatID string, t time.Time) error
 	GetAllSSLastProcessedTimes() (map[string]time.Time, error)
+
+	ClaimB2BDeal(deal entity.B2BDeal) (*entity.B2BDeal, bool, error)
+	SetB2BDealZohoId(orderUID, zohoID string) error
+	SetB2BDealPaymentId(orderUID, paymentID string) error
+	ClaimB2BDealPayment(orderUID string, claimedAt time.Time) (bool, error)
+	ReleaseB2BDealPayment(orderUID string) error
+	TakeOverB2BDeal(orderUID string, claimedAt time.Time) (bool, error)
+	ReleaseB2BDeal(orderUID string) error
+	GetB2BDeal(orderUID string) (*entity.B2BDeal, error)
+
+	SaveSubscription(sub entity.Subscription) error
+	GetSubscription(userID int, subscriptionType string) (*entity.Subscription, error)
+	GetSubscriptions(subscriptionType string) ([]entity.Subscription, error)
+	DeleteSubscription(userID int, subscriptionType string) (bool, error)
+}
+
+// WebhookDispatcher posts sync events to downstream systems and keeps their delivery log.
+type WebhookDispatcher interface {
+	Dispatch(event string, data any)
+	Deliveries(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error)
+	Delivery(id string) (*entity.WebhookDelivery, error)
+	Redeliver(id string) (*entity.WebhookDelivery, error)
+}
+
+// StatusNotifier delivers order status changes to subscribers.
+type StatusNotifier interface {
+	NotifyOrderStatus(sub entity.Subscription, change entity.OrderStatusChange)
 }
 
 type SmartSenderService interface {
@@ -94,15 +149,36 @@ type Core struct {
 	mongoRepo          MongoRepository
 	zoho               Zoho
 	ms                 MessageService
+	events             chan *entity.EventMessage
+	statusNotifier     StatusNotifier
+	webhooks           WebhookDispatcher
+	shadow             bool // Zoho shadow mode: events and status changes are marked as such
 	shippingItemZohoId string
 	statuses           map[int]string
 	statusesB2B        map[int]string
 	authKey            string
-	keys               map[string]string
+	tokens             map[string]entity.UserAuth
+	keys               map[string]cachedToken
 	keysMu             sync.RWMutex
+	keyLookups         *rate.Limiter
 	log                *slog.Logger
 	stopCh             chan struct{}
 
+	// b2bUnsavedDeals maps the order_uid of a B2B order to the Deal created for it while the
+	// Deal id could not be stored.
+	b2bUnsavedDeals sync.Map
+	// b2bUnsavedPayments does the same for the payment created for a paid B2B order.
+	b2bUnsavedPayments sync.Map
+
+	// Poller state, operated from the Telegram bot
+	startedAt       time.Time
+	paused          atomic.Bool
+	backfillPauses  atomic.Int32 // applying backfills running, each holding the pollers
+	lastOrderRun    time.Time
+	lastCustomerRun time.Time
+	runsMu          sync.RWMutex
+	report          syncReport
+
 	// SmartSender integration
 	smartSender       SmartSenderService
 	zohoFunctions     ZohoFunctionsService
@@ -118,8 +194,9 @@ type Core struct {
 }
 
 func New(log *slog.Logger, conf config.Config) *Core {
+	log = log.With(sl.Module("core"))
 	return &Core{
-		log: log.With(sl.Module("core")),
+		log: log,
 		statuses: map[int]string{
 			entity.OrderStatusNew:                "Нове",
 			entity.OrderStatusPayed:              "Оплачено, формування ТТН",
@@ -131,7 +208,9 @@ func New(log *slog.Logger, conf config.Config) *Core {
 			entity.OrderStatusPrepareForShipping: "Передано на збір",
 		},
 		authKey:         conf.Listen.ApiKey,
-		keys:            make(map[string]string),
+		tokens:          loadApiTokens(log, conf.Listen.Tokens),
+		keys:            make(map[string]cachedToken),
+		keyLookups:      rate.NewLimiter(apiTokenLookupRate, apiTokenLookupBurst),
 		stopCh:          make(chan struct{}),
 		ssLastProcessed: make(map[string]time.Time),
 	}
@@ -166,8 +245,25 @@ func (c *Core) SetZoho(zoho Zoho) {
 	c.zoho = zoho
 }
 
+// SetMessageService sets the receiver of the sync events and starts the worker sending them.
 func (c *Core) SetMessageService(ms MessageService) {
 	c.ms = ms
+	c.events = make(chan *entity.EventMessage, eventQueueSize)
+	go c.sendEvents()
+}
+
+func (c *Core) SetStatusNotifier(sn StatusNotifier) {
+	c.statusNotifier = sn
+}
+
+// SetShadowMode marks the events and status changes the core emits as coming from Zoho shadow
+// mode, where the records they name were never created.
+func (c *Core) SetShadowMode(shadow bool) {
+	c.shadow = shadow
+}
+
+func (c *Core) SetWebhookDispatcher(wd WebhookDispatcher) {
+	c.webhooks = wd
 }
 
 func (c *Core) SetSmartSenderService(ss SmartSenderService) {
@@ -220,6 +316,10 @@ func (c *Core) Start() {
 		return
 	}
 
+	c.runsMu.Lock()
+	c.startedAt = time.Now()
+	c.runsMu.Unlock()
+
 	go func() {
 		ticker := time.NewTicker(2 * time.Minute)
 		defer ticker.Stop()
@@ -230,9 +330,12 @@ func (c *Core) Start() {
 				c.log.Info("order processing stopped")
 				return
 			default:
-				c.ProcessOrders()
-				c.ProcessPendingPayments()
-				c.ProcessPaymentUpdates()
+				if !c.PollersPaused() {
+					c.ProcessOrders()
+					c.ProcessPendingPayments()
+					c.ProcessPaymentUpdates()
+					c.markRun(&c.lastOrderRun)
+				}
 			}
 
 			select {
@@ -254,7 +357,10 @@ func (c *Core) Start() {
 				c.log.Info("customer processing stopped")
 				return
 			default:
-				c.ProcessCustomers()
+				if !c.PollersPaused() {
+					c.ProcessCustomers()
+					c.markRun(&c.lastCustomerRun)
+				}
 			}
 
 			select {
@@ -266,36 +372,6 @@ func (c *Core) Start() {
 		}
 	}()
 
-	// Separate goroutine for MongoDB cleanup (runs every 12 hours)
-	go func() {
-		ticker := time.NewTicker(12 * time.Hour)
-		defer ticker.Stop()
-
-		// Run cleanup once at startup
-		c.cleanupExpiredMongoOrders()
-
-		for {
-			select {
-			case <-c.stopCh:
-				return
-			case <-ticker.C:
-				c.cleanupExpiredMongoOrders()
-			}
-		}
-	}()
-
 	// SmartSender processing goroutine
 	//c.startSmartSenderProcessing()
 }
-
-// cleanupExpiredMongoOrders removes old order records from MongoDB.
-func (c *Core) cleanupExpiredMongoOrders() {
-	if c.mongoRepo == nil {
-		return
-	}
-
-	_, err := c.mongoRepo.DeleteExpired()
-	if err != nil {
-		c.log.With(sl.Err(err)).Warn("failed to cleanup expired mongo orders")
-	}
-}
@@ -1,7 +1,11 @@
 package core
 
 import (
+	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"zohoclient/entity"
 	"zohoclient/internal/lib/sl"
 )
 
@@ -10,31 +14,80 @@ import (
 // that the next sync tick skips them instead of retrying forever.
 const customerZohoIdError = "[ERR]"
 
+// maxListedCustomers caps the customer ids named in a sync failure event.
+const maxListedCustomers = 20
+
 // ProcessCustomers fetches up to 100 OpenCart customers without a zoho_id,
 // upserts each into the Zoho Contacts module, and records the returned Zoho
 // record ID back on oc_customer.zoho_id. Customers that fail (e.g. missing
 // both email and phone) stay unmarked and will be retried on the next tick.
 func (c *Core) ProcessCustomers() {
 	log := c.log.With(sl.Module("customers"))
+	if _, _, err := c.syncCustomers(log); err != nil {
+		log.With(sl.Err(err)).Error("fetch customers")
+	}
+}
+
+// ResyncCustomers runs the customer sync until no customer is left without a zoho_id, and
+// returns how many were synced and how many failed. With retryFailed, customers marked [ERR]
+// by earlier runs are cleared first and tried again.
+func (c *Core) ResyncCustomers(retryFailed bool) (synced, failed int, err error) {
+	if c.repo == nil || c.zoho == nil {
+		return 0, 0, fmt.Errorf("customer sync needs both the database and the Zoho service")
+	}
+	log := c.log.With(sl.Module("customers"))
+
+	if retryFailed {
+		reset, err := c.repo.ResetCustomerErrors()
+		if err != nil {
+			return 0, 0, err
+		}
+		log.With(slog.Int64("customers", reset)).Info("failed customers queued for retry")
+	}
+
+	for {
+		ok, bad, err := c.syncCustomers(log)
+		synced += ok
+		failed += bad
+		if err != nil {
+			return synced, failed, fmt.Errorf("fetch customers: %w", err)
+		}
+		// A batch that marked nobody would be fetched again forever.
+		if ok+bad == 0 {
+			return synced, failed, nil
+		}
+	}
+}
 
-	total, synced, err := c.repo.CountCustomers()
+// syncCustomers upserts one batch of customers without a zoho_id and returns how many were
+// synced and how many marked [ERR]. A customer whose zoho_id cannot be written counts as neither.
+func (c *Core) syncCustomers(log *slog.Logger) (synced, failed int, err error) {
+	total, all, err := c.repo.CountCustomers()
 	if err != nil {
 		log.With(sl.Err(err)).Warn("count customers")
 	}
 
 	rows, err := c.repo.GetNewCustomers()
 	if err != nil {
-		log.With(sl.Err(err)).Error("fetch customers")
-		return
+		return 0, 0, err
 	}
 	if len(rows) == 0 {
-		return
+		return 0, 0, nil
 	}
 
+	// A batch of contacts Zoho rejects is announced once, not per contact.
+	var failedIds []int64
+	var lastErr error
+	defer func() {
+		if len(failedIds) > 0 {
+			c.emitCustomersFailed(failedIds, lastErr)
+		}
+	}()
+
 	log.Info("processing customers",
 		slog.Int("count", len(rows)),
 		slog.Int64("total", total),
-		slog.Int64("synced", synced),
+		slog.Int64("synced", all),
 	)
 
 	for _, row := range rows {
@@ -46,6 +99,14 @@ func (c *Core) ProcessCustomers() {
 				sl.Err(err),
 			).Error("upsert contact")
 			id = customerZohoIdError
+			c.countReport(func(r *entity.SyncReport) { r.CustomersFailed++ })
+			failedIds = append(failedIds, row.CustomerID)
+			lastErr = err
+		} else {
+			c.countReport(func(r *entity.SyncReport) { r.CustomersSynced++ })
+			c.dispatchWebhook(entity.WebhookCustomerSynced, entity.OrderEvent{
+				CustomerId: row.CustomerID, ZohoId: id, Email: row.Details.Email,
+			})
 		}
 		if err = c.repo.ChangeCustomerZohoId(row.CustomerID, id); err != nil {
 			log.With(
@@ -53,6 +114,34 @@ func (c *Core) ProcessCustomers() {
 				slog.String("zoho_id", id),
 				sl.Err(err),
 			).Error("update customer zoho_id")
+			continue
 		}
+		if id == customerZohoIdError {
+			failed++
+		} else {
+			synced++
+		}
+	}
+	return synced, failed, nil
+}
+
+// emitCustomersFailed announces the customers of a batch that were not synced, with the last
+// error Zoho gave.
+func (c *Core) emitCustomersFailed(ids []int64, err error) {
+	payload := entity.OrderEvent{Error: err.Error()}
+	subject := fmt.Sprintf("%d customers not synced to Zoho", len(ids))
+	if len(ids) == 1 {
+		payload.CustomerId = ids[0]
+		subject = fmt.Sprintf("Customer %d not synced to Zoho", ids[0])
+	}
+	list := make([]string, 0, min(len(ids), maxListedCustomers))
+	for _, id := range ids[:min(len(ids), maxListedCustomers)] {
+		list = append(list, strconv.FormatInt(id, 10))
+	}
+	if len(ids) > maxListedCustomers {
+		list = append(list, fmt.Sprintf("and %d more", len(ids)-maxListedCustomers))
 	}
+	c.emitEvent(entity.EventSyncFailure, subject,
+		fmt.Sprintf("Marked [ERR] and not retried (see /failed): %s.", strings.Join(list, ", ")),
+		payload)
 }
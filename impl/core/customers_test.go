@@ -0,0 +1,115 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/database/sql"
+)
+
+// customerRepo holds customers by id with their zoho_id, and serves them in batches of two.
+type customerRepo struct {
+	Repository
+	zohoIds map[int64]string
+	resets  int
+}
+
+func (r *customerRepo) CountCustomers() (int64, int64, error) { return int64(len(r.zohoIds)), 0, nil }
+
+func (r *customerRepo) GetNewCustomers() ([]*sql.CustomerRow, error) {
+	var rows []*sql.CustomerRow
+	for id := int64(1); id <= int64(len(r.zohoIds)) && len(rows) < 2; id++ {
+		if r.zohoIds[id] == "" {
+			rows = append(rows, &sql.CustomerRow{CustomerID: id, Details: &entity.ClientDetails{Email: fmt.Sprintf("c%d@example.com", id)}})
+		}
+	}
+	return rows, nil
+}
+
+func (r *customerRepo) ChangeCustomerZohoId(customerId int64, zohoId string) error {
+	r.zohoIds[customerId] = zohoId
+	return nil
+}
+
+func (r *customerRepo) ResetCustomerErrors() (int64, error) {
+	var n int64
+	for id, zohoId := range r.zohoIds {
+		if zohoId == customerZohoIdError {
+			r.zohoIds[id] = ""
+			n++
+		}
+	}
+	r.resets++
+	return n, nil
+}
+
+// contactZoho rejects the contacts whose email is listed.
+type contactZoho struct {
+	Zoho
+	reject map[string]bool
+}
+
+func (z *contactZoho) UpsertContact(details *entity.ClientDetails) (string, error) {
+	if z.reject[details.Email] {
+		return "", fmt.Errorf("invalid contact")
+	}
+	return "Z-" + details.Email, nil
+}
+
+// The resync keeps fetching batches until every customer is synced or marked [ERR]; with
+// retryFailed the earlier failures are tried again.
+func TestResyncCustomers(t *testing.T) {
+	repo := &customerRepo{zohoIds: map[int64]string{1: "", 2: "Z-2", 3: "", 4: customerZohoIdError, 5: "", 6: ""}}
+	zoho := &contactZoho{reject: map[string]bool{"c5@example.com": true}}
+	c := &Core{log: slog.New(slog.NewTextHandler(io.Discard, nil)), repo: repo, zoho: zoho}
+
+	synced, failed, err := c.ResyncCustomers(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced != 3 || failed != 1 || repo.resets != 0 {
+		t.Errorf("synced %d, failed %d, resets %d; want 3, 1, 0", synced, failed, repo.resets)
+	}
+	if repo.zohoIds[4] != customerZohoIdError || repo.zohoIds[5] != customerZohoIdError {
+		t.Errorf("zoho ids = %v, want 4 and 5 marked [ERR]", repo.zohoIds)
+	}
+
+	zoho.reject = nil
+	synced, failed, err = c.ResyncCustomers(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced != 2 || failed != 0 {
+		t.Errorf("retry: synced %d, failed %d; want 2, 0", synced, failed)
+	}
+}
+
+// Contacts Zoho rejects are announced once per batch, not once each.
+func TestProcessCustomers_OneFailureEventPerBatch(t *testing.T) {
+	repo := &customerRepo{zohoIds: map[int64]string{1: "", 2: ""}}
+	zoho := &contactZoho{reject: map[string]bool{"c1@example.com": true, "c2@example.com": true}}
+	c := &Core{log: slog.New(slog.NewTextHandler(io.Discard, nil)), repo: repo, zoho: zoho, stopCh: make(chan struct{})}
+	events := make(chanMessages, 10)
+	c.SetMessageService(events)
+	defer c.Stop()
+
+	c.ProcessCustomers()
+
+	select {
+	case msg := <-events:
+		if msg.Type != entity.EventSyncFailure || msg.Subject != "2 customers not synced to Zoho" || !strings.Contains(msg.Text, "1, 2") {
+			t.Errorf("event = %s %q %q, want one sync_failure naming customers 1 and 2", msg.Type, msg.Subject, msg.Text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no sync_failure event")
+	}
+	select {
+	case msg := <-events:
+		t.Errorf("unexpected second event %q", msg.Subject)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
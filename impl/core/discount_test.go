@@ -12,6 +12,9 @@ func r2(v float64) float64 { return math.Round(v*100) / 100 }
 
 func approx(a, b, eps float64) bool { return math.Abs(a-b) <= eps }
 
+// inCents is an order_total row in whole cents.
+func inCents(a entity.Amount) int64 { return int64(a.Round(2) / entity.Cent) }
+
 // zohoRecomputedGrand mimics how Zoho derives a Sales Order grand total:
 //
 //	line Total  = ListPrice x Quantity x (1 - DiscountP/100)   <- Zoho recomputes this itself;
@@ -52,7 +55,7 @@ func zohoNonTaxable(o entity.ZohoOrder) float64 {
 
 // zohoLineTotal reproduces Zoho's own line-total calculation, ignoring OrderedItem.Total.
 func zohoLineTotal(it entity.OrderedItem) float64 {
-	return it.ListPrice * float64(it.Quantity) * (1 - it.DiscountP/100)
+	return it.ListPrice.Float64() * float64(it.Quantity) * (1 - it.DiscountP/100)
 }
 
 // testShippingZohoID is the Zoho id of the non-taxable shipping product
@@ -76,17 +79,17 @@ func minimalClient() *entity.ClientDetails {
 func ocOrder(subTotal, taxValue, discount, coupon float64, couponTitle string, total, price, qty float64) *entity.CheckoutParams {
 	return &entity.CheckoutParams{
 		OrderId: 1, Currency: "PLN",
-		SubTotal: subTotal, TaxValue: taxValue, Discount: discount,
-		Coupon: coupon, CouponTitle: couponTitle, Total: total,
+		SubTotal: entity.AmountOf(subTotal), TaxValue: entity.AmountOf(taxValue), Discount: entity.AmountOf(discount),
+		Coupon: entity.AmountOf(coupon), CouponTitle: couponTitle, Total: entity.AmountOf(total),
 		ClientDetails: minimalClient(),
 		LineItems: []*entity.LineItem{
-			{Name: "P", ZohoId: "Z1", Price: price, Qty: qty, Tax: price * 0.23, Total: price * qty},
+			{Name: "P", ZohoId: "Z1", Price: entity.AmountOf(price), Qty: qty, Tax: entity.AmountOf(price * 0.23), Total: entity.AmountOf(price * qty)},
 		},
 	}
 }
 
 func withShipping(oc *entity.CheckoutParams, shipping float64) *entity.CheckoutParams {
-	oc.Shipping = shipping
+	oc.Shipping = entity.AmountOf(shipping)
 	return oc
 }
 
@@ -167,7 +170,7 @@ func TestBuildZohoOrder_ReductionModel(t *testing.T) {
 	for _, tt := range reductionCases {
 		t.Run(tt.name, func(t *testing.T) {
 			oc := ocOrder(tt.subTotal, tt.taxValue, tt.discount, tt.coupon, tt.couponTitle, tt.total, tt.price, tt.qty)
-			oc.Shipping = tt.shipping
+			oc.Shipping = entity.AmountOf(tt.shipping)
 			zo, _ := core.buildZohoOrder(oc, "c1")
 
 			if zo.VAT != 23 {
@@ -188,11 +191,11 @@ func TestBuildZohoOrder_ReductionModel(t *testing.T) {
 			// The health check flags legacy orders (VAT declared on undiscounted amounts) with
 			// a positive gap, and must stay silent on healthy ones.
 			gap := taxHealthGap(oc)
-			if tt.legacy && gap <= 0.01 {
-				t.Errorf("taxHealthGap = %.4f, want > 0.01 for a legacy order", gap)
+			if tt.legacy && gap <= entity.Cent {
+				t.Errorf("taxHealthGap = %s, want > 0.01 for a legacy order", gap)
 			}
-			if !tt.legacy && math.Abs(gap) > 0.01 {
-				t.Errorf("taxHealthGap = %.4f, want ~0 for a healthy order", gap)
+			if !tt.legacy && gap.Abs() > entity.Cent {
+				t.Errorf("taxHealthGap = %s, want ~0 for a healthy order", gap)
 			}
 
 			// The two invariants that matter:
@@ -202,8 +205,8 @@ func TestBuildZohoOrder_ReductionModel(t *testing.T) {
 			}
 			// 2. The VAT Zoho records is the VAT actually contained in that amount.
 			zohoVat := zohoNet(zo) * zo.VAT / 100
-			if lawful := oc.LawfulTax(); !approx(zohoVat, lawful, 0.01) {
-				t.Errorf("Zoho VAT = %.4f, want lawful VAT %.4f", zohoVat, lawful)
+			if lawful := oc.LawfulTax(); !approx(zohoVat, lawful.Float64(), 0.01) {
+				t.Errorf("Zoho VAT = %.4f, want lawful VAT %s", zohoVat, lawful)
 			}
 		})
 	}
@@ -228,11 +231,11 @@ func TestBuildZohoOrder_UntaxedShipping_Order16953(t *testing.T) {
 	core := newTestCore()
 	oc := &entity.CheckoutParams{
 		OrderId: 16953, Currency: "PLN",
-		SubTotal: 105.691, TaxValue: 24.3089, Shipping: 14.99, Total: 144.9899,
+		SubTotal: entity.AmountOf(105.691), TaxValue: entity.AmountOf(24.3089), Shipping: entity.AmountOf(14.99), Total: entity.AmountOf(144.9899),
 		ClientDetails: minimalClient(),
 		LineItems: []*entity.LineItem{
-			{Name: "P1", ZohoId: "Z1", Price: 52.8455, Qty: 1, Tax: 12.1545, Total: 52.8455},
-			{Name: "P2", ZohoId: "Z2", Price: 52.8455, Qty: 1, Tax: 12.1545, Total: 52.8455},
+			{Name: "P1", ZohoId: "Z1", Price: entity.AmountOf(52.8455), Qty: 1, Tax: entity.AmountOf(12.1545), Total: entity.AmountOf(52.8455)},
+			{Name: "P2", ZohoId: "Z2", Price: entity.AmountOf(52.8455), Qty: 1, Tax: entity.AmountOf(12.1545), Total: entity.AmountOf(52.8455)},
 		},
 	}
 
@@ -240,23 +243,23 @@ func TestBuildZohoOrder_UntaxedShipping_Order16953(t *testing.T) {
 	assertDiscountPWireFormat(t, zo)
 
 	// Untaxed shipping is how this shop is configured, not the VAT bug — no warning.
-	if gap := taxHealthGap(oc); math.Abs(gap) > 0.01 {
-		t.Errorf("taxHealthGap = %.4f, want ~0: untaxed shipping must not trigger the tax warning", gap)
+	if gap := taxHealthGap(oc); gap.Abs() > entity.Cent {
+		t.Errorf("taxHealthGap = %s, want ~0: untaxed shipping must not trigger the tax warning", gap)
 	}
 	if got := zo.OrderedItems[0].DiscountP; !approx(got, 0, 0.001) {
 		t.Errorf("line DiscountP = %v, want 0: untaxed shipping must not discount the products", got)
 	}
 	// The catalogue price, to within the sub-grosz noise of OpenCart's 4-decimal figures.
-	if got := zo.OrderedItems[0].ListPrice; !approx(got, 52.8455, 0.001) {
+	if got := zo.OrderedItems[0].ListPrice; !approx(got.Float64(), 52.8455, 0.001) {
 		t.Errorf("ListPrice = %v, want the catalogue price 52.8455", got)
 	}
 	// The shipping line rides at face value, outside the VAT base.
 	ship := zo.OrderedItems[len(zo.OrderedItems)-1]
-	if ship.Product.ID != testShippingZohoID || !approx(ship.ListPrice, 14.99, 0.0001) || ship.DiscountP != 0 {
+	if ship.Product.ID != testShippingZohoID || ship.ListPrice != entity.AmountOf(14.99) || ship.DiscountP != 0 {
 		t.Errorf("shipping line = %+v, want SHIP at 14.99 with no discount", ship)
 	}
-	if grand := zohoRecomputedGrand(zo); !approx(grand, oc.Total, 0.01) {
-		t.Errorf("Zoho grand total = %.4f, want %.4f (drift %.4f)", grand, oc.Total, grand-oc.Total)
+	if grand := zohoRecomputedGrand(zo); !approx(grand, oc.Total.Float64(), 0.01) {
+		t.Errorf("Zoho grand total = %.4f, want %s (drift %.4f)", grand, oc.Total, grand-oc.Total.Float64())
 	}
 }
 
@@ -267,15 +270,15 @@ func TestBuildZohoOrder_UntaxedShipping_Order17103(t *testing.T) {
 	core := newTestCore()
 	oc := &entity.CheckoutParams{
 		OrderId: 17103, Currency: "PLN",
-		SubTotal: 878.0474, TaxValue: 165.9510,
-		Coupon: -87.8047, CouponTitle: "Kupon (dark-6B89EB81)",
-		Shipping: 39.90, Total: 996.0936,
+		SubTotal: entity.AmountOf(878.0474), TaxValue: entity.AmountOf(165.9510),
+		Coupon: entity.AmountOf(-87.8047), CouponTitle: "Kupon (dark-6B89EB81)",
+		Shipping: entity.AmountOf(39.90), Total: entity.AmountOf(996.0936),
 		ClientDetails: minimalClient(),
 		LineItems: []*entity.LineItem{
-			{Name: "Gel Polish", ZohoId: "Z1", Price: 24.3902, Qty: 30, Tax: 5.1219, Total: 731.706},
-			{Name: "Builder Gel", ZohoId: "Z2", Price: 52.8455, Qty: 1, Tax: 11.0976, Total: 52.8455},
-			{Name: "Flash", ZohoId: "Z3", Price: 48.7805, Qty: 1, Tax: 10.2439, Total: 48.7805},
-			{Name: "Cat Eye", ZohoId: "Z4", Price: 44.7154, Qty: 1, Tax: 9.3902, Total: 44.7154},
+			{Name: "Gel Polish", ZohoId: "Z1", Price: entity.AmountOf(24.3902), Qty: 30, Tax: entity.AmountOf(5.1219), Total: entity.AmountOf(731.706)},
+			{Name: "Builder Gel", ZohoId: "Z2", Price: entity.AmountOf(52.8455), Qty: 1, Tax: entity.AmountOf(11.0976), Total: entity.AmountOf(52.8455)},
+			{Name: "Flash", ZohoId: "Z3", Price: entity.AmountOf(48.7805), Qty: 1, Tax: entity.AmountOf(10.2439), Total: entity.AmountOf(48.7805)},
+			{Name: "Cat Eye", ZohoId: "Z4", Price: entity.AmountOf(44.7154), Qty: 1, Tax: entity.AmountOf(9.3902), Total: entity.AmountOf(44.7154)},
 		},
 	}
 
@@ -285,8 +288,8 @@ func TestBuildZohoOrder_UntaxedShipping_Order17103(t *testing.T) {
 	if zo.VAT != 21 {
 		t.Errorf("VAT = %v, want 21", zo.VAT)
 	}
-	if gap := taxHealthGap(oc); math.Abs(gap) > 0.01 {
-		t.Errorf("taxHealthGap = %.4f, want ~0: this order is healthy", gap)
+	if gap := taxHealthGap(oc); gap.Abs() > entity.Cent {
+		t.Errorf("taxHealthGap = %s, want ~0: this order is healthy", gap)
 	}
 	// The coupon is 10% of the subtotal and that is exactly what the lines must carry.
 	if got := zo.OrderedItems[0].DiscountP; !approx(got, 10, 0.001) {
@@ -295,12 +298,12 @@ func TestBuildZohoOrder_UntaxedShipping_Order17103(t *testing.T) {
 	if got := r2(zohoLineTotal(zo.OrderedItems[0]) / 30); !approx(got, 21.95, 0.01) {
 		t.Errorf("unit net = %.2f, want 21.95", got)
 	}
-	if grand := zohoRecomputedGrand(zo); !approx(grand, oc.Total, 0.01) {
-		t.Errorf("Zoho grand total = %.4f, want %.4f (drift %.4f)", grand, oc.Total, grand-oc.Total)
+	if grand := zohoRecomputedGrand(zo); !approx(grand, oc.Total.Float64(), 0.01) {
+		t.Errorf("Zoho grand total = %.4f, want %s (drift %.4f)", grand, oc.Total, grand-oc.Total.Float64())
 	}
 	// The VAT Zoho records is the VAT the charged amount really contains.
-	if zohoVat := zohoNet(zo) * zo.VAT / 100; !approx(zohoVat, oc.LawfulTax(), 0.01) {
-		t.Errorf("Zoho VAT = %.4f, want lawful VAT %.4f", zohoVat, oc.LawfulTax())
+	if zohoVat := zohoNet(zo) * zo.VAT / 100; !approx(zohoVat, oc.LawfulTax().Float64(), 0.01) {
+		t.Errorf("Zoho VAT = %.4f, want lawful VAT %s", zohoVat, oc.LawfulTax())
 	}
 }
 
@@ -310,27 +313,30 @@ func TestBuildOrderedItem_DiscountCombination(t *testing.T) {
 		price      float64
 		master     float64
 		qty        float64
-		discountP  float64
+		net, gross float64 // the share of the order kept after its reductions
 		wantList   float64
 		wantDiscP  float64
 		wantNetTot float64
 	}{
-		{"no discount", 100, 0, 1, 0, 100, 0, 100},
-		{"order reduction only", 100, 0, 1, 10, 100, 10, 90},
-		{"special price only", 80, 100, 1, 0, 100, 20, 80},
-		{"special price + order reduction", 80, 100, 1, 10, 100, 28, 72},
+		{"no discount", 100, 0, 1, 0, 0, 100, 0, 100},
+		{"order reduction only", 100, 0, 1, 900, 1000, 100, 10, 90},
+		{"special price only", 80, 100, 1, 0, 0, 100, 20, 80},
+		{"special price + order reduction", 80, 100, 1, 900, 1000, 100, 28, 72},
+		// 1/3 off does not fit 2 decimals: DiscountP is 33.33 and ListPrice carries the rest,
+		// so that 2.9999 x (1 - 0.3333) is the net 2.0000 to 4 places.
+		{"percentage past 2 decimals", 3, 0, 2, 2, 3, 2.9999, 33.33, 4},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			li := &entity.LineItem{ZohoId: "Z", Price: tt.price, MasterPrice: tt.master, Qty: tt.qty}
-			it := buildOrderedItem(li, tt.discountP)
-			if !approx(it.ListPrice, tt.wantList, 0.001) {
+			li := &entity.LineItem{ZohoId: "Z", Price: entity.AmountOf(tt.price), MasterPrice: entity.AmountOf(tt.master), Qty: tt.qty}
+			it := buildOrderedItem(li, entity.AmountOf(tt.net), entity.AmountOf(tt.gross))
+			if it.ListPrice != entity.AmountOf(tt.wantList) {
 				t.Errorf("ListPrice = %v, want %v", it.ListPrice, tt.wantList)
 			}
 			if !approx(it.DiscountP, tt.wantDiscP, 0.001) {
 				t.Errorf("DiscountP = %v, want %v", it.DiscountP, tt.wantDiscP)
 			}
-			if !approx(it.Total, tt.wantNetTot, 0.001) {
+			if it.Total != entity.AmountOf(tt.wantNetTot) {
 				t.Errorf("Total(net) = %v, want %v", it.Total, tt.wantNetTot)
 			}
 		})
@@ -354,21 +360,21 @@ func TestComputeReverseTotals(t *testing.T) {
 	}{
 		{
 			name:       "plain - no reductions",
-			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 1000}},
+			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(1000)}},
 			grandTotal: 1230,
 			oc:         ocOrder(1000, 230, 0, 0, "", 1230, 100, 10),
 			wantSub:    100000, wantTax: 23000, wantDisc: 0, wantCoupon: 0, wantTotal: 123000,
 		},
 		{
 			name:       "coupon reconstructed as negative order_total.coupon",
-			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 900}},
+			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(900)}},
 			grandTotal: 1107,
 			oc:         ocOrder(1000, 207, 0, -100, "SAVE", 1107, 100, 10),
 			wantSub:    100000, wantTax: 20700, wantDisc: 0, wantCoupon: -10000, wantTotal: 110700,
 		},
 		{
 			name:       "discount reconstructed as negative order_total.discount",
-			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 900}},
+			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(900)}},
 			grandTotal: 1107,
 			oc:         ocOrder(1000, 207, 100, 0, "", 1107, 100, 10),
 			wantSub:    100000, wantTax: 20700, wantDisc: -10000, wantCoupon: 0, wantTotal: 110700,
@@ -377,7 +383,7 @@ func TestComputeReverseTotals(t *testing.T) {
 			// The lines blend coupon and discount into one figure; the split comes from the
 			// stored order's proportion (100 : 90).
 			name:       "coupon + discount split by stored proportion",
-			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 810}},
+			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(810)}},
 			grandTotal: 996.3,
 			oc:         ocOrder(1000, 186.3, 90, -100, "SAVE", 996.3, 100, 10),
 			wantSub:    100000, wantTax: 18630, wantDisc: -9000, wantCoupon: -10000, wantTotal: 99630,
@@ -385,7 +391,7 @@ func TestComputeReverseTotals(t *testing.T) {
 		{
 			// A manager raised the quantity in Zoho: totals recompute, the reduction rescales.
 			name:       "manager raised quantity - totals recomputed",
-			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: 100, Quantity: 12, Total: 1080}},
+			items:      []entity.ApiOrderedItem{{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 12, Total: entity.AmountOf(1080)}},
 			grandTotal: 1328.4,
 			oc:         ocOrder(1000, 207, 0, -100, "SAVE", 1107, 100, 10),
 			wantSub:    120000, wantTax: 24840, wantDisc: 0, wantCoupon: -12000, wantTotal: 132840,
@@ -397,8 +403,8 @@ func TestComputeReverseTotals(t *testing.T) {
 		{
 			name: "shipping, no reductions",
 			items: []entity.ApiOrderedItem{
-				{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 1000},
-				{ZohoID: testShippingZohoID, Price: 39.90, Quantity: 1, Total: 39.90},
+				{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(1000)},
+				{ZohoID: testShippingZohoID, Price: entity.AmountOf(39.90), Quantity: 1, Total: entity.AmountOf(39.90)},
 			},
 			grandTotal: 1269.90, // 1000 x 1.23 + 39.90
 			oc:         withShipping(ocOrder(1000, 230, 0, 0, "", 1269.90, 100, 10), 39.90),
@@ -407,8 +413,8 @@ func TestComputeReverseTotals(t *testing.T) {
 		{
 			name: "shipping + coupon - carriage is not discounted and not taxed",
 			items: []entity.ApiOrderedItem{
-				{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 900},
-				{ZohoID: testShippingZohoID, Price: 39.90, Quantity: 1, Total: 39.90},
+				{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(900)},
+				{ZohoID: testShippingZohoID, Price: entity.AmountOf(39.90), Quantity: 1, Total: entity.AmountOf(39.90)},
 			},
 			grandTotal: 1146.90, // 900 x 1.23 + 39.90
 			oc:         withShipping(ocOrder(1000, 207, 0, -100, "SAVE", 1146.90, 100, 10), 39.90),
@@ -419,7 +425,7 @@ func TestComputeReverseTotals(t *testing.T) {
 			// the payload, not the stored order, and must not leak into the coupon.
 			name: "manager removed the shipping line",
 			items: []entity.ApiOrderedItem{
-				{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 900},
+				{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(900)},
 			},
 			grandTotal: 1107,
 			oc:         withShipping(ocOrder(1000, 207, 0, -100, "SAVE", 1146.90, 100, 10), 39.90),
@@ -430,9 +436,9 @@ func TestComputeReverseTotals(t *testing.T) {
 			// the carriage must be counted by line total so both halves survive the merge.
 			name: "carriage split across two lines",
 			items: mergeItemsByZohoID([]entity.ApiOrderedItem{
-				{ZohoID: "Z1", Price: 100, Quantity: 10, Total: 1000},
-				{ZohoID: testShippingZohoID, Price: 20, Quantity: 1, Total: 20},
-				{ZohoID: testShippingZohoID, Price: 19.90, Quantity: 1, Total: 19.90},
+				{ZohoID: "Z1", Price: entity.AmountOf(100), Quantity: 10, Total: entity.AmountOf(1000)},
+				{ZohoID: testShippingZohoID, Price: entity.AmountOf(20), Quantity: 1, Total: entity.AmountOf(20)},
+				{ZohoID: testShippingZohoID, Price: entity.AmountOf(19.90), Quantity: 1, Total: entity.AmountOf(19.90)},
 			}),
 			grandTotal: 1269.90,
 			oc:         withShipping(ocOrder(1000, 230, 0, 0, "", 1269.90, 100, 10), 39.90),
@@ -443,28 +449,28 @@ func TestComputeReverseTotals(t *testing.T) {
 	core := newTestCore()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := core.computeReverseTotals(tt.items, tt.grandTotal, tt.oc)
-			if got.ItemsTotal != tt.wantSub {
-				t.Errorf("ItemsTotal = %d, want %d", got.ItemsTotal, tt.wantSub)
+			got := core.computeReverseTotals(tt.items, entity.AmountOf(tt.grandTotal), tt.oc)
+			if inCents(got.ItemsTotal) != tt.wantSub {
+				t.Errorf("ItemsTotal = %s, want %d cents", got.ItemsTotal, tt.wantSub)
 			}
-			if got.Tax != tt.wantTax {
-				t.Errorf("Tax = %d, want %d", got.Tax, tt.wantTax)
+			if inCents(got.Tax) != tt.wantTax {
+				t.Errorf("Tax = %s, want %d cents", got.Tax, tt.wantTax)
 			}
-			if got.Discount != tt.wantDisc {
-				t.Errorf("Discount = %d, want %d", got.Discount, tt.wantDisc)
+			if inCents(got.Discount) != tt.wantDisc {
+				t.Errorf("Discount = %s, want %d cents", got.Discount, tt.wantDisc)
 			}
-			if got.Coupon != tt.wantCoupon {
-				t.Errorf("Coupon = %d, want %d", got.Coupon, tt.wantCoupon)
+			if inCents(got.Coupon) != tt.wantCoupon {
+				t.Errorf("Coupon = %s, want %d cents", got.Coupon, tt.wantCoupon)
 			}
-			if got.Shipping != tt.wantShip {
-				t.Errorf("Shipping = %d, want %d", got.Shipping, tt.wantShip)
+			if inCents(got.Shipping) != tt.wantShip {
+				t.Errorf("Shipping = %s, want %d cents", got.Shipping, tt.wantShip)
 			}
-			if got.Total != tt.wantTotal {
-				t.Errorf("Total = %d, want %d", got.Total, tt.wantTotal)
+			if inCents(got.Total) != tt.wantTotal {
+				t.Errorf("Total = %s, want %d cents", got.Total, tt.wantTotal)
 			}
 			// order_total rows must always sum to the grand total.
 			if sum := got.ItemsTotal + got.Tax + got.Discount + got.Coupon + got.Shipping; sum != got.Total {
-				t.Errorf("rows sum %d != total %d", sum, got.Total)
+				t.Errorf("rows sum %s != total %s", sum, got.Total)
 			}
 		})
 	}
@@ -481,7 +487,7 @@ func reversePayload(zo entity.ZohoOrder) []entity.ApiOrderedItem {
 			ZohoID:   it.Product.ID,
 			Price:    it.ListPrice,
 			Quantity: int(it.Quantity),
-			Total:    zohoLineTotal(it),
+			Total:    entity.AmountOf(zohoLineTotal(it)),
 		})
 	}
 	return items
@@ -495,7 +501,7 @@ func TestStatusOnlyWebhookLeavesTotalsAlone(t *testing.T) {
 	for _, tt := range reductionCases {
 		t.Run(tt.name, func(t *testing.T) {
 			oc := ocOrder(tt.subTotal, tt.taxValue, tt.discount, tt.coupon, tt.couponTitle, tt.total, tt.price, tt.qty)
-			oc.Shipping = tt.shipping
+			oc.Shipping = entity.AmountOf(tt.shipping)
 			zo, _ := core.buildZohoOrder(oc, "c1")
 
 			if !itemsUnchanged(reversePayload(zo), oc, core.shippingItemZohoId) {
@@ -515,28 +521,28 @@ func TestForwardReverseRoundTrip(t *testing.T) {
 	for _, tt := range reductionCases {
 		t.Run(tt.name, func(t *testing.T) {
 			oc := ocOrder(tt.subTotal, tt.taxValue, tt.discount, tt.coupon, tt.couponTitle, tt.total, tt.price, tt.qty)
-			oc.Shipping = tt.shipping
+			oc.Shipping = entity.AmountOf(tt.shipping)
 
 			zo, _ := core.buildZohoOrder(oc, "c1")
 			grand := zohoRecomputedGrand(zo)
-			got := core.computeReverseTotals(reversePayload(zo), grand, oc)
+			got := core.computeReverseTotals(reversePayload(zo), entity.AmountOf(grand), oc)
 
 			// Always: the customer is charged exactly what they were charged before...
-			if !approx(float64(got.Total)/100, tt.total, 0.01) {
-				t.Errorf("round-trip total = %.2f, want %.2f (drift %.2f)",
-					float64(got.Total)/100, tt.total, float64(got.Total)/100-tt.total)
+			if !approx(got.Total.Float64(), tt.total, 0.01) {
+				t.Errorf("round-trip total = %s, want %.2f (drift %.2f)",
+					got.Total, tt.total, got.Total.Float64()-tt.total)
 			}
 			// ...the tax row is the VAT that amount really contains...
-			if !approx(float64(got.Tax)/100, oc.LawfulTax(), 0.01) {
-				t.Errorf("round-trip tax = %.2f, want lawful VAT %.2f", float64(got.Tax)/100, oc.LawfulTax())
+			if !approx(got.Tax.Float64(), oc.LawfulTax().Float64(), 0.01) {
+				t.Errorf("round-trip tax = %s, want lawful VAT %s", got.Tax, oc.LawfulTax())
 			}
 			// ...the carriage comes back untouched, never absorbed into a reduction...
-			if !approx(float64(got.Shipping)/100, tt.shipping, 0.01) {
-				t.Errorf("round-trip shipping = %.2f, want %.2f", float64(got.Shipping)/100, tt.shipping)
+			if !approx(got.Shipping.Float64(), tt.shipping, 0.01) {
+				t.Errorf("round-trip shipping = %s, want %.2f", got.Shipping, tt.shipping)
 			}
 			// ...and the rows reconcile, exactly.
 			if sum := got.ItemsTotal + got.Tax + got.Discount + got.Coupon + got.Shipping; sum != got.Total {
-				t.Errorf("rows sum %d != total %d", sum, got.Total)
+				t.Errorf("rows sum %s != total %s", sum, got.Total)
 			}
 
 			if tt.legacy {
@@ -549,11 +555,11 @@ func TestForwardReverseRoundTrip(t *testing.T) {
 			// OpenCart keeps 4 decimals while order_total rows are integer cents, and the rows
 			// are derived so they sum to the total exactly — a sub-cent remainder must settle
 			// somewhere.
-			if !approx(float64(got.Coupon)/100, -math.Abs(tt.coupon), 0.011) {
-				t.Errorf("round-trip coupon = %.2f, want %.2f", float64(got.Coupon)/100, -math.Abs(tt.coupon))
+			if !approx(got.Coupon.Float64(), -math.Abs(tt.coupon), 0.011) {
+				t.Errorf("round-trip coupon = %s, want %.2f", got.Coupon, -math.Abs(tt.coupon))
 			}
-			if !approx(float64(got.Discount)/100, -math.Abs(tt.discount), 0.011) {
-				t.Errorf("round-trip discount = %.2f, want %.2f", float64(got.Discount)/100, -math.Abs(tt.discount))
+			if !approx(got.Discount.Float64(), -math.Abs(tt.discount), 0.011) {
+				t.Errorf("round-trip discount = %s, want %.2f", got.Discount, -math.Abs(tt.discount))
 			}
 		})
 	}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"log/slog"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// eventQueueSize is how many events wait for the MessageService before new ones are dropped.
+const eventQueueSize = 100
+
+// emitEvent queues an event for the MessageService: notifiers talk to mail servers and remote
+// endpoints, and the sync must not wait for them. While the queue is full the event is dropped.
+func (c *Core) emitEvent(eventType, subject, text string, payload entity.OrderEvent) {
+	if c.ms == nil {
+		return
+	}
+	msg := &entity.EventMessage{
+		Type:    eventType,
+		Subject: subject,
+		Time:    time.Now(),
+		Text:    text,
+		Payload: payload,
+		Shadow:  c.shadow,
+	}
+	select {
+	case c.events <- msg:
+	default:
+		c.log.With(
+			slog.String("event", eventType),
+			slog.Int64("order_id", payload.OrderId),
+		).Warn("event queue full, event dropped")
+	}
+}
+
+// sendEvents hands the queued events to the MessageService one at a time until Stop.
+func (c *Core) sendEvents() {
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case msg := <-c.events:
+			if err := c.ms.SendEventMessage(msg); err != nil {
+				c.log.With(
+					sl.Err(err),
+					slog.String("event", msg.Type),
+					slog.String("subject", msg.Subject),
+				).Warn("send event")
+			}
+		}
+	}
+}
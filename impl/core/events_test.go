@@ -0,0 +1,135 @@
+package core
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+// chanMessages reports each event on a channel, since events are sent in the background.
+type chanMessages chan *entity.EventMessage
+
+func (m chanMessages) SendEventMessage(msg *entity.EventMessage) error {
+	m <- msg
+	return nil
+}
+
+// A first push announces the new Sales Order and the payment linked to it.
+func TestPushOrderToZoho_EmitsEvents(t *testing.T) {
+	core := pushTestCore(&fakeRepo{order: pushableOrder()}, &fakeZoho{})
+	events := make(chanMessages, 10)
+	core.SetMessageService(events)
+
+	if _, err := core.PushOrderToZoho(16939); err != nil {
+		t.Fatalf("PushOrderToZoho() error = %v", err)
+	}
+
+	got := make(map[string]entity.OrderEvent)
+	for range 2 {
+		select {
+		case msg := <-events:
+			got[msg.Type] = msg.Payload.(entity.OrderEvent)
+		case <-time.After(time.Second):
+			t.Fatalf("events = %v, want order_created and payment_linked", got)
+		}
+	}
+
+	created, ok := got[entity.EventOrderCreated]
+	if !ok || created.OrderId != 16939 || created.ZohoId != "NEW-ZOHO-ID" {
+		t.Errorf("order_created = %+v, ok=%v", created, ok)
+	}
+	linked, ok := got[entity.EventPaymentLinked]
+	if !ok || linked.PaymentId != "PAY-1" || linked.Total != entity.AmountOf(468) {
+		t.Errorf("payment_linked = %+v, ok=%v", linked, ok)
+	}
+}
+
+// A re-push updates the order in place and announces nothing.
+func TestPushOrderToZoho_UpdateEmitsNoEvent(t *testing.T) {
+	core := pushTestCore(&fakeRepo{zohoId: "739178000059413569", order: pushableOrder()}, &fakeZoho{})
+	events := make(chanMessages, 10)
+	core.SetMessageService(events)
+
+	if _, err := core.PushOrderToZoho(16939); err != nil {
+		t.Fatalf("PushOrderToZoho() error = %v", err)
+	}
+	select {
+	case msg := <-events:
+		t.Errorf("unexpected %s event", msg.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// In shadow mode the events carry the mark, so the channels left on can tell the admins.
+func TestPushOrderToZoho_ShadowEventsMarked(t *testing.T) {
+	core := pushTestCore(&fakeRepo{order: pushableOrder()}, &fakeZoho{})
+	events := make(chanMessages, 10)
+	core.SetMessageService(events)
+	core.SetShadowMode(true)
+
+	if _, err := core.PushOrderToZoho(16939); err != nil {
+		t.Fatalf("PushOrderToZoho() error = %v", err)
+	}
+	for range 2 {
+		select {
+		case msg := <-events:
+			if !msg.Shadow {
+				t.Errorf("%s event not marked as shadow", msg.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("want order_created and payment_linked")
+		}
+	}
+}
+
+// blockedMessages holds every send until released, like a notifier whose server hangs.
+type blockedMessages struct {
+	release chan struct{}
+	sent    chan *entity.EventMessage
+}
+
+func (m *blockedMessages) SendEventMessage(msg *entity.EventMessage) error {
+	<-m.release
+	m.sent <- msg
+	return nil
+}
+
+// A hanging notifier neither holds up the sync nor piles up goroutines: events wait in a bounded
+// queue, and those beyond it are dropped.
+func TestEmitEvent_BoundedQueue(t *testing.T) {
+	c := &Core{log: slog.New(slog.NewTextHandler(io.Discard, nil)), stopCh: make(chan struct{})}
+	ms := &blockedMessages{release: make(chan struct{}), sent: make(chan *entity.EventMessage, 2*eventQueueSize)}
+	c.SetMessageService(ms)
+	defer c.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		for i := range 2 * eventQueueSize {
+			c.emitEvent(entity.EventOrderCreated, "order", "", entity.OrderEvent{OrderId: int64(i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("emitEvent blocked on a hanging notifier")
+	}
+
+	close(ms.release)
+	sent := 0
+	for {
+		select {
+		case <-ms.sent:
+			sent++
+			continue
+		case <-time.After(100 * time.Millisecond):
+		}
+		break
+	}
+	// The worker holds one event while the queue fills up behind it.
+	if sent < eventQueueSize || sent > eventQueueSize+1 {
+		t.Errorf("sent %d events, want the %d queued (and the one in hand)", sent, eventQueueSize)
+	}
+}
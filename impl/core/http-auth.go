@@ -2,29 +2,189 @@ package core
 
 import (
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 	"zohoclient/entity"
+	"zohoclient/internal/config"
+	"zohoclient/internal/lib/sl"
 )
 
+const (
+	// apiTokenCacheTTL bounds how long an oc_api key stays cached, so disabling or rotating a key
+	// in the OpenCart admin panel takes effect without a restart.
+	apiTokenCacheTTL = 5 * time.Minute
+	// apiTokenMissTTL is how long a token found in neither the config nor oc_api is remembered as
+	// unknown, so a client retrying a bad token does not query the database on every request. It
+	// is short, as a key added in the admin panel is refused for that long.
+	apiTokenMissTTL = 30 * time.Second
+	// apiTokenCacheSize caps the cache. When it is full, expired entries are dropped first, then
+	// all the unknown tokens.
+	apiTokenCacheSize = 10000
+
+	// apiTokenLookupRate and apiTokenLookupBurst limit the oc_api queries for tokens not cached,
+	// which a client sending a new bogus token with every request could otherwise run at will.
+	// Tokens already cached are not affected.
+	apiTokenLookupRate  = 10
+	apiTokenLookupBurst = 50
+)
+
+// legacyClientName is the client the single listen.key token authenticates as.
+const legacyClientName = "internal"
+
+type cachedToken struct {
+	user     entity.UserAuth
+	loadedAt time.Time
+	// unknown marks a token oc_api has no enabled key for.
+	unknown bool
+}
+
+func (t cachedToken) fresh(now time.Time) bool {
+	if t.unknown {
+		return now.Sub(t.loadedAt) < apiTokenMissTTL
+	}
+	return now.Sub(t.loadedAt) < apiTokenCacheTTL
+}
+
+// AuthenticateByToken resolves a Bearer token to its API client. Tokens from the config
+// (listen.tokens, and the legacy listen.key with admin scope) are checked first, then enabled
+// OpenCart API keys (oc_api). Expired tokens are rejected.
 func (c *Core) AuthenticateByToken(token string) (*entity.UserAuth, error) {
 	if token == "" {
 		return nil, fmt.Errorf("token not provided")
 	}
 
+	user, err := c.lookupToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if user.Expired(time.Now()) {
+		return nil, fmt.Errorf("token of %s expired at %s", user.Name, user.ExpiresAt.Format(time.RFC3339))
+	}
+	return user, nil
+}
+
+func (c *Core) lookupToken(token string) (*entity.UserAuth, error) {
+	if user, ok := c.tokens[token]; ok {
+		return &user, nil
+	}
+
+	if c.authKey != "" && c.authKey == token {
+		return &entity.UserAuth{
+			Name:   legacyClientName,
+			Token:  token,
+			Scopes: []string{entity.ScopeAdmin},
+			Source: entity.TokenSourceConfig,
+		}, nil
+	}
+
 	// Check cached keys with read lock
 	c.keysMu.RLock()
-	userName, ok := c.keys[token]
+	cached, ok := c.keys[token]
 	c.keysMu.RUnlock()
-	if ok {
-		return &entity.UserAuth{Name: userName}, nil
+	if ok && cached.fresh(time.Now()) {
+		if cached.unknown {
+			return nil, nil
+		}
+		return &cached.user, nil
 	}
 
-	if c.authKey == token {
-		usr := "internal"
-		c.keysMu.Lock()
-		c.keys[token] = usr
-		c.keysMu.Unlock()
-		return &entity.UserAuth{Name: usr}, nil
+	if c.repo == nil {
+		return nil, nil
+	}
+	if c.keyLookups != nil && !c.keyLookups.Allow() {
+		return nil, fmt.Errorf("lookup api token: too many unknown tokens, try later")
+	}
+	user, err := c.repo.GetApiToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("lookup api token: %w", err)
 	}
 
-	return nil, fmt.Errorf("invalid token")
+	entry := cachedToken{loadedAt: time.Now(), unknown: user == nil}
+	if user != nil {
+		entry.user = *user
+	}
+	c.keysMu.Lock()
+	if _, ok = c.keys[token]; !ok && len(c.keys) >= apiTokenCacheSize {
+		c.pruneKeys(entry.loadedAt)
+	}
+	c.keys[token] = entry
+	c.keysMu.Unlock()
+
+	return user, nil
+}
+
+// pruneKeys makes room in the token cache: it drops the expired entries and, if that is not
+// enough, every unknown token. The caller holds keysMu.
+func (c *Core) pruneKeys(now time.Time) {
+	for token, cached := range c.keys {
+		if !cached.fresh(now) {
+			delete(c.keys, token)
+		}
+	}
+	if len(c.keys) < apiTokenCacheSize {
+		return
+	}
+	for token, cached := range c.keys {
+		if cached.unknown {
+			delete(c.keys, token)
+		}
+	}
+}
+
+// loadApiTokens indexes the configured API tokens. Entries without a token or with an
+// unparsable expiry are skipped with a warning, as are unknown scopes.
+func loadApiTokens(log *slog.Logger, tokens []config.ApiToken) map[string]entity.UserAuth {
+	known := []string{
+		entity.ScopeWebhookOrder,
+		entity.ScopeWebhookB2B,
+		entity.ScopePush,
+		entity.ScopeCatalogWrite,
+		entity.ScopeAdmin,
+	}
+
+	result := make(map[string]entity.UserAuth, len(tokens))
+	for _, t := range tokens {
+		tokenLog := log.With(slog.String("client", t.Name))
+		if strings.TrimSpace(t.Token) == "" {
+			tokenLog.Warn("api token skipped: empty token")
+			continue
+		}
+
+		user := entity.UserAuth{
+			Name:   t.Name,
+			Token:  t.Token,
+			Source: entity.TokenSourceConfig,
+		}
+		for _, scope := range t.Scopes {
+			if !slices.Contains(known, scope) {
+				tokenLog.Warn("api token scope ignored: unknown scope", slog.String("scope", scope))
+				continue
+			}
+			user.Scopes = append(user.Scopes, scope)
+		}
+
+		if t.Expires != "" {
+			expires, err := parseTokenExpiry(t.Expires)
+			if err != nil {
+				tokenLog.With(sl.Err(err)).Warn("api token skipped: invalid expiry")
+				continue
+			}
+			user.ExpiresAt = expires
+		}
+
+		result[t.Token] = user
+	}
+	return result
+}
+
+func parseTokenExpiry(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
 }
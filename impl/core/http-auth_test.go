@@ -0,0 +1,178 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+
+	"golang.org/x/time/rate"
+)
+
+type authRepo struct {
+	Repository
+	keys    map[string]*entity.UserAuth
+	lookups int
+}
+
+func (r *authRepo) GetApiToken(key string) (*entity.UserAuth, error) {
+	r.lookups++
+	return r.keys[key], nil
+}
+
+func authTestCore(repo *authRepo, tokens []config.ApiToken) *Core {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return &Core{
+		log:     log,
+		repo:    repo,
+		authKey: "legacy-key",
+		tokens:  loadApiTokens(log, tokens),
+		keys:    make(map[string]cachedToken),
+	}
+}
+
+func TestAuthenticateByToken(t *testing.T) {
+	repo := &authRepo{keys: map[string]*entity.UserAuth{
+		"oc-key":     {Name: "portal", Scopes: []string{entity.ScopeWebhookB2B}, Source: entity.TokenSourceOcApi},
+		"oc-expired": {Name: "old", ExpiresAt: time.Now().Add(-time.Hour)},
+	}}
+	core := authTestCore(repo, []config.ApiToken{
+		{Name: "zoho", Token: "zoho-new", Scopes: []string{entity.ScopeWebhookOrder}},
+		{Name: "zoho", Token: "zoho-old", Scopes: []string{entity.ScopeWebhookOrder}, Expires: time.Now().Add(time.Hour).Format(time.RFC3339)},
+		{Name: "retired", Token: "retired", Scopes: []string{entity.ScopePush}, Expires: "2020-01-01"},
+	})
+
+	tests := []struct {
+		name      string
+		token     string
+		wantName  string
+		wantScope string
+		wantErr   bool
+	}{
+		{"config token", "zoho-new", "zoho", entity.ScopeWebhookOrder, false},
+		{"rotated token still valid", "zoho-old", "zoho", entity.ScopeWebhookOrder, false},
+		{"expired config token", "retired", "", "", true},
+		{"legacy key is admin", "legacy-key", legacyClientName, entity.ScopeCatalogWrite, false},
+		{"oc_api key", "oc-key", "portal", entity.ScopeWebhookB2B, false},
+		{"expired oc_api key", "oc-expired", "", "", true},
+		{"unknown token", "nope", "", "", true},
+		{"empty token", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := core.AuthenticateByToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AuthenticateByToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if user.Name != tt.wantName || !user.HasScope(tt.wantScope) {
+				t.Errorf("user = %+v, want %s with scope %s", user, tt.wantName, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestAuthenticateByToken_OcApiCached(t *testing.T) {
+	repo := &authRepo{keys: map[string]*entity.UserAuth{
+		"oc-key": {Name: "portal", Scopes: []string{entity.ScopeWebhookB2B}},
+	}}
+	core := authTestCore(repo, nil)
+
+	for i := 0; i < 3; i++ {
+		if _, err := core.AuthenticateByToken("oc-key"); err != nil {
+			t.Fatalf("call %d error = %v", i+1, err)
+		}
+	}
+	if repo.lookups != 1 {
+		t.Errorf("oc_api lookups = %d, want 1", repo.lookups)
+	}
+
+	// A key disabled in the admin panel stops working once its cache entry is stale.
+	delete(repo.keys, "oc-key")
+	core.keys["oc-key"] = cachedToken{user: core.keys["oc-key"].user, loadedAt: time.Now().Add(-2 * apiTokenCacheTTL)}
+	if _, err := core.AuthenticateByToken("oc-key"); err == nil {
+		t.Error("disabled key still accepted after cache expiry")
+	}
+}
+
+// An unknown token is looked up once and then refused from the cache until the miss expires.
+func TestAuthenticateByToken_UnknownCached(t *testing.T) {
+	repo := &authRepo{keys: map[string]*entity.UserAuth{}}
+	core := authTestCore(repo, nil)
+
+	for i := 0; i < 3; i++ {
+		if _, err := core.AuthenticateByToken("bogus"); err == nil {
+			t.Fatalf("call %d accepted an unknown token", i+1)
+		}
+	}
+	if repo.lookups != 1 {
+		t.Errorf("oc_api lookups = %d, want 1", repo.lookups)
+	}
+
+	// A key added in the admin panel works once the miss has expired.
+	repo.keys["bogus"] = &entity.UserAuth{Name: "portal"}
+	core.keys["bogus"] = cachedToken{loadedAt: time.Now().Add(-2 * apiTokenMissTTL), unknown: true}
+	if user, err := core.AuthenticateByToken("bogus"); err != nil || user.Name != "portal" {
+		t.Errorf("AuthenticateByToken() = %+v, %v, want portal", user, err)
+	}
+}
+
+// A stream of different bogus tokens is held to the lookup rate, and fills the cache only up to
+// its size.
+func TestAuthenticateByToken_LookupsLimited(t *testing.T) {
+	repo := &authRepo{keys: map[string]*entity.UserAuth{
+		"oc-key": {Name: "portal"},
+	}}
+	core := authTestCore(repo, nil)
+	core.keyLookups = rate.NewLimiter(0, 3)
+
+	if _, err := core.AuthenticateByToken("oc-key"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		_, _ = core.AuthenticateByToken(fmt.Sprintf("bogus-%d", i))
+	}
+	if repo.lookups != 3 {
+		t.Errorf("oc_api lookups = %d, want 3", repo.lookups)
+	}
+	if _, err := core.AuthenticateByToken("oc-key"); err != nil {
+		t.Errorf("cached key refused while lookups are limited: %v", err)
+	}
+
+	core.keyLookups = nil
+	for i := 0; i < apiTokenCacheSize+10; i++ {
+		_, _ = core.AuthenticateByToken(fmt.Sprintf("flood-%d", i))
+	}
+	if len(core.keys) > apiTokenCacheSize {
+		t.Errorf("token cache holds %d entries, want at most %d", len(core.keys), apiTokenCacheSize)
+	}
+	if _, ok := core.keys["oc-key"]; !ok {
+		t.Error("a valid key was dropped to make room for unknown tokens")
+	}
+}
+
+func TestLoadApiTokens(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tokens := loadApiTokens(log, []config.ApiToken{
+		{Name: "ok", Token: "t1", Scopes: []string{entity.ScopePush, "bogus"}, Expires: "2030-06-01"},
+		{Name: "empty", Token: " "},
+		{Name: "bad-expiry", Token: "t2", Expires: "soon"},
+	})
+
+	if len(tokens) != 1 {
+		t.Fatalf("loaded %d tokens, want 1", len(tokens))
+	}
+	got := tokens["t1"]
+	if len(got.Scopes) != 1 || got.Scopes[0] != entity.ScopePush {
+		t.Errorf("scopes = %v, want [push]", got.Scopes)
+	}
+	if want := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC); !got.ExpiresAt.Equal(want) {
+		t.Errorf("expires = %v, want %v", got.ExpiresAt, want)
+	}
+}
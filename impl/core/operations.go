@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// syncFailuresLimit caps how many failed orders and customers SyncFailures lists of each kind.
+const syncFailuresLimit = 20
+
+// OrderSyncState returns the Zoho sync state stored on an order.
+func (c *Core) OrderSyncState(orderId int64) (*entity.OrderSyncState, error) {
+	state, err := c.repo.GetOrderSyncState(orderId)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("order %d not found", orderId)
+	}
+	return state, nil
+}
+
+// SyncFailures lists the most recent orders and customers the sync gave up on.
+func (c *Core) SyncFailures() ([]entity.SyncFailure, error) {
+	return c.repo.GetSyncFailures(syncFailuresLimit)
+}
+
+// RetryOrder pushes an order to Zoho again and, if its payment was marked failed, clears the
+// sentinel so the payment poller creates the payment on its next run.
+func (c *Core) RetryOrder(orderId int64) (string, error) {
+	log := c.log.With(slog.Int64("order_id", orderId))
+
+	paymentId, err := c.repo.GetOrderZohoPaymentId(orderId)
+	if err != nil {
+		return "", fmt.Errorf("get payment id: %w", err)
+	}
+
+	zohoId, err := c.PushOrderToZoho(orderId)
+	if err != nil {
+		log.With(sl.Err(err)).Error("retry order")
+		return zohoId, err
+	}
+
+	if paymentId == paymentZohoIdError {
+		if err = c.repo.UpdateOrderZohoPaymentId(orderId, ""); err != nil {
+			return zohoId, fmt.Errorf("clear payment error: %w", err)
+		}
+		log.Info("payment error cleared")
+	}
+
+	log.With(slog.String("zoho_id", zohoId)).Info("order retried")
+	return zohoId, nil
+}
+
+// OrdersPlacedBetween lists, in id order, the orders placed in [from, to) that are synced to
+// Zoho or waiting to be. [ERR] and [B2B] orders are left out.
+func (c *Core) OrdersPlacedBetween(from, to time.Time) ([]int64, error) {
+	synced, err := c.repo.OrdersSyncedBetween(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("load synced orders: %w", err)
+	}
+	unsynced, err := c.repo.OrdersUnsyncedBetween(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("load unsynced orders: %w", err)
+	}
+
+	ids := make([]int64, 0, len(synced)+len(unsynced))
+	for _, s := range synced {
+		ids = append(ids, s.Order.OrderId)
+	}
+	for _, u := range unsynced {
+		ids = append(ids, u.OrderId)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// RetryCustomer clears a customer's zoho_id so the customer poller upserts the contact again.
+// Upserts are keyed on email and phone in Zoho, so retrying a synced customer is harmless.
+func (c *Core) RetryCustomer(customerId int64) error {
+	if err := c.repo.ChangeCustomerZohoId(customerId, ""); err != nil {
+		return err
+	}
+	c.log.With(slog.Int64("customer_id", customerId)).Info("customer queued for retry")
+	return nil
+}
+
+// PausePollers stops the order, payment and customer pollers from starting new runs. A run in
+// progress finishes normally; webhooks and API calls are not affected.
+func (c *Core) PausePollers() {
+	if !c.paused.Swap(true) {
+		c.log.Warn("pollers paused")
+	}
+}
+
+// ResumePollers lets paused pollers run again from their next tick. Pollers held by an
+// applying backfill stay held until it finishes.
+func (c *Core) ResumePollers() {
+	if c.paused.Swap(false) {
+		if c.backfillPauses.Load() > 0 {
+			c.log.Info("pollers resumed, held until the backfill finishes")
+			return
+		}
+		c.log.Info("pollers resumed")
+	}
+}
+
+// PollersPaused reports whether the pollers are paused, by an admin or by a running backfill.
+func (c *Core) PollersPaused() bool {
+	return c.paused.Load() || c.backfillPauses.Load() > 0
+}
+
+// SyncStats returns the poller state with customer and failure counts.
+func (c *Core) SyncStats() (*entity.SyncStats, error) {
+	c.runsMu.RLock()
+	stats := &entity.SyncStats{
+		StartedAt:       c.startedAt,
+		Paused:          c.PollersPaused(),
+		LastOrderRun:    c.lastOrderRun,
+		LastCustomerRun: c.lastCustomerRun,
+	}
+	c.runsMu.RUnlock()
+
+	var err error
+	stats.CustomersTotal, stats.CustomersSynced, err = c.repo.CountCustomers()
+	if err != nil {
+		return nil, err
+	}
+	stats.FailedPayments, stats.FailedCustomers, err = c.repo.CountSyncFailures()
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// markRun records the end of a poller run.
+func (c *Core) markRun(last *time.Time) {
+	c.runsMu.Lock()
+	*last = time.Now()
+	c.runsMu.Unlock()
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"testing"
+)
+
+type retryRepo struct {
+	fakeRepo
+	paymentId      string
+	clearedPayment bool
+}
+
+func (r *retryRepo) GetOrderZohoPaymentId(int64) (string, error) { return r.paymentId, nil }
+
+func (r *retryRepo) UpdateOrderZohoPaymentId(_ int64, zohoPaymentId string) error {
+	r.clearedPayment = zohoPaymentId == ""
+	return nil
+}
+
+// A retried order whose payment was given up on is re-pushed and its "[ERR]" sentinel cleared,
+// so the payment poller creates the payment again.
+func TestRetryOrder_ClearsPaymentError(t *testing.T) {
+	repo := &retryRepo{
+		fakeRepo:  fakeRepo{zohoId: "739178000059413569", order: pushableOrder()},
+		paymentId: paymentZohoIdError,
+	}
+	zoho := &fakeZoho{}
+	core := pushTestCore(&repo.fakeRepo, zoho)
+	core.repo = repo
+
+	zohoId, err := core.RetryOrder(16939)
+	if err != nil {
+		t.Fatalf("RetryOrder() error = %v", err)
+	}
+	if zohoId != "739178000059413569" {
+		t.Errorf("zohoId = %q, want the existing one", zohoId)
+	}
+	if zoho.updateOrderCalls != 1 {
+		t.Errorf("UpdateOrder calls = %d, want 1", zoho.updateOrderCalls)
+	}
+	if !repo.clearedPayment {
+		t.Error("payment error sentinel was not cleared")
+	}
+}
+
+// A real payment id must be left alone: clearing it would create a second payment.
+func TestRetryOrder_KeepsRealPayment(t *testing.T) {
+	repo := &retryRepo{
+		fakeRepo:  fakeRepo{zohoId: "739178000059413569", order: pushableOrder()},
+		paymentId: "PAY-1",
+	}
+	core := pushTestCore(&repo.fakeRepo, &fakeZoho{})
+	core.repo = repo
+
+	if _, err := core.RetryOrder(16939); err != nil {
+		t.Fatalf("RetryOrder() error = %v", err)
+	}
+	if repo.clearedPayment {
+		t.Error("real payment id was cleared")
+	}
+}
+
+func TestPauseResumePollers(t *testing.T) {
+	core := pushTestCore(&fakeRepo{}, &fakeZoho{})
+
+	if core.PollersPaused() {
+		t.Fatal("pollers paused on a new core")
+	}
+	core.PausePollers()
+	if !core.PollersPaused() {
+		t.Error("PausePollers() did not pause")
+	}
+	core.ResumePollers()
+	if core.PollersPaused() {
+		t.Error("ResumePollers() did not resume")
+	}
+}
@@ -26,6 +26,10 @@ const (
 	// b2bZohoId is a sentinel written into oc_order.zoho_id for B2B orders, which are excluded
 	// from the Sales_Orders sync. It is not a real Zoho record id.
 	b2bZohoId = "[B2B]"
+
+	// taxGapTolerance is the taxHealthGap, in order currency, above which an order's declared VAT
+	// is reported as wrong.
+	taxGapTolerance = entity.Cent
 )
 
 type Currency struct {
@@ -73,10 +77,10 @@ func (c *Core) processOrder(order *entity.CheckoutParams, existingZohoId string,
 		slog.Int64("order_id", order.OrderId),
 		slog.String("currency", order.Currency),
 		slog.String("tax", order.TaxTitle),
-		slog.Float64("total", round2(order.Total)),
-		slog.Float64("tax_value", round2(order.TaxValue)),
+		slog.Float64("total", order.Total.Round(2).Float64()),
+		slog.Float64("tax_value", order.TaxValue.Round(2).Float64()),
 		slog.String("coupon", order.CouponTitle),
-		slog.Float64("shipping", order.Shipping),
+		slog.Float64("shipping", order.Shipping.Float64()),
 		slog.String("name", fmt.Sprintf("%s : %s", order.ClientDetails.FirstName, order.ClientDetails.LastName)),
 		slog.String("country", order.ClientDetails.Country),
 		slog.String("tax_id", order.ClientDetails.TaxId),
@@ -108,6 +112,7 @@ func (c *Core) processOrder(order *entity.CheckoutParams, existingZohoId string,
 		c.processProductsWithoutZohoID(order.LineItems)
 
 		if err := hasEmptyZohoID(order.LineItems); err != nil {
+			c.reportBlockedOrder(order.OrderId)
 			return "", fmt.Errorf("product without Zoho ID: %w", err)
 		}
 	}
@@ -139,12 +144,25 @@ func (c *Core) processOrder(order *entity.CheckoutParams, existingZohoId string,
 				return "", fmt.Errorf("update Zoho order: %w", err)
 			}
 			infoTag = "order updated"
+			c.countReport(func(r *entity.SyncReport) { r.OrdersUpdated++ })
 		} else {
 			zohoId, zohoModifiedTime, err = c.zoho.CreateOrder(zohoOrder)
 			if err != nil {
 				return "", fmt.Errorf("create Zoho order: %w", err)
 			}
+			c.countReport(func(r *entity.SyncReport) { r.OrdersCreated++ })
+			c.emitEvent(entity.EventOrderCreated,
+				fmt.Sprintf("Order %d created in Zoho", order.OrderId),
+				fmt.Sprintf("%s %s, %s", order.ClientDetails.FirstName, order.ClientDetails.LastName, entity.NewMoney(order.Total, order.Currency)),
+				entity.OrderEvent{OrderId: order.OrderId, ZohoId: zohoId, Currency: order.Currency, Total: order.Total.Round(2)})
+		}
+		if taxHealthGap(order).Abs() > taxGapTolerance {
+			c.reportTaxGap(order.OrderId)
 		}
+		c.dispatchWebhook(entity.WebhookOrderSynced, entity.OrderEvent{
+			OrderId: order.OrderId, ZohoId: zohoId, StatusId: order.StatusId,
+			Email: order.ClientDetails.Email, Currency: order.Currency, Total: order.Total.Round(2),
+		})
 
 		//// Add remaining items in chunks
 		//if err := addChunkedItems(chunkedItems, func(chunk []*entity.OrderedItem) (string, error) {
@@ -183,7 +201,7 @@ func (c *Core) processOrder(order *entity.CheckoutParams, existingZohoId string,
 	}
 
 	// Save order version to MongoDB
-	c.saveOrderVersionToMongo(order.OrderId, order)
+	c.saveOrderVersionToMongo(order.OrderId, entity.VersionOutbound, order)
 
 	log.With(slog.String("zoho_id", zohoId)).Info(infoTag)
 
@@ -236,8 +254,8 @@ func (c *Core) createZohoPayment(order *entity.CheckoutParams, zohoOrderId strin
 
 	payment := entity.ZohoPayment{
 		Name:                    fmt.Sprintf("Payment #%d", order.OrderId),
-		Sells:                   entity.ZohoSellsRef{ID: zohoOrderId},
-		Sum:                     round2(float64(order.PaymentAmount) / 100),
+		Sells:                   &entity.ZohoSellsRef{ID: zohoOrderId},
+		Sum:                     entity.Amount(order.PaymentAmount) * entity.Cent,
 		Currency:                order.Currency,
 		StripePaymentIntentID:   order.PaymentId,
 		StripeCheckoutSessionID: order.PaymentSessionId,
@@ -252,6 +270,7 @@ func (c *Core) createZohoPayment(order *entity.CheckoutParams, zohoOrderId strin
 
 	zohoPaymentId, err := c.zoho.CreatePayment(payment)
 	if err != nil {
+		c.countReport(func(r *entity.SyncReport) { r.PaymentsErrored++ })
 		log.With(sl.Err(err)).Error("create Zoho payment")
 		// Non-transient failure (e.g. linked Sales Order deleted in Zoho):
 		// mark with a sentinel so the order is not retried forever.
@@ -259,6 +278,10 @@ func (c *Core) createZohoPayment(order *entity.CheckoutParams, zohoOrderId strin
 			if markErr := c.repo.UpdateOrderZohoPaymentId(order.OrderId, paymentZohoIdError); markErr != nil {
 				log.With(sl.Err(markErr)).Error("mark failed payment")
 			}
+			c.emitEvent(entity.EventSyncFailure,
+				fmt.Sprintf("Payment for order %d not created in Zoho", order.OrderId),
+				"The payment is marked [ERR] and will not be retried; see /failed.",
+				entity.OrderEvent{OrderId: order.OrderId, ZohoId: zohoOrderId, Currency: order.Currency, Error: err.Error()})
 		}
 		return
 	}
@@ -271,6 +294,13 @@ func (c *Core) createZohoPayment(order *entity.CheckoutParams, zohoOrderId strin
 		return
 	}
 
+	c.countReport(func(r *entity.SyncReport) { r.PaymentsCreated++ })
+	linked := entity.OrderEvent{OrderId: order.OrderId, ZohoId: zohoOrderId, PaymentId: zohoPaymentId, Currency: order.Currency, Total: payment.Sum}
+	c.emitEvent(entity.EventPaymentLinked,
+		fmt.Sprintf("Payment for order %d linked in Zoho", order.OrderId),
+		fmt.Sprintf("%s, %s", entity.NewMoney(payment.Sum, payment.Currency), payment.Status),
+		linked)
+	c.dispatchWebhook(entity.WebhookPaymentLinked, linked)
 	log.With(slog.String("zoho_payment_id", zohoPaymentId)).Info("payment created")
 }
 
@@ -309,9 +339,11 @@ func (c *Core) updateZohoPayment(order *entity.CheckoutParams) {
 
 	zohoStatus := entity.ConvertPaymentStatus(order.PaymentStatus)
 	if err := c.zoho.UpdatePaymentStatus(zohoPaymentId, zohoStatus); err != nil {
+		c.countReport(func(r *entity.SyncReport) { r.PaymentsErrored++ })
 		log.With(sl.Err(err)).Error("update Zoho payment status")
 		return
 	}
+	c.countReport(func(r *entity.SyncReport) { r.PaymentsUpdated++ })
 
 	if err := c.repo.SetOrderZohoPaymentStatus(order.OrderId, order.PaymentStatus); err != nil {
 		log.With(sl.Err(err)).Error("store synced zoho_payment_status")
@@ -406,32 +438,36 @@ func (c *Core) processProductsWithoutZohoID(products []*entity.LineItem) {
 	}
 }
 
-// buildOrderedItem builds a Zoho subform line. discountP is the PRE-tax percentage applied
-// uniformly across the order; a per-line special price (MasterPrice > Price) is combined with
-// it so the line reports a single ListPrice and one effective discount.
+// buildOrderedItem builds a Zoho subform line. The paid price is cut to net/gross of itself,
+// the share of the catalogue value the order keeps after its PRE-tax reductions; a zero gross
+// leaves it whole. A per-line special price (MasterPrice > Price) is combined with that, so the
+// line reports a single ListPrice and one effective discount.
 //
 // Zoho's DiscountP subform field accepts at most 2 decimal places — too coarse to pin the VAT
-// base on its own. So the percentage is sent rounded to 2 decimals and the residual is folded
-// into ListPrice (which takes 4 decimals): the line net Zoho recomputes as
-// ListPrice x Qty x (1 - DiscountP/100) still equals the exact net. When the percentage fits
-// in 2 decimals the compensation is an identity and ListPrice stays at the catalogue price.
-func buildOrderedItem(lineItem *entity.LineItem, discountP float64) entity.OrderedItem {
+// base on its own. So the percentage is sent to 2 decimals and ListPrice (which takes 4) is set
+// so that the line net Zoho recomputes as ListPrice x Qty x (1 - DiscountP/100) is the exact
+// net. When the percentage fits in 2 decimals, ListPrice stays at the catalogue price.
+func buildOrderedItem(lineItem *entity.LineItem, net, gross entity.Amount) entity.OrderedItem {
 	listPrice := lineItem.Price
 	if lineItem.MasterPrice > 0 && lineItem.MasterPrice > lineItem.Price {
 		listPrice = lineItem.MasterPrice
 	}
-	// Exact net unit = paid price further reduced by the order-level percentage.
-	netUnit := lineItem.Price * (1 - discountP/100)
+	netUnit := lineItem.Price
+	if gross > 0 {
+		netUnit = lineItem.Price.Scale(net, gross)
+	}
+
 	lineDiscountP := 0.0
 	if listPrice > 0 {
-		lineDiscountP = round2((1 - netUnit/listPrice) * 100)
-	}
-	if lineDiscountP < 100 {
-		listPrice = round4(netUnit / (1 - lineDiscountP/100))
+		// The share taken off as a fraction to 4 places (Unit is 1) is the percentage to 2.
+		// Dividing the integer by 100 gives the float nearest that decimal, as Zoho's own
+		// "12.34" parses to.
+		off := entity.Unit.Scale(listPrice-netUnit, listPrice)
+		lineDiscountP = float64(off) / 100
+		if kept := entity.Unit - off; kept > 0 && listPrice.Scale(kept, entity.Unit) != netUnit {
+			listPrice = netUnit.Scale(entity.Unit, kept)
+		}
 	}
-	// Net line total. Zoho recomputes this value from ListPrice and DiscountP rather than
-	// storing what we send, so it is kept at full precision to match.
-	totalNet := round4(lineItem.Qty * listPrice * (1 - lineDiscountP/100))
 	return entity.OrderedItem{
 		Product: entity.ZohoProduct{
 			ID: lineItem.ZohoId,
@@ -439,12 +475,17 @@ func buildOrderedItem(lineItem *entity.LineItem, discountP float64) entity.Order
 		Quantity:  int64(lineItem.Qty),
 		DiscountP: lineDiscountP,
 		ListPrice: listPrice,
-		Total:     totalNet,
+		// Zoho recomputes the line total from ListPrice and DiscountP and ignores this one; it
+		// is the exact net for the record.
+		Total: netUnit.Mul(lineItem.Qty),
 	}
 }
 
+// buildGood builds a B2B Goods record. Its total carries the order discount on the line, in the
+// order's currency to the cent, as does its price.
 func buildGood(lineItem *entity.LineItem, currency Currency, discountP float64) entity.Good {
-	totalWithDiscount := round2(lineItem.Qty * lineItem.Price * discountP / 100)
+	price := lineItem.Price
+	totalWithDiscount := price.Mul(lineItem.Qty * discountP / 100).Round(2)
 	good := entity.Good{
 		Product: entity.ZohoProduct{
 			ID: lineItem.ZohoId,
@@ -457,20 +498,20 @@ func buildGood(lineItem *entity.LineItem, currency Currency, discountP float64)
 
 	switch currency.Code {
 	case entity.CurrencyUAH:
-		good.PriceUAH = round2(lineItem.Price * currency.Rate)
-		good.TotalUAH = round2(totalWithDiscount * currency.Rate)
+		good.PriceUAH = price.Mul(currency.Rate).Round(2)
+		good.TotalUAH = totalWithDiscount.Mul(currency.Rate).Round(2)
 		break
 	case entity.CurrencyPLN:
-		good.PricePLN = round2(lineItem.Price)
-		good.TotalPLN = round2(totalWithDiscount)
+		good.PricePLN = price.Round(2)
+		good.TotalPLN = totalWithDiscount
 		break
 	case entity.CurrencyUSD:
-		good.PriceUSD = round2(lineItem.Price * currency.Rate)
-		good.TotalUSD = round2(totalWithDiscount * currency.Rate)
+		good.PriceUSD = price.Mul(currency.Rate).Round(2)
+		good.TotalUSD = totalWithDiscount.Mul(currency.Rate).Round(2)
 		break
 	case entity.CurrencyEUR:
-		good.PriceEUR = round2(lineItem.Price * currency.Rate)
-		good.TotalEUR = round2(totalWithDiscount * currency.Rate)
+		good.PriceEUR = price.Mul(currency.Rate).Round(2)
+		good.TotalEUR = totalWithDiscount.Mul(currency.Rate).Round(2)
 		break
 	}
 
@@ -481,7 +522,7 @@ func buildGood(lineItem *entity.LineItem, currency Currency, discountP float64)
 // the VAT actually contained in what the customer was charged. A positive gap means the shop
 // declared VAT on money the customer never paid (VAT charged on undiscounted amounts, the
 // docs/OPENCART_VAT_BUG_RU.md case); ~0 means the order is healthy.
-func taxHealthGap(oc *entity.CheckoutParams) float64 {
+func taxHealthGap(oc *entity.CheckoutParams) entity.Amount {
 	if oc.VatRate() <= 0 {
 		return 0
 	}
@@ -506,31 +547,24 @@ func (c *Core) buildZohoOrder(oc *entity.CheckoutParams, contactID string) (enti
 	// customer was actually charged, and the VAT Zoho records equal the VAT actually contained
 	// in that amount.
 	//
-	// The net base therefore needs real precision, but Zoho caps DiscountP at 2 decimal
-	// places — buildOrderedItem sends the rounded percentage and folds the residual into
-	// ListPrice so the recomputed base is still exact.
+	// The net base therefore needs real precision. Each line keeps productNet/SubTotal of its
+	// paid price, scaled exactly; Zoho caps DiscountP at 2 decimal places, and buildOrderedItem
+	// sets ListPrice so that the base Zoho recomputes is still exact.
 	rate := oc.VatRate()
-	couponAmount := math.Abs(oc.Coupon)
-
-	var discountP float64
-	if oc.SubTotal > 0 {
-		productNet := (oc.Total - oc.Shipping) / (1 + rate)
-		discountP = round4((1 - productNet/oc.SubTotal) * 100)
-		if discountP < 0 {
-			discountP = 0
-		}
-	}
+
+	// An order charged more than its catalogue value gets no discount.
+	productNet := min((oc.Total - oc.Shipping).Div(1+rate), oc.SubTotal)
 
 	// Health check. Where tax_value is over-declared, the shop is still charging VAT on
 	// discounted amounts and the order we are about to sync is worth more than the customer
 	// paid for — see docs/OPENCART_VAT_BUG_RU.md. The order still syncs coherently (Zoho gets
 	// the amount actually charged, with the VAT that amount really contains), but say so loudly.
-	if gap := taxHealthGap(oc); math.Abs(gap) > 0.01 {
+	if gap := taxHealthGap(oc); gap.Abs() > taxGapTolerance {
 		c.log.With(
 			slog.Int64("order_id", oc.OrderId),
-			slog.Float64("tax_value", round2(oc.TaxValue)),
-			slog.Float64("lawful_tax", round2(oc.TaxValue-gap)),
-			slog.Float64("over_declared", math.Round(gap*100)/100),
+			slog.Float64("tax_value", oc.TaxValue.Round(2).Float64()),
+			slog.Float64("lawful_tax", (oc.TaxValue-gap).Round(2).Float64()),
+			slog.Float64("over_declared", gap.Round(2).Float64()),
 		).Warn("OpenCart tax_value does not match the VAT contained in the taxed portion of the order total")
 	}
 
@@ -539,7 +573,7 @@ func (c *Core) buildZohoOrder(oc *entity.CheckoutParams, contactID string) (enti
 	// Build all ordered items at list price, carrying the whole pre-tax reduction.
 	allItems := make([]entity.OrderedItem, 0, len(lineItems))
 	for _, d := range lineItems {
-		allItems = append(allItems, buildOrderedItem(d, discountP))
+		allItems = append(allItems, buildOrderedItem(d, productNet, oc.SubTotal))
 	}
 	// Add shipping as item without discount
 	if oc.Shipping > 0 {
@@ -548,7 +582,7 @@ func (c *Core) buildZohoOrder(oc *entity.CheckoutParams, contactID string) (enti
 			Qty:    1,
 			Price:  oc.Shipping,
 		}
-		allItems = append(allItems, buildOrderedItem(shippingItem, 0))
+		allItems = append(allItems, buildOrderedItem(shippingItem, 0, 0))
 	}
 
 	// Split into main order items (first chunk) and remaining chunks
@@ -572,14 +606,14 @@ func (c *Core) buildZohoOrder(oc *entity.CheckoutParams, contactID string) (enti
 		Discount:        0,
 		DiscountP:       0,
 		CouponTitle:     oc.CouponTitle,
-		CouponValue:     round2(couponAmount),
+		CouponValue:     oc.Coupon.Abs().Round(2),
 		Description:     oc.Comment,
 		CustomerNo:      "",
 		ShippingState:   "",
 		Tax:             0,
 		VAT:             round0(rate * 100),
-		GrandTotal:      round2(oc.Total),
-		SubTotal:        round2(oc.SubTotal),
+		GrandTotal:      oc.Total.Round(2),
+		SubTotal:        oc.SubTotal.Round(2),
 		Currency:        oc.Currency,
 		BillingCountry:  oc.ClientDetails.Country,
 		Carrier:         "",
@@ -682,9 +716,9 @@ func (c *Core) buildZohoOrderB2B(oc *entity.CheckoutParams, contactID string) (e
 		OrderSource:    ZohoOrderSource,
 	}
 
-	setCurrencyTotals(&order, orderCurrency.Code,
-		oc.Total*orderCurrency.Rate,
-		(oc.Total-oc.TaxValue)*orderCurrency.Rate,
+	setCurrencyTotals(&order,
+		entity.NewMoney(oc.Total.Mul(orderCurrency.Rate), orderCurrency.Code),
+		entity.NewMoney((oc.Total-oc.TaxValue).Mul(orderCurrency.Rate), orderCurrency.Code),
 	)
 
 	return order, chunkedItems
@@ -746,21 +780,23 @@ func chunkSlice[T any](items []T, size int) [][]*T {
 }
 
 // setCurrencyTotals assigns grand total and sub total to the correct currency-specific
-// fields on a ZohoOrderB2B, based on the currency code.
-func setCurrencyTotals(order *entity.ZohoOrderB2B, code string, grandTotal, subTotal float64) {
-	switch code {
+// fields on a ZohoOrderB2B, based on the grand total's currency.
+func setCurrencyTotals(order *entity.ZohoOrderB2B, grandTotal, subTotal entity.Money) {
+	grand := grandTotal.Amount.Abs().Round(2)
+	sub := subTotal.Amount.Abs().Round(2)
+	switch grandTotal.Currency {
 	case entity.CurrencyUAH:
-		order.GrandTotalUAH = round2(grandTotal)
-		order.SubTotalUAH = round2(subTotal)
+		order.GrandTotalUAH = grand
+		order.SubTotalUAH = sub
 	case entity.CurrencyPLN:
-		order.GrandTotalPLN = round2(grandTotal)
-		order.SubTotalPLN = round2(subTotal)
+		order.GrandTotalPLN = grand
+		order.SubTotalPLN = sub
 	case entity.CurrencyUSD:
-		order.GrandTotalUSD = round2(grandTotal)
-		order.SubTotalUSD = round2(subTotal)
+		order.GrandTotalUSD = grand
+		order.SubTotalUSD = sub
 	case entity.CurrencyEUR:
-		order.GrandTotalEUR = round2(grandTotal)
-		order.SubTotalEUR = round2(subTotal)
+		order.GrandTotalEUR = grand
+		order.SubTotalEUR = sub
 	}
 }
 
@@ -772,26 +808,9 @@ func round0(value float64) float64 {
 	return math.Round(value)
 }
 
-// round2 rounds a float64 to 2 decimal points
-func round2(value float64) float64 {
-	if value < 0 {
-		value = -value
-	}
-	return math.Round(value*100) / 100
-}
-
-// fmtCents formats an int64 cents amount as a 2-decimal currency string.
-func fmtCents(cents int64) string {
-	return fmt.Sprintf("%.2f", float64(cents)/100)
-}
-
-// round4 rounds a float64 to 4 decimal points, used for rates and percentages.
-func round4(value float64) float64 {
-	return math.Round(value*10000) / 10000
-}
-
-// saveOrderVersionToMongo saves the order payload as a new version to MongoDB.
-func (c *Core) saveOrderVersionToMongo(orderID int64, payload interface{}) {
+// saveOrderVersionToMongo saves the order payload as a new version to MongoDB; direction tells
+// whether it was pushed to Zoho or received from it.
+func (c *Core) saveOrderVersionToMongo(orderID int64, direction string, payload interface{}) {
 	if c.mongoRepo == nil {
 		return
 	}
@@ -802,7 +821,7 @@ func (c *Core) saveOrderVersionToMongo(orderID int64, payload interface{}) {
 		return
 	}
 
-	err = c.mongoRepo.SaveOrderVersion(orderID, string(payloadBytes))
+	err = c.mongoRepo.SaveOrderVersion(orderID, direction, string(payloadBytes))
 	if err != nil {
 		c.log.With(sl.Err(err), slog.Int64("order_id", orderID)).Warn("failed to save order version to mongo")
 	}
@@ -213,13 +213,13 @@ func TestBuildZohoOrder_Chunking(t *testing.T) {
 					Name:   "Product",
 					ZohoId: "zoho-id",
 					Qty:    1,
-					Price:  10.00,
+					Price:  entity.AmountOf(10.00),
 				}
 			}
 
 			order := &entity.CheckoutParams{
 				OrderId:   123,
-				Total:     float64(tt.itemCount) * 10.00,
+				Total:     entity.AmountOf(float64(tt.itemCount) * 10.00),
 				Currency:  "PLN",
 				StatusId:  1,
 				LineItems: lineItems,
@@ -279,50 +279,50 @@ func TestCalculateDiscountPercent(t *testing.T) {
 		{
 			name: "no discount - full price equals total",
 			items: []entity.ApiOrderedItem{
-				{Price: 100.0, Quantity: 1, Total: 100.0},
+				{Price: entity.AmountOf(100.0), Quantity: 1, Total: entity.AmountOf(100.0)},
 			},
 			expected: 0,
 		},
 		{
 			name: "10% discount",
 			items: []entity.ApiOrderedItem{
-				{Price: 100.0, Quantity: 1, Total: 90.0},
+				{Price: entity.AmountOf(100.0), Quantity: 1, Total: entity.AmountOf(90.0)},
 			},
 			expected: 0.1,
 		},
 		{
 			name: "25% discount",
 			items: []entity.ApiOrderedItem{
-				{Price: 100.0, Quantity: 1, Total: 75.0},
+				{Price: entity.AmountOf(100.0), Quantity: 1, Total: entity.AmountOf(75.0)},
 			},
 			expected: 0.25,
 		},
 		{
 			name: "50% discount",
 			items: []entity.ApiOrderedItem{
-				{Price: 100.0, Quantity: 2, Total: 100.0}, // Full would be 200, so 50% off
+				{Price: entity.AmountOf(100.0), Quantity: 2, Total: entity.AmountOf(100.0)}, // Full would be 200, so 50% off
 			},
 			expected: 0.5,
 		},
 		{
 			name: "multiple items with mixed discounts",
 			items: []entity.ApiOrderedItem{
-				{Price: 100.0, Quantity: 1, Total: 90.0}, // 10% off
-				{Price: 50.0, Quantity: 2, Total: 90.0},  // 10% off (full = 100)
+				{Price: entity.AmountOf(100.0), Quantity: 1, Total: entity.AmountOf(90.0)}, // 10% off
+				{Price: entity.AmountOf(50.0), Quantity: 2, Total: entity.AmountOf(90.0)},  // 10% off (full = 100)
 			},
 			expected: 0.1, // (90+90) / (100+100) = 180/200 = 0.9, so discount = 0.1
 		},
 		{
 			name: "100% discount (free)",
 			items: []entity.ApiOrderedItem{
-				{Price: 100.0, Quantity: 1, Total: 0.0},
+				{Price: entity.AmountOf(100.0), Quantity: 1, Total: 0.0},
 			},
 			expected: 1.0,
 		},
 		{
 			name: "negative discount (shouldn't happen but handled)",
 			items: []entity.ApiOrderedItem{
-				{Price: 100.0, Quantity: 1, Total: 110.0}, // More than full price
+				{Price: entity.AmountOf(100.0), Quantity: 1, Total: entity.AmountOf(110.0)}, // More than full price
 			},
 			expected: -0.1,
 		},
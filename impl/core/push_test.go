@@ -62,12 +62,12 @@ func (f *fakeZoho) CreatePayment(entity.ZohoPayment) (string, error) {
 func pushableOrder() *entity.CheckoutParams {
 	return &entity.CheckoutParams{
 		OrderId: 16939, Currency: "PLN",
-		SubTotal: 422.764, TaxValue: 87.5121, Coupon: -42.2764, Total: 468.00,
+		SubTotal: entity.AmountOf(422.764), TaxValue: entity.AmountOf(87.5121), Coupon: entity.AmountOf(-42.2764), Total: entity.AmountOf(468.00),
 		CouponTitle:   "Kupon (dark-591B9EAB)",
 		PaymentStatus: "complete", PaymentAmount: 46800,
 		ClientDetails: minimalClient(),
 		LineItems: []*entity.LineItem{
-			{Name: "P", Id: 1, Uid: "uid-1", ZohoId: "Z1", Price: 52.8455, Qty: 8, Tax: 12.1545, Total: 422.764},
+			{Name: "P", Id: 1, Uid: "uid-1", ZohoId: "Z1", Price: entity.AmountOf(52.8455), Qty: 8, Tax: entity.AmountOf(12.1545), Total: entity.AmountOf(422.764)},
 		},
 	}
 }
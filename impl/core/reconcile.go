@@ -0,0 +1,239 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/database/sql"
+	"zohoclient/internal/lib/sl"
+)
+
+// Reconcile checks that OpenCart and Zoho agree on the orders placed in [from, to). Every
+// synced order is read back from Zoho and compared: grand total, products and quantities,
+// status, and the linked payment. Orders still without a zoho_id after opts.UnsyncedAfter are
+// reported, and so are the Sales Orders created in the range whose ID_site names no OpenCart
+// order, or an order synced to another record.
+//
+// Nothing is written on either side.
+func (c *Core) Reconcile(opts entity.ReconcileOptions) (entity.ReconcileResult, error) {
+	res := entity.ReconcileResult{From: opts.From, To: opts.To, Issues: []entity.ReconcileIssue{}}
+	if c.repo == nil || c.zoho == nil {
+		return res, fmt.Errorf("reconciliation needs both the database and the Zoho service")
+	}
+
+	log := c.log.With(
+		sl.Module("reconcile"),
+		slog.Time("from", opts.From),
+		slog.Time("to", opts.To),
+	)
+
+	synced, err := c.repo.OrdersSyncedBetween(opts.From, opts.To)
+	if err != nil {
+		return res, fmt.Errorf("load synced orders: %w", err)
+	}
+	log.With(slog.Int("orders", len(synced))).Info("reconciliation started")
+
+	for i, s := range synced {
+		issues := c.reconcileOrder(s)
+		res.Checked++
+		if len(issues) == 0 {
+			res.Matched++
+		}
+		res.Issues = append(res.Issues, issues...)
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(synced))
+		}
+	}
+
+	unsynced, err := c.repo.OrdersUnsyncedBetween(opts.From, opts.To)
+	if err != nil {
+		return res, fmt.Errorf("load unsynced orders: %w", err)
+	}
+	res.Issues = append(res.Issues, c.reconcileUnsynced(unsynced, time.Now().Add(-opts.UnsyncedAfter))...)
+
+	records, err := c.zoho.SalesOrdersCreatedBetween(opts.From, opts.To)
+	if err != nil {
+		return res, fmt.Errorf("list zoho sales orders: %w", err)
+	}
+	res.ZohoOrders = len(records)
+	orphans, err := c.reconcileZohoOrders(records)
+	if err != nil {
+		return res, err
+	}
+	res.Issues = append(res.Issues, orphans...)
+
+	log.With(
+		slog.Int("checked", res.Checked),
+		slog.Int("matched", res.Matched),
+		slog.Int("zoho_orders", res.ZohoOrders),
+		slog.Int("issues", len(res.Issues)),
+	).Info("reconciliation finished")
+
+	return res, nil
+}
+
+// reconcileOrder compares one synced order with its Sales Order.
+func (c *Core) reconcileOrder(s sql.SyncedOrder) []entity.ReconcileIssue {
+	oc := s.Order
+	issue := func(kind, ocSide, zohoSide, detail string) entity.ReconcileIssue {
+		return entity.ReconcileIssue{Kind: kind, OrderId: oc.OrderId, ZohoID: s.ZohoID,
+			OpenCart: ocSide, Zoho: zohoSide, Detail: detail}
+	}
+
+	record, err := c.zoho.GetOrder(s.ZohoID)
+	if err != nil {
+		return []entity.ReconcileIssue{issue(entity.ReconcileMissing, "", "", err.Error())}
+	}
+
+	var issues []entity.ReconcileIssue
+	if diff, diverged := totalsDiverged(oc.Total, record.GrandTotal); diverged {
+		issues = append(issues, issue(entity.ReconcileTotal,
+			entity.NewMoney(oc.Total, oc.Currency).String(), record.GrandTotal.Round(2).String(),
+			"diff "+signedMoney(entity.NewMoney(diff, ""))))
+	}
+	if detail := itemsDifference(c.allOrderedItems(oc), record.OrderedItems); detail != "" {
+		issues = append(issues, issue(entity.ReconcileItems, "", "", detail))
+	}
+	if zohoStatus := c.GetStatusIdByName(record.Status); zohoStatus >= 0 && zohoStatus != oc.StatusId {
+		issues = append(issues, issue(entity.ReconcileStatus, c.statusLabel(oc.StatusId), record.Status, ""))
+	}
+	for _, detail := range c.paymentDifferences(oc, s.ZohoID) {
+		issues = append(issues, issue(entity.ReconcilePayment, "", "", detail))
+	}
+	return issues
+}
+
+// itemsDifference lists the products whose quantity differs between the subform OpenCart
+// would send and the one Zoho holds, or "" when they agree. Lines of one product are summed.
+func itemsDifference(want []entity.OrderedItem, got []entity.ZohoOrderedItemRow) string {
+	quantities := make(map[string][2]float64)
+	for _, item := range want {
+		q := quantities[item.Product.ID]
+		q[0] += float64(item.Quantity)
+		quantities[item.Product.ID] = q
+	}
+	for _, row := range got {
+		q := quantities[row.Product.ID]
+		q[1] += row.Quantity
+		quantities[row.Product.ID] = q
+	}
+
+	var diffs []string
+	for id, q := range quantities {
+		if q[0] != q[1] {
+			diffs = append(diffs, fmt.Sprintf("%s: OpenCart %g, Zoho %g", id, q[0], q[1]))
+		}
+	}
+	sort.Strings(diffs)
+	return strings.Join(diffs, "; ")
+}
+
+// paymentDifferences checks the payment OpenCart recorded against those linked in Zoho.
+func (c *Core) paymentDifferences(oc *entity.CheckoutParams, zohoId string) []string {
+	ocPayment, err := c.repo.GetOrderZohoPaymentId(oc.OrderId)
+	if err != nil {
+		return []string{fmt.Sprintf("read zoho_payment_id: %v", err)}
+	}
+	payments, err := c.zoho.GetOrderPayments(zohoId)
+	if err != nil {
+		return []string{fmt.Sprintf("read zoho payments: %v", err)}
+	}
+
+	var diffs []string
+	linked := slices.IndexFunc(payments, func(p entity.ZohoPaymentRecord) bool { return p.ID == ocPayment })
+	switch {
+	case ocPayment == paymentZohoIdError:
+		diffs = append(diffs, "payment failed to sync ([ERR])")
+	case ocPayment == "" && len(payments) > 0:
+		diffs = append(diffs, fmt.Sprintf("Zoho payment %s is not recorded in OpenCart", payments[0].ID))
+	case ocPayment == "" && oc.PaymentStatus != "":
+		diffs = append(diffs, fmt.Sprintf("paid in OpenCart (%s), no payment in Zoho", oc.PaymentStatus))
+	case ocPayment != "" && linked < 0:
+		diffs = append(diffs, fmt.Sprintf("zoho_payment_id %s is not linked to the Sales Order", ocPayment))
+	case linked >= 0 && oc.PaymentAmount > 0:
+		if paid := entity.Amount(oc.PaymentAmount) * entity.Cent; amountChanged(paid, payments[linked].Sum) {
+			diffs = append(diffs, fmt.Sprintf("payment sum: OpenCart %s, Zoho %s", paid.Round(2), payments[linked].Sum.Round(2)))
+		}
+	}
+	if len(payments) > 1 {
+		diffs = append(diffs, fmt.Sprintf("%d payments linked to the Sales Order", len(payments)))
+	}
+	return diffs
+}
+
+// reconcileUnsynced reports the orders placed before cutoff that still have no zoho_id.
+func (c *Core) reconcileUnsynced(orders []sql.UnsyncedOrder, cutoff time.Time) []entity.ReconcileIssue {
+	var issues []entity.ReconcileIssue
+	for _, o := range orders {
+		if !o.Created.Before(cutoff) {
+			continue
+		}
+		issues = append(issues, entity.ReconcileIssue{
+			Kind:     entity.ReconcileUnsynced,
+			OrderId:  o.OrderId,
+			OpenCart: c.statusLabel(o.StatusId),
+			Detail:   fmt.Sprintf("placed %s", o.Created.Format("2006-01-02 15:04")),
+		})
+	}
+	return issues
+}
+
+// reconcileZohoOrders reports the Sales Orders whose ID_site names no OpenCart order, or an
+// order that is not synced to them. Records without an ID_site were made by hand in Zoho and
+// are left out.
+func (c *Core) reconcileZohoOrders(records []entity.ZohoOrderRecord) ([]entity.ReconcileIssue, error) {
+	var issues []entity.ReconcileIssue
+	ids := make([]int64, 0, len(records))
+	for _, r := range records {
+		if r.IDsite == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(r.IDsite, 10, 64)
+		if err != nil {
+			issues = append(issues, entity.ReconcileIssue{Kind: entity.ReconcileOrphan, ZohoID: r.ID,
+				Zoho: r.Subject, Detail: fmt.Sprintf("ID_site %q is not an order id", r.IDsite)})
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return issues, nil
+	}
+
+	known, err := c.repo.OrderZohoIds(ids)
+	if err != nil {
+		return issues, fmt.Errorf("look up zoho orders in opencart: %w", err)
+	}
+	for _, r := range records {
+		id, err := strconv.ParseInt(r.IDsite, 10, 64)
+		if err != nil {
+			continue
+		}
+		zohoId, ok := known[id]
+		switch {
+		case !ok:
+			issues = append(issues, entity.ReconcileIssue{Kind: entity.ReconcileOrphan, OrderId: id, ZohoID: r.ID,
+				Zoho: r.Subject, Detail: "no such order in OpenCart"})
+		case zohoId == "":
+			issues = append(issues, entity.ReconcileIssue{Kind: entity.ReconcileDuplicate, OrderId: id, ZohoID: r.ID,
+				Zoho: r.Subject, Detail: "the OpenCart order has no zoho_id, the next push creates another record"})
+		case zohoId != r.ID:
+			issues = append(issues, entity.ReconcileIssue{Kind: entity.ReconcileDuplicate, OrderId: id, ZohoID: r.ID,
+				OpenCart: zohoId, Zoho: r.Subject, Detail: fmt.Sprintf("the OpenCart order is synced to %s", zohoId)})
+		}
+	}
+	return issues, nil
+}
+
+// statusLabel names an OpenCart status for a report.
+func (c *Core) statusLabel(statusId int) string {
+	if name, ok := c.statuses[statusId]; ok {
+		return fmt.Sprintf("%s (%d)", name, statusId)
+	}
+	return fmt.Sprintf("status %d", statusId)
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/database/sql"
+)
+
+type reconcileRepo struct {
+	Repository
+	synced     []sql.SyncedOrder
+	unsynced   []sql.UnsyncedOrder
+	zohoIds    map[int64]string
+	paymentIds map[int64]string
+}
+
+func (r *reconcileRepo) OrdersSyncedBetween(time.Time, time.Time) ([]sql.SyncedOrder, error) {
+	return r.synced, nil
+}
+
+func (r *reconcileRepo) OrdersUnsyncedBetween(time.Time, time.Time) ([]sql.UnsyncedOrder, error) {
+	return r.unsynced, nil
+}
+
+func (r *reconcileRepo) OrderZohoIds(ids []int64) (map[int64]string, error) {
+	found := make(map[int64]string)
+	for _, id := range ids {
+		if zohoId, ok := r.zohoIds[id]; ok {
+			found[id] = zohoId
+		}
+	}
+	return found, nil
+}
+
+func (r *reconcileRepo) GetOrderZohoPaymentId(orderId int64) (string, error) {
+	return r.paymentIds[orderId], nil
+}
+
+type reconcileZoho struct {
+	Zoho
+	records  map[string]*entity.ZohoOrderRecord
+	created  []entity.ZohoOrderRecord
+	payments map[string][]entity.ZohoPaymentRecord
+}
+
+func (z *reconcileZoho) GetOrder(id string) (*entity.ZohoOrderRecord, error) {
+	if rec, ok := z.records[id]; ok {
+		return rec, nil
+	}
+	return nil, fmt.Errorf("zoho api: 404 Not Found")
+}
+
+func (z *reconcileZoho) SalesOrdersCreatedBetween(time.Time, time.Time) ([]entity.ZohoOrderRecord, error) {
+	return z.created, nil
+}
+
+func (z *reconcileZoho) GetOrderPayments(id string) ([]entity.ZohoPaymentRecord, error) {
+	return z.payments[id], nil
+}
+
+func TestReconcile(t *testing.T) {
+	agreed := order17103()
+	agreed.StatusId = entity.OrderStatusNew
+
+	edited := order17103()
+	edited.OrderId, edited.StatusId = 17104, entity.OrderStatusPayed
+	edited.PaymentStatus, edited.PaymentAmount = "succeeded", 99609
+
+	lost := order17103()
+	lost.OrderId = 17105
+
+	c := backfillCore(nil, nil)
+	agreedRec := syncedCorrectly(c, agreed, "ZO-1")
+	agreedRec.Status = "Нове"
+	editedRec := syncedCorrectly(c, edited, "ZO-2")
+	editedRec.Status = "Нове"
+	editedRec.GrandTotal = 900
+	editedRec.OrderedItems[0].Quantity = 29
+
+	repo := &reconcileRepo{
+		synced: []sql.SyncedOrder{
+			{ZohoID: "ZO-1", Order: agreed},
+			{ZohoID: "ZO-2", Order: edited},
+			{ZohoID: "ZO-3", Order: lost},
+		},
+		unsynced: []sql.UnsyncedOrder{
+			{OrderId: 17106, StatusId: entity.OrderStatusNew, Created: time.Now().Add(-48 * time.Hour)},
+			{OrderId: 17107, StatusId: entity.OrderStatusNew, Created: time.Now()},
+		},
+		zohoIds:    map[int64]string{17103: "ZO-1", 17104: "ZO-2", 17105: "ZO-3", 17106: ""},
+		paymentIds: map[int64]string{17104: "PAY-9"},
+	}
+	zoho := &reconcileZoho{
+		records: map[string]*entity.ZohoOrderRecord{"ZO-1": agreedRec, "ZO-2": editedRec},
+		created: []entity.ZohoOrderRecord{
+			{ID: "ZO-1", IDsite: "17103"},
+			{ID: "ZO-4", IDsite: "17106"},
+			{ID: "ZO-5", IDsite: "99999"},
+			{ID: "ZO-6"},
+		},
+		payments: map[string][]entity.ZohoPaymentRecord{"ZO-2": {{ID: "PAY-1", Sum: entity.AmountOf(996.09)}}},
+	}
+	c.repo, c.zoho = repo, zoho
+
+	res, err := c.Reconcile(entity.ReconcileOptions{UnsyncedAfter: 6 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Checked != 3 || res.Matched != 1 || res.ZohoOrders != 4 {
+		t.Errorf("checked %d, matched %d, zoho orders %d; want 3, 1, 4", res.Checked, res.Matched, res.ZohoOrders)
+	}
+
+	want := map[string]int64{
+		entity.ReconcileTotal:     17104,
+		entity.ReconcileItems:     17104,
+		entity.ReconcileStatus:    17104,
+		entity.ReconcilePayment:   17104,
+		entity.ReconcileMissing:   17105,
+		entity.ReconcileUnsynced:  17106,
+		entity.ReconcileDuplicate: 17106,
+		entity.ReconcileOrphan:    99999,
+	}
+	if len(res.Issues) != len(want) {
+		t.Fatalf("issues = %+v, want %d", res.Issues, len(want))
+	}
+	for _, issue := range res.Issues {
+		if want[issue.Kind] != issue.OrderId {
+			t.Errorf("%s issue for order %d, want %d", issue.Kind, issue.OrderId, want[issue.Kind])
+		}
+	}
+}
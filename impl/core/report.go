@@ -0,0 +1,85 @@
+package core
+
+import (
+	"slices"
+	"sync"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// syncReport accumulates the counts of the daily report until it is taken.
+type syncReport struct {
+	mu      sync.Mutex
+	counts  entity.SyncReport
+	blocked map[int64]struct{}
+	taxGap  map[int64]struct{}
+}
+
+// countReport applies update to the running report.
+func (c *Core) countReport(update func(r *entity.SyncReport)) {
+	c.report.mu.Lock()
+	defer c.report.mu.Unlock()
+	update(&c.report.counts)
+}
+
+// reportBlockedOrder records an order held back by a product without a Zoho id. The poller
+// retries it every run, so orders are counted once.
+func (c *Core) reportBlockedOrder(orderId int64) {
+	c.report.mu.Lock()
+	defer c.report.mu.Unlock()
+	if c.report.blocked == nil {
+		c.report.blocked = make(map[int64]struct{})
+	}
+	c.report.blocked[orderId] = struct{}{}
+}
+
+// reportTaxGap records an order whose declared VAT is off by more than taxGapTolerance.
+func (c *Core) reportTaxGap(orderId int64) {
+	c.report.mu.Lock()
+	defer c.report.mu.Unlock()
+	if c.report.taxGap == nil {
+		c.report.taxGap = make(map[int64]struct{})
+	}
+	c.report.taxGap[orderId] = struct{}{}
+}
+
+// TakeSyncReport returns what the sync did since the previous report (or since start) and
+// starts a new period. Customer totals are read from the database at the time of the call.
+func (c *Core) TakeSyncReport() entity.SyncReport {
+	now := time.Now()
+
+	c.report.mu.Lock()
+	report := c.report.counts
+	report.OrdersBlockedOnProducts = sortedIds(c.report.blocked)
+	report.OrdersWithTaxGap = sortedIds(c.report.taxGap)
+	c.report.counts = entity.SyncReport{From: now}
+	c.report.blocked = nil
+	c.report.taxGap = nil
+	c.report.mu.Unlock()
+
+	if report.From.IsZero() {
+		c.runsMu.RLock()
+		report.From = c.startedAt
+		c.runsMu.RUnlock()
+	}
+	report.To = now
+
+	if c.repo != nil {
+		total, synced, err := c.repo.CountCustomers()
+		if err != nil {
+			c.log.With(sl.Err(err)).Warn("count customers for report")
+		}
+		report.CustomersTotal, report.CustomersInZoho = total, synced
+	}
+	return report
+}
+
+func sortedIds(set map[int64]struct{}) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+	"zohoclient/entity"
+)
+
+type reportRepo struct {
+	fakeRepo
+}
+
+func (r *reportRepo) CountCustomers() (int64, int64, error) { return 100, 97, nil }
+
+// A pushed order and its payment are counted, and taking the report starts a new period.
+func TestTakeSyncReport(t *testing.T) {
+	repo := &reportRepo{fakeRepo: fakeRepo{order: pushableOrder()}}
+	core := pushTestCore(&repo.fakeRepo, &fakeZoho{})
+	core.repo = repo
+
+	if _, err := core.PushOrderToZoho(16939); err != nil {
+		t.Fatalf("PushOrderToZoho() error = %v", err)
+	}
+	core.countReport(func(r *entity.SyncReport) { r.WebhooksEchoSuppressed++ })
+	core.reportBlockedOrder(17110)
+	core.reportBlockedOrder(17103)
+	core.reportBlockedOrder(17110)
+
+	report := core.TakeSyncReport()
+	if report.OrdersCreated != 1 || report.PaymentsCreated != 1 || report.WebhooksEchoSuppressed != 1 {
+		t.Errorf("report = %+v, want 1 order, 1 payment, 1 echo", report)
+	}
+	if len(report.OrdersBlockedOnProducts) != 2 || report.OrdersBlockedOnProducts[0] != 17103 {
+		t.Errorf("blocked = %v, want [17103 17110]", report.OrdersBlockedOnProducts)
+	}
+	if report.CustomersTotal != 100 || report.CustomersInZoho != 97 {
+		t.Errorf("customers = %d/%d, want 97/100", report.CustomersInZoho, report.CustomersTotal)
+	}
+	if report.To.IsZero() {
+		t.Error("report has no end time")
+	}
+
+	next := core.TakeSyncReport()
+	if next.OrdersCreated != 0 || len(next.OrdersBlockedOnProducts) != 0 {
+		t.Errorf("second report = %+v, want the counters reset", next)
+	}
+	if next.From.Before(report.To) {
+		t.Errorf("second report starts %v, before the first ended %v", next.From, report.To)
+	}
+}
@@ -0,0 +1,262 @@
+package core
+
+import (
+	"sync"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/database/sql"
+)
+
+// shadowRepository keeps in memory what the sync writes back to OpenCart in shadow mode:
+// zoho_id, the payment id and status, the customer's zoho_id and zoho_modified_time. The reads
+// the pollers select on see those values, so an order is recorded once per run of the service
+// rather than on every poll. Everything else, including the updates webhooks apply, goes to
+// the database as in live mode.
+//
+// Customers are still taken 100 at a time without a zoho_id, so only the first batch is
+// recorded.
+type shadowRepository struct {
+	Repository
+	mu              sync.Mutex
+	orderZohoIds    map[int64]string
+	paymentIds      map[int64]string
+	paymentStatuses map[int64]string
+	customerZohoIds map[int64]string
+}
+
+// NewShadowRepository wraps repo for shadow mode.
+func NewShadowRepository(repo Repository) Repository {
+	return &shadowRepository{
+		Repository:      repo,
+		orderZohoIds:    make(map[int64]string),
+		paymentIds:      make(map[int64]string),
+		paymentStatuses: make(map[int64]string),
+		customerZohoIds: make(map[int64]string),
+	}
+}
+
+func (r *shadowRepository) remember(m map[int64]string, id int64, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m[id] = value
+	return nil
+}
+
+func (r *shadowRepository) recall(m map[int64]string, id int64) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := m[id]
+	return value, ok
+}
+
+func (r *shadowRepository) ChangeOrderZohoId(orderId int64, zohoId string) error {
+	return r.remember(r.orderZohoIds, orderId, zohoId)
+}
+
+func (r *shadowRepository) UpdateOrderZohoPaymentId(orderId int64, zohoPaymentId string) error {
+	return r.remember(r.paymentIds, orderId, zohoPaymentId)
+}
+
+func (r *shadowRepository) UpdateOrderZohoPayment(orderId int64, zohoPaymentId, syncedStatus string) error {
+	_ = r.remember(r.paymentIds, orderId, zohoPaymentId)
+	return r.remember(r.paymentStatuses, orderId, syncedStatus)
+}
+
+func (r *shadowRepository) SetOrderZohoPaymentStatus(orderId int64, syncedStatus string) error {
+	return r.remember(r.paymentStatuses, orderId, syncedStatus)
+}
+
+// SetOrderZohoModifiedTime drops the time: it belongs to a write Zoho never saw, and echo
+// suppression must keep working from the live value.
+func (r *shadowRepository) SetOrderZohoModifiedTime(int64, time.Time) error {
+	return nil
+}
+
+func (r *shadowRepository) ChangeCustomerZohoId(customerId int64, zohoId string) error {
+	return r.remember(r.customerZohoIds, customerId, zohoId)
+}
+
+func (r *shadowRepository) GetNewOrders() ([]*entity.CheckoutParams, error) {
+	orders, err := r.Repository.GetNewOrders()
+	return shadowFilter(orders, func(o *entity.CheckoutParams) bool {
+		_, ok := r.recall(r.orderZohoIds, o.OrderId)
+		return ok
+	}), err
+}
+
+func (r *shadowRepository) GetOrdersPendingPayment() ([]*entity.CheckoutParams, error) {
+	orders, err := r.Repository.GetOrdersPendingPayment()
+	return shadowFilter(orders, func(o *entity.CheckoutParams) bool {
+		_, ok := r.recall(r.paymentIds, o.OrderId)
+		return ok
+	}), err
+}
+
+func (r *shadowRepository) GetOrdersPendingPaymentUpdate() ([]*entity.CheckoutParams, error) {
+	orders, err := r.Repository.GetOrdersPendingPaymentUpdate()
+	return shadowFilter(orders, func(o *entity.CheckoutParams) bool {
+		status, ok := r.recall(r.paymentStatuses, o.OrderId)
+		return ok && status == o.PaymentStatus
+	}), err
+}
+
+func (r *shadowRepository) GetNewCustomers() ([]*sql.CustomerRow, error) {
+	rows, err := r.Repository.GetNewCustomers()
+	return shadowFilter(rows, func(row *sql.CustomerRow) bool {
+		_, ok := r.recall(r.customerZohoIds, row.CustomerID)
+		return ok
+	}), err
+}
+
+func (r *shadowRepository) OrderSearchId(orderId int64) (string, *entity.CheckoutParams, error) {
+	zohoId, order, err := r.Repository.OrderSearchId(orderId)
+	if shadowId, ok := r.recall(r.orderZohoIds, orderId); ok && zohoId == "" {
+		zohoId = shadowId
+	}
+	return zohoId, order, err
+}
+
+func (r *shadowRepository) GetOrderZohoId(orderId int64) (string, error) {
+	zohoId, err := r.Repository.GetOrderZohoId(orderId)
+	if shadowId, ok := r.recall(r.orderZohoIds, orderId); ok && zohoId == "" {
+		zohoId = shadowId
+	}
+	return zohoId, err
+}
+
+func (r *shadowRepository) GetOrderZohoPaymentId(orderId int64) (string, error) {
+	paymentId, err := r.Repository.GetOrderZohoPaymentId(orderId)
+	if shadowId, ok := r.recall(r.paymentIds, orderId); ok {
+		paymentId = shadowId
+	}
+	return paymentId, err
+}
+
+// shadowFilter drops the rows shadow mode has already handled.
+func shadowFilter[T any](rows []T, handled func(T) bool) []T {
+	kept := rows[:0]
+	for _, row := range rows {
+		if !handled(row) {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// shadowMongoRepository keeps the B2B deal store in memory in shadow mode, so a Deal that was
+// only recorded does not block the live one, and leaves out the versions of orders pushed to
+// Zoho.
+type shadowMongoRepository struct {
+	MongoRepository
+	mu    sync.Mutex
+	deals map[string]entity.B2BDeal
+}
+
+// NewShadowMongoRepository wraps mongoRepo for shadow mode.
+func NewShadowMongoRepository(mongoRepo MongoRepository) MongoRepository {
+	return &shadowMongoRepository{MongoRepository: mongoRepo, deals: make(map[string]entity.B2BDeal)}
+}
+
+func (m *shadowMongoRepository) SaveOrderVersion(orderID int64, direction, payload string) error {
+	if direction == entity.VersionOutbound {
+		return nil
+	}
+	return m.MongoRepository.SaveOrderVersion(orderID, direction, payload)
+}
+
+// ClaimB2BDeal claims the order in memory, unless it was created in live mode already.
+func (m *shadowMongoRepository) ClaimB2BDeal(deal entity.B2BDeal) (*entity.B2BDeal, bool, error) {
+	stored, err := m.MongoRepository.GetB2BDeal(deal.OrderUID)
+	if err != nil {
+		return nil, false, err
+	}
+	if stored != nil {
+		return stored, false, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.deals[deal.OrderUID]; ok {
+		return &existing, false, nil
+	}
+	deal.CreatedAt = time.Now()
+	m.deals[deal.OrderUID] = deal
+	return nil, true, nil
+}
+
+func (m *shadowMongoRepository) SetB2BDealZohoId(orderUID, zohoID string) error {
+	return m.update(orderUID, func(d *entity.B2BDeal) { d.ZohoID = zohoID })
+}
+
+func (m *shadowMongoRepository) SetB2BDealPaymentId(orderUID, paymentID string) error {
+	return m.update(orderUID, func(d *entity.B2BDeal) { d.PaymentID = paymentID })
+}
+
+// ClaimB2BDealPayment claims the payment in memory; a deal stored in live mode is copied there
+// rather than changed.
+func (m *shadowMongoRepository) ClaimB2BDealPayment(orderUID string, claimedAt time.Time) (bool, error) {
+	stored, err := m.GetB2BDeal(orderUID)
+	if err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deal, ok := m.deals[orderUID]
+	if !ok && stored != nil {
+		deal = *stored
+	}
+	if deal.PaymentID != "" || !deal.PaymentClaimedAt.Equal(claimedAt) {
+		return false, nil
+	}
+	deal.OrderUID = orderUID
+	deal.PaymentClaimedAt = time.Now()
+	m.deals[orderUID] = deal
+	return true, nil
+}
+
+func (m *shadowMongoRepository) ReleaseB2BDealPayment(orderUID string) error {
+	return m.update(orderUID, func(d *entity.B2BDeal) { d.PaymentClaimedAt = time.Time{} })
+}
+
+// TakeOverB2BDeal renews a stale claim in memory; a stale claim stored in live mode is copied
+// there rather than changed.
+func (m *shadowMongoRepository) TakeOverB2BDeal(orderUID string, claimedAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deal, ok := m.deals[orderUID]
+	if ok && (deal.ZohoID != "" || !deal.CreatedAt.Equal(claimedAt)) {
+		return false, nil
+	}
+	deal.OrderUID = orderUID
+	deal.CreatedAt = time.Now()
+	m.deals[orderUID] = deal
+	return true, nil
+}
+
+func (m *shadowMongoRepository) ReleaseB2BDeal(orderUID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.deals, orderUID)
+	return nil
+}
+
+// GetB2BDeal prefers a deal recorded in shadow mode over the stored one.
+func (m *shadowMongoRepository) GetB2BDeal(orderUID string) (*entity.B2BDeal, error) {
+	m.mu.Lock()
+	deal, ok := m.deals[orderUID]
+	m.mu.Unlock()
+	if ok {
+		return &deal, nil
+	}
+	return m.MongoRepository.GetB2BDeal(orderUID)
+}
+
+func (m *shadowMongoRepository) update(orderUID string, apply func(*entity.B2BDeal)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deal := m.deals[orderUID]
+	deal.OrderUID = orderUID
+	apply(&deal)
+	m.deals[orderUID] = deal
+	return nil
+}
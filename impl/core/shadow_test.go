@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+	"zohoclient/entity"
+)
+
+// newOrdersRepo lists its order as new for as long as its zoho_id is empty in the database.
+type newOrdersRepo struct {
+	*fakeRepo
+}
+
+func (r newOrdersRepo) GetNewOrders() ([]*entity.CheckoutParams, error) {
+	if r.zohoId != "" {
+		return nil, nil
+	}
+	return []*entity.CheckoutParams{r.order}, nil
+}
+
+// In shadow mode an order is sent once, nothing is written back to OpenCart, and a later push
+// updates the record the shadow create made up rather than creating another one.
+func TestShadowRepository_RecordsOrderOnce(t *testing.T) {
+	repo := &fakeRepo{order: pushableOrder()}
+	zoho := &fakeZoho{}
+	core := pushTestCore(repo, zoho)
+	core.repo = NewShadowRepository(newOrdersRepo{repo})
+
+	core.ProcessOrders()
+	core.ProcessOrders()
+
+	if zoho.createOrderCalls != 1 || zoho.createPaymentCalls != 1 {
+		t.Errorf("create order=%d payment=%d, want 1 each", zoho.createOrderCalls, zoho.createPaymentCalls)
+	}
+	if repo.changeZohoIdCalls != 0 {
+		t.Errorf("zoho_id written to OpenCart %d time(s), want 0", repo.changeZohoIdCalls)
+	}
+
+	if _, err := core.PushOrderToZoho(16939); err != nil {
+		t.Fatal(err)
+	}
+	if zoho.createOrderCalls != 1 || zoho.updatedID != "NEW-ZOHO-ID" {
+		t.Errorf("push: create=%d, updated %q, want an update of NEW-ZOHO-ID", zoho.createOrderCalls, zoho.updatedID)
+	}
+}
+
+// A B2B deal recorded in shadow mode stays in memory: a redelivery finds it, the store never
+// sees it.
+func TestShadowMongoRepository_KeepsDealsInMemory(t *testing.T) {
+	store := NewShadowMongoRepository(&fakeDealStore{})
+
+	if _, claimed, err := store.ClaimB2BDeal(entity.B2BDeal{OrderUID: "uid-1"}); err != nil || !claimed {
+		t.Fatalf("claim = %v, %v, want a claim", claimed, err)
+	}
+	if err := store.SetB2BDealZohoId("uid-1", "shadow-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	existing, claimed, err := store.ClaimB2BDeal(entity.B2BDeal{OrderUID: "uid-1"})
+	if err != nil || claimed || existing.ZohoID != "shadow-1" {
+		t.Errorf("redelivery = %+v, %v, %v, want the shadow deal", existing, claimed, err)
+	}
+}
+
+// fakeDealStore has no deals and panics on any write.
+type fakeDealStore struct {
+	MongoRepository
+}
+
+func (fakeDealStore) GetB2BDeal(string) (*entity.B2BDeal, error) { return nil, nil }
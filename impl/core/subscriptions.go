@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// RequestSubscription records a Telegram user's request to be notified of order status
+// changes. A new request waits for an admin to confirm it unless an admin makes it; sending
+// it again only replaces the filter and keeps the state.
+func (c *Core) RequestSubscription(userId int, user string, filter entity.SubscriptionFilter, admin bool) (*entity.Subscription, error) {
+	if c.mongoRepo == nil {
+		return nil, fmt.Errorf("subscription store not available")
+	}
+	sub, err := c.mongoRepo.GetSubscription(userId, entity.SubscriptionTypeStatus)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		created := entity.NewSubscription(userId, user)
+		created.CreatedAt = time.Now()
+		sub = &created
+	}
+	sub.User = user
+	sub.SubscriptionFilter = filter
+	if admin {
+		sub.Role = "admin"
+		sub.Confirm()
+	}
+	if err = c.mongoRepo.SaveSubscription(*sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ConfirmSubscription activates a pending subscription.
+func (c *Core) ConfirmSubscription(userId int) (*entity.Subscription, error) {
+	if c.mongoRepo == nil {
+		return nil, fmt.Errorf("subscription store not available")
+	}
+	sub, err := c.mongoRepo.GetSubscription(userId, entity.SubscriptionTypeStatus)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, entity.ErrSubscriptionNotFound
+	}
+	sub.Confirm()
+	if err = c.mongoRepo.SaveSubscription(*sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// CancelSubscription removes a subscription, pending or active.
+func (c *Core) CancelSubscription(userId int) error {
+	if c.mongoRepo == nil {
+		return fmt.Errorf("subscription store not available")
+	}
+	deleted, err := c.mongoRepo.DeleteSubscription(userId, entity.SubscriptionTypeStatus)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return entity.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// Subscriptions lists the order status subscriptions, oldest first.
+func (c *Core) Subscriptions() ([]entity.Subscription, error) {
+	if c.mongoRepo == nil {
+		return nil, fmt.Errorf("subscription store not available")
+	}
+	return c.mongoRepo.GetSubscriptions(entity.SubscriptionTypeStatus)
+}
+
+// statusChange describes an order moved to another status by a Zoho update.
+func statusChange(orderId int64, zohoId string, params *entity.CheckoutParams, from, to int, statusName string) entity.OrderStatusChange {
+	change := entity.OrderStatusChange{
+		OrderId:      orderId,
+		ZohoId:       zohoId,
+		FromStatusId: from,
+		StatusId:     to,
+		StatusName:   statusName,
+		Currency:     params.Currency,
+		Total:        params.Total.Round(2),
+	}
+	if params.ClientDetails != nil {
+		change.CustomerGroupId = params.ClientDetails.GroupId
+		change.Customer = strings.TrimSpace(params.ClientDetails.FirstName + " " + params.ClientDetails.LastName)
+	}
+	return change
+}
+
+// notifyStatusChange tells the active subscribers whose filter matches about a status change.
+// It runs in the background so a slow store or chat never delays the webhook reply.
+func (c *Core) notifyStatusChange(change entity.OrderStatusChange) {
+	if c.statusNotifier == nil || c.mongoRepo == nil || change.FromStatusId == change.StatusId {
+		return
+	}
+	change.Shadow = c.shadow
+	go func() {
+		subs, err := c.mongoRepo.GetSubscriptions(entity.SubscriptionTypeStatus)
+		if err != nil {
+			c.log.With(sl.Err(err), slog.Int64("order_id", change.OrderId)).Warn("load status subscriptions")
+			return
+		}
+		for _, sub := range subs {
+			if sub.IsActive() && sub.Matches(change) {
+				c.statusNotifier.NotifyOrderStatus(sub, change)
+			}
+		}
+	}()
+}
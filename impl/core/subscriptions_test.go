@@ -0,0 +1,189 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+// subscriptionStore keeps subscriptions in memory, keyed by user id.
+type subscriptionStore struct {
+	MongoRepository
+	subs map[int]entity.Subscription
+}
+
+func (s *subscriptionStore) SaveSubscription(sub entity.Subscription) error {
+	s.subs[sub.UserID] = sub
+	return nil
+}
+
+func (s *subscriptionStore) GetSubscription(userID int, _ string) (*entity.Subscription, error) {
+	sub, ok := s.subs[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &sub, nil
+}
+
+func (s *subscriptionStore) GetSubscriptions(_ string) ([]entity.Subscription, error) {
+	var subs []entity.Subscription
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (s *subscriptionStore) DeleteSubscription(userID int, _ string) (bool, error) {
+	_, ok := s.subs[userID]
+	delete(s.subs, userID)
+	return ok, nil
+}
+
+type notified struct {
+	userId  int
+	orderId int64
+	shadow  bool
+}
+
+// chanNotifier reports each notification on a channel, since they are sent in the background.
+type chanNotifier chan notified
+
+func (n chanNotifier) NotifyOrderStatus(sub entity.Subscription, change entity.OrderStatusChange) {
+	n <- notified{userId: sub.UserID, orderId: change.OrderId, shadow: change.Shadow}
+}
+
+func subscriptionCore() (*Core, *subscriptionStore) {
+	store := &subscriptionStore{subs: make(map[int]entity.Subscription)}
+	return &Core{
+		log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		mongoRepo: store,
+	}, store
+}
+
+func TestRequestSubscription(t *testing.T) {
+	c, store := subscriptionCore()
+
+	sub, err := c.RequestSubscription(7, "@anna", entity.SubscriptionFilter{Statuses: []int{2}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.IsActive() || sub.IsAdmin() || sub.CreatedAt.IsZero() {
+		t.Errorf("guest request = %+v, want a pending guest subscription", sub)
+	}
+
+	if _, err = c.ConfirmSubscription(7); err != nil {
+		t.Fatal(err)
+	}
+	// Asking again with another filter keeps the confirmation.
+	sub, err = c.RequestSubscription(7, "@anna", entity.SubscriptionFilter{Currencies: []string{"PLN"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sub.IsActive() || len(sub.Statuses) != 0 || len(sub.Currencies) != 1 {
+		t.Errorf("re-request = %+v, want active with the new filter", sub)
+	}
+
+	sub, err = c.RequestSubscription(1, "@admin", entity.SubscriptionFilter{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sub.IsActive() || !sub.IsAdmin() {
+		t.Errorf("admin request = %+v, want an active admin subscription", sub)
+	}
+	if len(store.subs) != 2 {
+		t.Errorf("stored %d subscriptions, want 2", len(store.subs))
+	}
+}
+
+func TestConfirmAndCancelUnknownSubscription(t *testing.T) {
+	c, _ := subscriptionCore()
+	if _, err := c.ConfirmSubscription(9); !errors.Is(err, entity.ErrSubscriptionNotFound) {
+		t.Errorf("ConfirmSubscription error = %v, want ErrSubscriptionNotFound", err)
+	}
+	if err := c.CancelSubscription(9); !errors.Is(err, entity.ErrSubscriptionNotFound) {
+		t.Errorf("CancelSubscription error = %v, want ErrSubscriptionNotFound", err)
+	}
+}
+
+func TestNotifyStatusChange(t *testing.T) {
+	c, store := subscriptionCore()
+	notifier := make(chanNotifier, 10)
+	c.SetStatusNotifier(notifier)
+
+	all := entity.NewSubscription(1, "all")
+	all.Confirm()
+	paidOnly := entity.NewSubscription(2, "paid")
+	paidOnly.Confirm()
+	paidOnly.Statuses = []int{entity.OrderStatusPayed}
+	eurOnly := entity.NewSubscription(3, "eur")
+	eurOnly.Confirm()
+	eurOnly.Currencies = []string{entity.CurrencyEUR}
+	pending := entity.NewSubscription(4, "pending")
+	for _, sub := range []entity.Subscription{all, paidOnly, eurOnly, pending} {
+		store.subs[sub.UserID] = sub
+	}
+
+	params := &entity.CheckoutParams{
+		Currency:      entity.CurrencyPLN,
+		Total:         entity.AmountOf(100),
+		ClientDetails: &entity.ClientDetails{FirstName: "Jan", LastName: "Kowalski", GroupId: 1},
+	}
+	change := statusChange(17103, "Z-1", params, entity.OrderStatusNew, entity.OrderStatusPayed, "paid")
+	if change.Customer != "Jan Kowalski" || change.CustomerGroupId != 1 {
+		t.Errorf("statusChange = %+v, want customer and group from the order", change)
+	}
+	c.notifyStatusChange(change)
+
+	got := make(map[int]bool)
+	for range 2 {
+		select {
+		case n := <-notifier:
+			if n.orderId != 17103 {
+				t.Errorf("notified order %d, want 17103", n.orderId)
+			}
+			got[n.userId] = true
+		case <-time.After(time.Second):
+			t.Fatalf("notified %v, want users 1 and 2", got)
+		}
+	}
+	if !got[1] || !got[2] {
+		t.Errorf("notified %v, want users 1 and 2", got)
+	}
+	select {
+	case n := <-notifier:
+		t.Errorf("unexpected notification of user %d", n.userId)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A webhook that leaves the status alone notifies nobody.
+	c.notifyStatusChange(statusChange(17103, "Z-1", params, entity.OrderStatusPayed, entity.OrderStatusPayed, "paid"))
+	select {
+	case n := <-notifier:
+		t.Errorf("unchanged status notified user %d", n.userId)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifyStatusChange_Shadow(t *testing.T) {
+	c, store := subscriptionCore()
+	notifier := make(chanNotifier, 10)
+	c.SetStatusNotifier(notifier)
+	c.SetShadowMode(true)
+
+	all := entity.NewSubscription(1, "all")
+	all.Confirm()
+	store.subs[all.UserID] = all
+
+	c.notifyStatusChange(entity.OrderStatusChange{OrderId: 17103, FromStatusId: entity.OrderStatusNew, StatusId: entity.OrderStatusPayed})
+	select {
+	case n := <-notifier:
+		if !n.shadow {
+			t.Error("status change in shadow mode not marked as shadow")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscriber not notified")
+	}
+}
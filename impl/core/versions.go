@@ -0,0 +1,244 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// OrderVersions returns the stored versions of an order, oldest first, each with the snapshot
+// the diff works on.
+func (c *Core) OrderVersions(orderId int64) ([]entity.OrderVersionInfo, error) {
+	if c.mongoRepo == nil {
+		return nil, entity.ErrVersionsNotAvailable
+	}
+	versions, err := c.mongoRepo.GetOrderVersions(orderId)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, entity.ErrOrderVersionNotFound
+	}
+
+	infos := make([]entity.OrderVersionInfo, 0, len(versions))
+	for _, v := range versions {
+		info := entity.OrderVersionInfo{
+			Version:      v.Version,
+			Direction:    versionDirection(v),
+			CreationDate: v.CreationDate,
+		}
+		if json.Valid([]byte(v.Payload)) {
+			info.Payload = json.RawMessage(v.Payload)
+		}
+		snapshot, err := c.orderSnapshot(v)
+		if err != nil {
+			c.log.With(sl.Err(err), slog.Int64("order_id", orderId), slog.Int64("version", v.Version)).
+				Warn("decode order version")
+		} else {
+			info.Snapshot = &snapshot
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// OrderVersionDiff compares two stored versions of an order: status, total, shipping and the
+// product lines with their quantities and prices.
+func (c *Core) OrderVersionDiff(orderId, from, to int64) (*entity.OrderVersionDiff, error) {
+	if c.mongoRepo == nil {
+		return nil, entity.ErrVersionsNotAvailable
+	}
+	a, err := c.loadOrderVersion(orderId, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.loadOrderVersion(orderId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	snapA, err := c.orderSnapshot(*a)
+	if err != nil {
+		return nil, fmt.Errorf("version %d: %w", from, err)
+	}
+	snapB, err := c.orderSnapshot(*b)
+	if err != nil {
+		return nil, fmt.Errorf("version %d: %w", to, err)
+	}
+
+	diff := diffOrderSnapshots(snapA, snapB)
+	diff.OrderID = orderId
+	diff.From = entity.OrderVersionRef{Version: a.Version, Direction: versionDirection(*a), CreationDate: a.CreationDate}
+	diff.To = entity.OrderVersionRef{Version: b.Version, Direction: versionDirection(*b), CreationDate: b.CreationDate}
+	return diff, nil
+}
+
+func (c *Core) loadOrderVersion(orderId, version int64) (*entity.OrderVersion, error) {
+	v, err := c.mongoRepo.GetOrderVersion(orderId, version)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, entity.ErrOrderVersionNotFound
+	}
+	return v, nil
+}
+
+// versionDirection returns the direction of a version. Versions stored before it was recorded
+// are told apart by their payload: only an ApiOrder has ordered_items.
+func versionDirection(v entity.OrderVersion) string {
+	if v.Direction != "" {
+		return v.Direction
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(v.Payload), &fields); err != nil {
+		return ""
+	}
+	if _, ok := fields["ordered_items"]; ok {
+		return entity.VersionInbound
+	}
+	return entity.VersionOutbound
+}
+
+// orderSnapshot decodes a version's payload into the fields both directions share. The
+// shipping line of an inbound order is counted as shipping, not as a product.
+func (c *Core) orderSnapshot(v entity.OrderVersion) (entity.OrderSnapshot, error) {
+	var snapshot entity.OrderSnapshot
+	switch versionDirection(v) {
+	case entity.VersionOutbound:
+		var params entity.CheckoutParams
+		if err := json.Unmarshal([]byte(v.Payload), &params); err != nil {
+			return snapshot, fmt.Errorf("decode checkout params: %w", err)
+		}
+		snapshot.Status = params.Status
+		snapshot.Total = params.Total
+		snapshot.Shipping = params.Shipping
+		snapshot.Currency = params.Currency
+		for _, li := range params.LineItems {
+			if li == nil {
+				continue
+			}
+			snapshot.Items = append(snapshot.Items, entity.SnapshotItem{
+				Key:      lineItemKey(li),
+				Name:     li.Name,
+				Quantity: li.Qty,
+				Price:    li.Price,
+				Total:    li.Total,
+			})
+		}
+	case entity.VersionInbound:
+		var order entity.ApiOrder
+		if err := json.Unmarshal([]byte(v.Payload), &order); err != nil {
+			return snapshot, fmt.Errorf("decode api order: %w", err)
+		}
+		snapshot.Status = order.Status
+		snapshot.Total = order.GrandTotal
+		for _, item := range order.OrderedItems {
+			if item.Shipping || (c.shippingItemZohoId != "" && item.ZohoID == c.shippingItemZohoId) {
+				snapshot.Shipping += item.Total
+				continue
+			}
+			snapshot.Items = append(snapshot.Items, entity.SnapshotItem{
+				Key:      item.ZohoID,
+				Quantity: float64(item.Quantity),
+				Price:    item.Price,
+				Total:    item.Total,
+			})
+		}
+	default:
+		return snapshot, fmt.Errorf("unknown payload")
+	}
+	return snapshot, nil
+}
+
+// lineItemKey matches an OpenCart line to the Zoho line of the same product; lines without a
+// Zoho id fall back to the uid, then the name.
+func lineItemKey(li *entity.LineItem) string {
+	switch {
+	case li.ZohoId != "":
+		return li.ZohoId
+	case li.Uid != "":
+		return "uid:" + li.Uid
+	default:
+		return "name:" + li.Name
+	}
+}
+
+// diffOrderSnapshots lists what changed from one snapshot to the other. Lines of the same
+// product are summed; amounts are compared to the cent.
+func diffOrderSnapshots(from, to entity.OrderSnapshot) *entity.OrderVersionDiff {
+	diff := &entity.OrderVersionDiff{Items: []entity.ItemDifference{}}
+	if strings.TrimSpace(from.Status) != strings.TrimSpace(to.Status) {
+		diff.Status = &entity.ValueChange{From: from.Status, To: to.Status}
+	}
+	if amountChanged(from.Total, to.Total) {
+		diff.Total = &entity.ValueChange{From: from.Total.Round(2), To: to.Total.Round(2)}
+	}
+	if amountChanged(from.Shipping, to.Shipping) {
+		diff.Shipping = &entity.ValueChange{From: from.Shipping.Round(2), To: to.Shipping.Round(2)}
+	}
+
+	before := sumSnapshotItems(from.Items)
+	after := sumSnapshotItems(to.Items)
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		a, inA := before[key]
+		b, inB := after[key]
+		d := entity.ItemDifference{
+			Key:          key,
+			Name:         a.Name,
+			FromQuantity: a.Quantity,
+			ToQuantity:   b.Quantity,
+			FromPrice:    a.Price.Round(2),
+			ToPrice:      b.Price.Round(2),
+		}
+		if d.Name == "" {
+			d.Name = b.Name
+		}
+		switch {
+		case !inA:
+			d.Change = entity.ItemAdded
+		case !inB:
+			d.Change = entity.ItemRemoved
+		case a.Quantity != b.Quantity || amountChanged(a.Price, b.Price):
+			d.Change = entity.ItemChanged
+		default:
+			continue
+		}
+		diff.Items = append(diff.Items, d)
+	}
+	return diff
+}
+
+func sumSnapshotItems(items []entity.SnapshotItem) map[string]entity.SnapshotItem {
+	sums := make(map[string]entity.SnapshotItem, len(items))
+	for _, item := range items {
+		sum, ok := sums[item.Key]
+		if !ok {
+			sums[item.Key] = item
+			continue
+		}
+		sum.Quantity += item.Quantity
+		sum.Total += item.Total
+		sums[item.Key] = sum
+	}
+	return sums
+}
+
+func amountChanged(a, b entity.Amount) bool {
+	return a.Round(2) != b.Round(2)
+}
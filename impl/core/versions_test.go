@@ -0,0 +1,135 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"zohoclient/entity"
+)
+
+// versionStore keeps the versions of one order in memory.
+type versionStore struct {
+	MongoRepository
+	versions []entity.OrderVersion
+}
+
+func (s *versionStore) SaveOrderVersion(orderID int64, direction, payload string) error {
+	s.versions = append(s.versions, entity.OrderVersion{
+		OrderID:   orderID,
+		Version:   int64(len(s.versions)),
+		Direction: direction,
+		Payload:   payload,
+	})
+	return nil
+}
+
+func (s *versionStore) GetOrderVersions(orderID int64) ([]entity.OrderVersion, error) {
+	var versions []entity.OrderVersion
+	for _, v := range s.versions {
+		if v.OrderID == orderID {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (s *versionStore) GetOrderVersion(orderID, version int64) (*entity.OrderVersion, error) {
+	for _, v := range s.versions {
+		if v.OrderID == orderID && v.Version == version {
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
+
+func versionCore() (*Core, *versionStore) {
+	store := &versionStore{}
+	return &Core{
+		log:                slog.New(slog.NewTextHandler(io.Discard, nil)),
+		mongoRepo:          store,
+		shippingItemZohoId: testShippingZohoID,
+	}, store
+}
+
+func TestOrderVersionDiff(t *testing.T) {
+	c, _ := versionCore()
+
+	c.saveOrderVersionToMongo(5, entity.VersionOutbound, &entity.CheckoutParams{
+		OrderId: 5, Status: "Processing", Total: entity.AmountOf(468), Shipping: entity.AmountOf(20), Currency: "PLN",
+		LineItems: []*entity.LineItem{
+			{Name: "Cable", ZohoId: "z1", Qty: 3, Price: entity.AmountOf(81), Total: entity.AmountOf(243)},
+			{Name: "Plug", ZohoId: "z2", Qty: 1, Price: entity.AmountOf(50), Total: entity.AmountOf(50)},
+		},
+	})
+	c.saveOrderVersionToMongo(5, entity.VersionInbound, &entity.ApiOrder{
+		ZohoID: "so1", Status: "Shipped", GrandTotal: entity.AmountOf(387),
+		OrderedItems: []entity.ApiOrderedItem{
+			{ZohoID: "z1", Price: entity.AmountOf(81), Total: entity.AmountOf(162), Quantity: 2},
+			{ZohoID: "z3", Price: entity.AmountOf(10), Total: entity.AmountOf(10), Quantity: 1},
+			{ZohoID: testShippingZohoID, Price: entity.AmountOf(20), Total: entity.AmountOf(20), Quantity: 1},
+		},
+	})
+
+	diff, err := c.OrderVersionDiff(5, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.From.Direction != entity.VersionOutbound || diff.To.Direction != entity.VersionInbound {
+		t.Errorf("directions = %s → %s", diff.From.Direction, diff.To.Direction)
+	}
+	if diff.Status == nil || diff.Status.To != "Shipped" {
+		t.Errorf("status change = %+v", diff.Status)
+	}
+	if diff.Total == nil || diff.Total.From != entity.AmountOf(468) || diff.Total.To != entity.AmountOf(387) {
+		t.Errorf("total change = %+v", diff.Total)
+	}
+	if diff.Shipping != nil {
+		t.Errorf("shipping change = %+v, want none: the shipping line is not a product", diff.Shipping)
+	}
+
+	want := map[string]string{"z1": entity.ItemChanged, "z2": entity.ItemRemoved, "z3": entity.ItemAdded}
+	if len(diff.Items) != len(want) {
+		t.Fatalf("items = %+v, want %d", diff.Items, len(want))
+	}
+	for _, item := range diff.Items {
+		if want[item.Key] != item.Change {
+			t.Errorf("item %s change = %s, want %s", item.Key, item.Change, want[item.Key])
+		}
+	}
+	if diff.Items[0].Name != "Cable" || diff.Items[0].FromQuantity != 3 || diff.Items[0].ToQuantity != 2 {
+		t.Errorf("z1 = %+v, want Cable 3 → 2", diff.Items[0])
+	}
+
+	if _, err = c.OrderVersionDiff(5, 0, 9); !errors.Is(err, entity.ErrOrderVersionNotFound) {
+		t.Errorf("unknown version err = %v, want ErrOrderVersionNotFound", err)
+	}
+}
+
+func TestOrderVersionsLegacyDirection(t *testing.T) {
+	c, store := versionCore()
+
+	// Versions saved before the direction was recorded.
+	inbound, _ := json.Marshal(entity.ApiOrder{ZohoID: "so1", Status: "Shipped", GrandTotal: entity.AmountOf(10),
+		OrderedItems: []entity.ApiOrderedItem{{ZohoID: "z1", Price: entity.AmountOf(10), Total: entity.AmountOf(10), Quantity: 1}}})
+	store.versions = []entity.OrderVersion{
+		{OrderID: 5, Version: 0, Payload: `{"order_id":5,"status":"New","total":10,"line_items":[]}`},
+		{OrderID: 5, Version: 1, Payload: string(inbound)},
+	}
+
+	versions, err := c.OrderVersions(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions[0].Direction != entity.VersionOutbound || versions[1].Direction != entity.VersionInbound {
+		t.Errorf("directions = %s, %s, want outbound, inbound", versions[0].Direction, versions[1].Direction)
+	}
+	if versions[1].Snapshot == nil || len(versions[1].Snapshot.Items) != 1 {
+		t.Errorf("inbound snapshot = %+v", versions[1].Snapshot)
+	}
+
+	if _, err = c.OrderVersions(6); !errors.Is(err, entity.ErrOrderVersionNotFound) {
+		t.Errorf("unknown order err = %v, want ErrOrderVersionNotFound", err)
+	}
+}
@@ -0,0 +1,35 @@
+package core
+
+import "zohoclient/entity"
+
+// dispatchWebhook hands an event to the outbound webhooks, if any are configured.
+func (c *Core) dispatchWebhook(event string, data entity.OrderEvent) {
+	if c.webhooks == nil {
+		return
+	}
+	c.webhooks.Dispatch(event, data)
+}
+
+// WebhookDeliveries lists the outbound webhook delivery log, newest first.
+func (c *Core) WebhookDeliveries(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error) {
+	if c.webhooks == nil {
+		return nil, entity.ErrWebhooksNotConfigured
+	}
+	return c.webhooks.Deliveries(filter)
+}
+
+// WebhookDelivery returns one outbound webhook delivery, or nil if the id is unknown.
+func (c *Core) WebhookDelivery(id string) (*entity.WebhookDelivery, error) {
+	if c.webhooks == nil {
+		return nil, entity.ErrWebhooksNotConfigured
+	}
+	return c.webhooks.Delivery(id)
+}
+
+// RedeliverWebhook queues a logged delivery to be sent again.
+func (c *Core) RedeliverWebhook(id string) (*entity.WebhookDelivery, error) {
+	if c.webhooks == nil {
+		return nil, entity.ErrWebhooksNotConfigured
+	}
+	return c.webhooks.Redeliver(id)
+}
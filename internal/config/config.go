@@ -19,6 +19,9 @@ type Config struct {
 		Database string `yaml:"database" env-default:""`
 		Port     string `yaml:"port" env-default:"8080"`
 		Prefix   string `yaml:"prefix" env-default:""`
+		// Migrations is "auto" to apply the pending schema migrations at startup, or "verify"
+		// to only check that they are in place, for a user that may not alter the tables.
+		Migrations string `yaml:"migrations" env-default:"auto"`
 	} `yaml:"sql"`
 	Mongo struct {
 		Enabled     bool   `yaml:"enabled" env-default:"false"`
@@ -27,7 +30,11 @@ type Config struct {
 		User        string `yaml:"user" env-default:"admin"`
 		Password    string `yaml:"password" env-default:"pass"`
 		Database    string `yaml:"database" env-default:""`
-		ExpiredDays int    `yaml:"expired_days" env-default:"7"`
+		ExpiredDays int    `yaml:"expired_days"`
+		MaxPoolSize uint64 `yaml:"max_pool_size" env-default:"20"`
+		MinPoolSize uint64 `yaml:"min_pool_size" env-default:"0"`
+		// Timeout bounds every operation, in seconds.
+		Timeout int `yaml:"timeout" env-default:"10"`
 	} `yaml:"mongo"`
 	Telegram struct {
 		Enabled     bool   `yaml:"enabled" env-default:"false"`
@@ -35,6 +42,15 @@ type Config struct {
 		AdminId     string `yaml:"admin_id" env-default:""`
 		BotName     string `yaml:"bot_name" env-default:"ZohoBot"`
 		MinLogLevel string `yaml:"min_log_level" env-default:"debug"`
+		// DedupWindow is how long, in seconds, repeats of an alert are held back and summarized
+		// in a digest instead of sent. 0 sends every alert.
+		DedupWindow int `yaml:"dedup_window"`
+		// SendRate is how many alerts per second are sent to one chat; the rest wait in a queue
+		// of QueueSize alerts, beyond which they are dropped and counted.
+		SendRate  float64     `yaml:"send_rate" env-default:"1"`
+		QueueSize int         `yaml:"queue_size" env-default:"100"`
+		Report    DailyReport `yaml:"report"`
+		Reconcile Reconcile   `yaml:"reconcile"`
 	} `yaml:"telegram"`
 	Zoho struct {
 		ClientId     string `yaml:"client_id" env-default:""`
@@ -44,6 +60,19 @@ type Config struct {
 		CrmUrl       string `yaml:"crm_url" env-default:""`
 		Scope        string `yaml:"scope" env-default:""`
 		ApiVersion   string `yaml:"api_version" env-default:""`
+		// Mode "shadow" keeps the sync running without writing to Zoho: writes are recorded in the
+		// call log instead of sent, and nothing they return is written back to OpenCart.
+		Mode string `yaml:"mode" env-default:"live"`
+		// Audit logs every API call in MongoDB for RetentionDays. Values of the Redact fields are
+		// masked in the stored bodies, which are cut to MaxBody bytes. ShadowRaw stores the writes
+		// recorded in shadow mode whole instead, personal data included.
+		Audit struct {
+			Enabled       bool     `yaml:"enabled" env-default:"false"`
+			RetentionDays int      `yaml:"retention_days" env-default:"30"`
+			MaxBody       int      `yaml:"max_body" env-default:"16384"`
+			Redact        []string `yaml:"redact" env-separator:"," env-default:"Email,Phone,Mobile,First_Name,Last_Name,Billing_Street,Shipping_Street,postcode,A0d3aa57fb7d0fc67725ca891b3965663"`
+			ShadowRaw     bool     `yaml:"shadow_raw" env-default:"false"`
+		} `yaml:"audit"`
 	} `yaml:"zoho"`
 	ProdRepo struct {
 		Login    string `yaml:"login" env-default:""`
@@ -51,10 +80,68 @@ type Config struct {
 		ProdUrl  string `yaml:"prod_url" env-default:""`
 	} `yaml:"prod_repo"`
 	Listen struct {
-		BindIP string `yaml:"bind_ip" env-default:"127.0.0.1"`
-		Port   string `yaml:"port" env:"PORT" env-default:"8080"`
-		ApiKey string `yaml:"key" env-default:""`
+		BindIP string     `yaml:"bind_ip" env-default:"127.0.0.1"`
+		Port   string     `yaml:"port" env:"PORT" env-default:"8080"`
+		ApiKey string     `yaml:"key" env-default:""`
+		Tokens []ApiToken `yaml:"tokens"`
+		// TrustedProxies are the addresses or networks (CIDR) of reverse proxies in front of the
+		// service; only their X-Forwarded-For is used to tell clients apart for rate limits and
+		// in the API call log.
+		TrustedProxies []string `yaml:"trusted_proxies"`
+		// AuditDays is how long the record of each authenticated API call is kept in MongoDB.
+		AuditDays int `yaml:"audit_days" env-default:"30"`
+		Limits    struct {
+			WebhookOrder RouteLimits `yaml:"webhook_order"`
+			WebhookB2B   RouteLimits `yaml:"webhook_b2b"`
+			Push         RouteLimits `yaml:"push"`
+		} `yaml:"limits"`
 	} `yaml:"listen"`
+	Webhooks struct {
+		B2B struct {
+			Header  string   `yaml:"header" env-default:"X-Signature"`
+			Secrets []string `yaml:"secrets" env-separator:","`
+			// Tolerance is the max age of the payload timestamp in seconds, 0 for any.
+			Tolerance int `yaml:"tolerance"`
+		} `yaml:"b2b"`
+		Zoho struct {
+			Header  string   `yaml:"header" env-default:"X-Webhook-Secret"`
+			Secrets []string `yaml:"secrets" env-separator:","`
+		} `yaml:"zoho"`
+	} `yaml:"webhooks"`
+	// Notify routes the sync events (order created, payment linked, order updated from Zoho,
+	// sync failure) to the enabled channels. Events lists the event types a channel receives;
+	// empty means all.
+	Notify struct {
+		Telegram struct {
+			Enabled bool     `yaml:"enabled" env-default:"false"`
+			Events  []string `yaml:"events" env-separator:","`
+		} `yaml:"telegram"`
+		Email struct {
+			Enabled  bool     `yaml:"enabled" env-default:"false"`
+			Host     string   `yaml:"host" env-default:""`
+			Port     int      `yaml:"port" env-default:"587"`
+			Username string   `yaml:"username" env-default:""`
+			Password string   `yaml:"password" env-default:""`
+			From     string   `yaml:"from" env-default:""`
+			To       []string `yaml:"to" env-separator:","`
+			Events   []string `yaml:"events" env-separator:","`
+		} `yaml:"email"`
+		Webhook struct {
+			Enabled bool     `yaml:"enabled" env-default:"false"`
+			Url     string   `yaml:"url" env-default:""`
+			Secret  string   `yaml:"secret" env-default:""`
+			Header  string   `yaml:"header" env-default:"X-Signature"`
+			Timeout int      `yaml:"timeout" env-default:"10"`
+			Events  []string `yaml:"events" env-separator:","`
+		} `yaml:"webhook"`
+	} `yaml:"notify"`
+	// OutboundWebhooks posts sync events to downstream systems; deliveries are logged in
+	// MongoDB and retried with backoff up to MaxAttempts times.
+	OutboundWebhooks struct {
+		MaxAttempts int                 `yaml:"max_attempts" env-default:"8"`
+		Timeout     int                 `yaml:"timeout" env-default:"10"`
+		Subscribers []WebhookSubscriber `yaml:"subscribers"`
+	} `yaml:"outbound_webhooks"`
 	SmartSender struct {
 		Enabled      bool   `yaml:"enabled" env-default:"false"`
 		ApiKey       string `yaml:"api_key" env-default:""`
@@ -65,19 +152,95 @@ type Config struct {
 	} `yaml:"smartsender"`
 }
 
+// ApiToken is an API client credential. Expires is optional, as RFC 3339 or YYYY-MM-DD; a
+// token is rotated by adding the new one under the same name and removing the old one once
+// the client has switched.
+type ApiToken struct {
+	Name    string   `yaml:"name"`
+	Token   string   `yaml:"token"`
+	Scopes  []string `yaml:"scopes"`
+	Expires string   `yaml:"expires"`
+}
+
+// RouteLimits bounds one group of API routes. Rate is requests per second allowed per API
+// client and, separately, per remote IP, with up to Burst at once; MaxBody caps the request
+// body in bytes. Zero disables the respective limit, so the defaults are set by Load rather
+// than by env-default, which would also replace an explicit 0.
+type RouteLimits struct {
+	Rate    float64 `yaml:"rate"`
+	Burst   int     `yaml:"burst"`
+	MaxBody int64   `yaml:"max_body"`
+}
+
+// WebhookSubscriber is a downstream system receiving outbound webhooks. Events lists the
+// event types it wants, empty for all; Secret signs every body.
+type WebhookSubscriber struct {
+	Name   string   `yaml:"name"`
+	Url    string   `yaml:"url"`
+	Secret string   `yaml:"secret"`
+	Events []string `yaml:"events"`
+}
+
+// DailyReport schedules the daily sync digest sent by the Telegram bot. Time is HH:MM in
+// Timezone; Recipients is a comma-separated list of Telegram chat ids, empty for all admins.
+type DailyReport struct {
+	Enabled    bool   `yaml:"enabled" env-default:"false"`
+	Time       string `yaml:"time" env-default:"08:00"`
+	Timezone   string `yaml:"timezone" env-default:"Europe/Warsaw"`
+	Recipients string `yaml:"recipients" env-default:""`
+}
+
+// Reconcile schedules the OpenCart/Zoho reconciliation. At Time it checks the Days full days
+// before; orders without a zoho_id are reported once UnsyncedHours old. With ReportDir set, the
+// issues are also written there as CSV.
+type Reconcile struct {
+	Enabled       bool   `yaml:"enabled" env-default:"false"`
+	Time          string `yaml:"time" env-default:"06:00"`
+	Timezone      string `yaml:"timezone" env-default:"Europe/Warsaw"`
+	Recipients    string `yaml:"recipients" env-default:""`
+	Days          int    `yaml:"days" env-default:"1"`
+	UnsyncedHours int    `yaml:"unsynced_hours" env-default:"6"`
+	ReportDir     string `yaml:"report_dir" env-default:""`
+}
+
 var instance *Config
 var once sync.Once
 
 func MustLoad(path string) *Config {
 	var err error
 	once.Do(func() {
-		instance = &Config{}
-		if err = cleanenv.ReadConfig(path, instance); err != nil {
-			desc, _ := cleanenv.GetDescription(instance, nil)
-			err = fmt.Errorf("%s; %s", err, desc)
-			instance = nil
+		instance, err = Load(path)
+		if err != nil {
 			log.Fatal(err)
 		}
 	})
 	return instance
 }
+
+// Load reads the config file, filling in defaults and the environment, and returns an error
+// instead of exiting.
+func Load(path string) (*Config, error) {
+	conf := defaults()
+	if err := cleanenv.ReadConfig(path, conf); err != nil {
+		desc, _ := cleanenv.GetDescription(conf, nil)
+		return nil, fmt.Errorf("%s; %s", err, desc)
+	}
+	return conf, nil
+}
+
+// defaults returns a Config holding the defaults of the settings for which 0 means "off".
+// cleanenv applies env-default to every zero field, an explicit 0 in the file included, so these
+// are set before the file is read and only the keys it names replace them.
+func defaults() *Config {
+	conf := &Config{}
+	limits := RouteLimits{Rate: 5, Burst: 20, MaxBody: 2 << 20}
+	// Zoho does not redeliver a reverse-sync webhook it got a 429 for, so that route is limited
+	// only on request; its body cap still applies.
+	conf.Listen.Limits.WebhookOrder = RouteLimits{Burst: limits.Burst, MaxBody: limits.MaxBody}
+	conf.Listen.Limits.WebhookB2B = limits
+	conf.Listen.Limits.Push = limits
+	conf.Webhooks.B2B.Tolerance = 300
+	conf.Telegram.DedupWindow = 300
+	conf.Mongo.ExpiredDays = 7
+	return conf
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func load(t *testing.T, yaml string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return conf
+}
+
+func TestLoadRouteLimits(t *testing.T) {
+	conf := load(t, `
+env: local
+listen:
+  limits:
+    webhook_order:
+      rate: 10
+    webhook_b2b:
+      rate: 0
+    push:
+      burst: 0
+      max_body: 0
+`)
+
+	limits := conf.Listen.Limits
+	if got := limits.WebhookOrder; got != (RouteLimits{Rate: 10, Burst: 20, MaxBody: 2097152}) {
+		t.Errorf("webhook_order = %+v, want rate 10 and the other defaults", got)
+	}
+	if got := limits.WebhookB2B; got != (RouteLimits{Rate: 0, Burst: 20, MaxBody: 2097152}) {
+		t.Errorf("webhook_b2b = %+v, want rate 0 and the other defaults", got)
+	}
+	if got := limits.Push; got != (RouteLimits{Rate: 5, Burst: 0, MaxBody: 0}) {
+		t.Errorf("push = %+v, want burst and max_body 0", got)
+	}
+}
+
+func TestLoadWebhookOrderLimitOptIn(t *testing.T) {
+	limits := load(t, "env: local\n").Listen.Limits
+	if got := limits.WebhookOrder; got.Rate != 0 || got.MaxBody != 2097152 {
+		t.Errorf("webhook_order = %+v, want no rate limit and the body cap", got)
+	}
+	if got := limits.WebhookB2B; got != (RouteLimits{Rate: 5, Burst: 20, MaxBody: 2097152}) {
+		t.Errorf("webhook_b2b = %+v, want the defaults", got)
+	}
+}
+
+func TestLoadTolerance(t *testing.T) {
+	if got := load(t, "env: local\n").Webhooks.B2B.Tolerance; got != 300 {
+		t.Errorf("default tolerance = %d, want 300", got)
+	}
+	conf := load(t, `
+env: local
+webhooks:
+  b2b:
+    tolerance: 0
+`)
+	if got := conf.Webhooks.B2B.Tolerance; got != 0 {
+		t.Errorf("tolerance = %d, want the explicit 0", got)
+	}
+}
+
+func TestLoadDedupWindow(t *testing.T) {
+	if got := load(t, "env: local\n").Telegram.DedupWindow; got != 300 {
+		t.Errorf("default dedup_window = %d, want 300", got)
+	}
+	conf := load(t, `
+env: local
+telegram:
+  dedup_window: 0
+`)
+	if got := conf.Telegram.DedupWindow; got != 0 {
+		t.Errorf("dedup_window = %d, want the explicit 0", got)
+	}
+}
+
+func TestLoadExpiredDays(t *testing.T) {
+	if got := load(t, "env: local\n").Mongo.ExpiredDays; got != 7 {
+		t.Errorf("default expired_days = %d, want 7", got)
+	}
+	conf := load(t, `
+env: local
+mongo:
+  expired_days: 0
+`)
+	if got := conf.Mongo.ExpiredDays; got != 0 {
+		t.Errorf("expired_days = %d, want the explicit 0", got)
+	}
+}
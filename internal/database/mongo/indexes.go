@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Server error codes of an index that exists with other options.
+const (
+	codeIndexOptionsConflict  = 85
+	codeIndexKeySpecsConflict = 86
+)
+
+// ensureIndexes creates the indexes the queries rely on. Creating an index that already exists
+// is a no-op, so this runs on every start.
+func (m *MongoDB) ensureIndexes() error {
+	indexes := map[string][]mongo.IndexModel{
+		versionsCollection: {
+			{Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "version", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		smartsenderCollection: {
+			{Keys: bson.D{{Key: "chat_id", Value: 1}}},
+		},
+		b2bDealsCollection: {
+			{Keys: bson.D{{Key: "order_uid", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		subscriptionsCollection: {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "subscription_type", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		zohoCallsCollection: {
+			{Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "time", Value: -1}}},
+			{Keys: bson.D{{Key: "record_id", Value: 1}, {Key: "time", Value: -1}}},
+		},
+		apiCallsCollection: {
+			{Keys: bson.D{{Key: "client", Value: 1}, {Key: "time", Value: -1}}},
+		},
+		deliveriesCollection: {
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt", Value: 1}}},
+			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		},
+	}
+
+	for name, models := range indexes {
+		ctx, cancel := m.context()
+		_, err := m.collection(name).Indexes().CreateMany(ctx, models)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("mongodb create index on %s error: %w", name, err)
+		}
+	}
+
+	// Versions expire one by one; a counter goes once its order had no new version for as
+	// long, so numbering restarts only after every version is gone. The legacy orders
+	// collection, one document per order, keeps its TTL for documents a failed migration left.
+	ttls := []struct {
+		collection, field string
+		days              int
+	}{
+		{versionsCollection, "creation_date", m.expiredDays},
+		{versionSeqCollection, "updated_at", m.expiredDays},
+		{legacyOrdersCollection, "creation_date", m.expiredDays},
+		{zohoCallsCollection, "time", m.auditDays},
+		{apiCallsCollection, "time", m.apiDays},
+	}
+	for _, t := range ttls {
+		if err := m.ensureTTL(t.collection, t.field, t.days); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTTL lets the server expire documents of a collection days after the time in field.
+// A changed retention is applied to the existing index in place; zero days removes it.
+func (m *MongoDB) ensureTTL(collection, field string, days int) error {
+	if days <= 0 {
+		return m.dropTTL(collection, field)
+	}
+	ttl := int32(days * 24 * 60 * 60)
+	keys := bson.D{{Key: field, Value: 1}}
+
+	ctx, cancel := m.context()
+	defer cancel()
+
+	_, err := m.collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetExpireAfterSeconds(ttl),
+	})
+	if err == nil {
+		return nil
+	}
+
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || (cmdErr.Code != codeIndexOptionsConflict && cmdErr.Code != codeIndexKeySpecsConflict) {
+		return fmt.Errorf("mongodb create TTL index on %s error: %w", collection, err)
+	}
+	err = m.client.Database(m.database).RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "index", Value: bson.D{{Key: "keyPattern", Value: keys}, {Key: "expireAfterSeconds", Value: ttl}}},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("mongodb update TTL index on %s error: %w", collection, err)
+	}
+	m.log.With(
+		slog.String("collection", collection),
+		slog.Int("days", days),
+	).Info("TTL index updated")
+	return nil
+}
+
+// dropTTL removes the TTL index on field, so that turning expiry off also stops the server from
+// deleting documents under the index an earlier start created.
+func (m *MongoDB) dropTTL(collection, field string) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	cursor, err := m.collection(collection).Indexes().List(ctx)
+	if err != nil {
+		return fmt.Errorf("mongodb list indexes on %s error: %w", collection, err)
+	}
+	var indexes []struct {
+		Name        string `bson:"name"`
+		Key         bson.D `bson:"key"`
+		ExpireAfter *int32 `bson:"expireAfterSeconds"`
+	}
+	if err = cursor.All(ctx, &indexes); err != nil {
+		return fmt.Errorf("mongodb list indexes on %s error: %w", collection, err)
+	}
+
+	for _, index := range indexes {
+		if index.ExpireAfter == nil || len(index.Key) != 1 || index.Key[0].Key != field {
+			continue
+		}
+		if _, err = m.collection(collection).Indexes().DropOne(ctx, index.Name); err != nil {
+			return fmt.Errorf("mongodb drop TTL index on %s error: %w", collection, err)
+		}
+		m.log.With(
+			slog.String("collection", collection),
+		).Info("TTL index dropped")
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyOrder is an order as the orders collection held it before versions became documents of
+// their own: one document per order with the versions in an array.
+type legacyOrder struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	OrderID  int64              `bson:"order_id"`
+	Versions []struct {
+		CreationDate time.Time `bson:"creation_date"`
+		Payload      string    `bson:"payload"`
+	} `bson:"versions"`
+}
+
+// migrateLegacyVersions moves the history left in the legacy orders collection into the
+// versions collection and drops the legacy documents. The legacy versions of an order become
+// its first ones; versions it got since the new collection took over are renumbered after them.
+// The legacy versions carry no direction, which is told from their payload when read.
+//
+// It runs on start, before anything saves versions, and the client is not used when it fails.
+// Each step can be repeated, so a migration cut short picks up where it stopped on the next start.
+func (m *MongoDB) migrateLegacyVersions() error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	legacy := m.collection(legacyOrdersCollection)
+	count, err := legacy.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return fmt.Errorf("mongodb count error: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	cursor, err := legacy.Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("mongodb find error: %w", err)
+	}
+	defer cursor.Close(context.Background())
+
+	migrated := 0
+	for {
+		// Each batch of the cursor gets its own timeout: the collection may be large.
+		next, cancelNext := m.context()
+		ok := cursor.Next(next)
+		cancelNext()
+		if !ok {
+			break
+		}
+		var order legacyOrder
+		if err = cursor.Decode(&order); err != nil {
+			return fmt.Errorf("mongodb decode error: %w", err)
+		}
+		if err = migrateLegacyOrder(m, order); err != nil {
+			return fmt.Errorf("migrate versions of order %d: %w", order.OrderID, err)
+		}
+		migrated++
+	}
+	if err = cursor.Err(); err != nil {
+		return fmt.Errorf("mongodb cursor error: %w", err)
+	}
+	m.log.With(slog.Int("orders", migrated)).Info("legacy order versions migrated")
+	return nil
+}
+
+// legacySteps are the storage steps of migrating one legacy order. Each can be repeated.
+type legacySteps interface {
+	reserveVersions(order legacyOrder) error
+	shiftVersions(order legacyOrder) error
+	insertLegacyVersions(order legacyOrder) error
+	deleteLegacyOrder(order legacyOrder) error
+}
+
+// migrateLegacyOrder moves the versions of one legacy order into place. The counter is raised
+// past every number the migration hands out before any version moves, so a migration cut short
+// leaves no number that a new version could take again.
+func migrateLegacyOrder(steps legacySteps, order legacyOrder) error {
+	if err := steps.reserveVersions(order); err != nil {
+		return err
+	}
+	if err := steps.shiftVersions(order); err != nil {
+		return err
+	}
+	if err := steps.insertLegacyVersions(order); err != nil {
+		return err
+	}
+	return steps.deleteLegacyOrder(order)
+}
+
+// reserveVersions raises the version counter of the order to after the highest number its
+// versions have once the newer ones are moved up past the legacy ones.
+func (m *MongoDB) reserveVersions(order legacyOrder) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	shift := int64(len(order.Versions))
+	cursor, err := m.collection(versionsCollection).Find(ctx,
+		bson.M{"order_id": order.OrderID},
+		options.Find().SetProjection(bson.M{"version": 1, "legacy": 1, "shifted": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("mongodb find error: %w", err)
+	}
+	var versions []struct {
+		Version int64 `bson:"version"`
+		Legacy  bool  `bson:"legacy"`
+		Shifted bool  `bson:"shifted"`
+	}
+	if err = cursor.All(ctx, &versions); err != nil {
+		return fmt.Errorf("mongodb decode error: %w", err)
+	}
+
+	next := shift
+	for _, v := range versions {
+		final := v.Version
+		if !v.Legacy && !v.Shifted {
+			final += shift
+		}
+		next = max(next, final+1)
+	}
+	if next == 0 {
+		// A legacy order without versions: there is nothing to count.
+		return nil
+	}
+
+	_, err = m.collection(versionSeqCollection).UpdateOne(ctx,
+		bson.M{"_id": order.OrderID},
+		bson.M{"$max": bson.M{"seq": next}, "$set": bson.M{"updated_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("mongodb version counter error: %w", err)
+	}
+	return nil
+}
+
+// shiftVersions makes room: it moves the order's newer versions up past the legacy ones,
+// highest first so no two share a number on the way. The flag is set with the move, so none
+// moves twice.
+func (m *MongoDB) shiftVersions(order legacyOrder) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	versions := m.collection(versionsCollection)
+	shift := int64(len(order.Versions))
+
+	cursor, err := versions.Find(ctx,
+		bson.M{"order_id": order.OrderID, "legacy": bson.M{"$exists": false}, "shifted": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.D{{Key: "version", Value: -1}}).SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("mongodb find error: %w", err)
+	}
+	var newer []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &newer); err != nil {
+		return fmt.Errorf("mongodb decode error: %w", err)
+	}
+	for _, v := range newer {
+		_, err = versions.UpdateOne(ctx,
+			bson.M{"_id": v.ID},
+			bson.M{"$inc": bson.M{"version": shift}, "$set": bson.M{"shifted": true}},
+		)
+		if err != nil {
+			return fmt.Errorf("mongodb update error: %w", err)
+		}
+	}
+	return nil
+}
+
+// insertLegacyVersions stores the legacy versions as the order's first ones; those a previous
+// run inserted are skipped.
+func (m *MongoDB) insertLegacyVersions(order legacyOrder) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	for i, v := range order.Versions {
+		_, err := m.collection(versionsCollection).InsertOne(ctx, bson.M{
+			"order_id":      order.OrderID,
+			"version":       int64(i),
+			"creation_date": v.CreationDate,
+			"payload":       v.Payload,
+			"legacy":        true,
+		})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("mongodb insert error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (m *MongoDB) deleteLegacyOrder(order legacyOrder) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	if _, err := m.collection(legacyOrdersCollection).DeleteOne(ctx, bson.M{"_id": order.ID}); err != nil {
+		return fmt.Errorf("mongodb delete error: %w", err)
+	}
+	return nil
+}
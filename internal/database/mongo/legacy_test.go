@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errInterrupted = errors.New("interrupted")
+
+type memVersion struct {
+	version int64
+	legacy  bool
+	shifted bool
+}
+
+// memLegacy is the versions of one order in memory, with the semantics of the Mongo steps. The
+// step numbered failAt fails; failMidShift makes the shift stop after moving one version.
+type memLegacy struct {
+	versions     []memVersion
+	seq          int64
+	legacyLeft   bool
+	step         int
+	failAt       int
+	failMidShift bool
+}
+
+func (s *memLegacy) interrupt() bool {
+	s.step++
+	return s.step == s.failAt
+}
+
+func (s *memLegacy) reserveVersions(order legacyOrder) error {
+	if s.interrupt() {
+		return errInterrupted
+	}
+	shift := int64(len(order.Versions))
+	next := shift
+	for _, v := range s.versions {
+		final := v.version
+		if !v.legacy && !v.shifted {
+			final += shift
+		}
+		next = max(next, final+1)
+	}
+	s.seq = max(s.seq, next)
+	return nil
+}
+
+func (s *memLegacy) shiftVersions(order legacyOrder) error {
+	if s.interrupt() {
+		return errInterrupted
+	}
+	moved := 0
+	for i := len(s.versions) - 1; i >= 0; i-- {
+		v := &s.versions[i]
+		if v.legacy || v.shifted {
+			continue
+		}
+		if s.failMidShift && moved == 1 {
+			return errInterrupted
+		}
+		v.version += int64(len(order.Versions))
+		v.shifted = true
+		moved++
+	}
+	return nil
+}
+
+func (s *memLegacy) insertLegacyVersions(order legacyOrder) error {
+	if s.interrupt() {
+		return errInterrupted
+	}
+	for i := range order.Versions {
+		if !s.has(int64(i)) {
+			s.versions = append(s.versions, memVersion{version: int64(i), legacy: true})
+		}
+	}
+	return nil
+}
+
+func (s *memLegacy) deleteLegacyOrder(legacyOrder) error {
+	if s.interrupt() {
+		return errInterrupted
+	}
+	s.legacyLeft = false
+	return nil
+}
+
+func (s *memLegacy) has(version int64) bool {
+	for _, v := range s.versions {
+		if v.version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// check fails the test when two versions share a number or the counter would hand out one
+// that is taken.
+func (s *memLegacy) check(t *testing.T, when string) {
+	t.Helper()
+	seen := make(map[int64]bool)
+	for _, v := range s.versions {
+		if seen[v.version] {
+			t.Errorf("%s: version %d stored twice", when, v.version)
+		}
+		seen[v.version] = true
+		if v.version >= s.seq {
+			t.Errorf("%s: counter at %d, the next version would take %d again", when, s.seq, v.version)
+		}
+	}
+}
+
+// A migration cut short at any step leaves distinct version numbers and a counter past them,
+// and the next run completes it.
+func TestMigrateLegacyOrder_Interrupted(t *testing.T) {
+	order := legacyOrder{OrderID: 17103}
+	order.Versions = make([]struct {
+		CreationDate time.Time `bson:"creation_date"`
+		Payload      string    `bson:"payload"`
+	}, 2)
+
+	tests := []struct {
+		name         string
+		failAt       int
+		failMidShift bool
+	}{
+		{"before the counter", 1, false},
+		{"before the shift", 2, false},
+		{"during the shift", 0, true},
+		{"before the insert", 3, false},
+		{"before the delete", 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memLegacy{
+				versions:     []memVersion{{version: 0}, {version: 1}, {version: 2}},
+				seq:          3,
+				legacyLeft:   true,
+				failAt:       tt.failAt,
+				failMidShift: tt.failMidShift,
+			}
+
+			if err := migrateLegacyOrder(store, order); !errors.Is(err, errInterrupted) {
+				t.Fatalf("first run error = %v, want the interruption", err)
+			}
+			store.check(t, "after the interruption")
+
+			store.failAt, store.failMidShift, store.step = 0, false, 0
+			if err := migrateLegacyOrder(store, order); err != nil {
+				t.Fatalf("second run error = %v", err)
+			}
+			store.check(t, "after the second run")
+			if store.legacyLeft || store.seq != 5 || len(store.versions) != 5 {
+				t.Errorf("after the second run: %+v, want 5 versions, counter 5 and the legacy order gone", store)
+			}
+			for _, v := range store.versions {
+				if v.legacy != (v.version < 2) {
+					t.Errorf("version %d legacy=%v, want the legacy ones first", v.version, v.legacy)
+				}
+			}
+		})
+	}
+}
@@ -16,24 +16,48 @@ import (
 )
 
 const (
-	ordersCollection      = "orders"
-	smartsenderCollection = "smartsender_state"
+	legacyOrdersCollection  = "orders"
+	versionsCollection      = "order_versions"
+	versionSeqCollection    = "order_version_seq"
+	smartsenderCollection   = "smartsender_state"
+	b2bDealsCollection      = "b2b_deals"
+	subscriptionsCollection = "subscriptions"
+	deliveriesCollection    = "webhook_deliveries"
+	zohoCallsCollection     = "zoho_calls"
+	apiCallsCollection      = "api_calls"
+
+	// maxDeliveries caps one page of the webhook delivery log.
+	maxDeliveries = 500
+	// maxZohoCalls caps one page of the Zoho call audit log.
+	maxZohoCalls = 500
+	// maxApiCalls caps one page of the API call audit log.
+	maxApiCalls = 500
 )
 
+// MongoDB holds one client for the life of the service; the driver pools its connections.
 type MongoDB struct {
-	ctx           context.Context
-	clientOptions *options.ClientOptions
-	database      string
-	expiredDays   int
-	log           *slog.Logger
+	client      *mongo.Client
+	database    string
+	expiredDays int
+	auditDays   int
+	apiDays     int
+	timeout     time.Duration
+	log         *slog.Logger
 }
 
+// NewMongoClient connects to MongoDB, checks the server answers, creates the indexes and moves
+// order history left by older versions into place. It returns nil when MongoDB is disabled.
 func NewMongoClient(conf *config.Config, logger *slog.Logger) (*MongoDB, error) {
 	if !conf.Mongo.Enabled {
 		return nil, nil
 	}
 	connectionUri := fmt.Sprintf("mongodb://%s:%s", conf.Mongo.Host, conf.Mongo.Port)
-	clientOptions := options.Client().ApplyURI(connectionUri)
+	clientOptions := options.Client().
+		ApplyURI(connectionUri).
+		SetMaxPoolSize(conf.Mongo.MaxPoolSize).
+		SetMinPoolSize(conf.Mongo.MinPoolSize).
+		SetMaxConnIdleTime(5 * time.Minute).
+		SetServerSelectionTimeout(time.Duration(conf.Mongo.Timeout) * time.Second)
 	if conf.Mongo.User != "" {
 		clientOptions.SetAuth(options.Credential{
 			Username:   conf.Mongo.User,
@@ -41,116 +65,132 @@ func NewMongoClient(conf *config.Config, logger *slog.Logger) (*MongoDB, error)
 			AuthSource: conf.Mongo.Database,
 		})
 	}
-	client := &MongoDB{
-		ctx:           context.Background(),
-		clientOptions: clientOptions,
-		database:      conf.Mongo.Database,
-		expiredDays:   conf.Mongo.ExpiredDays,
-		log:           logger.With(sl.Module("mongodb")),
-	}
-	return client, nil
-}
 
-func (m *MongoDB) connect() (*mongo.Client, error) {
-	connection, err := mongo.Connect(m.ctx, m.clientOptions)
+	client, err := mongo.Connect(context.Background(), clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("mongodb connect error: %w", err)
 	}
-	return connection, nil
-}
+	m := &MongoDB{
+		client:      client,
+		database:    conf.Mongo.Database,
+		expiredDays: conf.Mongo.ExpiredDays,
+		auditDays:   conf.Zoho.Audit.RetentionDays,
+		apiDays:     conf.Listen.AuditDays,
+		timeout:     time.Duration(conf.Mongo.Timeout) * time.Second,
+		log:         logger.With(sl.Module("mongodb")),
+	}
 
-func (m *MongoDB) disconnect(connection *mongo.Client) {
-	_ = connection.Disconnect(m.ctx)
+	if err = m.Ping(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if err = m.ensureIndexes(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if err = m.migrateLegacyVersions(); err != nil {
+		// Versions saved on top of a half-done migration would be moved up again by the next
+		// run, so the client is not handed out until it has finished.
+		m.Close()
+		return nil, fmt.Errorf("legacy order versions not migrated: %w", err)
+	}
+	return m, nil
 }
 
-func (m *MongoDB) findError(err error) error {
-	if errors.Is(err, mongo.ErrNoDocuments) {
-		return nil
+// Ping checks that the server answers within the operation timeout.
+func (m *MongoDB) Ping() error {
+	ctx, cancel := m.context()
+	defer cancel()
+	if err := m.client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("mongodb ping error: %w", err)
 	}
-	return fmt.Errorf("mongodb find error: %w", err)
+	return nil
 }
 
-// SaveOrderVersion saves or updates an order with a new version in MongoDB.
-// If the order exists, appends the new version. If not, creates a new order document.
-// Version ID is auto-generated as sequential number (0, 1, 2, ...).
-func (m *MongoDB) SaveOrderVersion(orderID int64, payload string) error {
-	connection, err := m.connect()
-	if err != nil {
-		return err
+// Close disconnects the client, waiting for in-flight operations up to the operation timeout.
+func (m *MongoDB) Close() {
+	ctx, cancel := m.context()
+	defer cancel()
+	if err := m.client.Disconnect(ctx); err != nil {
+		m.log.With(sl.Err(err)).Warn("mongodb disconnect")
 	}
-	defer m.disconnect(connection)
+}
+
+// context bounds one operation.
+func (m *MongoDB) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), m.timeout)
+}
 
-	collection := connection.Database(m.database).Collection(ordersCollection)
+func (m *MongoDB) collection(name string) *mongo.Collection {
+	return m.client.Database(m.database).Collection(name)
+}
 
-	// Try to find existing order
-	filter := bson.M{"order_id": orderID}
-	var existingOrder entity.MongoOrder
-	err = collection.FindOne(m.ctx, filter).Decode(&existingOrder)
+// SaveOrderVersion stores a payload as the next version of an order. Version numbers (0, 1,
+// 2, ...) come from a per-order counter incremented atomically, so concurrent saves of one
+// order get distinct numbers; the unique (order_id, version) index backs that up.
+func (m *MongoDB) SaveOrderVersion(orderID int64, direction, payload string) error {
+	ctx, cancel := m.context()
+	defer cancel()
 
+	now := time.Now()
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := m.collection(versionSeqCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": orderID},
+		bson.M{"$inc": bson.M{"seq": 1}, "$set": bson.M{"updated_at": now}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			// Create new order document with version 0
-			version := entity.Version{ID: "0", Payload: payload, CreationDate: time.Now()}
-			newOrder := entity.MongoOrder{
-				CreationDate: time.Now(),
-				OrderID:      orderID,
-				Versions:     []entity.Version{version},
-			}
-			_, err = collection.InsertOne(m.ctx, newOrder)
-			if err != nil {
-				return fmt.Errorf("mongodb insert error: %w", err)
-			}
-			//m.log.Debug("created new order in mongodb", slog.Int64("order_id", orderID), slog.String("version_id", "0"))
-			return nil
-		}
-		return m.findError(err)
+		return fmt.Errorf("mongodb version counter error: %w", err)
 	}
 
-	// Order exists, append new version with next sequential ID
-	nextID := fmt.Sprintf("%d", len(existingOrder.Versions))
-	version := entity.Version{ID: nextID, Payload: payload, CreationDate: time.Now()}
-	update := bson.M{
-		"$push": bson.M{"versions": version},
+	version := entity.OrderVersion{
+		OrderID:      orderID,
+		Version:      counter.Seq - 1,
+		Direction:    direction,
+		CreationDate: now,
+		Payload:      payload,
 	}
-	_, err = collection.UpdateOne(m.ctx, filter, update)
-	if err != nil {
-		return fmt.Errorf("mongodb update error: %w", err)
+	if _, err = m.collection(versionsCollection).InsertOne(ctx, version); err != nil {
+		return fmt.Errorf("mongodb insert error: %w", err)
 	}
-
-	//m.log.Debug("added version to order in mongodb", slog.Int64("order_id", orderID), slog.String("version_id", nextID))
 	return nil
 }
 
-// DeleteExpired removes order documents older than expiredDays from MongoDB.
-// Returns the number of deleted documents.
-func (m *MongoDB) DeleteExpired() (int64, error) {
-	if m.expiredDays <= 0 {
-		return 0, nil
-	}
+// GetOrderVersions returns the stored versions of an order, oldest first.
+func (m *MongoDB) GetOrderVersions(orderID int64) ([]entity.OrderVersion, error) {
+	ctx, cancel := m.context()
+	defer cancel()
 
-	connection, err := m.connect()
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	cursor, err := m.collection(versionsCollection).Find(ctx, bson.M{"order_id": orderID}, opts)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("mongodb find error: %w", err)
 	}
-	defer m.disconnect(connection)
+	defer cursor.Close(ctx)
 
-	collection := connection.Database(m.database).Collection(ordersCollection)
+	var versions []entity.OrderVersion
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("mongodb decode error: %w", err)
+	}
+	return versions, nil
+}
 
-	cutoffDate := time.Now().AddDate(0, 0, -m.expiredDays)
-	filter := bson.M{"creation_date": bson.M{"$lt": cutoffDate}}
+// GetOrderVersion returns one version of an order, or nil if it does not exist or has expired.
+func (m *MongoDB) GetOrderVersion(orderID, version int64) (*entity.OrderVersion, error) {
+	ctx, cancel := m.context()
+	defer cancel()
 
-	result, err := collection.DeleteMany(m.ctx, filter)
+	var v entity.OrderVersion
+	err := m.collection(versionsCollection).FindOne(ctx, bson.M{"order_id": orderID, "version": version}).Decode(&v)
 	if err != nil {
-		return 0, fmt.Errorf("mongodb delete error: %w", err)
-	}
-
-	if result.DeletedCount > 0 {
-		m.log.Info("deleted expired orders from mongodb",
-			slog.Int64("deleted_count", result.DeletedCount),
-			slog.Int("expired_days", m.expiredDays))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mongodb find error: %w", err)
 	}
-
-	return result.DeletedCount, nil
+	return &v, nil
 }
 
 // SSState represents SmartSender state document in MongoDB
@@ -161,16 +201,13 @@ type SSState struct {
 
 // GetSSLastProcessedTime retrieves the last processed time for a chat from MongoDB
 func (m *MongoDB) GetSSLastProcessedTime(chatID string) (time.Time, error) {
-	connection, err := m.connect()
-	if err != nil {
-		return time.Time{}, err
-	}
-	defer m.disconnect(connection)
+	ctx, cancel := m.context()
+	defer cancel()
 
-	collection := connection.Database(m.database).Collection(smartsenderCollection)
+	collection := m.collection(smartsenderCollection)
 
 	var state SSState
-	err = collection.FindOne(m.ctx, bson.M{"chat_id": chatID}).Decode(&state)
+	err := collection.FindOne(ctx, bson.M{"chat_id": chatID}).Decode(&state)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return time.Time{}, nil
@@ -183,19 +220,16 @@ func (m *MongoDB) GetSSLastProcessedTime(chatID string) (time.Time, error) {
 
 // SetSSLastProcessedTime saves the last processed time for a chat to MongoDB
 func (m *MongoDB) SetSSLastProcessedTime(chatID string, t time.Time) error {
-	connection, err := m.connect()
-	if err != nil {
-		return err
-	}
-	defer m.disconnect(connection)
+	ctx, cancel := m.context()
+	defer cancel()
 
-	collection := connection.Database(m.database).Collection(smartsenderCollection)
+	collection := m.collection(smartsenderCollection)
 
 	filter := bson.M{"chat_id": chatID}
 	update := bson.M{"$set": bson.M{"chat_id": chatID, "last_processed_time": t}}
 	opts := options.Update().SetUpsert(true)
 
-	_, err = collection.UpdateOne(m.ctx, filter, update, opts)
+	_, err := collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
 		return fmt.Errorf("mongodb upsert error: %w", err)
 	}
@@ -205,22 +239,19 @@ func (m *MongoDB) SetSSLastProcessedTime(chatID string, t time.Time) error {
 
 // GetAllSSLastProcessedTimes retrieves all chat last processed times from MongoDB
 func (m *MongoDB) GetAllSSLastProcessedTimes() (map[string]time.Time, error) {
-	connection, err := m.connect()
-	if err != nil {
-		return nil, err
-	}
-	defer m.disconnect(connection)
+	ctx, cancel := m.context()
+	defer cancel()
 
-	collection := connection.Database(m.database).Collection(smartsenderCollection)
+	collection := m.collection(smartsenderCollection)
 
-	cursor, err := collection.Find(m.ctx, bson.M{})
+	cursor, err := collection.Find(ctx, bson.M{})
 	if err != nil {
 		return nil, fmt.Errorf("mongodb find error: %w", err)
 	}
-	defer cursor.Close(m.ctx)
+	defer cursor.Close(ctx)
 
 	result := make(map[string]time.Time)
-	for cursor.Next(m.ctx) {
+	for cursor.Next(ctx) {
 		var state SSState
 		if err := cursor.Decode(&state); err != nil {
 			continue
@@ -230,3 +261,446 @@ func (m *MongoDB) GetAllSSLastProcessedTimes() (map[string]time.Time, error) {
 
 	return result, nil
 }
+
+// ClaimB2BDeal reserves a B2B portal order for Deal creation. The first caller for an order_uid
+// inserts the mapping (without a Zoho id yet) and gets claimed=true; every later caller gets the
+// stored mapping back and claimed=false. The unique index on order_uid (created at startup)
+// makes the claim atomic, so two concurrent deliveries of the same order cannot both create a Deal.
+func (m *MongoDB) ClaimB2BDeal(deal entity.B2BDeal) (*entity.B2BDeal, bool, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	now := time.Now()
+	deal.CreatedAt = now
+	deal.UpdatedAt = now
+
+	filter := bson.M{"order_uid": deal.OrderUID}
+	update := bson.M{"$setOnInsert": deal}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var existing entity.B2BDeal
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&existing)
+	if err == nil {
+		return &existing, false, nil
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// No document before the upsert: this call inserted it.
+		return &deal, true, nil
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent upsert won the insert; read what it stored.
+		if err = collection.FindOne(ctx, filter).Decode(&existing); err != nil {
+			return nil, false, fmt.Errorf("mongodb find error: %w", err)
+		}
+		return &existing, false, nil
+	}
+	return nil, false, fmt.Errorf("mongodb upsert error: %w", err)
+}
+
+// TakeOverB2BDeal renews a claim that has gone stale without a Zoho Deal id, so the caller may
+// create the Deal. claimedAt is the claim time the caller saw; when another delivery has renewed
+// or completed the claim since, nothing changes and false is returned.
+func (m *MongoDB) TakeOverB2BDeal(orderUID string, claimedAt time.Time) (bool, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	now := time.Now()
+	filter := bson.M{"order_uid": orderUID, "zoho_id": "", "created_at": claimedAt}
+	update := bson.M{"$set": bson.M{"created_at": now, "updated_at": now}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("mongodb update error: %w", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// SetB2BDealZohoId records the Zoho Deal id created for a claimed B2B order.
+func (m *MongoDB) SetB2BDealZohoId(orderUID, zohoID string) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	filter := bson.M{"order_uid": orderUID}
+	update := bson.M{"$set": bson.M{"zoho_id": zohoID, "updated_at": time.Now()}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("mongodb update error: %w", err)
+	}
+	return nil
+}
+
+// SetB2BDealPaymentId records the Zoho payment created for a paid B2B order, so a redelivered
+// order_paid does not create a second payment.
+func (m *MongoDB) SetB2BDealPaymentId(orderUID, paymentID string) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	filter := bson.M{"order_uid": orderUID}
+	update := bson.M{"$set": bson.M{"payment_id": paymentID, "updated_at": time.Now()}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("mongodb update error: %w", err)
+	}
+	return nil
+}
+
+// ClaimB2BDealPayment reserves a B2B order for payment creation. claimedAt is the payment
+// claim the caller saw: zero when there was none, or a stale claim it takes over. The claim is
+// made only if it is still the same and no payment has been recorded, so of two concurrent
+// order_paid deliveries only one creates the payment.
+func (m *MongoDB) ClaimB2BDealPayment(orderUID string, claimedAt time.Time) (bool, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	filter := bson.M{"order_uid": orderUID, "payment_id": bson.M{"$in": bson.A{nil, ""}}}
+	if claimedAt.IsZero() {
+		filter["payment_claimed_at"] = bson.M{"$exists": false}
+	} else {
+		filter["payment_claimed_at"] = claimedAt
+	}
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"payment_claimed_at": now, "updated_at": now}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("mongodb update error: %w", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// ReleaseB2BDealPayment removes a payment claim that never got a Zoho payment, so a retry of
+// order_paid can create it.
+func (m *MongoDB) ReleaseB2BDealPayment(orderUID string) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	filter := bson.M{"order_uid": orderUID, "payment_id": bson.M{"$in": bson.A{nil, ""}}}
+	update := bson.M{"$unset": bson.M{"payment_claimed_at": ""}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("mongodb update error: %w", err)
+	}
+	return nil
+}
+
+// ReleaseB2BDeal removes a claim that never got a Zoho Deal, so a retry of the webhook can
+// create it. Mappings that already carry a Zoho id are never removed.
+func (m *MongoDB) ReleaseB2BDeal(orderUID string) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	_, err := collection.DeleteOne(ctx, bson.M{"order_uid": orderUID, "zoho_id": ""})
+	if err != nil {
+		return fmt.Errorf("mongodb delete error: %w", err)
+	}
+	return nil
+}
+
+// GetB2BDeal returns the Deal mapping for a B2B portal order, or nil if the order is unknown.
+func (m *MongoDB) GetB2BDeal(orderUID string) (*entity.B2BDeal, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(b2bDealsCollection)
+
+	var deal entity.B2BDeal
+	err := collection.FindOne(ctx, bson.M{"order_uid": orderUID}).Decode(&deal)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mongodb find error: %w", err)
+	}
+	return &deal, nil
+}
+
+// SaveSubscription stores a subscription, replacing the one of the same user and type.
+func (m *MongoDB) SaveSubscription(sub entity.Subscription) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(subscriptionsCollection)
+
+	filter := bson.M{"user_id": sub.UserID, "subscription_type": sub.SubscriptionType}
+	_, err := collection.ReplaceOne(ctx, filter, sub, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("mongodb upsert error: %w", err)
+	}
+	return nil
+}
+
+// GetSubscription returns the subscription of a Telegram user, or nil if there is none.
+func (m *MongoDB) GetSubscription(userID int, subscriptionType string) (*entity.Subscription, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(subscriptionsCollection)
+
+	var sub entity.Subscription
+	filter := bson.M{"user_id": userID, "subscription_type": subscriptionType}
+	err := collection.FindOne(ctx, filter).Decode(&sub)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mongodb find error: %w", err)
+	}
+	return &sub, nil
+}
+
+// GetSubscriptions returns all subscriptions of a type, oldest first.
+func (m *MongoDB) GetSubscriptions(subscriptionType string) ([]entity.Subscription, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(subscriptionsCollection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"subscription_type": subscriptionType}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb find error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var subs []entity.Subscription
+	if err = cursor.All(ctx, &subs); err != nil {
+		return nil, fmt.Errorf("mongodb decode error: %w", err)
+	}
+	return subs, nil
+}
+
+// DeleteSubscription removes the subscription of a Telegram user and reports whether there
+// was one.
+func (m *MongoDB) DeleteSubscription(userID int, subscriptionType string) (bool, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(subscriptionsCollection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"user_id": userID, "subscription_type": subscriptionType})
+	if err != nil {
+		return false, fmt.Errorf("mongodb delete error: %w", err)
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// SaveWebhookDelivery stores a webhook delivery, replacing the previous state of the same id.
+func (m *MongoDB) SaveWebhookDelivery(delivery entity.WebhookDelivery) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(deliveriesCollection)
+
+	delivery.UpdatedAt = time.Now()
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("mongodb upsert error: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDelivery returns a webhook delivery by id, or nil if there is none.
+func (m *MongoDB) GetWebhookDelivery(id string) (*entity.WebhookDelivery, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(deliveriesCollection)
+
+	var delivery entity.WebhookDelivery
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&delivery)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mongodb find error: %w", err)
+	}
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries returns the newest webhook deliveries matching the filter.
+func (m *MongoDB) GetWebhookDeliveries(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(deliveriesCollection)
+
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Event != "" {
+		query["event"] = filter.Event
+	}
+	if filter.Subscriber != "" {
+		query["subscriber"] = filter.Subscriber
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxDeliveries {
+		limit = maxDeliveries
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb find error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []entity.WebhookDelivery
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("mongodb decode error: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ClaimDueWebhookDelivery leases the pending delivery whose next attempt is the longest overdue
+// until the given time, or returns nil when none is due. A delivery leased by another worker is
+// skipped until its lease runs out, so two workers never attempt the same delivery at once.
+func (m *MongoDB) ClaimDueWebhookDelivery(now, until time.Time) (*entity.WebhookDelivery, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	collection := m.collection(deliveriesCollection)
+
+	filter := bson.M{
+		"status":       entity.DeliveryPending,
+		"next_attempt": bson.M{"$lte": now},
+		"leased_until": bson.M{"$not": bson.M{"$gt": now}},
+	}
+	update := bson.M{"$set": bson.M{"leased_until": until, "updated_at": now}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery entity.WebhookDelivery
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mongodb update error: %w", err)
+	}
+	return &delivery, nil
+}
+
+// SaveZohoCall stores the audit record of a Zoho API call.
+func (m *MongoDB) SaveZohoCall(call entity.ZohoCall) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	if _, err := m.collection(zohoCallsCollection).InsertOne(ctx, call); err != nil {
+		return fmt.Errorf("mongodb insert error: %w", err)
+	}
+	return nil
+}
+
+// GetZohoCalls returns the newest audited Zoho API calls matching the filter.
+func (m *MongoDB) GetZohoCalls(filter entity.ZohoCallFilter) ([]entity.ZohoCall, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	query := bson.M{}
+	if filter.OrderID != 0 {
+		query["order_id"] = filter.OrderID
+	}
+	if filter.RecordID != "" {
+		query["record_id"] = filter.RecordID
+	}
+	if filter.Path != "" {
+		query["path"] = filter.Path
+	}
+	if filter.Code != "" {
+		query["code"] = filter.Code
+	}
+	if filter.Failed {
+		query["failed"] = true
+	}
+	if !filter.Since.IsZero() {
+		query["time"] = bson.M{"$gte": filter.Since}
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxZohoCalls {
+		limit = maxZohoCalls
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := m.collection(zohoCallsCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb find error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var calls []entity.ZohoCall
+	if err = cursor.All(ctx, &calls); err != nil {
+		return nil, fmt.Errorf("mongodb decode error: %w", err)
+	}
+	return calls, nil
+}
+
+// SaveApiCall stores the audit record of an authenticated API call.
+func (m *MongoDB) SaveApiCall(call entity.ApiCall) error {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	if _, err := m.collection(apiCallsCollection).InsertOne(ctx, call); err != nil {
+		return fmt.Errorf("mongodb insert error: %w", err)
+	}
+	return nil
+}
+
+// GetApiCalls returns the newest audited API calls matching the filter.
+func (m *MongoDB) GetApiCalls(filter entity.ApiCallFilter) ([]entity.ApiCall, error) {
+	ctx, cancel := m.context()
+	defer cancel()
+
+	query := bson.M{}
+	if filter.Client != "" {
+		query["client"] = filter.Client
+	}
+	if filter.Path != "" {
+		query["path"] = filter.Path
+	}
+	if filter.Status != 0 {
+		query["status"] = filter.Status
+	}
+	if filter.Denied {
+		query["denied"] = true
+	}
+	if !filter.Since.IsZero() {
+		query["time"] = bson.M{"$gte": filter.Since}
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxApiCalls {
+		limit = maxApiCalls
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := m.collection(apiCallsCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb find error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var calls []entity.ApiCall
+	if err = cursor.All(ctx, &calls); err != nil {
+		return nil, fmt.Errorf("mongodb decode error: %w", err)
+	}
+	return calls, nil
+}
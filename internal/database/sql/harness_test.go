@@ -0,0 +1,169 @@
+package sql
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	gms "github.com/dolthub/go-mysql-server/sql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/sirupsen/logrus"
+	_ "time/tzdata" // newMySql loads Europe/Warsaw
+)
+
+// The repository tests run against go-mysql-server, an in-memory MySQL engine started in the
+// test process and reached through the MySQL driver like a real server. testDSNEnv names a real
+// MySQL server, without a database, to run them against instead:
+//
+//	docker run -d --name zoho-test-mysql -e MYSQL_ROOT_PASSWORD=secret -p 3306:3306 mysql:8
+//	ZOHO_TEST_MYSQL_DSN='root:secret@tcp(127.0.0.1:3306)/' go test ./internal/database/sql/
+//
+// Every test gets a database of its own, dropped when it ends.
+const testDSNEnv = "ZOHO_TEST_MYSQL_DSN"
+
+var embedded struct {
+	once sync.Once
+	dsn  string
+	err  error
+}
+
+// testDSN returns the DSN of the server the tests run against, starting the embedded one on
+// first use. It stays up until the test binary exits.
+func testDSN(t *testing.T) string {
+	t.Helper()
+	if dsn := os.Getenv(testDSNEnv); dsn != "" {
+		return dsn
+	}
+	embedded.once.Do(func() {
+		logrus.SetOutput(io.Discard) // the engine logs every connection
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			embedded.err = err
+			return
+		}
+		provider := memory.NewDBProvider()
+		srv, err := server.NewServer(
+			server.Config{Protocol: "tcp", Listener: listener},
+			sqle.NewDefault(provider),
+			gms.NewContext,
+			memory.NewSessionBuilder(provider),
+			nil,
+		)
+		if err != nil {
+			_ = listener.Close()
+			embedded.err = err
+			return
+		}
+		go func() { _ = srv.Start() }()
+		embedded.dsn = "root@tcp(" + listener.Addr().String() + ")/"
+	})
+	if embedded.err != nil {
+		t.Fatalf("start go-mysql-server: %v", embedded.err)
+	}
+	return embedded.dsn
+}
+
+// testPrefix is the table prefix of the fixtures, as in a stock OpenCart install.
+const testPrefix = "oc_"
+
+// openTestDB creates a database holding the OpenCart tables named with prefix, as they are
+// before any migration, and connects to it.
+func openTestDB(t *testing.T, prefix string) *sql.DB {
+	t.Helper()
+	conf, err := mysql.ParseDSN(testDSN(t))
+	if err != nil {
+		t.Fatalf("%s: %v", testDSNEnv, err)
+	}
+
+	server, err := sql.Open("mysql", conf.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	name := "zoho_test_" + hex.EncodeToString(buf)
+	if _, err = server.Exec("CREATE DATABASE `" + name + "` CHARACTER SET utf8mb4"); err != nil {
+		_ = server.Close()
+		t.Fatalf("create database: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := server.Exec("DROP DATABASE `" + name + "`"); err != nil {
+			t.Errorf("drop database %s: %v", name, err)
+		}
+		_ = server.Close()
+	})
+
+	conf.DBName = name
+	conf.ParseTime = true
+	db, err := sql.Open("mysql", conf.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	loadSQL(t, db, "testdata/opencart.sql", prefix)
+	return db
+}
+
+// newTestMySql returns the repository on a fresh, migrated database with the fixtures loaded.
+func newTestMySql(t *testing.T) *MySql {
+	t.Helper()
+	db := openTestDB(t, testPrefix)
+	s, err := newMySql(db, testPrefix, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("new repository: %v", err)
+	}
+	t.Cleanup(s.closeStmt)
+	if _, err = s.MigrateUp(0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	loadSQL(t, db, "testdata/fixtures.sql", testPrefix)
+	return s
+}
+
+// loadSQL runs the statements of a testdata file, with the tables named with prefix.
+func loadSQL(t *testing.T, db *sql.DB, file, prefix string) {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	script := strings.ReplaceAll(strings.Join(lines, "\n"), "`oc_", "`"+prefix)
+
+	for _, statement := range strings.Split(script, ";\n") {
+		if statement = strings.TrimSpace(statement); statement == "" {
+			continue
+		}
+		if _, err = db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v\n%s", file, err, statement)
+		}
+	}
+}
+
+// queryInt runs a query returning a single number.
+func queryInt(t *testing.T, s *MySql, query string, args ...any) int64 {
+	t.Helper()
+	var n int64
+	if err := s.db.QueryRow(strings.ReplaceAll(query, "`oc_", "`"+s.prefix), args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
@@ -0,0 +1,475 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Schema modes of the sql.migrations setting.
+const (
+	MigrationsAuto   = "auto"
+	MigrationsVerify = "verify"
+)
+
+// schemaVersionTable records the migrations applied to the OpenCart database. It carries the
+// zoho_ prefix because the database is shared with OpenCart and wfsync.
+const schemaVersionTable = "zoho_schema_version"
+
+// migrationLockTimeout bounds the wait for another instance applying migrations, in seconds.
+const migrationLockTimeout = 60
+
+// ErrMigrationDataLoss is returned by MigrateDown when a migration it would revert drops data,
+// unless forced.
+var ErrMigrationDataLoss = errors.New("reverting would drop data")
+
+// migration is one numbered change to the OpenCart schema. Every step is idempotent: the
+// columns were added on startup before there were migrations, and wfsync adds its own, so a
+// migration must cope with a schema that already has some or all of its changes. MySQL
+// commits DDL at once, so a migration that fails halfway is rerun from the start.
+type migration struct {
+	version int
+	name    string
+	up      func(s *MySql) error
+	down    func(s *MySql) error
+	// present reports whether the schema has the changes, whether or not they were recorded.
+	present func(s *MySql) (bool, error)
+	// loses lists the data down would drop; nil when it drops none.
+	loses func(s *MySql) ([]string, error)
+}
+
+// schemaColumn is a column zoho-client adds to an OpenCart table.
+type schemaColumn struct {
+	table      string
+	name       string
+	definition string
+}
+
+// zohoColumns hold the Zoho side of the sync.
+var zohoColumns = []schemaColumn{
+	{"product", "zoho_id", "VARCHAR(64) NOT NULL DEFAULT ''"},
+	{"order", "zoho_id", "VARCHAR(64) NOT NULL DEFAULT ''"},
+	{"order", "zoho_payment_id", "VARCHAR(64) NOT NULL DEFAULT ''"},
+	// zoho_payment_status mirrors the wf_payment_status value that was last reflected
+	// into the linked Zoho Payments record. It lets the pending-payment poller detect
+	// when wfsync advances the payment (e.g. held -> paid) and push the new status to
+	// Zoho, instead of treating the payment as done forever once zoho_payment_id is set.
+	{"order", "zoho_payment_status", "VARCHAR(32) NOT NULL DEFAULT ''"},
+	// zoho_modified_time mirrors Zoho's Sales_Orders.Modified_Time and is used to
+	// suppress echo webhooks: an inbound update whose Modified_Time is older than or
+	// equal to the stored value is our own write coming back and is skipped.
+	{"order", "zoho_modified_time", "DATETIME NULL"},
+	{"customer", "zoho_id", "VARCHAR(64) NOT NULL DEFAULT ''"},
+	// zoho_scopes and zoho_expires_at turn OpenCart API keys into zoho-client API tokens; a key
+	// without scopes authenticates but cannot call any route.
+	{"api", "zoho_scopes", "VARCHAR(255) NOT NULL DEFAULT ''"},
+	{"api", "zoho_expires_at", "DATETIME NULL"},
+}
+
+// The wf_* columns are owned and written by the wfsync service (Stripe payment state);
+// zoho-client only reads them when syncing payments to Zoho. We (re)create them
+// defensively so that a fresh database, or a deploy that starts zoho-client before
+// wfsync, does not break order reads with an "unknown column" error. Definitions MUST stay
+// in sync with wfsync (opencart/database/sql-client.go).
+var wfsyncColumns = []schemaColumn{
+	{"order", "wf_payment_status", "VARCHAR(32) NOT NULL DEFAULT ''"},
+	{"order", "wf_payment_id", "VARCHAR(64) NOT NULL DEFAULT ''"},
+	{"order", "wf_payment_amount", "BIGINT NOT NULL DEFAULT 0"},
+	{"order", "wf_payment_session", "VARCHAR(128) NOT NULL DEFAULT ''"},
+}
+
+// schemaIndex is an index zoho-client adds to an OpenCart table.
+type schemaIndex struct {
+	table   string
+	name    string
+	columns string
+}
+
+// zohoIndexes serve the lookups by Zoho id: OrderSearchByZohoId, the product match of
+// UpdateOrderWithTransaction and the [ERR] counts of the customer sync.
+var zohoIndexes = []schemaIndex{
+	{"order", "idx_zoho_id", "`zoho_id`"},
+	{"product", "idx_zoho_id", "`zoho_id`"},
+	{"customer", "idx_zoho_id", "`zoho_id`"},
+}
+
+// migrations are applied in order; a released migration must never change, add a new one.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "zoho_columns",
+		up:      func(s *MySql) error { return s.addColumns(zohoColumns) },
+		down:    func(s *MySql) error { return s.dropColumns(zohoColumns) },
+		present: func(s *MySql) (bool, error) { return s.hasColumns(zohoColumns) },
+		loses:   func(s *MySql) ([]string, error) { return s.columnsWithData(zohoColumns) },
+	},
+	{
+		version: 2,
+		name:    "wfsync_columns",
+		up:      func(s *MySql) error { return s.addColumns(wfsyncColumns) },
+		// wfsync owns the columns and their data; they stay.
+		down:    func(s *MySql) error { return nil },
+		present: func(s *MySql) (bool, error) { return s.hasColumns(wfsyncColumns) },
+	},
+	{
+		version: 3,
+		name:    "zoho_id_indexes",
+		up:      func(s *MySql) error { return s.addIndexes(zohoIndexes) },
+		down:    func(s *MySql) error { return s.dropIndexes(zohoIndexes) },
+		present: func(s *MySql) (bool, error) { return s.hasIndexes(zohoIndexes) },
+	},
+}
+
+// LatestMigration is the version of the newest migration.
+func LatestMigration() int {
+	return migrations[len(migrations)-1].version
+}
+
+// MigrationStatus describes one migration: whether it was recorded as applied, and whether the
+// schema actually has its changes.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Present   bool
+}
+
+// MigrationStatus lists every migration. It only reads the database.
+func (s *MySql) MigrationStatus() ([]MigrationStatus, error) {
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		present, err := m.present(s)
+		if err != nil {
+			return nil, fmt.Errorf("migration %d %s: %w", m.version, m.name, err)
+		}
+		appliedAt, ok := applied[m.version]
+		statuses[i] = MigrationStatus{
+			Version:   m.version,
+			Name:      m.name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+			Present:   present,
+		}
+	}
+	return statuses, nil
+}
+
+// VerifySchema checks that the schema has the changes of every migration, without altering
+// anything or reading the version table, so a user without ALTER or CREATE can run it.
+func (s *MySql) VerifySchema() error {
+	var missing []string
+	for _, m := range migrations {
+		present, err := m.present(s)
+		if err != nil {
+			return fmt.Errorf("migration %d %s: %w", m.version, m.name, err)
+		}
+		if !present {
+			missing = append(missing, fmt.Sprintf("%d %s", m.version, m.name))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("schema is missing migrations %s; run \"zohoclient migrate up\" as a user allowed to alter the tables",
+			strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// MigrateUp applies the migrations not yet recorded, up to and including version to, or all
+// of them when to is 0, and returns how many it applied.
+func (s *MySql) MigrateUp(to int) (int, error) {
+	if to == 0 {
+		to = LatestMigration()
+	}
+	if to < 0 || to > LatestMigration() {
+		return 0, fmt.Errorf("no migration %d, the latest is %d", to, LatestMigration())
+	}
+	unlock, err := s.lockMigrations()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if err = s.createSchemaVersionTable(); err != nil {
+		return 0, err
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if m.version > to {
+			break
+		}
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+		if err = m.up(s); err != nil {
+			return count, fmt.Errorf("migration %d %s: %w", m.version, m.name, err)
+		}
+		query := fmt.Sprintf("INSERT INTO `%s%s` (version, name, applied_at) VALUES (?, ?, ?)", s.prefix, schemaVersionTable)
+		if _, err = s.db.Exec(query, m.version, m.name, time.Now().UTC()); err != nil {
+			return count, fmt.Errorf("record migration %d %s: %w", m.version, m.name, err)
+		}
+		count++
+		s.log.With(
+			slog.Int("version", m.version),
+			slog.String("name", m.name),
+		).Info("schema migration applied")
+	}
+	// The columns of a table may have changed under the insert cache.
+	s.structure = make(map[string]map[string]Column)
+	return count, nil
+}
+
+// MigrateDown reverts the recorded migrations above version to, newest first, and returns how
+// many it reverted. Unless forced, it reverts none when any of them would drop data, such as
+// the Zoho ids linking OpenCart records to Zoho, and returns ErrMigrationDataLoss naming it.
+func (s *MySql) MigrateDown(to int, force bool) (int, error) {
+	if to < 0 || to > LatestMigration() {
+		return 0, fmt.Errorf("no migration %d, the latest is %d", to, LatestMigration())
+	}
+	unlock, err := s.lockMigrations()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if !force {
+		if err = s.checkDataLoss(to, applied); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.version <= to {
+			break
+		}
+		if _, ok := applied[m.version]; !ok {
+			continue
+		}
+		if err = m.down(s); err != nil {
+			return count, fmt.Errorf("revert migration %d %s: %w", m.version, m.name, err)
+		}
+		query := fmt.Sprintf("DELETE FROM `%s%s` WHERE version = ?", s.prefix, schemaVersionTable)
+		if _, err = s.db.Exec(query, m.version); err != nil {
+			return count, fmt.Errorf("unrecord migration %d %s: %w", m.version, m.name, err)
+		}
+		count++
+		s.log.With(
+			slog.Int("version", m.version),
+			slog.String("name", m.name),
+		).Info("schema migration reverted")
+	}
+	s.structure = make(map[string]map[string]Column)
+	return count, nil
+}
+
+// checkDataLoss returns ErrMigrationDataLoss when reverting the applied migrations above
+// version to would drop data.
+func (s *MySql) checkDataLoss(to int, applied map[int]time.Time) error {
+	var lost []string
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; !ok || m.version <= to || m.loses == nil {
+			continue
+		}
+		data, err := m.loses(s)
+		if err != nil {
+			return fmt.Errorf("migration %d %s: %w", m.version, m.name, err)
+		}
+		if len(data) > 0 {
+			lost = append(lost, fmt.Sprintf("migration %d %s: %s", m.version, m.name, strings.Join(data, ", ")))
+		}
+	}
+	if len(lost) > 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationDataLoss, strings.Join(lost, "; "))
+	}
+	return nil
+}
+
+// applySchema brings the schema in line with mode at startup.
+func (s *MySql) applySchema(mode string) error {
+	switch mode {
+	case "", MigrationsAuto:
+		_, err := s.MigrateUp(0)
+		return err
+	case MigrationsVerify:
+		return s.VerifySchema()
+	default:
+		return fmt.Errorf("invalid sql.migrations %q, want %s or %s", mode, MigrationsAuto, MigrationsVerify)
+	}
+}
+
+func (s *MySql) createSchemaVersionTable() error {
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s%s` ("+
+		"version INT NOT NULL PRIMARY KEY, "+
+		"name VARCHAR(64) NOT NULL, "+
+		"applied_at DATETIME NOT NULL)", s.prefix, schemaVersionTable)
+	if _, err := s.db.Exec(query); err != nil {
+		return fmt.Errorf("create %s: %w", schemaVersionTable, err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the recorded migrations with the time they were applied; none
+// when the version table does not exist yet.
+func (s *MySql) appliedMigrations() (map[int]time.Time, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`,
+		s.prefix+schemaVersionTable).Scan(&n)
+	if err != nil {
+		return nil, fmt.Errorf("check %s: %w", schemaVersionTable, err)
+	}
+	applied := make(map[int]time.Time)
+	if n == 0 {
+		return applied, nil
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf("SELECT version, applied_at FROM `%s%s`", s.prefix, schemaVersionTable))
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", schemaVersionTable, err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err = rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", schemaVersionTable, err)
+		}
+		applied[version] = at
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s: %w", schemaVersionTable, err)
+	}
+	return applied, nil
+}
+
+// lockMigrations takes a named lock so that instances starting together migrate one at a
+// time. The lock belongs to a connection, which is held until unlock.
+func (s *MySql) lockMigrations() (func(), error) {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration lock: %w", err)
+	}
+	name := s.prefix + schemaVersionTable
+	var got sql.NullInt64
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(CONCAT(DATABASE(), '.', ?), ?)", name, migrationLockTimeout).Scan(&got); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("migration lock: %w", err)
+	}
+	if got.Int64 != 1 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("migration lock: another instance has held it for %d s", migrationLockTimeout)
+	}
+	return func() {
+		_, _ = conn.ExecContext(ctx, "SELECT RELEASE_LOCK(CONCAT(DATABASE(), '.', ?))", name)
+		_ = conn.Close()
+	}, nil
+}
+
+func (s *MySql) addColumns(columns []schemaColumn) error {
+	for _, c := range columns {
+		if err := s.addColumnIfNotExists(c.table, c.name, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MySql) dropColumns(columns []schemaColumn) error {
+	for _, c := range columns {
+		if err := s.dropColumnIfExists(c.table, c.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnsWithData lists the columns that hold a value other than their default in any row, with
+// the number of such rows.
+func (s *MySql) columnsWithData(columns []schemaColumn) ([]string, error) {
+	var data []string
+	for _, c := range columns {
+		exists, err := s.columnExists(c.table, c.name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		var n int64
+		query := fmt.Sprintf("SELECT COUNT(*) FROM `%s%s` WHERE %s", s.prefix, c.table, c.setCondition())
+		if err = s.db.QueryRow(query).Scan(&n); err != nil {
+			return nil, fmt.Errorf("count %s.%s: %w", c.table, c.name, err)
+		}
+		if n > 0 {
+			data = append(data, fmt.Sprintf("%s.%s (%d rows)", c.table, c.name, n))
+		}
+	}
+	return data, nil
+}
+
+// setCondition selects the rows where the column holds something other than its default.
+func (c schemaColumn) setCondition() string {
+	switch {
+	case strings.Contains(c.definition, "DEFAULT ''"):
+		return fmt.Sprintf("`%s` <> ''", c.name)
+	case strings.Contains(c.definition, "DEFAULT 0"):
+		return fmt.Sprintf("`%s` <> 0", c.name)
+	default:
+		return fmt.Sprintf("`%s` IS NOT NULL", c.name)
+	}
+}
+
+func (s *MySql) hasColumns(columns []schemaColumn) (bool, error) {
+	for _, c := range columns {
+		if ok, err := s.columnExists(c.table, c.name); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (s *MySql) addIndexes(indexes []schemaIndex) error {
+	for _, i := range indexes {
+		if err := s.addIndexIfNotExists(i.table, i.name, i.columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MySql) dropIndexes(indexes []schemaIndex) error {
+	for _, i := range indexes {
+		if err := s.dropIndexIfExists(i.table, i.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MySql) hasIndexes(indexes []schemaIndex) (bool, error) {
+	for _, i := range indexes {
+		if ok, err := s.indexExists(i.table, i.name); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,205 @@
+package sql
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestMigrations_Numbered(t *testing.T) {
+	names := make(map[string]bool)
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %d %s is number %d", m.version, m.name, i+1)
+		}
+		if m.name == "" || names[m.name] || m.up == nil || m.down == nil || m.present == nil {
+			t.Errorf("migration %d %q is incomplete or named twice", m.version, m.name)
+		}
+		names[m.name] = true
+	}
+	if LatestMigration() != len(migrations) {
+		t.Errorf("latest = %d, want %d", LatestMigration(), len(migrations))
+	}
+}
+
+// newUnmigrated returns the repository on a fresh database, before any migration.
+func newUnmigrated(t *testing.T, prefix string) *MySql {
+	t.Helper()
+	s, err := newMySql(openTestDB(t, prefix), prefix, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.closeStmt)
+	return s
+}
+
+func TestMySql_Migrate(t *testing.T) {
+	for _, prefix := range []string{"oc_", "shop2_"} {
+		t.Run(prefix, func(t *testing.T) {
+			s := newUnmigrated(t, prefix)
+
+			n, err := s.MigrateUp(0)
+			if err != nil || n != LatestMigration() {
+				t.Fatalf("up = %d, %v, want %d", n, err, LatestMigration())
+			}
+			for _, c := range append(zohoColumns, wfsyncColumns...) {
+				if ok, err := s.columnExists(c.table, c.name); err != nil || !ok {
+					t.Errorf("%s%s.%s was not added: %v", prefix, c.table, c.name, err)
+				}
+			}
+			for _, i := range zohoIndexes {
+				if ok, err := s.indexExists(i.table, i.name); err != nil || !ok {
+					t.Errorf("index %s on %s%s was not added: %v", i.name, prefix, i.table, err)
+				}
+			}
+			order, _ := s.loadTableStructure("order")
+			if c := order["zoho_id"]; c.IsNullable || c.DefaultValue == nil || c.DataType != "varchar" {
+				t.Errorf("zoho_id = %+v, want VARCHAR NOT NULL DEFAULT ''", c)
+			}
+			if c := order["zoho_modified_time"]; !c.IsNullable || c.DataType != "datetime" {
+				t.Errorf("zoho_modified_time = %+v, want DATETIME NULL", c)
+			}
+			if c := order["wf_payment_amount"]; c.IsNullable || c.DataType != "bigint" {
+				t.Errorf("wf_payment_amount = %+v, want BIGINT NOT NULL", c)
+			}
+			if err = s.VerifySchema(); err != nil {
+				t.Errorf("verify: %v", err)
+			}
+
+			// Every start runs it again.
+			if n, err = s.MigrateUp(0); err != nil || n != 0 {
+				t.Errorf("second up = %d, %v, want none", n, err)
+			}
+
+			// Down to 1 drops the indexes and keeps the columns wfsync owns.
+			if n, err = s.MigrateDown(1, false); err != nil || n != 2 {
+				t.Fatalf("down to 1 = %d, %v, want 2", n, err)
+			}
+			if ok, _ := s.indexExists("order", "idx_zoho_id"); ok {
+				t.Error("the order index is still there")
+			}
+			if ok, _ := s.columnExists("order", "wf_payment_status"); !ok {
+				t.Error("wf_payment_status was dropped")
+			}
+			if n, err = s.MigrateDown(0, false); err != nil || n != 1 {
+				t.Fatalf("down to 0 = %d, %v, want 1", n, err)
+			}
+			if ok, _ := s.columnExists("order", "zoho_id"); ok {
+				t.Error("order.zoho_id is still there")
+			}
+
+			statuses, err := s.MigrationStatus()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statuses[0].Applied || statuses[0].Present || statuses[1].Applied || !statuses[1].Present {
+				t.Errorf("status after down = %+v", statuses)
+			}
+			if n, err = s.MigrateUp(0); err != nil || n != LatestMigration() {
+				t.Errorf("up again = %d, %v", n, err)
+			}
+		})
+	}
+}
+
+// A database the service bootstrapped before there were migrations has the columns but no
+// version table.
+func TestMySql_MigrateBootstrapped(t *testing.T) {
+	s := newUnmigrated(t, testPrefix)
+	if err := s.VerifySchema(); err == nil || !strings.Contains(err.Error(), "1 zoho_columns") {
+		t.Errorf("verify of a bare schema = %v", err)
+	}
+
+	if err := s.addColumns(append(zohoColumns, wfsyncColumns...)); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range statuses {
+		if st.Applied || st.Present != (st.Version != 3) {
+			t.Errorf("status %+v", st)
+		}
+	}
+	err = s.VerifySchema()
+	if err == nil || !strings.Contains(err.Error(), "3 zoho_id_indexes") || strings.Contains(err.Error(), "zoho_columns") {
+		t.Errorf("verify = %v, want only the indexes missing", err)
+	}
+
+	if n, err := s.MigrateUp(0); err != nil || n != LatestMigration() {
+		t.Fatalf("up = %d, %v", n, err)
+	}
+	if statuses, err = s.MigrationStatus(); err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range statuses {
+		if !st.Applied || !st.Present || st.AppliedAt.IsZero() {
+			t.Errorf("status %+v after up", st)
+		}
+	}
+}
+
+// Another OpenCart database on the same server, already migrated, must not hide the missing
+// columns of this one.
+func TestMySql_MigrateOtherDatabase(t *testing.T) {
+	if _, err := newUnmigrated(t, testPrefix).MigrateUp(0); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newUnmigrated(t, testPrefix)
+	if n, err := s.MigrateUp(0); err != nil || n != LatestMigration() {
+		t.Fatalf("up = %d, %v", n, err)
+	}
+	if _, err := s.GetOrderSyncState(1); err != nil {
+		t.Errorf("order columns missing: %v", err)
+	}
+}
+
+// Reverting the columns that link records to Zoho would make the sync create them all again:
+// it takes force once they hold data.
+func TestMySql_MigrateDownKeepsSyncData(t *testing.T) {
+	s := newTestMySql(t)
+
+	n, err := s.MigrateDown(0, false)
+	if !errors.Is(err, ErrMigrationDataLoss) || n != 0 {
+		t.Fatalf("down = %d, %v, want ErrMigrationDataLoss", n, err)
+	}
+	for _, want := range []string{"order.zoho_id", "customer.zoho_id", "api.zoho_scopes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not name %s", err, want)
+		}
+	}
+	// Nothing was reverted, not even the indexes above the columns.
+	if ok, _ := s.indexExists("order", "idx_zoho_id"); !ok {
+		t.Error("the order index was dropped")
+	}
+	if n, err = s.MigrateDown(1, false); err != nil || n != 2 {
+		t.Errorf("down to 1 = %d, %v, want 2: it drops no data", n, err)
+	}
+
+	if n, err = s.MigrateDown(0, true); err != nil || n != 1 {
+		t.Fatalf("forced down = %d, %v, want 1", n, err)
+	}
+	if ok, _ := s.columnExists("order", "zoho_id"); ok {
+		t.Error("order.zoho_id is still there")
+	}
+}
+
+func TestMySql_MigrateBounds(t *testing.T) {
+	s := newUnmigrated(t, testPrefix)
+	if _, err := s.MigrateUp(LatestMigration() + 1); err == nil {
+		t.Error("migrated to a version that does not exist")
+	}
+	if n, err := s.MigrateUp(1); err != nil || n != 1 {
+		t.Errorf("up to 1 = %d, %v", n, err)
+	}
+	if ok, _ := s.columnExists("order", "wf_payment_status"); ok {
+		t.Error("up to 1 ran migration 2")
+	}
+	if err := s.applySchema("lazy"); err == nil {
+		t.Error("accepted an unknown mode")
+	}
+}
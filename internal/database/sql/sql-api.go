@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"zohoclient/entity"
+)
+
+// GetApiToken looks up an enabled OpenCart API key (System > Users > API). The zoho_scopes
+// column holds the comma-separated scopes granted to the key, zoho_expires_at its optional
+// expiry. Returns nil when no enabled key matches.
+func (s *MySql) GetApiToken(key string) (*entity.UserAuth, error) {
+	query := fmt.Sprintf("SELECT username, zoho_scopes, zoho_expires_at FROM %sapi WHERE `key` = ? AND status = 1", s.prefix)
+
+	var (
+		username  string
+		scopes    string
+		expiresAt sql.NullTime
+	)
+	err := s.db.QueryRow(query, key).Scan(&username, &scopes, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+
+	user := &entity.UserAuth{
+		Name:   username,
+		Token:  key,
+		Scopes: splitScopes(scopes),
+		Source: entity.TokenSourceOcApi,
+	}
+	if expiresAt.Valid {
+		user.ExpiresAt = expiresAt.Time
+	}
+	return user, nil
+}
+
+func splitScopes(value string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,32 @@
+package sql
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+func TestMySql_GetApiToken(t *testing.T) {
+	s := newTestMySql(t)
+
+	user, err := s.GetApiToken("k-active")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil || user.Name != "zoho" || user.Token != "k-active" || user.Source != entity.TokenSourceOcApi ||
+		!slices.Equal(user.Scopes, []string{"orders:read", "orders:write"}) || !user.ExpiresAt.IsZero() {
+		t.Errorf("user = %+v", user)
+	}
+
+	if user, err = s.GetApiToken("k-expiring"); err != nil || user == nil ||
+		!user.ExpiresAt.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expiring key = %+v, %v", user, err)
+	}
+
+	for _, key := range []string{"k-disabled", "k-unknown"} {
+		if user, err = s.GetApiToken(key); err != nil || user != nil {
+			t.Errorf("%s = %+v, %v, want none", key, user, err)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math"
 	"strings"
 	"sync"
 	"time"
@@ -37,7 +36,22 @@ type MySql struct {
 	log        *slog.Logger
 }
 
+// NewSQLClient connects to the OpenCart database and brings its schema in line with
+// sql.migrations: the pending migrations are applied, or only verified.
 func NewSQLClient(conf *config.Config, log *slog.Logger) (*MySql, error) {
+	sdb, err := Connect(conf, log)
+	if err != nil {
+		return nil, err
+	}
+	if err = sdb.applySchema(conf.SQL.Migrations); err != nil {
+		sdb.Close()
+		return nil, err
+	}
+	return sdb, nil
+}
+
+// Connect connects to the OpenCart database without touching its schema.
+func Connect(conf *config.Config, log *slog.Logger) (*MySql, error) {
 	if !conf.SQL.Enabled {
 		return nil, fmt.Errorf("SQL client is disabled in configuration")
 	}
@@ -63,66 +77,29 @@ func NewSQLClient(conf *config.Config, log *slog.Logger) (*MySql, error) {
 	db.SetMaxIdleConns(10)           // макс. кол-во "неактивных" соединений в пуле
 	db.SetConnMaxLifetime(time.Hour) // время жизни соединения
 
-	sdb := &MySql{
-		db:         db,
-		prefix:     conf.SQL.Prefix,
-		structure:  make(map[string]map[string]Column),
-		statements: make(map[string]*sql.Stmt),
-		log:        log,
-	}
-
-	if err = sdb.addColumnIfNotExists("product", "zoho_id", "VARCHAR(64) NOT NULL DEFAULT ''"); err != nil {
-		return nil, err
-	}
-	if err = sdb.addColumnIfNotExists("order", "zoho_id", "VARCHAR(64) NOT NULL DEFAULT ''"); err != nil {
-		return nil, err
-	}
-	if err = sdb.addColumnIfNotExists("order", "zoho_payment_id", "VARCHAR(64) NOT NULL DEFAULT ''"); err != nil {
-		return nil, err
-	}
-	// zoho_payment_status mirrors the wf_payment_status value that was last reflected
-	// into the linked Zoho Payments record. It lets the pending-payment poller detect
-	// when wfsync advances the payment (e.g. held -> paid) and push the new status to
-	// Zoho, instead of treating the payment as done forever once zoho_payment_id is set.
-	if err = sdb.addColumnIfNotExists("order", "zoho_payment_status", "VARCHAR(32) NOT NULL DEFAULT ''"); err != nil {
-		return nil, err
-	}
-	// zoho_modified_time mirrors Zoho's Sales_Orders.Modified_Time and is used to
-	// suppress echo webhooks: an inbound update whose Modified_Time is older than or
-	// equal to the stored value is our own write coming back and is skipped.
-	if err = sdb.addColumnIfNotExists("order", "zoho_modified_time", "DATETIME NULL"); err != nil {
-		return nil, err
-	}
-	if err = sdb.addColumnIfNotExists("customer", "zoho_id", "VARCHAR(64) NOT NULL DEFAULT ''"); err != nil {
-		return nil, err
-	}
-
-	// The wf_* columns are owned and written by the wfsync service (Stripe payment state);
-	// zoho-client only reads them when syncing payments to Zoho. We (re)create them
-	// defensively so that a fresh database, or a deploy that starts zoho-client before
-	// wfsync, does not break order reads with an "unknown column" error. addColumnIfNotExists
-	// is idempotent, so this is a no-op once wfsync has run. Definitions MUST stay in sync
-	// with wfsync (opencart/database/sql-client.go).
-	if err = sdb.addColumnIfNotExists("order", "wf_payment_status", "VARCHAR(32) NOT NULL DEFAULT ''"); err != nil {
-		return nil, err
-	}
-	if err = sdb.addColumnIfNotExists("order", "wf_payment_id", "VARCHAR(64) NOT NULL DEFAULT ''"); err != nil {
-		return nil, err
-	}
-	if err = sdb.addColumnIfNotExists("order", "wf_payment_amount", "BIGINT NOT NULL DEFAULT 0"); err != nil {
-		return nil, err
-	}
-	if err = sdb.addColumnIfNotExists("order", "wf_payment_session", "VARCHAR(128) NOT NULL DEFAULT ''"); err != nil {
+	sdb, err := newMySql(db, conf.SQL.Prefix, log)
+	if err != nil {
+		_ = db.Close()
 		return nil, err
 	}
+	return sdb, nil
+}
 
+// newMySql sets up the repository on an open connection to the OpenCart tables named with
+// prefix.
+func newMySql(db *sql.DB, prefix string, log *slog.Logger) (*MySql, error) {
 	loc, err := time.LoadLocation(locationCode)
 	if err != nil {
 		return nil, fmt.Errorf("load location: %w", err)
 	}
-	sdb.loc = loc
-
-	return sdb, nil
+	return &MySql{
+		db:         db,
+		loc:        loc,
+		prefix:     prefix,
+		structure:  make(map[string]map[string]Column),
+		statements: make(map[string]*sql.Stmt),
+		log:        log,
+	}, nil
 }
 
 func (s *MySql) Close() {
@@ -144,20 +121,21 @@ func (s *MySql) Stats() string {
 	return ""
 }
 
-func (s *MySql) GetNewOrders() ([]*entity.CheckoutParams, error) {
-	statuses := []int{
-		entity.OrderStatusNew,
-		entity.OrderStatusPending,
-		entity.OrderStatusPayed,
-		entity.OrderStatusPrepareForShipping,
-		entity.OrderStatusPaymentLinkRequest,
-		entity.OrderStatusPaymentLinkCreated,
-	}
+// syncStatuses are the order statuses the poller pushes to Zoho.
+var syncStatuses = []int{
+	entity.OrderStatusNew,
+	entity.OrderStatusPending,
+	entity.OrderStatusPayed,
+	entity.OrderStatusPrepareForShipping,
+	entity.OrderStatusPaymentLinkRequest,
+	entity.OrderStatusPaymentLinkCreated,
+}
 
+func (s *MySql) GetNewOrders() ([]*entity.CheckoutParams, error) {
 	from := time.Now().Add(-30 * 24 * time.Hour)
 
 	var orders []*entity.CheckoutParams
-	for _, status := range statuses {
+	for _, status := range syncStatuses {
 		params, err := s.OrderSearchStatus(status, from)
 		if err != nil {
 			s.log.With(
@@ -481,7 +459,7 @@ func (s *MySql) orderProducts(orderId int64) ([]*entity.LineItem, error) {
 	return products, nil
 }
 
-func (s *MySql) OrderTotal(orderId int64, code string) (string, float64, error) {
+func (s *MySql) OrderTotal(orderId int64, code string) (string, entity.Amount, error) {
 	stmt, err := s.stmtSelectOrderTotals()
 	if err != nil {
 		return "", 0, err
@@ -495,7 +473,7 @@ func (s *MySql) OrderTotal(orderId int64, code string) (string, float64, error)
 	}(rows)
 
 	var title string
-	var value float64
+	var value entity.Amount
 	for rows.Next() {
 		if err = rows.Scan(
 			&title,
@@ -513,8 +491,7 @@ func (s *MySql) OrderTotal(orderId int64, code string) (string, float64, error)
 }
 
 // GetOrderProductTotals queries the sum of total and tax columns from order_product table for a given order.
-// Returns sums in cents as stored in the database.
-func (s *MySql) GetOrderProductTotals(orderId int64) (totalSum int64, taxSum int64, error error) {
+func (s *MySql) GetOrderProductTotals(orderId int64) (totalSum entity.Amount, taxSum entity.Amount, error error) {
 	query := fmt.Sprintf("SELECT COALESCE(SUM(total), 0), COALESCE(SUM(tax), 0) FROM %sorder_product WHERE order_id = ?", s.prefix)
 
 	err := s.db.QueryRow(query, orderId).Scan(&totalSum, &taxSum)
@@ -529,10 +506,10 @@ func (s *MySql) GetOrderProductTotals(orderId int64) (totalSum int64, taxSum int
 // product's zoho_id. Multiple rows may share a ZohoID; callers that need a unique
 // per-product view should aggregate.
 type OrderProductSummary struct {
-	ZohoID       string
-	Name         string
-	Quantity     int
-	TotalInCents int64
+	ZohoID   string
+	Name     string
+	Quantity int
+	Total    entity.Amount
 }
 
 // GetOrderProductsSummary returns the current order_product rows for a given order
@@ -553,14 +530,10 @@ func (s *MySql) GetOrderProductsSummary(orderId int64) ([]OrderProductSummary, e
 
 	var items []OrderProductSummary
 	for rows.Next() {
-		var (
-			it         OrderProductSummary
-			totalFloat float64
-		)
-		if err := rows.Scan(&it.ZohoID, &it.Name, &it.Quantity, &totalFloat); err != nil {
+		var it OrderProductSummary
+		if err := rows.Scan(&it.ZohoID, &it.Name, &it.Quantity, &it.Total); err != nil {
 			return nil, fmt.Errorf("scan order product: %w", err)
 		}
-		it.TotalInCents = int64(math.Round(totalFloat * 100))
 		items = append(items, it)
 	}
 	if err := rows.Err(); err != nil {
@@ -839,13 +812,12 @@ func (s *MySql) GetOrderZohoId(orderId int64) (string, error) {
 }
 
 // OrderProductData represents the data needed to insert a product line item into an order.
-// All monetary values are in cents (int64) to avoid floating point precision issues.
 type OrderProductData struct {
-	ZohoID       string
-	Quantity     int
-	PriceInCents int64 // Per-unit price, in cents
-	TotalInCents int64 // Line total, in cents
-	TaxInCents   int64 // Unit tax, in cents
+	ZohoID   string
+	Quantity int
+	Price    entity.Amount // Per-unit price
+	Total    entity.Amount // Line total
+	Tax      entity.Amount // Unit tax
 }
 
 // OrderUpdateTransaction encapsulates all data needed for a complete order update within a transaction
@@ -853,7 +825,7 @@ type OrderUpdateTransaction struct {
 	OrderID       int64
 	Items         []OrderProductData
 	CurrencyValue float64
-	OrderTotal    int64
+	OrderTotal    entity.Amount
 	Totals        OrderTotalsData
 	// NewStatusID, when > 0, updates oc_order.order_status_id atomically with the
 	// rest of the transaction. The order_history row uses this status. Leave at 0
@@ -864,16 +836,16 @@ type OrderUpdateTransaction struct {
 
 // OrderTotalsData contains all order_total entries to be updated
 type OrderTotalsData struct {
-	SubTotal      int64 // in cents
-	Tax           int64 // in cents
+	SubTotal      entity.Amount
+	Tax           entity.Amount
 	TaxTitle      string
-	Discount      int64 // in cents
+	Discount      entity.Amount
 	DiscountTitle string
-	Shipping      int64 // in cents
+	Shipping      entity.Amount
 	ShippingTitle string
-	Coupon        int64
+	Coupon        entity.Amount
 	CouponTitle   string
-	Total         int64 // in cents
+	Total         entity.Amount
 }
 
 // UpdateOrderWithTransaction performs a complete order update within a single transaction.
@@ -885,10 +857,10 @@ func (s *MySql) UpdateOrderWithTransaction(data OrderUpdateTransaction) error {
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
+	// Rolled back on every early return, including those that leave err nil or shadow it;
+	// after Commit this is a no-op.
 	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-		}
+		_ = tx.Rollback()
 	}()
 
 	// Get current order status for order_history. FOR UPDATE locks the order row so
@@ -928,11 +900,7 @@ func (s *MySql) UpdateOrderWithTransaction(data OrderUpdateTransaction) error {
 			return fmt.Errorf("empty zoho_id in order item")
 		}
 
-		priceFloat := float64(item.PriceInCents) / 100.0
-		totalFloat := float64(item.TotalInCents) / 100.0
-		taxFloat := float64(item.TaxInCents) / 100.0
-
-		res, err := tx.Exec(insertQuery, data.OrderID, item.Quantity, priceFloat, totalFloat, taxFloat, item.ZohoID)
+		res, err := tx.Exec(insertQuery, data.OrderID, item.Quantity, item.Price, item.Total, item.Tax, item.ZohoID)
 		if err != nil {
 			return fmt.Errorf("insert order item (zoho_id: %s): %w", item.ZohoID, err)
 		}
@@ -947,19 +915,19 @@ func (s *MySql) UpdateOrderWithTransaction(data OrderUpdateTransaction) error {
 
 	// Step 3: Update order.total (and optionally order_status_id) in the order table.
 	now := time.Now()
-	totalFloat := (float64(data.OrderTotal) / 100) / data.CurrencyValue
+	total := data.OrderTotal.Div(data.CurrencyValue)
 
 	effectiveStatusId := orderStatusId
 	if data.NewStatusID > 0 && data.NewStatusID != orderStatusId {
 		updateQuery := fmt.Sprintf("UPDATE %sorder SET date_modified = ?, total = ?, order_status_id = ? WHERE order_id = ?", s.prefix)
-		_, err = tx.Exec(updateQuery, now, totalFloat, data.NewStatusID, data.OrderID)
+		_, err = tx.Exec(updateQuery, now, total, data.NewStatusID, data.OrderID)
 		if err != nil {
 			return fmt.Errorf("update order total and status: %w", err)
 		}
 		effectiveStatusId = data.NewStatusID
 	} else {
 		updateQuery := fmt.Sprintf("UPDATE %sorder SET date_modified = ?, total = ? WHERE order_id = ?", s.prefix)
-		_, err = tx.Exec(updateQuery, now, totalFloat, data.OrderID)
+		_, err = tx.Exec(updateQuery, now, total, data.OrderID)
 		if err != nil {
 			return fmt.Errorf("update order total: %w", err)
 		}
@@ -978,7 +946,7 @@ func (s *MySql) UpdateOrderWithTransaction(data OrderUpdateTransaction) error {
 
 	totalsToUpdate := []struct {
 		code  string
-		value int64
+		value entity.Amount
 	}{
 		{subTotalCode, data.Totals.SubTotal},
 		{totalCodeTax, data.Totals.Tax},
@@ -989,8 +957,7 @@ func (s *MySql) UpdateOrderWithTransaction(data OrderUpdateTransaction) error {
 	}
 
 	for _, t := range totalsToUpdate {
-		valueFloat := float64(t.value) / 100.0
-		_, err = tx.Exec(updateTotalQuery, valueFloat, data.OrderID, t.code)
+		_, err = tx.Exec(updateTotalQuery, t.value, data.OrderID, t.code)
 		if err != nil {
 			return fmt.Errorf("update order_total (code: %s): %w", t.code, err)
 		}
@@ -1001,7 +968,7 @@ func (s *MySql) UpdateOrderWithTransaction(data OrderUpdateTransaction) error {
 		INSERT INTO %sorder_history (order_id, order_status_id, notify, comment, date_added)
 		VALUES (?, ?, 0, ?, ?)
 	`, s.prefix)
-	comment := fmt.Sprintf("Order updated from zoho, total = %.2f", totalFloat)
+	comment := fmt.Sprintf("Order updated from zoho, total = %s", total.Round(2))
 	if data.StatusComment != "" {
 		comment = data.StatusComment + ". " + comment
 	}
@@ -0,0 +1,305 @@
+package sql
+
+import (
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+// lineItems maps the line items of an order by product uid.
+func lineItems(order *entity.CheckoutParams) map[string]*entity.LineItem {
+	items := make(map[string]*entity.LineItem)
+	for _, item := range order.LineItems {
+		items[item.Uid] = item
+	}
+	return items
+}
+
+func TestMySql_GetNewOrders(t *testing.T) {
+	s := newTestMySql(t)
+
+	orders, err := s.GetNewOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 || orders[0].OrderId != 1001 {
+		t.Fatalf("orders = %+v, want 1001 only", orders)
+	}
+	order := orders[0]
+
+	client := order.ClientDetails
+	if client.FirstName != "Jan" || client.LastName != "Kowalski" || client.Email != "jan@example.com" ||
+		client.City != "Kraków" || client.Street != "ul. Floriańska 1" || client.ZipCode != "31-019" ||
+		client.Region != "Małopolskie" || client.CityId != 2631 || client.TaxId != "PL5252248481" || client.GroupId != 1 {
+		t.Errorf("client = %+v", client)
+	}
+	if order.StatusId != entity.OrderStatusNew || order.Currency != "PLN" || order.CurrencyValue != 1 ||
+		order.Total != entity.AmountOf(125.7) || order.Comment != "Leave at the door" || order.ShippingCode != "flat.flat" ||
+		order.Source != entity.SourceOpenCart {
+		t.Errorf("order = %+v", order)
+	}
+	if order.SubTotal != entity.AmountOf(90) || order.TaxTitle != "VAT 23%" || order.TaxValue != entity.AmountOf(20.7) ||
+		order.ShippingTitle != "Kurier DPD" || order.Shipping != entity.AmountOf(15) || order.Discount != 0 || order.Coupon != 0 {
+		t.Errorf("totals: sub %v, tax %q %v, shipping %q %v, discount %v, coupon %v", order.SubTotal,
+			order.TaxTitle, order.TaxValue, order.ShippingTitle, order.Shipping, order.Discount, order.Coupon)
+	}
+	if order.PostTerminal != "WAW123" {
+		t.Errorf("post terminal %q, want WAW123", order.PostTerminal)
+	}
+
+	items := lineItems(order)
+	coffee, machine := items["uid-1"], items["uid-2"]
+	if len(items) != 2 || coffee == nil || machine == nil {
+		t.Fatalf("line items = %+v", order.LineItems)
+	}
+	// The name comes from language 2.
+	if coffee.Name != "Kawa 1kg" || coffee.Id != 1 || coffee.ZohoId != "Z-P1" || coffee.Sku != "M-1" ||
+		coffee.Qty != 2 || coffee.Price != entity.AmountOf(20) || coffee.Total != entity.AmountOf(40) || coffee.Tax != entity.AmountOf(4.6) || coffee.MasterPrice != entity.AmountOf(20) {
+		t.Errorf("coffee = %+v", coffee)
+	}
+	// Sold below the catalogue price.
+	if machine.Price != entity.AmountOf(50) || machine.MasterPrice != entity.AmountOf(55) {
+		t.Errorf("machine = %+v, want price 50 of 55", machine)
+	}
+}
+
+func TestMySql_OrderZohoId(t *testing.T) {
+	s := newTestMySql(t)
+
+	if err := s.ChangeOrderZohoId(1001, "Z-SO-1001"); err != nil {
+		t.Fatal(err)
+	}
+	if zohoId, err := s.GetOrderZohoId(1001); err != nil || zohoId != "Z-SO-1001" {
+		t.Errorf("zoho id = %q, %v", zohoId, err)
+	}
+
+	zohoId, order, err := s.OrderSearchId(1001)
+	if err != nil || zohoId != "Z-SO-1001" || order.OrderId != 1001 || len(order.LineItems) != 2 {
+		t.Errorf("search by id = %q, %+v, %v", zohoId, order, err)
+	}
+	orderId, order, err := s.OrderSearchByZohoId("Z-SO-1001")
+	if err != nil || orderId != 1001 || order.Total != entity.AmountOf(125.7) || len(order.LineItems) != 2 {
+		t.Errorf("search by zoho id = %d, %+v, %v", orderId, order, err)
+	}
+
+	// Synced, the order is no longer new.
+	if orders, err := s.GetNewOrders(); err != nil || len(orders) != 0 {
+		t.Errorf("new orders = %+v, %v, want none", orders, err)
+	}
+
+	if _, _, err = s.OrderSearchId(9999); err == nil {
+		t.Error("found a missing order")
+	}
+	if _, _, err = s.OrderSearchByZohoId("Z-SO-9999"); err == nil {
+		t.Error("found a missing zoho id")
+	}
+}
+
+func TestMySql_Payments(t *testing.T) {
+	s := newTestMySql(t)
+
+	pending, err := s.GetOrdersPendingPayment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].OrderId != 1002 {
+		t.Fatalf("pending payment = %+v, want 1002", pending)
+	}
+	if p := pending[0]; p.PaymentStatus != "succeeded" || p.PaymentId != "pi_1002" || p.PaymentAmount != 5000 ||
+		p.PaymentSessionId != "cs_1002" {
+		t.Errorf("payment = %q %q %d %q", p.PaymentStatus, p.PaymentId, p.PaymentAmount, p.PaymentSessionId)
+	}
+
+	if err = s.UpdateOrderZohoPayment(1002, "Z-PAY-1002", "succeeded"); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := s.GetOrderZohoPaymentId(1002); err != nil || id != "Z-PAY-1002" {
+		t.Errorf("payment id = %q, %v", id, err)
+	}
+	if pending, err = s.GetOrdersPendingPayment(); err != nil || len(pending) != 0 {
+		t.Errorf("pending payment = %+v, %v, want none", pending, err)
+	}
+
+	// 1003 advanced from pending to succeeded; 1002 is up to date and 1004 failed.
+	updates, err := s.GetOrdersPendingPaymentUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0].OrderId != 1003 {
+		t.Fatalf("pending update = %+v, want 1003", updates)
+	}
+	if err = s.SetOrderZohoPaymentStatus(1003, "succeeded"); err != nil {
+		t.Fatal(err)
+	}
+	if updates, err = s.GetOrdersPendingPaymentUpdate(); err != nil || len(updates) != 0 {
+		t.Errorf("pending update = %+v, %v, want none", updates, err)
+	}
+
+	if err = s.UpdateOrderZohoPaymentId(1004, "Z-PAY-1004"); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := s.GetOrderZohoPaymentId(1004); id != "Z-PAY-1004" {
+		t.Errorf("payment id = %q, want Z-PAY-1004", id)
+	}
+}
+
+func TestMySql_OrderColumns(t *testing.T) {
+	s := newTestMySql(t)
+
+	if err := s.UpdateOrderTracking(1002, "DPD123456"); err != nil {
+		t.Fatal(err)
+	}
+	if tracking, err := s.GetOrderTracking(1002); err != nil || tracking != "DPD123456" {
+		t.Errorf("tracking = %q, %v", tracking, err)
+	}
+
+	if modified, err := s.GetOrderZohoModifiedTime(1002); err != nil || !modified.IsZero() {
+		t.Errorf("modified time = %v, %v, want none", modified, err)
+	}
+	modified := time.Date(2026, 10, 1, 9, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	if err := s.SetOrderZohoModifiedTime(1002, modified); err != nil {
+		t.Fatal(err)
+	}
+	if stored, err := s.GetOrderZohoModifiedTime(1002); err != nil || !stored.Equal(modified) {
+		t.Errorf("modified time = %v, %v, want %v", stored, err, modified)
+	}
+	if err := s.SetOrderZohoModifiedTime(1002, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := s.GetOrderZohoModifiedTime(1002); !stored.IsZero() {
+		t.Errorf("modified time = %v after clearing", stored)
+	}
+}
+
+func TestMySql_Products(t *testing.T) {
+	s := newTestMySql(t)
+
+	if err := s.UpdateProductZohoId("uid-3", "Z-P3"); err != nil {
+		t.Fatal(err)
+	}
+	if zohoId, err := s.GetProductZohoIdByUid("uid-3"); err != nil || zohoId != "Z-P3" {
+		t.Errorf("zoho id = %q, %v", zohoId, err)
+	}
+	if zohoId, err := s.GetProductZohoIdByUid("uid-9"); err != nil || zohoId != "" {
+		t.Errorf("zoho id of a missing product = %q, %v", zohoId, err)
+	}
+
+	if name, zohoId, err := s.GetProductByUid("uid-1"); err != nil || name != "Kawa 1kg" || zohoId != "Z-P1" {
+		t.Errorf("product = %q, %q, %v", name, zohoId, err)
+	}
+	if name, zohoId, err := s.GetProductByUid("uid-9"); err != nil || name != "" || zohoId != "" {
+		t.Errorf("missing product = %q, %q, %v", name, zohoId, err)
+	}
+
+	summary, err := s.GetOrderProductsSummary(1001)
+	if err != nil || len(summary) != 2 {
+		t.Fatalf("summary = %+v, %v", summary, err)
+	}
+	totals := make(map[string]entity.Amount)
+	for _, item := range summary {
+		totals[item.ZohoID] = item.Total
+	}
+	if totals["Z-P1"] != entity.AmountOf(40) || totals["Z-P2"] != entity.AmountOf(50) {
+		t.Errorf("summary = %+v", summary)
+	}
+}
+
+func TestMySql_UpdateOrderWithTransaction(t *testing.T) {
+	s := newTestMySql(t)
+
+	update := OrderUpdateTransaction{
+		OrderID: 1001,
+		Items: []OrderProductData{
+			{ZohoID: "Z-P2", Quantity: 2, Price: 5000 * entity.Cent, Total: 10000 * entity.Cent, Tax: 1150 * entity.Cent},
+			{ZohoID: "Z-P1", Quantity: 1, Price: 2000 * entity.Cent, Total: 2000 * entity.Cent, Tax: 460 * entity.Cent},
+		},
+		CurrencyValue: 1,
+		OrderTotal:    16260 * entity.Cent,
+		Totals: OrderTotalsData{
+			SubTotal: 12000 * entity.Cent,
+			Tax:      2760 * entity.Cent,
+			Shipping: 1500 * entity.Cent,
+			Total:    16260 * entity.Cent,
+		},
+		NewStatusID:   entity.OrderStatusPending,
+		StatusComment: "Confirmed in Zoho",
+	}
+	if err := s.UpdateOrderWithTransaction(update); err != nil {
+		t.Fatal(err)
+	}
+
+	_, order, err := s.OrderSearchId(1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.StatusId != entity.OrderStatusPending || order.Total != entity.AmountOf(162.6) || order.SubTotal != entity.AmountOf(120) ||
+		order.TaxValue != entity.AmountOf(27.6) || order.Shipping != entity.AmountOf(15) || order.ShippingTitle != "Kurier DPD" {
+		t.Errorf("order: status %d, total %v, sub %v, tax %v, shipping %q %v", order.StatusId, order.Total,
+			order.SubTotal, order.TaxValue, order.ShippingTitle, order.Shipping)
+	}
+	items := lineItems(order)
+	if machine := items["uid-2"]; len(items) != 2 || machine == nil || machine.Qty != 2 || machine.Total != entity.AmountOf(100) ||
+		machine.Tax != entity.AmountOf(11.5) || machine.Name != "Ekspres" || machine.Sku != "M-2" {
+		t.Errorf("line items = %+v", order.LineItems)
+	}
+	if n := queryInt(t, s, "SELECT CAST(value * 100 AS SIGNED) FROM `oc_order_total` WHERE order_id = 1001 AND code = 'total'"); n != 16260 {
+		t.Errorf("order_total total = %d cents, want 16260", n)
+	}
+
+	var status int64
+	var comment string
+	err = s.db.QueryRow("SELECT order_status_id, comment FROM `oc_order_history` WHERE order_id = 1001").Scan(&status, &comment)
+	if err != nil || status != entity.OrderStatusPending || comment != "Confirmed in Zoho. Order updated from zoho, total = 162.60" {
+		t.Errorf("history = %d %q, %v", status, comment, err)
+	}
+}
+
+func TestMySql_UpdateOrderWithTransaction_RollsBack(t *testing.T) {
+	s := newTestMySql(t)
+
+	for _, zohoId := range []string{"Z-NONE", ""} {
+		err := s.UpdateOrderWithTransaction(OrderUpdateTransaction{
+			OrderID: 1001,
+			Items: []OrderProductData{
+				{ZohoID: "Z-P1", Quantity: 1, Price: 2000 * entity.Cent, Total: 2000 * entity.Cent, Tax: 460 * entity.Cent},
+				{ZohoID: zohoId, Quantity: 1, Price: 1000 * entity.Cent, Total: 1000 * entity.Cent, Tax: 230 * entity.Cent},
+			},
+			CurrencyValue: 1,
+			OrderTotal:    3690 * entity.Cent,
+			Totals:        OrderTotalsData{SubTotal: 3000 * entity.Cent, Tax: 690 * entity.Cent, Total: 3690 * entity.Cent},
+		})
+		if err == nil {
+			t.Fatalf("update with product %q succeeded", zohoId)
+		}
+	}
+
+	if n := queryInt(t, s, "SELECT COUNT(*) FROM `oc_order_product` WHERE order_id = 1001"); n != 2 {
+		t.Errorf("%d order products after the failed updates, want the 2 from before", n)
+	}
+	if n := queryInt(t, s, "SELECT COUNT(*) FROM `oc_order_history` WHERE order_id = 1001"); n != 0 {
+		t.Errorf("%d history records after the failed updates", n)
+	}
+
+	// The failed updates released the order row: a good one goes through.
+	done := make(chan error, 1)
+	go func() {
+		done <- s.UpdateOrderWithTransaction(OrderUpdateTransaction{
+			OrderID:       1001,
+			Items:         []OrderProductData{{ZohoID: "Z-P1", Quantity: 1, Price: 2000 * entity.Cent, Total: 2000 * entity.Cent, Tax: 460 * entity.Cent}},
+			CurrencyValue: 1,
+			OrderTotal:    2460 * entity.Cent,
+			Totals:        OrderTotalsData{SubTotal: 2000 * entity.Cent, Tax: 460 * entity.Cent, Total: 2460 * entity.Cent},
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the order row is still locked")
+	}
+	if n := queryInt(t, s, "SELECT COUNT(*) FROM `oc_order_product` WHERE order_id = 1001"); n != 1 {
+		t.Errorf("%d order products, want 1", n)
+	}
+}
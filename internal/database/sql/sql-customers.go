@@ -140,3 +140,14 @@ func (s *MySql) stmtUpdateCustomerZohoId() (*sql.Stmt, error) {
 	)
 	return s.prepareStmt("updateCustomerZohoId", query)
 }
+
+// ResetCustomerErrors clears the "[ERR]" sentinel from every customer, so the next sync
+// upserts them again, and returns how many were reset.
+func (s *MySql) ResetCustomerErrors() (int64, error) {
+	query := fmt.Sprintf(`UPDATE %scustomer SET zoho_id = '' WHERE zoho_id = ?`, s.prefix)
+	res, err := s.db.Exec(query, customerZohoIdError)
+	if err != nil {
+		return 0, fmt.Errorf("reset customer errors: %w", err)
+	}
+	return res.RowsAffected()
+}
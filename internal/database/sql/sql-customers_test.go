@@ -0,0 +1,40 @@
+package sql
+
+import "testing"
+
+func TestMySql_Customers(t *testing.T) {
+	s := newTestMySql(t)
+
+	rows, err := s.GetNewCustomers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].CustomerID != 1 || rows[1].CustomerID != 4 {
+		t.Fatalf("new customers = %+v, want 1 and 4", rows)
+	}
+	if d := rows[0].Details; d.Email != "jan@example.com" || d.City != "Kraków" || d.Country != "Poland" || d.GroupId != 1 {
+		t.Errorf("customer 1 = %+v", d)
+	}
+	// No address: the customer still syncs.
+	if d := rows[1].Details; d.City != "" || d.Country != "" || d.GroupId != 6 {
+		t.Errorf("customer 4 = %+v", d)
+	}
+
+	if err = s.ChangeCustomerZohoId(1, "Z-C1"); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err = s.GetNewCustomers(); err != nil || len(rows) != 1 || rows[0].CustomerID != 4 {
+		t.Errorf("new customers = %+v, %v, want 4", rows, err)
+	}
+	// The [ERR] sentinel counts as synced.
+	if total, synced, err := s.CountCustomers(); err != nil || total != 4 || synced != 3 {
+		t.Errorf("count = %d, %d, %v, want 4, 3", total, synced, err)
+	}
+
+	if n, err := s.ResetCustomerErrors(); err != nil || n != 1 {
+		t.Errorf("reset = %d, %v, want 1", n, err)
+	}
+	if rows, err = s.GetNewCustomers(); err != nil || len(rows) != 2 || rows[0].CustomerID != 3 {
+		t.Errorf("new customers = %+v, %v, want 3 and 4", rows, err)
+	}
+}
@@ -0,0 +1,92 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// orderIdsPerQuery caps the placeholders of one IN list.
+const orderIdsPerQuery = 500
+
+// UnsyncedOrder is an order the poller should have pushed but that carries no Zoho id yet.
+type UnsyncedOrder struct {
+	OrderId  int64
+	StatusId int
+	Created  time.Time
+}
+
+// OrdersUnsyncedBetween returns the orders placed in [from, to) in a status the poller pushes
+// whose zoho_id is still empty.
+func (s *MySql) OrdersUnsyncedBetween(from, to time.Time) ([]UnsyncedOrder, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(syncStatuses)), ",")
+	query := fmt.Sprintf(
+		`SELECT order_id, order_status_id, date_added
+		   FROM %sorder
+		  WHERE date_added >= ? AND date_added < ?
+		    AND (zoho_id = '' OR zoho_id IS NULL)
+		    AND order_status_id IN (%s)
+		  ORDER BY order_id`,
+		s.prefix, placeholders,
+	)
+	args := []any{from, to}
+	for _, status := range syncStatuses {
+		args = append(args, status)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query unsynced orders: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var orders []UnsyncedOrder
+	for rows.Next() {
+		var o UnsyncedOrder
+		if err = rows.Scan(&o.OrderId, &o.StatusId, &o.Created); err != nil {
+			return nil, fmt.Errorf("scan unsynced order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate unsynced orders: %w", err)
+	}
+	return orders, nil
+}
+
+// OrderZohoIds returns the zoho_id of each of the given orders that exists; an order missing
+// from the map does not exist in OpenCart.
+func (s *MySql) OrderZohoIds(orderIds []int64) (map[int64]string, error) {
+	found := make(map[int64]string, len(orderIds))
+	for start := 0; start < len(orderIds); start += orderIdsPerQuery {
+		chunk := orderIds[start:min(start+orderIdsPerQuery, len(orderIds))]
+		query := fmt.Sprintf(
+			`SELECT order_id, IFNULL(zoho_id, '') FROM %sorder WHERE order_id IN (%s)`,
+			s.prefix, strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ","),
+		)
+		args := make([]any, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+
+		rows, err := s.db.Query(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("query order zoho ids: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			var zohoId string
+			if err = rows.Scan(&id, &zohoId); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("scan order zoho id: %w", err)
+			}
+			found[id] = zohoId
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("iterate order zoho ids: %w", err)
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,51 @@
+package sql
+
+import (
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+func TestMySql_OrdersUnsyncedBetween(t *testing.T) {
+	s := newTestMySql(t)
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+	// 1005 is a B2B order and 1006 is cancelled.
+	orders, err := s.OrdersUnsyncedBetween(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 || orders[0].OrderId != 1001 || orders[0].StatusId != 1 ||
+		!orders[0].Created.Equal(day.Add(9*time.Hour)) {
+		t.Errorf("unsynced = %+v, want 1001", orders)
+	}
+}
+
+func TestMySql_OrdersSyncedBetween(t *testing.T) {
+	s := newTestMySql(t)
+	from := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+	found, err := s.OrdersSyncedBetween(from, from.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].ZohoID != "Z-SO-1002" || found[0].Order.OrderId != 1002 ||
+		found[1].ZohoID != "Z-SO-1003" || found[1].Order.Total != entity.AmountOf(60) {
+		t.Errorf("synced = %+v, want 1002 and 1003", found)
+	}
+}
+
+func TestMySql_OrderZohoIds(t *testing.T) {
+	s := newTestMySql(t)
+
+	ids, err := s.OrderZohoIds([]int64{1001, 1002, 1005, 9999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[1001] != "" || ids[1002] != "Z-SO-1002" || ids[1005] != "[B2B]" {
+		t.Errorf("zoho ids = %v", ids)
+	}
+	if _, ok := ids[9999]; ok {
+		t.Error("a missing order has a zoho id")
+	}
+}
@@ -0,0 +1,104 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"zohoclient/entity"
+)
+
+// GetOrderSyncState returns the Zoho sync columns of an order, or nil if the order does not exist.
+func (s *MySql) GetOrderSyncState(orderId int64) (*entity.OrderSyncState, error) {
+	query := fmt.Sprintf(
+		`SELECT
+			order_id,
+			order_status_id,
+			zoho_id,
+			zoho_payment_id,
+			zoho_payment_status,
+			wf_payment_status,
+			zoho_modified_time,
+			date_modified
+		 FROM %sorder
+		 WHERE order_id = ?`,
+		s.prefix,
+	)
+
+	var (
+		state        entity.OrderSyncState
+		modifiedTime sql.NullTime
+	)
+	err := s.db.QueryRow(query, orderId).Scan(
+		&state.OrderId,
+		&state.StatusId,
+		&state.ZohoId,
+		&state.ZohoPaymentId,
+		&state.ZohoPaymentStatus,
+		&state.PaymentStatus,
+		&modifiedTime,
+		&state.DateModified,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query order sync state: %w", err)
+	}
+	if modifiedTime.Valid {
+		state.ZohoModifiedTime = modifiedTime.Time
+	}
+	return &state, nil
+}
+
+// GetSyncFailures returns up to limit orders whose payment and customers whose contact were
+// marked with the "[ERR]" sentinel, most recent first.
+func (s *MySql) GetSyncFailures(limit int) ([]entity.SyncFailure, error) {
+	query := fmt.Sprintf(
+		`(SELECT '%s', order_id, CONCAT(firstname, ' ', lastname), email, date_modified
+		   FROM %sorder
+		  WHERE zoho_payment_id = '%s'
+		  ORDER BY date_modified DESC
+		  LIMIT ?)
+		 UNION ALL
+		 (SELECT '%s', customer_id, CONCAT(firstname, ' ', lastname), email, date_added
+		   FROM %scustomer
+		  WHERE zoho_id = '%s'
+		  ORDER BY date_added DESC
+		  LIMIT ?)`,
+		entity.SyncFailureOrderPayment, s.prefix, paymentZohoIdError,
+		entity.SyncFailureCustomer, s.prefix, customerZohoIdError,
+	)
+
+	rows, err := s.db.Query(query, limit, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query sync failures: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var failures []entity.SyncFailure
+	for rows.Next() {
+		var f entity.SyncFailure
+		if err = rows.Scan(&f.Kind, &f.ID, &f.Name, &f.Email, &f.DateModified); err != nil {
+			return nil, fmt.Errorf("scan sync failure: %w", err)
+		}
+		failures = append(failures, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sync failures: %w", err)
+	}
+	return failures, nil
+}
+
+// CountSyncFailures returns how many orders and customers carry the "[ERR]" sentinel.
+func (s *MySql) CountSyncFailures() (orders int64, customers int64, err error) {
+	query := fmt.Sprintf(
+		`SELECT
+			(SELECT COUNT(*) FROM %sorder WHERE zoho_payment_id = '%s'),
+			(SELECT COUNT(*) FROM %scustomer WHERE zoho_id = '%s')`,
+		s.prefix, paymentZohoIdError, s.prefix, customerZohoIdError,
+	)
+	if err = s.db.QueryRow(query).Scan(&orders, &customers); err != nil {
+		return 0, 0, fmt.Errorf("count sync failures: %w", err)
+	}
+	return orders, customers, nil
+}
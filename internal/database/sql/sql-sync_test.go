@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+func TestMySql_GetOrderSyncState(t *testing.T) {
+	s := newTestMySql(t)
+
+	state, err := s.GetOrderSyncState(1003)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.OrderId != 1003 || state.StatusId != entity.OrderStatusPayed || state.ZohoId != "Z-SO-1003" ||
+		state.ZohoPaymentId != "Z-PAY-1003" || state.ZohoPaymentStatus != "pending" || state.PaymentStatus != "succeeded" ||
+		!state.ZohoModifiedTime.IsZero() || state.DateModified.IsZero() {
+		t.Errorf("state = %+v", state)
+	}
+
+	if state, err = s.GetOrderSyncState(9999); err != nil || state != nil {
+		t.Errorf("state of a missing order = %+v, %v, want none", state, err)
+	}
+}
+
+func TestMySql_SyncFailures(t *testing.T) {
+	s := newTestMySql(t)
+
+	failures, err := s.GetSyncFailures(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []entity.SyncFailure{
+		{Kind: entity.SyncFailureOrderPayment, ID: 1004, Name: "Piotr Zieliński", Email: "piotr@example.com",
+			DateModified: time.Date(2026, 10, 7, 12, 0, 0, 0, time.UTC)},
+		{Kind: entity.SyncFailureCustomer, ID: 3, Name: "Piotr Zieliński", Email: "piotr@example.com",
+			DateModified: time.Date(2026, 9, 3, 10, 0, 0, 0, time.UTC)},
+	}
+	if len(failures) != len(want) {
+		t.Fatalf("failures = %+v, want %+v", failures, want)
+	}
+	for i := range want {
+		if f := failures[i]; f.Kind != want[i].Kind || f.ID != want[i].ID || f.Name != want[i].Name ||
+			f.Email != want[i].Email || !f.DateModified.Equal(want[i].DateModified) {
+			t.Errorf("failure %d = %+v, want %+v", i, f, want[i])
+		}
+	}
+
+	if orders, customers, err := s.CountSyncFailures(); err != nil || orders != 1 || customers != 1 {
+		t.Errorf("count = %d, %d, %v, want 1, 1", orders, customers, err)
+	}
+}
@@ -393,6 +393,9 @@ func (s *MySql) stmtSelectOrdersPendingPaymentUpdate() (*sql.Stmt, error) {
 // imports this package and the reference cannot go the other way.
 const paymentZohoIdError = "[ERR]"
 
+// customerZohoIdError is the matching sentinel in oc_customer.zoho_id (impl/core customerZohoIdError).
+const customerZohoIdError = "[ERR]"
+
 func (s *MySql) stmtSelectOrderSimpleFields() (*sql.Stmt, error) {
 	query := fmt.Sprintf(
 		`SELECT IFNULL(field29, '') FROM %sorder_simple_fields WHERE order_id = ?`,
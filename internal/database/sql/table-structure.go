@@ -21,7 +21,7 @@ func (s *MySql) loadTableStructure(tableName string) (map[string]Column, error)
 	query := fmt.Sprintf(`
         SELECT COLUMN_NAME, COLUMN_DEFAULT, IS_NULLABLE, DATA_TYPE, EXTRA
           FROM information_schema.columns
-         WHERE table_name = '%s%s'
+         WHERE table_schema = DATABASE() AND table_name = '%s%s'
          ORDER BY ORDINAL_POSITION`, s.prefix, tableName)
 
 	rows, err := s.db.Query(query)
@@ -67,23 +67,73 @@ func (s *MySql) loadTableStructure(tableName string) (map[string]Column, error)
 	return columns, nil
 }
 
+// columnExists reports whether the table has the column. A server may hold other OpenCart
+// databases with the same tables, so only the current one is looked at.
+func (s *MySql) columnExists(tableName, columnName string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?`,
+		s.prefix+tableName, columnName).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("checking column %s existence in %s: %w", columnName, tableName, err)
+	}
+	return n > 0, nil
+}
+
 func (s *MySql) addColumnIfNotExists(tableName, columnName, columnType string) error {
-	// Check if the column exists
-	query := fmt.Sprintf(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_NAME = '%s%s' AND COLUMN_NAME = '%s'`,
-		s.prefix, tableName, columnName)
-	var column string
-	err := s.db.QueryRow(query).Scan(&column)
+	exists, err := s.columnExists(tableName, columnName)
+	if err != nil || exists {
+		return err
+	}
+	alterQuery := fmt.Sprintf("ALTER TABLE `%s%s` ADD COLUMN `%s` %s", s.prefix, tableName, columnName, columnType)
+	if _, err = s.db.Exec(alterQuery); err != nil {
+		return fmt.Errorf("add column %s to table %s: %w", columnName, tableName, err)
+	}
+	return nil
+}
+
+func (s *MySql) dropColumnIfExists(tableName, columnName string) error {
+	exists, err := s.columnExists(tableName, columnName)
+	if err != nil || !exists {
+		return err
+	}
+	alterQuery := fmt.Sprintf("ALTER TABLE `%s%s` DROP COLUMN `%s`", s.prefix, tableName, columnName)
+	if _, err = s.db.Exec(alterQuery); err != nil {
+		return fmt.Errorf("drop column %s from table %s: %w", columnName, tableName, err)
+	}
+	return nil
+}
+
+// indexExists reports whether the table has an index of that name.
+func (s *MySql) indexExists(tableName, indexName string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?`,
+		s.prefix+tableName, indexName).Scan(&n)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			// Column does not exist, so add it
-			alterQuery := fmt.Sprintf(`ALTER TABLE %s%s ADD COLUMN %s %s`, s.prefix, tableName, columnName, columnType)
-			_, err = s.db.Exec(alterQuery)
-			if err != nil {
-				return fmt.Errorf("add column %s to table %s: %w", columnName, tableName, err)
-			}
-		} else {
-			return fmt.Errorf("checking column %s existence in %s: %w", columnName, tableName, err)
-		}
+		return false, fmt.Errorf("checking index %s existence in %s: %w", indexName, tableName, err)
+	}
+	return n > 0, nil
+}
+
+func (s *MySql) addIndexIfNotExists(tableName, indexName, columns string) error {
+	exists, err := s.indexExists(tableName, indexName)
+	if err != nil || exists {
+		return err
+	}
+	alterQuery := fmt.Sprintf("ALTER TABLE `%s%s` ADD INDEX `%s` (%s)", s.prefix, tableName, indexName, columns)
+	if _, err = s.db.Exec(alterQuery); err != nil {
+		return fmt.Errorf("add index %s to table %s: %w", indexName, tableName, err)
+	}
+	return nil
+}
+
+func (s *MySql) dropIndexIfExists(tableName, indexName string) error {
+	exists, err := s.indexExists(tableName, indexName)
+	if err != nil || !exists {
+		return err
+	}
+	alterQuery := fmt.Sprintf("ALTER TABLE `%s%s` DROP INDEX `%s`", s.prefix, tableName, indexName)
+	if _, err = s.db.Exec(alterQuery); err != nil {
+		return fmt.Errorf("drop index %s from table %s: %w", indexName, tableName, err)
 	}
 	return nil
 }
@@ -0,0 +1,26 @@
+package sql
+
+import "testing"
+
+func TestMySql_Insert(t *testing.T) {
+	s := newTestMySql(t)
+
+	if err := s.ChangeOrderStatus(1006, 2, "Restored by the customer"); err != nil {
+		t.Fatal(err)
+	}
+	if n := queryInt(t, s, "SELECT COUNT(*) FROM `oc_order_history` WHERE order_id = 1006 AND order_status_id = 2 AND comment = ?",
+		"Restored by the customer"); n != 1 {
+		t.Errorf("%d history records, want 1", n)
+	}
+	if n := queryInt(t, s, "SELECT order_status_id FROM `oc_order` WHERE order_id = 1006"); n != 2 {
+		t.Errorf("status %d, want 2", n)
+	}
+
+	// Without a comment there is no history record.
+	if err := s.ChangeOrderStatus(1006, 7, ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := queryInt(t, s, "SELECT COUNT(*) FROM `oc_order_history` WHERE order_id = 1006"); n != 1 {
+		t.Errorf("%d history records, want 1", n)
+	}
+}
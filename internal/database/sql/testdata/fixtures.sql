@@ -0,0 +1,80 @@
+-- Fixtures loaded after the migrations. Every `oc_` is replaced with the
+-- prefix under test.
+--
+-- 1001 new order, not in Zoho yet, two products, shipping and a post terminal
+-- 1002 in Zoho, paid through wfsync, no Zoho payment yet
+-- 1003 in Zoho with a payment whose status has advanced since
+-- 1004 in Zoho, payment failed with the [ERR] sentinel
+-- 1005 a B2B order, routed to Deals
+-- 1006 cancelled, never synced
+
+INSERT INTO `oc_product` (`product_id`, `product_uid`, `model`, `sku`, `price`, `status`, `zoho_id`, `date_added`, `date_modified`) VALUES
+  (1, 'uid-1', 'M-1', 'SKU-1', 20.0000, 1, 'Z-P1', '2026-01-10 10:00:00', '2026-01-10 10:00:00'),
+  (2, 'uid-2', 'M-2', 'SKU-2', 55.0000, 1, 'Z-P2', '2026-01-10 10:00:00', '2026-01-10 10:00:00'),
+  (3, 'uid-3', 'M-3', 'SKU-3', 10.0000, 1, '', '2026-01-10 10:00:00', '2026-01-10 10:00:00');
+
+INSERT INTO `oc_product_description` (`product_id`, `language_id`, `name`, `description`) VALUES
+  (1, 1, 'Coffee 1kg', ''),
+  (1, 2, 'Kawa 1kg', ''),
+  (2, 2, 'Ekspres', ''),
+  (3, 2, 'Filtr', '');
+
+INSERT INTO `oc_order` (`order_id`, `customer_id`, `customer_group_id`, `firstname`, `lastname`, `email`, `telephone`, `custom_field`,
+    `shipping_address_1`, `shipping_city`, `shipping_postcode`, `shipping_country`, `shipping_zone`, `shipping_zone_id`, `shipping_code`,
+    `comment`, `total`, `order_status_id`, `currency_code`, `currency_value`, `date_added`, `date_modified`,
+    `zoho_id`, `zoho_payment_id`, `zoho_payment_status`, `wf_payment_status`, `wf_payment_id`, `wf_payment_amount`, `wf_payment_session`) VALUES
+  (1001, 1, 1, ' Jan', 'Kowalski ', 'jan@example.com', '+48600100200', '{"2":"PL5252248481"}',
+    'ul. Floriańska 1', 'Kraków', '31-019', 'Poland', 'Małopolskie', 2631, 'flat.flat',
+    'Leave at the door', 125.7000, 1, 'PLN', 1.00000000, '2026-10-05 09:00:00', NOW(),
+    '', '', '', '', '', 0, ''),
+  (1002, 2, 1, 'Anna', 'Nowak', 'anna@example.com', '+48600100201', '',
+    'ul. Długa 2', 'Gdańsk', '80-827', 'Poland', 'Pomorskie', 2634, 'flat.flat',
+    '', 50.0000, 17, 'PLN', 1.00000000, '2026-10-05 10:00:00', NOW(),
+    'Z-SO-1002', '', '', 'succeeded', 'pi_1002', 5000, 'cs_1002'),
+  (1003, 2, 1, 'Anna', 'Nowak', 'anna@example.com', '+48600100201', '',
+    'ul. Długa 2', 'Gdańsk', '80-827', 'Poland', 'Pomorskie', 2634, 'flat.flat',
+    '', 60.0000, 17, 'PLN', 1.00000000, '2026-10-06 10:00:00', NOW(),
+    'Z-SO-1003', 'Z-PAY-1003', 'pending', 'succeeded', 'pi_1003', 6000, 'cs_1003'),
+  (1004, 3, 1, 'Piotr', 'Zieliński', 'piotr@example.com', '+48600100202', '',
+    'ul. Prosta 3', 'Warszawa', '00-850', 'Poland', 'Mazowieckie', 2637, 'flat.flat',
+    '', 70.0000, 17, 'PLN', 1.00000000, '2026-10-07 10:00:00', '2026-10-07 12:00:00',
+    'Z-SO-1004', '[ERR]', '', 'succeeded', 'pi_1004', 7000, 'cs_1004'),
+  (1005, 4, 6, 'Ewa', 'Wójcik', 'ewa@example.com', '+48600100203', '',
+    'ul. Polna 4', 'Poznań', '60-001', 'Poland', 'Wielkopolskie', 2640, 'flat.flat',
+    '', 1230.0000, 1, 'PLN', 1.00000000, '2026-10-05 11:00:00', NOW(),
+    '[B2B]', '', '', '', '', 0, ''),
+  (1006, 1, 1, 'Jan', 'Kowalski', 'jan@example.com', '+48600100200', '',
+    'ul. Floriańska 1', 'Kraków', '31-019', 'Poland', 'Małopolskie', 2631, 'flat.flat',
+    '', 20.0000, 7, 'PLN', 1.00000000, '2026-10-05 12:00:00', NOW(),
+    '', '', '', '', '', 0, '');
+
+INSERT INTO `oc_order_product` (`order_id`, `product_id`, `name`, `model`, `quantity`, `price`, `total`, `tax`, `reward`) VALUES
+  (1001, 1, 'Kawa 1kg', 'M-1', 2, 20.0000, 40.0000, 4.6000, 0),
+  (1001, 2, 'Ekspres', 'M-2', 1, 50.0000, 50.0000, 11.5000, 0);
+
+INSERT INTO `oc_order_total` (`order_id`, `code`, `title`, `value`, `sort_order`) VALUES
+  (1001, 'sub_total', 'Suma częściowa', 90.0000, 1),
+  (1001, 'shipping', 'Kurier DPD', 15.0000, 3),
+  (1001, 'tax', 'VAT 23%', 20.7000, 5),
+  (1001, 'total', 'Razem', 125.7000, 9);
+
+INSERT INTO `oc_order_simple_fields` (`order_id`, `field29`) VALUES
+  (1001, ' WAW123 ');
+
+INSERT INTO `oc_country` (`country_id`, `name`, `iso_code_2`, `iso_code_3`) VALUES
+  (170, 'Poland', 'PL', 'POL');
+
+INSERT INTO `oc_address` (`address_id`, `customer_id`, `firstname`, `lastname`, `address_1`, `city`, `postcode`, `country_id`) VALUES
+  (1, 1, 'Jan', 'Kowalski', 'ul. Floriańska 1', 'Kraków', '31-019', 170);
+
+-- 1 new with an address, 2 in Zoho, 3 failed, 4 new without an address
+INSERT INTO `oc_customer` (`customer_id`, `customer_group_id`, `firstname`, `lastname`, `email`, `telephone`, `address_id`, `zoho_id`, `date_added`) VALUES
+  (1, 1, 'Jan', 'Kowalski', 'jan@example.com', '+48600100200', 1, '', '2026-09-01 10:00:00'),
+  (2, 1, 'Anna', 'Nowak', 'anna@example.com', '+48600100201', 0, 'Z-C2', '2026-09-02 10:00:00'),
+  (3, 1, 'Piotr', 'Zieliński', 'piotr@example.com', '+48600100202', 0, '[ERR]', '2026-09-03 10:00:00'),
+  (4, 6, 'Ewa', 'Wójcik', 'ewa@example.com', '+48600100203', 0, '', '2026-09-04 10:00:00');
+
+INSERT INTO `oc_api` (`username`, `key`, `status`, `zoho_scopes`, `zoho_expires_at`, `date_added`, `date_modified`) VALUES
+  ('zoho', 'k-active', 1, 'orders:read, orders:write', NULL, '2026-01-01 00:00:00', '2026-01-01 00:00:00'),
+  ('report', 'k-expiring', 1, 'orders:read', '2027-01-01 00:00:00', '2026-01-01 00:00:00', '2026-01-01 00:00:00'),
+  ('old', 'k-disabled', 0, 'orders:read', NULL, '2026-01-01 00:00:00', '2026-01-01 00:00:00');
@@ -0,0 +1,156 @@
+-- The OpenCart 3 tables zoho-client reads and writes, cut down to the columns it uses, as they
+-- are before the migrations: without zoho_id and the other columns zoho-client adds. Every
+-- `oc_` is replaced with the prefix under test.
+
+CREATE TABLE `oc_order` (
+  `order_id` int(11) NOT NULL AUTO_INCREMENT,
+  `customer_id` int(11) NOT NULL DEFAULT 0,
+  `customer_group_id` int(11) NOT NULL DEFAULT 0,
+  `firstname` varchar(32) NOT NULL,
+  `lastname` varchar(32) NOT NULL,
+  `email` varchar(96) NOT NULL,
+  `telephone` varchar(32) NOT NULL,
+  `custom_field` text NOT NULL,
+  `shipping_address_1` varchar(128) NOT NULL DEFAULT '',
+  `shipping_city` varchar(128) NOT NULL DEFAULT '',
+  `shipping_postcode` varchar(10) NOT NULL DEFAULT '',
+  `shipping_country` varchar(128) NOT NULL DEFAULT '',
+  `shipping_zone` varchar(128) NOT NULL DEFAULT '',
+  `shipping_zone_id` int(11) NOT NULL DEFAULT 0,
+  `shipping_code` varchar(128) NOT NULL DEFAULT '',
+  `comment` text NOT NULL,
+  `total` decimal(15,4) NOT NULL DEFAULT 0.0000,
+  `order_status_id` int(11) NOT NULL DEFAULT 0,
+  `currency_code` varchar(3) NOT NULL,
+  `currency_value` decimal(15,8) NOT NULL DEFAULT 1.00000000,
+  `tracking` varchar(64) NOT NULL DEFAULT '',
+  `date_added` datetime NOT NULL,
+  `date_modified` datetime NOT NULL,
+  PRIMARY KEY (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_order_product` (
+  `order_product_id` int(11) NOT NULL AUTO_INCREMENT,
+  `order_id` int(11) NOT NULL,
+  `product_id` int(11) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `model` varchar(64) NOT NULL,
+  `quantity` int(4) NOT NULL,
+  `price` decimal(15,4) NOT NULL DEFAULT 0.0000,
+  `total` decimal(15,4) NOT NULL DEFAULT 0.0000,
+  `tax` decimal(15,4) NOT NULL DEFAULT 0.0000,
+  `reward` int(8) NOT NULL,
+  `sku` varchar(64) NOT NULL DEFAULT '',
+  `upc` varchar(12) NOT NULL DEFAULT '',
+  `ean` varchar(14) NOT NULL DEFAULT '',
+  `jan` varchar(13) NOT NULL DEFAULT '',
+  `isbn` varchar(17) NOT NULL DEFAULT '',
+  `mpn` varchar(64) NOT NULL DEFAULT '',
+  `location` varchar(128) NOT NULL DEFAULT '',
+  `weight` decimal(15,8) NOT NULL DEFAULT 0.00000000,
+  `discount_type` varchar(16) NOT NULL DEFAULT '',
+  `discount_amount` decimal(15,4) NOT NULL DEFAULT 0.0000,
+  PRIMARY KEY (`order_product_id`),
+  KEY `order_id` (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_order_total` (
+  `order_total_id` int(10) NOT NULL AUTO_INCREMENT,
+  `order_id` int(11) NOT NULL,
+  `code` varchar(32) NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `value` decimal(15,4) NOT NULL DEFAULT 0.0000,
+  `sort_order` int(3) NOT NULL,
+  PRIMARY KEY (`order_total_id`),
+  KEY `order_id` (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_order_history` (
+  `order_history_id` int(11) NOT NULL AUTO_INCREMENT,
+  `order_id` int(11) NOT NULL,
+  `order_status_id` int(11) NOT NULL,
+  `notify` tinyint(1) NOT NULL DEFAULT 0,
+  `comment` text NOT NULL,
+  `date_added` datetime NOT NULL,
+  PRIMARY KEY (`order_history_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_order_simple_fields` (
+  `order_id` int(11) NOT NULL,
+  `metadata` text NULL,
+  `field29` text NULL,
+  PRIMARY KEY (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_product` (
+  `product_id` int(11) NOT NULL AUTO_INCREMENT,
+  `product_uid` varchar(64) NULL,
+  `model` varchar(64) NOT NULL,
+  `sku` varchar(64) NOT NULL DEFAULT '',
+  `upc` varchar(12) NOT NULL DEFAULT '',
+  `ean` varchar(14) NOT NULL DEFAULT '',
+  `jan` varchar(13) NOT NULL DEFAULT '',
+  `isbn` varchar(17) NOT NULL DEFAULT '',
+  `mpn` varchar(64) NOT NULL DEFAULT '',
+  `location` varchar(128) NOT NULL DEFAULT '',
+  `quantity` int(4) NOT NULL DEFAULT 0,
+  `price` decimal(15,4) NOT NULL DEFAULT 0.0000,
+  `weight` decimal(15,8) NOT NULL DEFAULT 0.00000000,
+  `status` tinyint(1) NOT NULL DEFAULT 0,
+  `date_added` datetime NOT NULL,
+  `date_modified` datetime NOT NULL,
+  PRIMARY KEY (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_product_description` (
+  `product_id` int(11) NOT NULL,
+  `language_id` int(11) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `description` text NOT NULL,
+  PRIMARY KEY (`product_id`, `language_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_customer` (
+  `customer_id` int(11) NOT NULL AUTO_INCREMENT,
+  `customer_group_id` int(11) NOT NULL,
+  `firstname` varchar(32) NOT NULL,
+  `lastname` varchar(32) NOT NULL,
+  `email` varchar(96) NOT NULL,
+  `telephone` varchar(32) NOT NULL,
+  `address_id` int(11) NOT NULL DEFAULT 0,
+  `status` tinyint(1) NOT NULL DEFAULT 1,
+  `date_added` datetime NOT NULL,
+  PRIMARY KEY (`customer_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_address` (
+  `address_id` int(11) NOT NULL AUTO_INCREMENT,
+  `customer_id` int(11) NOT NULL,
+  `firstname` varchar(32) NOT NULL,
+  `lastname` varchar(32) NOT NULL,
+  `address_1` varchar(128) NOT NULL,
+  `city` varchar(128) NOT NULL,
+  `postcode` varchar(10) NOT NULL,
+  `country_id` int(11) NOT NULL DEFAULT 0,
+  `zone_id` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`address_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_country` (
+  `country_id` int(11) NOT NULL AUTO_INCREMENT,
+  `name` varchar(128) NOT NULL,
+  `iso_code_2` varchar(2) NOT NULL,
+  `iso_code_3` varchar(3) NOT NULL,
+  `status` tinyint(1) NOT NULL DEFAULT 1,
+  PRIMARY KEY (`country_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oc_api` (
+  `api_id` int(11) NOT NULL AUTO_INCREMENT,
+  `username` varchar(64) NOT NULL,
+  `key` text NOT NULL,
+  `status` tinyint(1) NOT NULL,
+  `date_added` datetime NOT NULL,
+  `date_modified` datetime NOT NULL,
+  PRIMARY KEY (`api_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -6,14 +6,22 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"net/netip"
 	"time"
+	"zohoclient/entity"
 	"zohoclient/internal/config"
+	"zohoclient/internal/http-server/handlers/audit"
 	"zohoclient/internal/http-server/handlers/b2b"
 	"zohoclient/internal/http-server/handlers/errors"
 	"zohoclient/internal/http-server/handlers/order"
+	"zohoclient/internal/http-server/handlers/outbound"
 	"zohoclient/internal/http-server/middleware/authenticate"
+	"zohoclient/internal/http-server/middleware/bodylimit"
+	"zohoclient/internal/http-server/middleware/ratelimit"
+	"zohoclient/internal/http-server/middleware/signature"
 	"zohoclient/internal/http-server/middleware/timeout"
 	"zohoclient/internal/lib/sl"
+	"zohoclient/internal/lib/util"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -28,8 +36,11 @@ type Server struct {
 
 type Handler interface {
 	authenticate.Authenticate
+	authenticate.CallLog
 	order.Core
 	b2b.Core
+	outbound.Core
+	audit.Core
 }
 
 func New(conf *config.Config, log *slog.Logger, handler Handler) (*Server, error) {
@@ -38,12 +49,20 @@ func New(conf *config.Config, log *slog.Logger, handler Handler) (*Server, error
 		log:  log.With(sl.Module("api.server")),
 	}
 
+	proxies, err := util.ParseTrustedProxies(conf.Listen.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	limits := func(log *slog.Logger, l config.RouteLimits) []func(http.Handler) http.Handler {
+		return routeLimits(log, l, proxies)
+	}
+
 	router := chi.NewRouter()
 	router.Use(timeout.Timeout(5))
 	router.Use(middleware.RequestID)
 	router.Use(middleware.Recoverer)
 	router.Use(render.SetContentType(render.ContentTypeJSON))
-	router.Use(authenticate.New(log, handler))
+	router.Use(authenticate.New(log, handler, handler, proxies))
 
 	router.NotFound(errors.NotFound(log))
 	router.MethodNotAllowed(errors.NotAllowed(log))
@@ -51,14 +70,53 @@ func New(conf *config.Config, log *slog.Logger, handler Handler) (*Server, error
 	router.Route("/zoho", func(v1 chi.Router) {
 		v1.Route("/webhook", func(webhook chi.Router) {
 			webhook.Route("/order", func(r chi.Router) {
+				r.Use(authenticate.RequireScope(log, entity.ScopeWebhookOrder))
+				r.Use(limits(log, conf.Listen.Limits.WebhookOrder)...)
+				r.Use(signature.SharedSecret(log, conf.Webhooks.Zoho.Header, conf.Webhooks.Zoho.Secrets))
 				r.Post("/", order.UpdateOrder(log, handler))
 			})
 			webhook.Route("/b2b", func(r chi.Router) {
+				r.Use(authenticate.RequireScope(log, entity.ScopeWebhookB2B))
+				r.Use(limits(log, conf.Listen.Limits.WebhookB2B)...)
+				r.Use(signature.HMAC(log, signature.Options{
+					Secrets:   conf.Webhooks.B2B.Secrets,
+					Header:    conf.Webhooks.B2B.Header,
+					Tolerance: time.Duration(conf.Webhooks.B2B.Tolerance) * time.Second,
+					Timestamp: b2b.PayloadTimestamp,
+				}))
 				r.Post("/", b2b.Webhook(log, handler))
 			})
 		})
+		v1.Route("/b2b", func(b2bRoute chi.Router) {
+			b2bRoute.Route("/order", func(r chi.Router) {
+				r.Use(authenticate.RequireScope(log, entity.ScopeWebhookB2B))
+				r.Use(limits(log, conf.Listen.Limits.WebhookB2B)...)
+				r.Get("/{uid}", b2b.GetOrder(log, handler))
+			})
+		})
+		v1.Route("/order/{id}/versions", func(r chi.Router) {
+			r.Use(authenticate.RequireScope(log, entity.ScopeAdmin))
+			r.Get("/", order.Versions(log, handler))
+			r.Get("/{a}/diff/{b}", order.VersionDiff(log, handler))
+		})
+		v1.Route("/outbound/deliveries", func(r chi.Router) {
+			r.Use(authenticate.RequireScope(log, entity.ScopeAdmin))
+			r.Get("/", outbound.ListDeliveries(log, handler))
+			r.Get("/{id}", outbound.GetDelivery(log, handler))
+			r.Post("/{id}/redeliver", outbound.Redeliver(log, handler))
+		})
+		v1.Route("/audit/calls", func(r chi.Router) {
+			r.Use(authenticate.RequireScope(log, entity.ScopeAdmin))
+			r.Get("/", audit.ZohoCalls(log, handler))
+		})
+		v1.Route("/audit/api-calls", func(r chi.Router) {
+			r.Use(authenticate.RequireScope(log, entity.ScopeAdmin))
+			r.Get("/", audit.ApiCalls(log, handler))
+		})
 		v1.Route("/push", func(push chi.Router) {
 			push.Route("/order", func(r chi.Router) {
+				r.Use(authenticate.RequireScope(log, entity.ScopePush))
+				r.Use(limits(log, conf.Listen.Limits.Push)...)
 				r.Get("/{id}", order.PushOrder(log, handler))
 			})
 		})
@@ -73,6 +131,14 @@ func New(conf *config.Config, log *slog.Logger, handler Handler) (*Server, error
 	return server, nil
 }
 
+// routeLimits returns the rate and body size middlewares for a route group.
+func routeLimits(log *slog.Logger, l config.RouteLimits, proxies []netip.Prefix) []func(http.Handler) http.Handler {
+	return []func(http.Handler) http.Handler{
+		ratelimit.New(log, ratelimit.Limits{Rate: l.Rate, Burst: l.Burst, TrustedProxies: proxies}),
+		bodylimit.New(log, l.MaxBody),
+	}
+}
+
 func (s *Server) Start() error {
 	serverAddress := fmt.Sprintf("%s:%s", s.conf.Listen.BindIP, s.conf.Listen.Port)
 	listener, err := net.Listen("tcp", serverAddress)
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+
+	"github.com/go-chi/render"
+)
+
+// ApiCalls returns the newest audited calls to this API, optionally filtered by the client,
+// path, status, denied and since query parameters; limit caps the count.
+func ApiCalls(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.audit.ApiCalls"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		query := r.URL.Query()
+		filter := entity.ApiCallFilter{
+			Client: query.Get("client"),
+			Path:   query.Get("path"),
+		}
+		var apiErr *apierrors.APIError
+		if v := query.Get("status"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 100 || n > 599 {
+				apiErr = apierrors.NewInvalidInputError("status", "must be an HTTP status code")
+			}
+			filter.Status = n
+		}
+		if v := query.Get("denied"); v != "" && apiErr == nil {
+			denied, err := strconv.ParseBool(v)
+			if err != nil {
+				apiErr = apierrors.NewInvalidInputError("denied", "must be true or false")
+			}
+			filter.Denied = denied
+		}
+		if v := query.Get("since"); v != "" && apiErr == nil {
+			since, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				apiErr = apierrors.NewInvalidInputError("since", "must be an RFC 3339 time")
+			}
+			filter.Since = since
+		}
+		if v := query.Get("limit"); v != "" && apiErr == nil {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				apiErr = apierrors.NewInvalidInputError("limit", "must be a positive integer")
+			}
+			filter.Limit = n
+		}
+		if apiErr != nil {
+			log.Warn("invalid query", slog.String("query", r.URL.RawQuery), slog.String("error_code", string(apiErr.Code)))
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+
+		calls, err := core.ApiCalls(filter)
+		if err != nil {
+			if errors.Is(err, entity.ErrApiAuditNotAvailable) {
+				apiErr = apierrors.NewServiceUnavailableError("api audit log")
+				log.Warn("api audit log not available", slog.String("error_code", string(apiErr.Code)))
+			} else {
+				apiErr = apierrors.NewDatabaseError("ApiCalls")
+				log.Error("api audit log failed",
+					slog.String("error", err.Error()),
+					slog.String("error_code", string(apiErr.Code)),
+				)
+			}
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+		if calls == nil {
+			calls = []entity.ApiCall{}
+		}
+		render.JSON(w, r, response.Ok(calls))
+	}
+}
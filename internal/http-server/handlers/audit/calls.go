@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+
+	"github.com/go-chi/render"
+)
+
+// ZohoCalls returns the newest audited Zoho API calls, optionally filtered by the order_id,
+// record_id, path, code, failed and since query parameters; limit caps the count.
+func ZohoCalls(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.audit.ZohoCalls"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		query := r.URL.Query()
+		filter := entity.ZohoCallFilter{
+			RecordID: query.Get("record_id"),
+			Path:     query.Get("path"),
+			Code:     query.Get("code"),
+		}
+		var apiErr *apierrors.APIError
+		if v := query.Get("order_id"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n <= 0 {
+				apiErr = apierrors.NewInvalidInputError("order_id", "must be a positive integer")
+			}
+			filter.OrderID = n
+		}
+		if v := query.Get("failed"); v != "" && apiErr == nil {
+			failed, err := strconv.ParseBool(v)
+			if err != nil {
+				apiErr = apierrors.NewInvalidInputError("failed", "must be true or false")
+			}
+			filter.Failed = failed
+		}
+		if v := query.Get("since"); v != "" && apiErr == nil {
+			since, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				apiErr = apierrors.NewInvalidInputError("since", "must be an RFC 3339 time")
+			}
+			filter.Since = since
+		}
+		if v := query.Get("limit"); v != "" && apiErr == nil {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				apiErr = apierrors.NewInvalidInputError("limit", "must be a positive integer")
+			}
+			filter.Limit = n
+		}
+		if apiErr != nil {
+			log.Warn("invalid query", slog.String("query", r.URL.RawQuery), slog.String("error_code", string(apiErr.Code)))
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+
+		calls, err := core.ZohoCalls(filter)
+		if err != nil {
+			if errors.Is(err, entity.ErrAuditNotAvailable) {
+				apiErr = apierrors.NewServiceUnavailableError("zoho audit log")
+				log.Warn("zoho audit log not available", slog.String("error_code", string(apiErr.Code)))
+			} else {
+				apiErr = apierrors.NewDatabaseError("ZohoCalls")
+				log.Error("zoho audit log failed",
+					slog.String("error", err.Error()),
+					slog.String("error_code", string(apiErr.Code)),
+				)
+			}
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+		if calls == nil {
+			calls = []entity.ZohoCall{}
+		}
+		render.JSON(w, r, response.Ok(calls))
+	}
+}
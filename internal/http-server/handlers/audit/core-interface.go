@@ -0,0 +1,9 @@
+package audit
+
+import "zohoclient/entity"
+
+// Core defines the interface for the Zoho API call and API client audit logs
+type Core interface {
+	ZohoCalls(filter entity.ZohoCallFilter) ([]entity.ZohoCall, error)
+	ApiCalls(filter entity.ApiCallFilter) ([]entity.ApiCall, error)
+}
@@ -5,4 +5,5 @@ import "zohoclient/entity"
 // Core defines the interface for B2B webhook business logic
 type Core interface {
 	ProcessB2BWebhook(payload *entity.B2BWebhookPayload) (string, error)
+	GetB2BDeal(orderUID string) (*entity.B2BDeal, error)
 }
@@ -0,0 +1,57 @@
+package b2b
+
+import (
+	"log/slog"
+	"net/http"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// GetOrder returns the Zoho Deal created for a B2B portal order, looked up by its order_uid.
+func GetOrder(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.b2b.GetOrder"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		orderUID := chi.URLParam(r, "uid")
+		if orderUID == "" {
+			apiErr := apierrors.NewBadRequestError("Order UID is required")
+			log.Warn("missing order uid parameter", slog.String("error_code", string(apiErr.Code)))
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+
+		log = log.With(slog.String("order_uid", orderUID))
+
+		deal, err := core.GetB2BDeal(orderUID)
+		if err != nil {
+			apiErr := apierrors.NewDatabaseError("GetB2BDeal")
+			log.Error("failed to look up B2B order",
+				slog.String("error", err.Error()),
+				slog.String("error_code", string(apiErr.Code)),
+			)
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+		if deal == nil {
+			apiErr := apierrors.NewNotFoundErrorWithID("B2B order", orderUID)
+			log.Debug("B2B order not found", slog.String("error_code", string(apiErr.Code)))
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+
+		render.JSON(w, r, response.Ok(deal))
+	}
+}
@@ -0,0 +1,18 @@
+package b2b
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PayloadTimestamp reads the send time of a B2B webhook from its raw body, for the signature
+// middleware's replay check.
+func PayloadTimestamp(body []byte) (time.Time, error) {
+	var payload struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return time.Time{}, err
+	}
+	return payload.Timestamp, nil
+}
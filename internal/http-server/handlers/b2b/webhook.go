@@ -2,6 +2,7 @@ package b2b
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
@@ -63,6 +64,20 @@ func Webhook(logger *slog.Logger, core Core) http.HandlerFunc {
 		)
 
 		zohoId, err := core.ProcessB2BWebhook(&payload)
+		if errors.Is(err, entity.ErrB2BOrderInProgress) {
+			apiErr := apierrors.NewConflictError("B2B order is still being processed, retry later")
+			log.Warn("B2B order already in progress", slog.String("error_code", string(apiErr.Code)))
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
+		if errors.Is(err, entity.ErrB2BOrderNotFound) {
+			apiErr := apierrors.NewNotFoundErrorWithID("B2B order", payload.Data.OrderUID)
+			log.Warn("B2B order has no Deal", slog.String("error_code", string(apiErr.Code)))
+			w.WriteHeader(apiErr.HTTPStatus)
+			render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+			return
+		}
 		if err != nil {
 			apiErr := apierrors.NewInternalError("Failed to process B2B webhook")
 			log.Error("failed to process B2B webhook",
@@ -7,4 +7,6 @@ import (
 type Core interface {
 	UpdateOrder(orderDetails *entity.ApiOrder) error
 	PushOrderToZoho(orderId int64) (string, error)
+	OrderVersions(orderId int64) ([]entity.OrderVersionInfo, error)
+	OrderVersionDiff(orderId, from, to int64) (*entity.OrderVersionDiff, error)
 }
@@ -0,0 +1,115 @@
+package order
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Versions returns the stored versions of an order, pushed to Zoho (outbound) and received
+// from it (inbound), oldest first.
+func Versions(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.order.Versions"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		orderId, ok := urlInt(w, r, log, "id")
+		if !ok {
+			return
+		}
+		log = log.With(slog.Int64("order_id", orderId))
+
+		versions, err := core.OrderVersions(orderId)
+		if err != nil {
+			writeVersionError(w, r, log, err, "OrderVersions", strconv.FormatInt(orderId, 10))
+			return
+		}
+		render.JSON(w, r, response.Ok(versions))
+	}
+}
+
+// VersionDiff compares two versions of an order: status, totals and the product lines.
+func VersionDiff(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.order.VersionDiff"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		orderId, ok := urlInt(w, r, log, "id")
+		if !ok {
+			return
+		}
+		from, ok := urlInt(w, r, log, "a")
+		if !ok {
+			return
+		}
+		to, ok := urlInt(w, r, log, "b")
+		if !ok {
+			return
+		}
+		log = log.With(slog.Int64("order_id", orderId), slog.Int64("from", from), slog.Int64("to", to))
+
+		diff, err := core.OrderVersionDiff(orderId, from, to)
+		if err != nil {
+			writeVersionError(w, r, log, err, "OrderVersionDiff", strconv.FormatInt(orderId, 10))
+			return
+		}
+		render.JSON(w, r, response.Ok(diff))
+	}
+}
+
+// urlInt reads a non-negative integer URL parameter, answering 400 when it is not one.
+func urlInt(w http.ResponseWriter, r *http.Request, log *slog.Logger, name string) (int64, bool) {
+	param := chi.URLParam(r, name)
+	n, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || n < 0 {
+		apiErr := apierrors.NewInvalidInputError(name, "must be a non-negative integer")
+		log.Warn("invalid url parameter",
+			slog.String(name, param),
+			slog.String("error_code", string(apiErr.Code)),
+		)
+		w.WriteHeader(apiErr.HTTPStatus)
+		render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+		return 0, false
+	}
+	return n, true
+}
+
+// writeVersionError maps a core error to the API error response.
+func writeVersionError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, operation, id string) {
+	var apiErr *apierrors.APIError
+	switch {
+	case errors.Is(err, entity.ErrOrderVersionNotFound):
+		apiErr = apierrors.NewNotFoundErrorWithID("Order version", id)
+		log.Debug("order version not found", slog.String("error_code", string(apiErr.Code)))
+	case errors.Is(err, entity.ErrVersionsNotAvailable):
+		apiErr = apierrors.NewServiceUnavailableError("order versions")
+		log.Warn("order version store not available", slog.String("error_code", string(apiErr.Code)))
+	default:
+		apiErr = apierrors.NewDatabaseError(operation)
+		log.Error("order versions failed",
+			slog.String("error", err.Error()),
+			slog.String("error_code", string(apiErr.Code)),
+		)
+	}
+	w.WriteHeader(apiErr.HTTPStatus)
+	render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+}
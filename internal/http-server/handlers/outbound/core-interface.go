@@ -0,0 +1,10 @@
+package outbound
+
+import "zohoclient/entity"
+
+// Core defines the interface for the outbound webhook delivery log
+type Core interface {
+	WebhookDeliveries(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error)
+	WebhookDelivery(id string) (*entity.WebhookDelivery, error)
+	RedeliverWebhook(id string) (*entity.WebhookDelivery, error)
+}
@@ -0,0 +1,132 @@
+package outbound
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ListDeliveries returns the newest outbound webhook deliveries, optionally filtered by the
+// status, event and subscriber query parameters; limit caps the count.
+func ListDeliveries(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.outbound.ListDeliveries"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		query := r.URL.Query()
+		filter := entity.WebhookDeliveryFilter{
+			Status:     query.Get("status"),
+			Event:      query.Get("event"),
+			Subscriber: query.Get("subscriber"),
+		}
+		if limit := query.Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n <= 0 {
+				apiErr := apierrors.NewInvalidInputError("limit", "must be a positive integer")
+				log.Warn("invalid limit", slog.String("limit", limit), slog.String("error_code", string(apiErr.Code)))
+				w.WriteHeader(apiErr.HTTPStatus)
+				render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+				return
+			}
+			filter.Limit = n
+		}
+
+		deliveries, err := core.WebhookDeliveries(filter)
+		if err != nil {
+			writeError(w, r, log, err, "WebhookDeliveries", "")
+			return
+		}
+		if deliveries == nil {
+			deliveries = []entity.WebhookDelivery{}
+		}
+		render.JSON(w, r, response.Ok(deliveries))
+	}
+}
+
+// GetDelivery returns one outbound webhook delivery with its body and last outcome.
+func GetDelivery(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.outbound.GetDelivery"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		id := chi.URLParam(r, "id")
+		log = log.With(slog.String("delivery_id", id))
+
+		delivery, err := core.WebhookDelivery(id)
+		if err == nil && delivery == nil {
+			err = entity.ErrDeliveryNotFound
+		}
+		if err != nil {
+			writeError(w, r, log, err, "WebhookDelivery", id)
+			return
+		}
+		render.JSON(w, r, response.Ok(delivery))
+	}
+}
+
+// Redeliver queues a delivery to be sent again with a fresh retry budget.
+func Redeliver(logger *slog.Logger, core Core) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.outbound.Redeliver"
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+
+		id := chi.URLParam(r, "id")
+		log = log.With(slog.String("delivery_id", id))
+
+		delivery, err := core.RedeliverWebhook(id)
+		if err != nil {
+			writeError(w, r, log, err, "RedeliverWebhook", id)
+			return
+		}
+
+		log.Info("webhook queued for redelivery")
+		w.WriteHeader(http.StatusAccepted)
+		render.JSON(w, r, response.OkWithMessage(delivery, "Delivery queued"))
+	}
+}
+
+// writeError maps a core error to the API error response.
+func writeError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, operation, id string) {
+	var apiErr *apierrors.APIError
+	switch {
+	case errors.Is(err, entity.ErrDeliveryNotFound):
+		apiErr = apierrors.NewNotFoundErrorWithID("Webhook delivery", id)
+		log.Debug("webhook delivery not found", slog.String("error_code", string(apiErr.Code)))
+	case errors.Is(err, entity.ErrWebhooksNotConfigured):
+		apiErr = apierrors.NewServiceUnavailableError("outbound webhooks")
+		log.Warn("outbound webhooks not configured", slog.String("error_code", string(apiErr.Code)))
+	default:
+		apiErr = apierrors.NewDatabaseError(operation)
+		log.Error("webhook delivery log failed",
+			slog.String("error", err.Error()),
+			slog.String("error_code", string(apiErr.Code)),
+		)
+	}
+	w.WriteHeader(apiErr.HTTPStatus)
+	render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"strings"
 	"time"
 	"zohoclient/entity"
@@ -20,7 +21,16 @@ type Authenticate interface {
 	AuthenticateByToken(token string) (*entity.UserAuth, error)
 }
 
-func New(log *slog.Logger, auth Authenticate) func(next http.Handler) http.Handler {
+// CallLog stores the audit record of each authenticated API call.
+type CallLog interface {
+	SaveApiCall(call entity.ApiCall) error
+}
+
+// New authenticates each request by its bearer token and puts the client into the request
+// context. With calls set, every authenticated request is also recorded per client: method,
+// path, status, the scope decision of RequireScope and the client IP, which X-Forwarded-For
+// only names when the connection comes from one of the trusted proxies.
+func New(log *slog.Logger, auth Authenticate, calls CallLog, trusted []netip.Prefix) func(next http.Handler) http.Handler {
 	mod := sl.Module("middleware.authenticate")
 	log.With(mod).Info("authenticate middleware initialized")
 
@@ -34,7 +44,7 @@ func New(log *slog.Logger, auth Authenticate) func(next http.Handler) http.Handl
 			}
 
 			id := middleware.GetReqID(r.Context())
-			remote := util.ExtractIPAddress(r.RemoteAddr, r.Header.Get("X-Forwarded-For"))
+			remote := util.ClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), trusted)
 			logger := log.With(
 				mod,
 				slog.String("method", r.Method),
@@ -45,12 +55,21 @@ func New(log *slog.Logger, auth Authenticate) func(next http.Handler) http.Handl
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
 			t1 := time.Now()
+			var call *entity.ApiCall
 			defer func() {
 				logger.With(
 					slog.Int("status", ww.Status()),
 					slog.Int("size", ww.BytesWritten()),
 					slog.Float64("duration", time.Since(t1).Seconds()),
 				).Info("incoming request")
+				if call != nil {
+					call.Status = ww.Status()
+					if call.Status == 0 {
+						call.Status = http.StatusOK
+					}
+					call.DurationMs = time.Since(t1).Milliseconds()
+					saveCall(logger, calls, *call)
+				}
 			}()
 
 			header := r.Header.Get("Authorization")
@@ -84,8 +103,21 @@ func New(log *slog.Logger, auth Authenticate) func(next http.Handler) http.Handl
 			}
 			logger = logger.With(
 				slog.String("user", user.Name),
+				slog.String("token_source", user.Source),
 			)
 			ctx := cont.PutUser(r.Context(), user)
+			if calls != nil {
+				call = &entity.ApiCall{
+					Time:        t1,
+					Client:      user.Name,
+					TokenSource: user.Source,
+					Method:      r.Method,
+					Path:        r.URL.Path,
+					RequestID:   id,
+					RemoteAddr:  remote,
+				}
+				ctx = cont.PutApiCall(ctx, call)
+			}
 
 			ww.Header().Set("X-Request-ID", id)
 			ww.Header().Set("X-User", user.Name)
@@ -96,6 +128,15 @@ func New(log *slog.Logger, auth Authenticate) func(next http.Handler) http.Handl
 	}
 }
 
+// saveCall records a call in the background, so a slow store never holds up the response.
+func saveCall(log *slog.Logger, calls CallLog, call entity.ApiCall) {
+	go func() {
+		if err := calls.SaveApiCall(call); err != nil {
+			log.With(sl.Err(err)).Warn("save api call")
+		}
+	}()
+}
+
 func authFailed(w http.ResponseWriter, r *http.Request, message string) {
 	render.Status(r, http.StatusUnauthorized)
 	render.JSON(w, r, response.Error(message))
@@ -6,8 +6,11 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
+	"time"
 	"zohoclient/entity"
+	"zohoclient/internal/lib/api/cont"
 )
 
 // MockAuth implements the Authenticate interface for testing
@@ -116,7 +119,7 @@ func TestAuthenticate_TokenExtraction(t *testing.T) {
 
 			// Create the middleware with discard logger
 			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-			middleware := New(logger, mockAuth)
+			middleware := New(logger, mockAuth, nil, nil)
 			handler := middleware(testHandler)
 
 			// Create request
@@ -153,7 +156,7 @@ func TestAuthenticate_OPTIONSBypass(t *testing.T) {
 	})
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	middleware := New(logger, mockAuth)
+	middleware := New(logger, mockAuth, nil, nil)
 	handler := middleware(testHandler)
 
 	// Create OPTIONS request without auth header
@@ -177,7 +180,7 @@ func TestAuthenticate_NilAuth(t *testing.T) {
 	})
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	middleware := New(logger, nil)
+	middleware := New(logger, nil, nil, nil)
 	handler := middleware(testHandler)
 
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -189,3 +192,127 @@ func TestAuthenticate_NilAuth(t *testing.T) {
 		t.Errorf("Should return unauthorized when auth is nil, got %d", rec.Code)
 	}
 }
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		scopes         []string
+		expectedStatus int
+	}{
+		{"has scope", []string{entity.ScopePush}, http.StatusOK},
+		{"admin has every scope", []string{entity.ScopeAdmin}, http.StatusOK},
+		{"other scope only", []string{entity.ScopeWebhookB2B}, http.StatusForbidden},
+		{"no scopes", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := RequireScope(logger, entity.ScopePush)(testHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req = req.WithContext(cont.PutUser(req.Context(), &entity.UserAuth{Name: "client", Scopes: tt.scopes}))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+// callRecorder collects the audit records saved in the background.
+type callRecorder chan entity.ApiCall
+
+func (c callRecorder) SaveApiCall(call entity.ApiCall) error {
+	c <- call
+	return nil
+}
+
+func TestAuthenticate_AuditsCalls(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		wantStatus int
+		wantDenied bool
+	}{
+		{"scope granted", []string{entity.ScopePush}, http.StatusOK, false},
+		{"scope denied", []string{entity.ScopeWebhookB2B}, http.StatusForbidden, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &scopedAuth{user: entity.UserAuth{Name: "erp", Scopes: tt.scopes, Source: entity.TokenSourceConfig}}
+			calls := make(callRecorder, 1)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler := New(logger, auth, calls, nil)(RequireScope(logger, entity.ScopePush)(testHandler))
+
+			req := httptest.NewRequest(http.MethodGet, "/zoho/push/order/17103", nil)
+			req.Header.Set("Authorization", "Bearer erp-token")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			select {
+			case call := <-calls:
+				if call.Client != "erp" || call.TokenSource != entity.TokenSourceConfig || call.Method != http.MethodGet ||
+					call.Path != "/zoho/push/order/17103" || call.Scope != entity.ScopePush ||
+					call.Denied != tt.wantDenied || call.Status != tt.wantStatus {
+					t.Errorf("call = %+v, want erp %s with status %d, denied %v", call, entity.ScopePush, tt.wantStatus, tt.wantDenied)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("call not recorded")
+			}
+		})
+	}
+}
+
+// scopedAuth authenticates every token as user.
+type scopedAuth struct {
+	user entity.UserAuth
+}
+
+func (a *scopedAuth) AuthenticateByToken(string) (*entity.UserAuth, error) {
+	user := a.user
+	return &user, nil
+}
+
+func TestAuthenticate_AuditsClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{"forged header from a client", "203.0.113.7:5000", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:5000", "198.51.100.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &scopedAuth{user: entity.UserAuth{Name: "erp", Scopes: []string{entity.ScopePush}}}
+			calls := make(callRecorder, 1)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := New(logger, auth, calls, trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "/zoho/push/order/17103", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("Authorization", "Bearer erp-token")
+			req.Header.Set("X-Forwarded-For", "198.51.100.2")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			select {
+			case call := <-calls:
+				if call.RemoteAddr != tt.want {
+					t.Errorf("RemoteAddr = %q, want %q", call.RemoteAddr, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("call not recorded")
+			}
+		})
+	}
+}
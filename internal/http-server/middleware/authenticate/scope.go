@@ -0,0 +1,55 @@
+package authenticate
+
+import (
+	"log/slog"
+	"net/http"
+	"zohoclient/internal/lib/api/cont"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+	"zohoclient/internal/lib/sl"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// RequireScope rejects requests whose authenticated client lacks scope with 403, and notes the
+// decision on the request's audit record. It must run after New, which puts the client and the
+// record into the request context.
+func RequireScope(log *slog.Logger, scope string) func(next http.Handler) http.Handler {
+	mod := sl.Module("middleware.authenticate")
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user := cont.GetUser(r.Context())
+			granted := user.HasScope(scope)
+			if call := cont.GetApiCall(r.Context()); call != nil {
+				call.Scope = scope
+				call.Denied = !granted
+			}
+			if !granted {
+				log.With(
+					mod,
+					slog.String("client", user.Name),
+					slog.String("token_source", user.Source),
+					slog.String("scope", scope),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				).Warn("client lacks scope")
+				apiErr := apierrors.NewForbiddenError("Token lacks scope " + scope)
+				w.WriteHeader(apiErr.HTTPStatus)
+				render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
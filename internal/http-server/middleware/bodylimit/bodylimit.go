@@ -0,0 +1,65 @@
+// Package bodylimit rejects request bodies larger than a configured size with 413.
+package bodylimit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+	"zohoclient/internal/lib/sl"
+
+	"github.com/go-chi/render"
+)
+
+// New returns a middleware rejecting bodies over maxBytes. The body is read up front, so a
+// handler never sees a truncated payload; a zero maxBytes disables the check.
+func New(log *slog.Logger, maxBytes int64) func(next http.Handler) http.Handler {
+	mod := sl.Module("middleware.bodylimit")
+
+	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				tooLarge(log.With(mod), w, r, maxBytes)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					tooLarge(log.With(mod), w, r, maxBytes)
+					return
+				}
+				apiErr := apierrors.NewBadRequestError("Failed to read request body")
+				w.WriteHeader(apiErr.HTTPStatus)
+				render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func tooLarge(log *slog.Logger, w http.ResponseWriter, r *http.Request, maxBytes int64) {
+	log.Warn("request body too large",
+		slog.String("path", r.URL.Path),
+		slog.String("remote_addr", r.RemoteAddr),
+		slog.Int64("content_length", r.ContentLength),
+		slog.Int64("max_bytes", maxBytes),
+	)
+	apiErr := apierrors.NewEntityTooLargeError(fmt.Sprintf("Request body exceeds %d bytes", maxBytes))
+	w.WriteHeader(apiErr.HTTPStatus)
+	render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+}
@@ -0,0 +1,52 @@
+package bodylimit
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		unknownLength  bool
+		expectedStatus int
+	}{
+		{"under limit", "12345", false, http.StatusOK},
+		{"at limit", "1234567890", false, http.StatusOK},
+		{"over limit by content length", "12345678901", false, http.StatusRequestEntityTooLarge},
+		{"over limit without content length", "12345678901", true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				received = string(b)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := New(logger, 10)(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus == http.StatusOK && received != tt.body {
+				t.Errorf("next handler got body %q, want %q", received, tt.body)
+			}
+		})
+	}
+}
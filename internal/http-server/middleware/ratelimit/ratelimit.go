@@ -0,0 +1,158 @@
+// Package ratelimit throttles inbound requests with token buckets kept per API client and per
+// remote IP. A request must get a token from both buckets; otherwise it is answered with 429 and
+// a Retry-After header. The remote IP is the connection's, or the one X-Forwarded-For names when
+// the connection comes from a trusted proxy.
+package ratelimit
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+	"zohoclient/internal/lib/api/cont"
+	"zohoclient/internal/lib/api/response"
+	apierrors "zohoclient/internal/lib/errors"
+	"zohoclient/internal/lib/sl"
+	"zohoclient/internal/lib/util"
+
+	"github.com/go-chi/render"
+	"golang.org/x/time/rate"
+)
+
+// Limits configures one route group. Rate is the sustained number of requests per second, Burst
+// how many may arrive at once. A zero Rate disables limiting. TrustedProxies are the reverse
+// proxies whose X-Forwarded-For is believed; without them requests are limited by the address
+// they come from.
+type Limits struct {
+	Rate           float64
+	Burst          int
+	TrustedProxies []netip.Prefix
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type store struct {
+	limits    Limits
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	// idle is how long a bucket takes to refill. A bucket unused for that long is full, the same
+	// as a new one, and is dropped.
+	idle time.Duration
+	now  func() time.Time
+}
+
+func newStore(limits Limits) *store {
+	s := &store{
+		limits:  limits,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+	if limits.Rate > 0 {
+		s.idle = time.Duration(float64(limits.Burst) / limits.Rate * float64(time.Second))
+	}
+	return s
+}
+
+// reserve takes a token from the bucket of every key. When one of them is empty nothing is
+// consumed and the wait until a token is available is returned.
+func (s *store) reserve(keys ...string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	reservations := make([]*rate.Reservation, 0, len(keys))
+	var wait time.Duration
+	for _, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{limiter: rate.NewLimiter(rate.Limit(s.limits.Rate), s.limits.Burst)}
+			s.buckets[key] = b
+		}
+		b.lastSeen = now
+
+		res := b.limiter.ReserveN(now, 1)
+		reservations = append(reservations, res)
+		if !res.OK() {
+			wait = time.Second
+			continue
+		}
+		wait = max(wait, res.DelayFrom(now))
+	}
+
+	if wait > 0 {
+		for _, res := range reservations {
+			res.CancelAt(now)
+		}
+	}
+	return wait
+}
+
+// sweep drops the buckets that have refilled, once per refill period. The map thus holds only
+// the keys seen within the last period or two, however many a client makes up.
+func (s *store) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.idle {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.lastSeen) >= s.idle {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// New returns a middleware applying limits to the route group it is mounted on. Each call keeps
+// its own buckets, so route groups are limited independently. It must run after the
+// authenticate middleware, which identifies the client.
+func New(log *slog.Logger, limits Limits) func(next http.Handler) http.Handler {
+	mod := sl.Module("middleware.ratelimit")
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+	s := newStore(limits)
+
+	return func(next http.Handler) http.Handler {
+		if limits.Rate <= 0 {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			remote := util.ClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), limits.TrustedProxies)
+			keys := []string{"ip:" + remote}
+			user := cont.GetUser(r.Context())
+			if user.Name != "" {
+				keys = append(keys, "client:"+user.Name)
+			}
+
+			if wait := s.reserve(keys...); wait > 0 {
+				retryAfter := int(math.Ceil(wait.Seconds()))
+				log.With(
+					mod,
+					slog.String("user", user.Name),
+					slog.String("remote_addr", remote),
+					slog.String("path", r.URL.Path),
+					slog.Int("retry_after", retryAfter),
+				).Warn("rate limit exceeded")
+
+				apiErr := apierrors.NewRateLimitError("Too many requests, retry later")
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				w.WriteHeader(apiErr.HTTPStatus)
+				render.JSON(w, r, response.ErrorFromAPIError(apiErr))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
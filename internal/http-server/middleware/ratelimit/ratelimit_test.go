@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/api/cont"
+	"zohoclient/internal/lib/util"
+)
+
+func TestStore_Reserve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newStore(Limits{Rate: 1, Burst: 2})
+	s.now = func() time.Time { return now }
+
+	if wait := s.reserve("ip:a"); wait != 0 {
+		t.Fatalf("first request wait = %v, want 0", wait)
+	}
+	if wait := s.reserve("ip:a"); wait != 0 {
+		t.Fatalf("second request (burst) wait = %v, want 0", wait)
+	}
+	if wait := s.reserve("ip:a"); wait != time.Second {
+		t.Fatalf("third request wait = %v, want 1s", wait)
+	}
+	if wait := s.reserve("ip:b"); wait != 0 {
+		t.Errorf("other key wait = %v, want 0", wait)
+	}
+
+	now = now.Add(time.Second)
+	if wait := s.reserve("ip:a"); wait != 0 {
+		t.Errorf("request after refill wait = %v, want 0", wait)
+	}
+}
+
+// A request refused by one bucket must not use up a token from the other.
+func TestStore_ReserveAllOrNothing(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newStore(Limits{Rate: 1, Burst: 1})
+	s.now = func() time.Time { return now }
+
+	s.reserve("client:zoho")
+	if wait := s.reserve("ip:a", "client:zoho"); wait == 0 {
+		t.Fatal("expected the exhausted client bucket to refuse")
+	}
+	if wait := s.reserve("ip:a"); wait != 0 {
+		t.Errorf("ip bucket wait = %v, want 0: refused request consumed its token", wait)
+	}
+}
+
+func TestStore_SweepsIdleBuckets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newStore(Limits{Rate: 1, Burst: 1})
+	s.now = func() time.Time { return now }
+
+	s.reserve("ip:a")
+	now = now.Add(500 * time.Millisecond)
+	s.reserve("ip:b")
+	now = now.Add(600 * time.Millisecond)
+	s.reserve("ip:c")
+
+	// ip:a has refilled and goes; ip:b has not.
+	if _, ok := s.buckets["ip:a"]; ok {
+		t.Error("refilled bucket not dropped")
+	}
+	if _, ok := s.buckets["ip:b"]; !ok {
+		t.Error("bucket still refilling was dropped")
+	}
+}
+
+// X-Forwarded-For is the client's own text unless a trusted proxy set it: rotating it must not
+// get a client fresh buckets.
+func TestNew_ForwardedFor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	proxies, err := util.ParseTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := New(logger, Limits{Rate: 0.1, Burst: 1, TrustedProxies: proxies})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(remote, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+		req.RemoteAddr = remote + ":1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name         string
+		remote       string
+		forwardedFor string
+		want         int
+	}{
+		{"direct client", "203.0.113.5", "1.1.1.1", http.StatusOK},
+		{"direct client rotating the header", "203.0.113.5", "2.2.2.2", http.StatusTooManyRequests},
+		{"client behind the proxy", "10.0.0.1", "198.51.100.7", http.StatusOK},
+		{"another client behind the proxy", "10.0.0.1", "198.51.100.8", http.StatusOK},
+		{"spoofed entry left of the real one", "10.0.0.1", "9.9.9.9, 198.51.100.7", http.StatusTooManyRequests},
+		{"chain of trusted proxies", "10.0.0.1", "198.51.100.9, 192.168.1.1", http.StatusOK},
+		{"same client through the chain", "192.168.1.1", "198.51.100.9", http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		if got := send(tt.remote, tt.forwardedFor); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := New(logger, Limits{Rate: 0.1, Burst: 1})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(user string, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+		req.RemoteAddr = ip + ":1234"
+		if user != "" {
+			req = req.WithContext(cont.PutUser(req.Context(), &entity.UserAuth{Name: user}))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send("zoho", "10.0.0.1"); rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", rec.Code)
+	}
+
+	rec := send("zoho", "10.0.0.2")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("same client from another IP status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "10" {
+		t.Errorf("Retry-After = %q, want 10", got)
+	}
+
+	if rec := send("", "10.0.0.1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("same IP without client status = %d, want 429", rec.Code)
+	}
+	if rec := send("portal", "10.0.0.3"); rec.Code != http.StatusOK {
+		t.Errorf("other client and IP status = %d, want 200", rec.Code)
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := New(logger, Limits{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package signature
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTimestamp(body []byte) (time.Time, error) {
+	var payload struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	err := json.Unmarshal(body, &payload)
+	return payload.Timestamp, err
+}
+
+func testBody(ts time.Time) string {
+	return `{"event":"order_confirmed","timestamp":"` + ts.Format(time.RFC3339) + `"}`
+}
+
+func TestHMAC(t *testing.T) {
+	now := time.Now()
+	body := testBody(now)
+
+	tests := []struct {
+		name           string
+		body           string
+		signature      string
+		expectedStatus int
+	}{
+		{"valid signature", body, Sign([]byte(body), "current"), http.StatusOK},
+		{"sha256 prefix", body, "sha256=" + Sign([]byte(body), "current"), http.StatusOK},
+		{"previous secret during rotation", body, Sign([]byte(body), "previous"), http.StatusOK},
+		{"unknown secret", body, Sign([]byte(body), "other"), http.StatusUnauthorized},
+		{"missing signature", body, "", http.StatusUnauthorized},
+		{"malformed signature", body, "not-hex", http.StatusUnauthorized},
+		{"tampered body", strings.Replace(body, "confirmed", "cancelled", 1), Sign([]byte(body), "current"), http.StatusUnauthorized},
+		{"replayed old delivery", testBody(now.Add(-10 * time.Minute)), Sign([]byte(testBody(now.Add(-10*time.Minute))), "current"), http.StatusUnauthorized},
+		{"timestamp too far ahead", testBody(now.Add(10 * time.Minute)), Sign([]byte(testBody(now.Add(10*time.Minute))), "current"), http.StatusUnauthorized},
+		{"missing timestamp", `{"event":"order_confirmed"}`, Sign([]byte(`{"event":"order_confirmed"}`), "current"), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				received = string(b)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := HMAC(logger, Options{
+				Secrets:   []string{"current", "previous"},
+				Tolerance: 5 * time.Minute,
+				Timestamp: testTimestamp,
+			})(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(DefaultHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus == http.StatusOK && received != tt.body {
+				t.Errorf("next handler got body %q, want %q", received, tt.body)
+			}
+		})
+	}
+}
+
+func TestHMAC_DisabledWithoutSecrets(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := HMAC(logger, Options{Secrets: []string{"", " "}})(next)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestSharedSecret(t *testing.T) {
+	tests := []struct {
+		name           string
+		secrets        []string
+		header         string
+		expectedStatus int
+	}{
+		{"matching secret", []string{"s1"}, "s1", http.StatusOK},
+		{"second secret during rotation", []string{"s1", "s2"}, "s2", http.StatusOK},
+		{"wrong secret", []string{"s1"}, "s3", http.StatusUnauthorized},
+		{"missing header", []string{"s1"}, "", http.StatusUnauthorized},
+		{"verification disabled", nil, "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := SharedSecret(logger, "", tt.secrets)(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			if tt.header != "" {
+				req.Header.Set(DefaultSecretHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
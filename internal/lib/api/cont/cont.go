@@ -9,6 +9,8 @@ type ctxKey string
 
 const UserDataKey ctxKey = "userData"
 
+const apiCallKey ctxKey = "apiCall"
+
 func PutUser(c context.Context, user *entity.UserAuth) context.Context {
 	return context.WithValue(c, UserDataKey, *user)
 }
@@ -20,3 +22,15 @@ func GetUser(c context.Context) *entity.UserAuth {
 	}
 	return &user
 }
+
+// PutApiCall stores the audit record of the request, for the middlewares after authentication
+// to complete.
+func PutApiCall(c context.Context, call *entity.ApiCall) context.Context {
+	return context.WithValue(c, apiCallKey, call)
+}
+
+// GetApiCall returns the audit record of the request, or nil when it is not audited.
+func GetApiCall(c context.Context) *entity.ApiCall {
+	call, _ := c.Value(apiCallKey).(*entity.ApiCall)
+	return call
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"zohoclient/bot"
+)
+
+// alertKey is the fingerprint of an alert: records that differ only in other attributes (order
+// id, email, ...) are repeats of the same problem.
+type alertKey struct {
+	level   slog.Level
+	module  string
+	message string
+	err     string
+}
+
+type alertEntry struct {
+	since      time.Time
+	suppressed int
+}
+
+// alertSummary is one line of a digest: an alert and how often it repeated unsent.
+type alertSummary struct {
+	key   alertKey
+	count int
+}
+
+// alertDeduper lets the first record of a fingerprint through and counts its repeats for the
+// rest of the window; flush turns the counts into digest lines. One deduper is shared by every
+// handler derived from the same TelegramHandler.
+type alertDeduper struct {
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[alertKey]*alertEntry
+}
+
+func newAlertDeduper(window time.Duration) *alertDeduper {
+	return &alertDeduper{
+		window:  window,
+		entries: make(map[alertKey]*alertEntry),
+	}
+}
+
+// admit reports whether an alert should be sent now. A repeat inside the window is counted
+// instead.
+func (d *alertDeduper) admit(key alertKey, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[key]
+	if ok && now.Sub(entry.since) < d.window {
+		entry.suppressed++
+		return false
+	}
+	d.entries[key] = &alertEntry{since: now}
+	return true
+}
+
+// flush forgets the fingerprints whose window has ended and returns those that repeated, most
+// frequent first.
+func (d *alertDeduper) flush(now time.Time) []alertSummary {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var summaries []alertSummary
+	for key, entry := range d.entries {
+		if now.Sub(entry.since) < d.window {
+			continue
+		}
+		if entry.suppressed > 0 {
+			summaries = append(summaries, alertSummary{key: key, count: entry.suppressed})
+		}
+		delete(d.entries, key)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].count != summaries[j].count {
+			return summaries[i].count > summaries[j].count
+		}
+		return summaries[i].key.message < summaries[j].key.message
+	})
+	return summaries
+}
+
+// formatDigest renders the digest of one level as a MarkdownV2 message.
+func formatDigest(level slog.Level, window time.Duration, summaries []alertSummary) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*%s* `digest`", level.String()))
+	for _, s := range summaries {
+		line := fmt.Sprintf("×%d in last %s: %s", s.count, formatWindow(window), s.key.message)
+		if s.key.module != "" {
+			line += fmt.Sprintf(" [%s]", s.key.module)
+		}
+		if s.key.err != "" {
+			line += ": " + s.key.err
+		}
+		sb.WriteString(bot.Sanitize("\n" + line))
+	}
+	return sb.String()
+}
+
+// formatWindow prints whole minutes as "5 min" and anything else as a duration.
+func formatWindow(window time.Duration) string {
+	if window >= time.Minute && window%time.Minute == 0 {
+		return fmt.Sprintf("%d min", int(window/time.Minute))
+	}
+	return window.String()
+}
+
+// runDigest flushes the deduper on a ticker and sends one digest per level that had repeats.
+func (h *TelegramHandler) runDigest() {
+	// Flushing a few times per window keeps a digest no more than a fifth of a window late.
+	ticker := time.NewTicker(h.dedup.window / 5)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		h.sendDigest(now)
+	}
+}
+
+func (h *TelegramHandler) sendDigest(now time.Time) {
+	byLevel := make(map[slog.Level][]alertSummary)
+	for _, s := range h.dedup.flush(now) {
+		byLevel[s.key.level] = append(byLevel[s.key.level], s)
+	}
+	for level, summaries := range byLevel {
+		h.bot.SendMessageWithLevel(formatDigest(level, h.dedup.window, summaries), level)
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/internal/lib/sl"
+)
+
+func TestAlertDeduper(t *testing.T) {
+	d := newAlertDeduper(5 * time.Minute)
+	now := time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+	contact := alertKey{level: slog.LevelError, module: "core", message: "create contact", err: "zoho down"}
+	other := alertKey{level: slog.LevelError, module: "core", message: "create order", err: "zoho down"}
+
+	if !d.admit(contact, now) {
+		t.Fatal("first alert suppressed")
+	}
+	for i := 1; i <= 37; i++ {
+		if d.admit(contact, now.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("repeat %d sent", i)
+		}
+	}
+	if !d.admit(other, now.Add(time.Minute)) {
+		t.Error("different message suppressed")
+	}
+
+	if got := d.flush(now.Add(4 * time.Minute)); len(got) != 0 {
+		t.Errorf("flush inside the window = %v, want nothing", got)
+	}
+
+	got := d.flush(now.Add(5 * time.Minute))
+	if len(got) != 1 || got[0].key != contact || got[0].count != 37 {
+		t.Fatalf("flush = %+v, want create contact x37", got)
+	}
+
+	// The window is over: the next occurrence is news again.
+	if !d.admit(contact, now.Add(6*time.Minute)) {
+		t.Error("alert after the window suppressed")
+	}
+	// "create order" never repeated: its window ends without a digest line.
+	if got = d.flush(now.Add(6 * time.Minute)); len(got) != 0 {
+		t.Errorf("flush = %+v, want nothing for an alert that did not repeat", got)
+	}
+}
+
+func TestFormatDigest(t *testing.T) {
+	got := formatDigest(slog.LevelError, 5*time.Minute, []alertSummary{
+		{key: alertKey{message: "create contact", module: "core", err: "status 500"}, count: 37},
+		{key: alertKey{message: "fetch customers"}, count: 2},
+	})
+
+	for _, want := range []string{
+		"*ERROR* `digest`",
+		"×37 in last 5 min: create contact \\[core\\]: status 500",
+		"×2 in last 5 min: fetch customers",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatDigest() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestTelegramHandler_AlertKey(t *testing.T) {
+	base := NewTelegramHandler(slog.NewTextHandler(io.Discard, nil), nil, slog.LevelDebug, time.Minute)
+	h := base.WithAttrs([]slog.Attr{sl.Module("core")}).(*TelegramHandler)
+	if h.dedup != base.dedup {
+		t.Fatal("derived handler does not share the deduper")
+	}
+
+	record := slog.NewRecord(time.Now(), slog.LevelError, "create contact", 0)
+	record.AddAttrs(slog.Int64("order_id", 1), sl.Err(errors.New("zoho down")))
+
+	key := h.alertKey(record)
+	want := alertKey{level: slog.LevelError, module: "core", message: "create contact", err: "zoho down"}
+	if key != want {
+		t.Errorf("alertKey() = %+v, want %+v", key, want)
+	}
+
+	// Only the first of two records that differ in other attributes gets through.
+	if err := h.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	second := slog.NewRecord(time.Now(), slog.LevelError, "create contact", 0)
+	second.AddAttrs(slog.Int64("order_id", 2), sl.Err(errors.New("zoho down")))
+	if h.dedup.admit(h.alertKey(second), time.Now()) {
+		t.Error("repeat with a different order id admitted")
+	}
+}
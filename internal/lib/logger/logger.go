@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 	"zohoclient/bot"
 )
 
@@ -53,8 +54,9 @@ func logFilePath(path string) string {
 	return filepath.Join(path, logFileName)
 }
 
-// SetupTelegramHandler adds a Telegram handler to the logger
-func SetupTelegramHandler(logger *slog.Logger, tgBot *bot.TgBot, minLevel slog.Level) *slog.Logger {
+// SetupTelegramHandler adds a Telegram handler to the logger. A positive dedupWindow collapses
+// repeated alerts into a digest sent once the window has passed.
+func SetupTelegramHandler(logger *slog.Logger, tgBot *bot.TgBot, minLevel slog.Level, dedupWindow time.Duration) *slog.Logger {
 	if tgBot == nil {
 		return logger
 	}
@@ -63,7 +65,10 @@ func SetupTelegramHandler(logger *slog.Logger, tgBot *bot.TgBot, minLevel slog.L
 	existingHandler := logger.Handler()
 
 	// Create a new Telegram handler that wraps the existing handler
-	tgHandler := NewTelegramHandler(existingHandler, tgBot, minLevel)
+	tgHandler := NewTelegramHandler(existingHandler, tgBot, minLevel, dedupWindow)
+	if tgHandler.dedup != nil {
+		go tgHandler.runDigest()
+	}
 
 	// Create a new logger with the Telegram handler
 	return slog.New(tgHandler)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 	"zohoclient/bot"
 )
 
@@ -16,17 +17,24 @@ type TelegramHandler struct {
 	mu       sync.Mutex
 	attrs    []slog.Attr
 	group    string
+	dedup    *alertDeduper
 }
 
-// NewTelegramHandler creates a new TelegramHandler
-func NewTelegramHandler(handler slog.Handler, bot *bot.TgBot, minLevel slog.Level) *TelegramHandler {
-	return &TelegramHandler{
+// NewTelegramHandler creates a new TelegramHandler. With a positive dedupWindow, repeats of an
+// alert (same level, message, module and error) within the window are not sent but summarized
+// in a digest; see runDigest.
+func NewTelegramHandler(handler slog.Handler, bot *bot.TgBot, minLevel slog.Level, dedupWindow time.Duration) *TelegramHandler {
+	h := &TelegramHandler{
 		handler:  handler,
 		bot:      bot,
 		minLevel: minLevel,
 		attrs:    make([]slog.Attr, 0),
 		group:    "",
 	}
+	if dedupWindow > 0 {
+		h.dedup = newAlertDeduper(dedupWindow)
+	}
+	return h
 }
 
 // Enabled implements slog.Handler.Enabled
@@ -44,6 +52,10 @@ func (h *TelegramHandler) Handle(ctx context.Context, record slog.Record) error
 
 	// If the level is high enough, send to Telegram
 	if record.Level >= h.minLevel {
+		if h.dedup != nil && !h.dedup.admit(h.alertKey(record), time.Now()) {
+			return nil
+		}
+
 		h.mu.Lock()
 		defer h.mu.Unlock()
 
@@ -81,6 +93,28 @@ func (h *TelegramHandler) Handle(ctx context.Context, record slog.Record) error
 	return nil
 }
 
+// alertKey fingerprints a record by level, message, module and error.
+func (h *TelegramHandler) alertKey(record slog.Record) alertKey {
+	key := alertKey{level: record.Level, message: record.Message}
+	if h.group != "" {
+		key.message = h.group + "." + record.Message
+	}
+	pick := func(attr slog.Attr) bool {
+		switch attr.Key {
+		case "mod":
+			key.module = attr.Value.String()
+		case "error":
+			key.err = attr.Value.String()
+		}
+		return true
+	}
+	for _, attr := range h.attrs {
+		pick(attr)
+	}
+	record.Attrs(pick)
+	return key
+}
+
 // WithAttrs implements slog.Handler.WithAttrs
 func (h *TelegramHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	// Create a new handler with the combined attributes
@@ -95,6 +129,7 @@ func (h *TelegramHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		mu:       sync.Mutex{},
 		attrs:    newAttrs,
 		group:    h.group,
+		dedup:    h.dedup,
 	}
 }
 
@@ -114,5 +149,6 @@ func (h *TelegramHandler) WithGroup(name string) slog.Handler {
 		mu:       sync.Mutex{},
 		attrs:    h.attrs,
 		group:    group,
+		dedup:    h.dedup,
 	}
 }
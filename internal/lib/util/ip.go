@@ -1,7 +1,9 @@
 package util
 
 import (
+	"fmt"
 	"net"
+	"net/netip"
 	"strings"
 )
 
@@ -34,3 +36,65 @@ func ExtractIPAddress(remoteAddr string, xForwardedFor string) string {
 
 	return ""
 }
+
+// ParseTrustedProxies parses the addresses and networks of reverse proxies, as "10.0.0.1" or
+// "10.0.0.0/8".
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", value, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", value, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIP returns the address of the client that sent a request. Unlike ExtractIPAddress it
+// cannot be spoofed: X-Forwarded-For is only read when the connection comes from a trusted
+// proxy, and then from the right, skipping the trusted proxies, since the entries on the left
+// are whatever the client chose to send.
+func ClientIP(remoteAddr, xForwardedFor string, trusted []netip.Prefix) string {
+	remote := ExtractIPAddress(remoteAddr, "")
+	if xForwardedFor == "" || !isTrustedProxy(remote, trusted) {
+		return remote
+	}
+
+	hops := strings.Split(xForwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := ExtractIPAddress("", strings.TrimSpace(hops[i]))
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// Garbage in the header: the last address known to be genuine is the proxy's.
+			return remote
+		}
+		if !isTrustedProxy(hop, trusted) {
+			return hop
+		}
+		remote = hop
+	}
+	return remote
+}
+
+func isTrustedProxy(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -29,6 +29,12 @@ func getValidator() *validator.Validate {
 	return instance
 }
 
+// RegisterType validates fields of the given types as the value fn returns for them, so a
+// type wrapping a number can carry the numeric tags (min, gt) of the number it wraps.
+func RegisterType(fn func(field reflect.Value) any, types ...any) {
+	getValidator().RegisterCustomTypeFunc(fn, types...)
+}
+
 // Struct validates a single struct object
 func Struct(s interface{}) error {
 	if s == nil {
@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"zohoclient/entity"
+)
+
+// Email sends each event as a plain-text mail. The server must accept SMTP with STARTTLS
+// (usually port 587); credentials are optional for a relay that does not need them.
+type Email struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmail(host string, port int, username, password, from string, to []string) *Email {
+	e := &Email{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+		to:   to,
+		send: smtp.SendMail,
+	}
+	if username != "" {
+		e.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return e
+}
+
+func (e *Email) SendEventMessage(msg *entity.EventMessage) error {
+	body, err := e.message(msg)
+	if err != nil {
+		return err
+	}
+	if err = e.send(e.addr, e.auth, e.from, e.to, body); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+// message builds the RFC 5322 mail: the event text, then its payload as indented JSON.
+func (e *Email) message(msg *entity.EventMessage) ([]byte, error) {
+	sent := msg.Time
+	if sent.IsZero() {
+		sent = time.Now()
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", sent.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	fmt.Fprintf(&b, "X-Event-Type: %s\r\n\r\n", msg.Type)
+
+	if msg.Text != "" {
+		b.WriteString(msg.Text)
+		b.WriteString("\r\n")
+	}
+	if msg.Payload != nil {
+		payload, err := json.MarshalIndent(msg.Payload, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshal event payload: %w", err)
+		}
+		b.WriteString("\r\n")
+		b.Write(payload)
+		b.WriteString("\r\n")
+	}
+	return b.Bytes(), nil
+}
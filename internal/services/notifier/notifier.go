@@ -0,0 +1,105 @@
+// Package notifier delivers the events of the sync core (entity.EventMessage) to Telegram,
+// email and outbound webhooks.
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+	"zohoclient/internal/lib/sl"
+)
+
+// Notifier delivers one event. It is the core's MessageService.
+type Notifier interface {
+	SendEventMessage(msg *entity.EventMessage) error
+}
+
+// New builds the notifiers enabled in the config behind one FanOut, or returns nil when none
+// is. tg may be nil when the Telegram bot is disabled. In Zoho shadow mode only Telegram is
+// built: email recipients and remote endpoints must not hear of records that were never
+// created, while the admins see the events marked as shadow ones.
+func New(conf *config.Config, tg TelegramSender, log *slog.Logger, shadow bool) (*FanOut, error) {
+	log = log.With(sl.Module("notifier"))
+	var notifiers []Notifier
+
+	if c := conf.Notify.Telegram; c.Enabled {
+		if tg == nil {
+			log.Warn("telegram notifications enabled but the telegram bot is not")
+		} else {
+			notifiers = append(notifiers, Only(NewTelegram(tg), c.Events))
+		}
+	}
+
+	if c := conf.Notify.Email; c.Enabled && shadow {
+		log.Info("email notifications are off in zoho shadow mode")
+	} else if c.Enabled {
+		if c.Host == "" || c.From == "" || len(c.To) == 0 {
+			return nil, fmt.Errorf("email notifier needs host, from and to")
+		}
+		notifiers = append(notifiers, Only(NewEmail(c.Host, c.Port, c.Username, c.Password, c.From, c.To), c.Events))
+	}
+
+	if c := conf.Notify.Webhook; c.Enabled && shadow {
+		log.Info("webhook notifications are off in zoho shadow mode")
+	} else if c.Enabled {
+		if c.Url == "" || c.Secret == "" {
+			return nil, fmt.Errorf("webhook notifier needs url and secret")
+		}
+		timeout := time.Duration(c.Timeout) * time.Second
+		notifiers = append(notifiers, Only(NewWebhook(c.Url, c.Secret, c.Header, timeout), c.Events))
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+	log.With(slog.Int("channels", len(notifiers))).Info("event notifications enabled")
+	return NewFanOut(notifiers...), nil
+}
+
+// FanOut sends every event to all of its notifiers. One failing channel does not keep the
+// event from the others; their errors are returned together.
+type FanOut struct {
+	notifiers []Notifier
+}
+
+func NewFanOut(notifiers ...Notifier) *FanOut {
+	return &FanOut{notifiers: notifiers}
+}
+
+func (f *FanOut) SendEventMessage(msg *entity.EventMessage) error {
+	var errs []error
+	for _, n := range f.notifiers {
+		if err := n.SendEventMessage(msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// filtered passes on only the listed event types.
+type filtered struct {
+	next   Notifier
+	events map[string]bool
+}
+
+// Only restricts a notifier to the given event types; with none it returns n unchanged.
+func Only(n Notifier, events []string) Notifier {
+	if len(events) == 0 {
+		return n
+	}
+	f := &filtered{next: n, events: make(map[string]bool, len(events))}
+	for _, e := range events {
+		f.events[e] = true
+	}
+	return f
+}
+
+func (f *filtered) SendEventMessage(msg *entity.EventMessage) error {
+	if !f.events[msg.Type] {
+		return nil
+	}
+	return f.next.SendEventMessage(msg)
+}
@@ -0,0 +1,144 @@
+package notifier
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+)
+
+type recorder struct {
+	got []string
+	err error
+}
+
+func (r *recorder) SendEventMessage(msg *entity.EventMessage) error {
+	r.got = append(r.got, msg.Type)
+	return r.err
+}
+
+func TestFanOut_SendsToAllAndJoinsErrors(t *testing.T) {
+	failing := &recorder{err: errors.New("smtp down")}
+	ok := &recorder{}
+	fan := NewFanOut(failing, ok)
+
+	err := fan.SendEventMessage(&entity.EventMessage{Type: entity.EventOrderCreated})
+	if err == nil || !strings.Contains(err.Error(), "smtp down") {
+		t.Errorf("error = %v, want the failing channel's error", err)
+	}
+	if len(ok.got) != 1 {
+		t.Errorf("healthy channel got %v, want the event despite the other failing", ok.got)
+	}
+}
+
+func TestOnly(t *testing.T) {
+	r := &recorder{}
+	n := Only(r, []string{entity.EventSyncFailure})
+	for _, typ := range []string{entity.EventOrderCreated, entity.EventSyncFailure, entity.EventPaymentLinked} {
+		_ = n.SendEventMessage(&entity.EventMessage{Type: typ})
+	}
+	if len(r.got) != 1 || r.got[0] != entity.EventSyncFailure {
+		t.Errorf("got %v, want only sync_failure", r.got)
+	}
+
+	if all := Only(r, nil); all != Notifier(r) {
+		t.Error("Only without events should return the notifier unchanged")
+	}
+}
+
+type levelRecorder struct {
+	msg   string
+	level slog.Level
+}
+
+func (l *levelRecorder) SendMessageWithLevel(msg string, level slog.Level) {
+	l.msg, l.level = msg, level
+}
+
+func TestTelegram_Levels(t *testing.T) {
+	sender := &levelRecorder{}
+	tg := NewTelegram(sender)
+
+	_ = tg.SendEventMessage(&entity.EventMessage{Type: entity.EventOrderCreated, Subject: "Order 1 created in Zoho", Text: "100.00 PLN"})
+	if sender.level != slog.LevelInfo {
+		t.Errorf("order_created level = %v, want info", sender.level)
+	}
+	if !strings.Contains(sender.msg, `*Order 1 created in Zoho*`) || !strings.Contains(sender.msg, `100\.00 PLN`) {
+		t.Errorf("message %q is not escaped MarkdownV2 with subject and text", sender.msg)
+	}
+
+	_ = tg.SendEventMessage(&entity.EventMessage{Type: entity.EventSyncFailure, Subject: "Customer 5 not synced"})
+	if sender.level != slog.LevelError {
+		t.Errorf("sync_failure level = %v, want error", sender.level)
+	}
+
+	_ = tg.SendEventMessage(&entity.EventMessage{Type: entity.EventOrderCreated, Subject: "Order 1 created in Zoho", Shadow: true})
+	if !strings.HasPrefix(sender.msg, `\[shadow\] *Order 1`) {
+		t.Errorf("shadow event %q, want it marked", sender.msg)
+	}
+}
+
+// In shadow mode the events name records that were never created: they reach the admins on
+// Telegram only.
+func TestNew_ShadowKeepsTelegramOnly(t *testing.T) {
+	conf := &config.Config{}
+	conf.Notify.Telegram.Enabled = true
+	conf.Notify.Email.Enabled = true
+	conf.Notify.Email.Host, conf.Notify.Email.From, conf.Notify.Email.To = "smtp.example.com", "sync@example.com", []string{"ops@example.com"}
+	conf.Notify.Webhook.Enabled = true
+	conf.Notify.Webhook.Url, conf.Notify.Webhook.Secret = "https://example.com/hook", "secret"
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	live, err := New(conf, &levelRecorder{}, log, false)
+	if err != nil || len(live.notifiers) != 3 {
+		t.Fatalf("live = %v, %v; want three channels", live, err)
+	}
+	shadow, err := New(conf, &levelRecorder{}, log, true)
+	if err != nil || len(shadow.notifiers) != 1 {
+		t.Fatalf("shadow = %v, %v; want one channel", shadow, err)
+	}
+	if _, ok := shadow.notifiers[0].(*Telegram); !ok {
+		t.Errorf("shadow channel = %T, want Telegram", shadow.notifiers[0])
+	}
+}
+
+func TestEmail_Message(t *testing.T) {
+	e := NewEmail("smtp.example.com", 587, "user", "pass", "sync@example.com", []string{"ops@example.com", "boss@example.com"})
+	var addr string
+	var to []string
+	var body []byte
+	e.send = func(a string, _ smtp.Auth, _ string, rcpt []string, msg []byte) error {
+		addr, to, body = a, rcpt, msg
+		return nil
+	}
+
+	err := e.SendEventMessage(&entity.EventMessage{
+		Type:    entity.EventPaymentLinked,
+		Subject: "Płatność 17103",
+		Time:    time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Text:    "468.00 PLN",
+		Payload: entity.OrderEvent{OrderId: 17103, PaymentId: "PAY-1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != "smtp.example.com:587" || len(to) != 2 {
+		t.Errorf("sent to %s %v", addr, to)
+	}
+	for _, want := range []string{
+		"To: ops@example.com, boss@example.com\r\n",
+		"Subject: =?utf-8?q?P=C5=82atno=C5=9B=C4=87_17103?=\r\n",
+		"Date: Sun, 18 Oct 2026 09:00:00 +0000\r\n",
+		"X-Event-Type: payment_linked\r\n\r\n468.00 PLN",
+		`"payment_id": "PAY-1"`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("mail missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package notifier
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"zohoclient/bot"
+	"zohoclient/entity"
+)
+
+// TelegramSender is the bot's admin broadcast.
+type TelegramSender interface {
+	SendMessageWithLevel(msg string, level slog.Level)
+}
+
+// Telegram sends events to the bot admins. Sync failures go out at error level, everything
+// else at info, so each admin's /level decides what they see.
+type Telegram struct {
+	sender TelegramSender
+}
+
+func NewTelegram(sender TelegramSender) *Telegram {
+	return &Telegram{sender: sender}
+}
+
+func (t *Telegram) SendEventMessage(msg *entity.EventMessage) error {
+	level := slog.LevelInfo
+	if msg.Type == entity.EventSyncFailure {
+		level = slog.LevelError
+	}
+	t.sender.SendMessageWithLevel(formatTelegram(msg), level)
+	return nil
+}
+
+// formatTelegram renders an event as a MarkdownV2 message.
+func formatTelegram(msg *entity.EventMessage) string {
+	var sb strings.Builder
+	if msg.Shadow {
+		sb.WriteString(bot.Sanitize("[shadow] "))
+	}
+	sb.WriteString(fmt.Sprintf("*%s* `%s`", bot.Sanitize(msg.Subject), bot.Sanitize(msg.Type)))
+	if text := strings.TrimSpace(msg.Text); text != "" {
+		sb.WriteString(bot.Sanitize("\n" + text))
+	}
+	return sb.String()
+}
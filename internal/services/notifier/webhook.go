@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/http-server/middleware/signature"
+	"zohoclient/internal/lib/httputil"
+)
+
+// Webhook POSTs each event as JSON to a URL, signed the same way inbound webhooks are
+// verified: the header carries "sha256=" and the hex HMAC-SHA256 of the body. The receiver
+// checks the event "time" to reject replays.
+type Webhook struct {
+	url    string
+	secret string
+	header string
+	client *http.Client
+}
+
+func NewWebhook(url, secret, header string, timeout time.Duration) *Webhook {
+	return &Webhook{
+		url:    url,
+		secret: secret,
+		header: header,
+		client: httputil.NewHTTPClient(timeout),
+	}
+}
+
+func (w *Webhook) SendEventMessage(msg *entity.EventMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", msg.Type)
+	req.Header.Set(w.header, "sha256="+signature.Sign(body, w.secret))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, bytes.TrimSpace(reply))
+	}
+	return nil
+}
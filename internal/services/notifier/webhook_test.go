@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/http-server/middleware/signature"
+)
+
+func TestWebhook_SignsBody(t *testing.T) {
+	var gotSig, gotType string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Signature")
+		gotType = r.Header.Get("X-Event-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w := NewWebhook(srv.URL, "s3cret", "X-Signature", time.Second)
+	err := w.SendEventMessage(&entity.EventMessage{
+		Type:    entity.EventOrderUpdated,
+		Subject: "Order 17103 updated from Zoho",
+		Payload: entity.OrderEvent{OrderId: 17103, StatusId: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "sha256=" + signature.Sign(gotBody, "s3cret"); gotSig != want {
+		t.Errorf("signature = %q, want %q", gotSig, want)
+	}
+	if gotType != entity.EventOrderUpdated {
+		t.Errorf("X-Event-Type = %q", gotType)
+	}
+	var decoded struct {
+		Type    string            `json:"type"`
+		Payload entity.OrderEvent `json:"payload"`
+	}
+	if err = json.Unmarshal(gotBody, &decoded); err != nil || decoded.Payload.OrderId != 17103 {
+		t.Errorf("body %s: %+v, %v", gotBody, decoded, err)
+	}
+}
+
+func TestWebhook_RejectedDelivery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := NewWebhook(srv.URL, "s3cret", "X-Signature", time.Second).
+		SendEventMessage(&entity.EventMessage{Type: entity.EventSyncFailure})
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "bad signature") {
+		t.Errorf("error = %v, want the status and reply", err)
+	}
+}
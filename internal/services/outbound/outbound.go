@@ -0,0 +1,326 @@
+// Package outbound posts sync events to downstream systems as signed JSON webhooks. Every
+// event is written to a delivery log first and then delivered by a background worker, which
+// retries failures with exponential backoff; the log survives restarts.
+package outbound
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+	"zohoclient/internal/http-server/middleware/signature"
+	"zohoclient/internal/lib/httputil"
+	"zohoclient/internal/lib/sl"
+)
+
+const (
+	// pollInterval is how often the worker looks for due retries when nothing wakes it.
+	pollInterval = 10 * time.Second
+	// leaseMargin is added to the request timeout for how long a claimed delivery is held by
+	// the worker that claimed it; a worker that dies mid-attempt releases it when it runs out.
+	leaseMargin = time.Minute
+
+	// Retries wait 30s, 1m, 2m, ... doubling up to an hour between attempts.
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+
+	signatureHeader = "X-Signature"
+)
+
+// Store is the delivery log.
+type Store interface {
+	SaveWebhookDelivery(delivery entity.WebhookDelivery) error
+	GetWebhookDelivery(id string) (*entity.WebhookDelivery, error)
+	GetWebhookDeliveries(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error)
+	ClaimDueWebhookDelivery(now, until time.Time) (*entity.WebhookDelivery, error)
+}
+
+type Dispatcher struct {
+	subscribers []config.WebhookSubscriber
+	store       Store
+	client      *http.Client
+	maxAttempts int
+	lease       time.Duration
+	log         *slog.Logger
+	wake        chan struct{}
+	stop        chan struct{}
+	now         func() time.Time
+
+	// While the delivery log cannot be written the worker holds off until pausedUntil, as every
+	// attempt it makes would be made again. saveFailures counts the failed runs in a row.
+	pausedUntil  time.Time
+	saveFailures int
+}
+
+// New returns the dispatcher for the configured subscribers, or nil when there are none.
+func New(conf *config.Config, store Store, log *slog.Logger) (*Dispatcher, error) {
+	c := conf.OutboundWebhooks
+	if len(c.Subscribers) == 0 {
+		return nil, nil
+	}
+	if store == nil {
+		return nil, fmt.Errorf("outbound webhooks need MongoDB for the delivery log")
+	}
+
+	names := make(map[string]bool, len(c.Subscribers))
+	for _, sub := range c.Subscribers {
+		if sub.Name == "" || sub.Url == "" || sub.Secret == "" {
+			return nil, fmt.Errorf("webhook subscriber %q: name, url and secret are required", sub.Name)
+		}
+		if names[sub.Name] {
+			return nil, fmt.Errorf("webhook subscriber %q is listed twice", sub.Name)
+		}
+		names[sub.Name] = true
+	}
+
+	maxAttempts := c.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	timeout := time.Duration(c.Timeout) * time.Second
+	return &Dispatcher{
+		subscribers: c.Subscribers,
+		store:       store,
+		client:      httputil.NewHTTPClient(timeout),
+		maxAttempts: maxAttempts,
+		lease:       timeout + leaseMargin,
+		log:         log.With(sl.Module("outbound")),
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		now:         time.Now,
+	}, nil
+}
+
+// Start runs the delivery worker until Stop.
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+}
+
+// Dispatch logs a delivery of the event for every subscriber that wants it and wakes the
+// worker. An event that cannot be logged is not delivered.
+func (d *Dispatcher) Dispatch(event string, data any) {
+	now := d.now()
+	for _, sub := range d.subscribers {
+		if len(sub.Events) > 0 && !slices.Contains(sub.Events, event) {
+			continue
+		}
+		id, err := newDeliveryId()
+		if err != nil {
+			d.log.With(sl.Err(err), slog.String("event", event), slog.String("subscriber", sub.Name)).Error("generate webhook id")
+			continue
+		}
+		body, err := json.Marshal(entity.WebhookEnvelope{ID: id, Event: event, Time: now, Data: data})
+		if err != nil {
+			d.log.With(sl.Err(err), slog.String("event", event), slog.String("subscriber", sub.Name)).Error("marshal webhook")
+			continue
+		}
+		delivery := entity.WebhookDelivery{
+			ID:          id,
+			Subscriber:  sub.Name,
+			URL:         sub.Url,
+			Event:       event,
+			Body:        string(body),
+			Status:      entity.DeliveryPending,
+			NextAttempt: now,
+			CreatedAt:   now,
+		}
+		if err = d.store.SaveWebhookDelivery(delivery); err != nil {
+			d.log.With(
+				sl.Err(err),
+				slog.String("event", event),
+				slog.String("subscriber", sub.Name),
+			).Error("log webhook delivery")
+		}
+	}
+	d.poke()
+}
+
+// Deliveries lists the delivery log, newest first.
+func (d *Dispatcher) Deliveries(filter entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error) {
+	return d.store.GetWebhookDeliveries(filter)
+}
+
+// Delivery returns one delivery, or nil if the id is unknown.
+func (d *Dispatcher) Delivery(id string) (*entity.WebhookDelivery, error) {
+	return d.store.GetWebhookDelivery(id)
+}
+
+// Redeliver queues a delivery for an immediate attempt with a fresh retry budget, whatever
+// its state. The same body is sent again, so receivers can de-duplicate on its id. A delivery
+// being attempted at the moment keeps its lease and ends with that attempt's outcome.
+func (d *Dispatcher) Redeliver(id string) (*entity.WebhookDelivery, error) {
+	delivery, err := d.store.GetWebhookDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, entity.ErrDeliveryNotFound
+	}
+	delivery.Status = entity.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttempt = d.now()
+	if err = d.store.SaveWebhookDelivery(*delivery); err != nil {
+		return nil, err
+	}
+	d.poke()
+	return delivery, nil
+}
+
+func (d *Dispatcher) poke() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) run() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+		d.deliverDue()
+	}
+}
+
+// deliverDue attempts every due delivery, claiming each before it is posted so that another
+// instance, or a redelivery requested meanwhile, does not post it a second time. When an outcome
+// cannot be saved the delivery stays due in the log, so the run stops there and the worker backs
+// off: carrying on would post the same deliveries again and again.
+func (d *Dispatcher) deliverDue() {
+	if d.now().Before(d.pausedUntil) {
+		return
+	}
+	for {
+		now := d.now()
+		delivery, err := d.store.ClaimDueWebhookDelivery(now, now.Add(d.lease))
+		if err != nil {
+			d.log.With(sl.Err(err)).Error("claim due webhook delivery")
+			return
+		}
+		if delivery == nil {
+			return
+		}
+		d.attempt(delivery)
+		delivery.LeasedUntil = time.Time{}
+		if err = d.store.SaveWebhookDelivery(*delivery); err != nil {
+			d.saveFailures++
+			d.pausedUntil = d.now().Add(backoff(d.saveFailures))
+			d.log.With(
+				sl.Err(err),
+				slog.String("id", delivery.ID),
+				slog.Time("paused_until", d.pausedUntil),
+			).Error("save webhook delivery, pausing deliveries")
+			return
+		}
+		d.saveFailures = 0
+	}
+}
+
+// attempt posts a delivery once and records the outcome: delivered, scheduled for a retry,
+// or failed once out of attempts.
+func (d *Dispatcher) attempt(delivery *entity.WebhookDelivery) {
+	log := d.log.With(
+		slog.String("id", delivery.ID),
+		slog.String("event", delivery.Event),
+		slog.String("subscriber", delivery.Subscriber),
+	)
+	delivery.Attempts++
+
+	idx := slices.IndexFunc(d.subscribers, func(s config.WebhookSubscriber) bool { return s.Name == delivery.Subscriber })
+	if idx < 0 {
+		delivery.Status = entity.DeliveryFailed
+		delivery.LastError = "subscriber no longer configured"
+		delivery.NextAttempt = time.Time{}
+		log.Warn("webhook dropped: subscriber no longer configured")
+		return
+	}
+	sub := d.subscribers[idx]
+	delivery.URL = sub.Url
+
+	code, err := d.post(delivery, sub.Secret)
+	delivery.ResponseCode = code
+	now := d.now()
+	if err == nil {
+		delivery.Status = entity.DeliveryDelivered
+		delivery.DeliveredAt = now
+		delivery.LastError = ""
+		delivery.NextAttempt = time.Time{}
+		log.With(slog.Int("attempts", delivery.Attempts)).Debug("webhook delivered")
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = entity.DeliveryFailed
+		delivery.NextAttempt = time.Time{}
+		log.With(sl.Err(err), slog.Int("attempts", delivery.Attempts)).Warn("webhook failed, giving up")
+		return
+	}
+	delivery.NextAttempt = now.Add(backoff(delivery.Attempts))
+	log.With(sl.Err(err), slog.Int("attempts", delivery.Attempts)).Debug("webhook failed, will retry")
+}
+
+// post sends the body and returns the response code; anything but 2xx is an error.
+func (d *Dispatcher) post(delivery *entity.WebhookDelivery, secret string) (int, error) {
+	body := []byte(delivery.Body)
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Attempt", strconv.Itoa(delivery.Attempts))
+	req.Header.Set(signatureHeader, "sha256="+signature.Sign(body, secret))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(reply))
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff is the wait before the retry following the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	if attempts > 8 {
+		return maxBackoff
+	}
+	wait := baseBackoff << (attempts - 1)
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+// newDeliveryId returns a random 128-bit hex id.
+func newDeliveryId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,322 @@
+package outbound
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+	"zohoclient/internal/http-server/middleware/signature"
+)
+
+// memStore is the delivery log in memory, with the same claim rules as Mongo.
+type memStore struct {
+	mu         sync.Mutex
+	deliveries map[string]entity.WebhookDelivery
+	saveErr    error
+}
+
+func (s *memStore) SaveWebhookDelivery(d entity.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.deliveries[d.ID] = d
+	return nil
+}
+
+func (s *memStore) GetWebhookDelivery(id string) (*entity.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func (s *memStore) GetWebhookDeliveries(entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []entity.WebhookDelivery
+	for _, d := range s.deliveries {
+		all = append(all, d)
+	}
+	return all, nil
+}
+
+func (s *memStore) ClaimDueWebhookDelivery(now, until time.Time) (*entity.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []entity.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Status == entity.DeliveryPending && !d.NextAttempt.After(now) && !d.LeasedUntil.After(now) {
+			due = append(due, d)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttempt.Before(due[j].NextAttempt) })
+	claimed := due[0]
+	claimed.LeasedUntil = until
+	s.deliveries[claimed.ID] = claimed
+	return &claimed, nil
+}
+
+func (s *memStore) only(t *testing.T) entity.WebhookDelivery {
+	t.Helper()
+	all, _ := s.GetWebhookDeliveries(entity.WebhookDeliveryFilter{})
+	if len(all) != 1 {
+		t.Fatalf("delivery log has %d entries, want 1", len(all))
+	}
+	return all[0]
+}
+
+func testDispatcher(t *testing.T, url string, maxAttempts int, subs ...config.WebhookSubscriber) (*Dispatcher, *memStore, *time.Time) {
+	t.Helper()
+	var conf config.Config
+	conf.OutboundWebhooks.MaxAttempts = maxAttempts
+	conf.OutboundWebhooks.Timeout = 1
+	if len(subs) == 0 {
+		subs = []config.WebhookSubscriber{{Name: "warehouse", Url: url, Secret: "s3cret"}}
+	}
+	conf.OutboundWebhooks.Subscribers = subs
+
+	store := &memStore{deliveries: make(map[string]entity.WebhookDelivery)}
+	d, err := New(&conf, store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, store, &now
+}
+
+func TestDispatch_DeliversSignedEnvelope(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	d, store, _ := testDispatcher(t, srv.URL, 3)
+	d.Dispatch(entity.WebhookOrderSynced, entity.OrderEvent{OrderId: 17103, ZohoId: "Z-1"})
+	d.deliverDue()
+
+	delivery := store.only(t)
+	if delivery.Status != entity.DeliveryDelivered || delivery.Attempts != 1 || delivery.ResponseCode != 200 {
+		t.Fatalf("delivery = %+v, want delivered on the first attempt", delivery)
+	}
+	if want := "sha256=" + signature.Sign(body, "s3cret"); got.Header.Get("X-Signature") != want {
+		t.Errorf("X-Signature = %q, want %q", got.Header.Get("X-Signature"), want)
+	}
+	if got.Header.Get("X-Webhook-Id") != delivery.ID || got.Header.Get("X-Webhook-Event") != entity.WebhookOrderSynced {
+		t.Errorf("headers = %v", got.Header)
+	}
+
+	var envelope struct {
+		ID    string            `json:"id"`
+		Event string            `json:"event"`
+		Data  entity.OrderEvent `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if envelope.ID != delivery.ID || envelope.Event != entity.WebhookOrderSynced || envelope.Data.OrderId != 17103 {
+		t.Errorf("envelope = %+v", envelope)
+	}
+}
+
+func TestDispatch_OnlySubscribedEvents(t *testing.T) {
+	d, store, _ := testDispatcher(t, "", 3,
+		config.WebhookSubscriber{Name: "warehouse", Url: "http://w", Secret: "a", Events: []string{entity.WebhookOrderSynced}},
+		config.WebhookSubscriber{Name: "accounting", Url: "http://a", Secret: "b", Events: []string{entity.WebhookPaymentLinked}},
+		config.WebhookSubscriber{Name: "audit", Url: "http://x", Secret: "c"},
+	)
+	d.Dispatch(entity.WebhookPaymentLinked, entity.OrderEvent{OrderId: 1})
+
+	subscribers := make(map[string]bool)
+	for _, delivery := range store.deliveries {
+		subscribers[delivery.Subscriber] = true
+	}
+	if len(subscribers) != 2 || !subscribers["accounting"] || !subscribers["audit"] {
+		t.Errorf("deliveries for %v, want accounting and audit", subscribers)
+	}
+}
+
+func TestDeliverDue_RetriesWithBackoffThenFails(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	d, store, now := testDispatcher(t, srv.URL, 3)
+	d.Dispatch(entity.WebhookCustomerSynced, entity.OrderEvent{CustomerId: 5})
+
+	d.deliverDue()
+	delivery := store.only(t)
+	if delivery.Status != entity.DeliveryPending || delivery.ResponseCode != 503 || delivery.LastError == "" {
+		t.Fatalf("after a failure: %+v, want pending with the error", delivery)
+	}
+	if want := now.Add(30 * time.Second); !delivery.NextAttempt.Equal(want) {
+		t.Errorf("next attempt = %v, want %v", delivery.NextAttempt, want)
+	}
+
+	// Not due yet: nothing is sent.
+	d.deliverDue()
+	if calls != 1 {
+		t.Fatalf("calls = %d before the backoff elapsed, want 1", calls)
+	}
+
+	*now = now.Add(30 * time.Second)
+	d.deliverDue()
+	if delivery = store.only(t); !delivery.NextAttempt.Equal(now.Add(time.Minute)) {
+		t.Errorf("second backoff ends %v, want a minute later", delivery.NextAttempt)
+	}
+
+	*now = now.Add(time.Minute)
+	d.deliverDue()
+	delivery = store.only(t)
+	if delivery.Status != entity.DeliveryFailed || delivery.Attempts != 3 || calls != 3 {
+		t.Fatalf("after max attempts: %+v, calls %d, want failed after 3", delivery, calls)
+	}
+
+	// Redelivery gives it a fresh budget and sends it at once.
+	if _, err := d.Redeliver(delivery.ID); err != nil {
+		t.Fatal(err)
+	}
+	d.deliverDue()
+	if delivery = store.only(t); delivery.Attempts != 1 || calls != 4 {
+		t.Errorf("after redeliver: attempts %d, calls %d, want 1 and 4", delivery.Attempts, calls)
+	}
+
+	if _, err := d.Redeliver("unknown"); err != entity.ErrDeliveryNotFound {
+		t.Errorf("Redeliver(unknown) error = %v, want ErrDeliveryNotFound", err)
+	}
+}
+
+// A delivery log that cannot be written must not have the worker post the same deliveries in a
+// loop: it stops at the first failed save and waits before trying again. The delivery it could
+// not save is posted again once its lease runs out.
+func TestDeliverDue_BacksOffWhenLogFails(t *testing.T) {
+	const pending = 50
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	d, store, now := testDispatcher(t, srv.URL, 3)
+	for i := 0; i < pending; i++ {
+		d.Dispatch(entity.WebhookOrderSynced, entity.OrderEvent{OrderId: int64(i)})
+	}
+	store.saveErr = errors.New("mongo down")
+
+	d.deliverDue()
+	d.deliverDue()
+	if calls != 1 {
+		t.Fatalf("posts = %d, want 1 before backing off", calls)
+	}
+
+	*now = now.Add(baseBackoff)
+	store.saveErr = nil
+	d.deliverDue()
+	if calls != pending {
+		t.Errorf("posts = %d after the pause, want the other %d", calls, pending-1)
+	}
+
+	*now = now.Add(d.lease)
+	d.deliverDue()
+	if calls != pending+1 {
+		t.Errorf("posts = %d after the lease, want the unsaved one again", calls)
+	}
+}
+
+// A delivery is claimed before it is posted: neither another instance polling the same log nor
+// a redelivery requested during the attempt posts it again.
+func TestDeliverDue_ClaimsBeforePosting(t *testing.T) {
+	calls := 0
+	var other *Dispatcher
+	var id string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			if _, err := other.Redeliver(id); err != nil {
+				t.Error(err)
+			}
+			other.deliverDue()
+		}
+	}))
+	defer srv.Close()
+
+	d, store, now := testDispatcher(t, srv.URL, 3)
+	other = &Dispatcher{}
+	*other = *d
+	d.Dispatch(entity.WebhookOrderSynced, entity.OrderEvent{OrderId: 17103})
+	id = store.only(t).ID
+
+	d.deliverDue()
+	if calls != 1 {
+		t.Fatalf("posts = %d, want 1", calls)
+	}
+	if delivery := store.only(t); delivery.Status != entity.DeliveryDelivered || !delivery.LeasedUntil.IsZero() {
+		t.Errorf("delivery = %+v, want delivered and released", delivery)
+	}
+
+	*now = now.Add(d.lease)
+	other.deliverDue()
+	if calls != 1 {
+		t.Errorf("posts = %d, want no second post", calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{40, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNew_Validates(t *testing.T) {
+	var conf config.Config
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if d, err := New(&conf, nil, log); d != nil || err != nil {
+		t.Errorf("no subscribers: %v, %v, want nil, nil", d, err)
+	}
+
+	conf.OutboundWebhooks.Subscribers = []config.WebhookSubscriber{{Name: "w", Url: "http://w", Secret: "s"}}
+	if _, err := New(&conf, nil, log); err == nil {
+		t.Error("want an error without a delivery log")
+	}
+
+	store := &memStore{deliveries: make(map[string]entity.WebhookDelivery)}
+	conf.OutboundWebhooks.Subscribers = append(conf.OutboundWebhooks.Subscribers, config.WebhookSubscriber{Name: "w", Url: "http://x", Secret: "s"})
+	if _, err := New(&conf, store, log); err == nil {
+		t.Error("want an error for a duplicate subscriber name")
+	}
+}
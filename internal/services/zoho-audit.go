@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// CallLog stores the audit record of each Zoho API call.
+type CallLog interface {
+	SaveZohoCall(call entity.ZohoCall) error
+}
+
+// redacted replaces the value of a redacted field in a stored body.
+const redacted = "***"
+
+// orderNumber finds the OpenCart order id in a Subject ("Order #17103") or a payment Name
+// ("Payment #17103").
+var orderNumber = regexp.MustCompile(`#(\d+)`)
+
+// SetCallLog turns on the audit of API calls.
+func (s *ZohoService) SetCallLog(callLog CallLog) {
+	s.callLog = callLog
+}
+
+// audit records a call in the background, so a slow store never holds up the sync.
+func (s *ZohoService) audit(method string, pathSegments []string, reqBody []byte, status int, respBody []byte, err error, duration time.Duration) {
+	if s.callLog == nil {
+		return
+	}
+	call := entity.ZohoCall{
+		Time:         time.Now().Add(-duration),
+		Method:       method,
+		Path:         path.Join(pathSegments...),
+		OrderID:      auditOrderId(reqBody),
+		RecordID:     auditRecordId(pathSegments),
+		RequestBody:  s.auditBody(reqBody),
+		Status:       status,
+		ResponseBody: s.auditBody(respBody),
+		DurationMs:   duration.Milliseconds(),
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	readAuditResponse(&call, respBody)
+	call.Failed = call.Error != "" || status < 200 || status >= 300 || (call.Code != "" && call.Code != "SUCCESS")
+
+	go func() {
+		if err := s.callLog.SaveZohoCall(call); err != nil {
+			s.log.With(
+				sl.Err(err),
+				slog.String("method", call.Method),
+				slog.String("path", call.Path),
+			).Warn("save zoho call")
+		}
+	}()
+}
+
+// auditOrderId finds the OpenCart order a request body is about: the ID_site of a Sales Order,
+// or the number in its Subject or in a payment's Name. Requests that carry none, like a
+// payment status update, are found by record_id instead.
+func auditOrderId(body []byte) int64 {
+	var payload struct {
+		Data []struct {
+			IDsite  string `json:"ID_site"`
+			Subject string `json:"Subject"`
+			Name    string `json:"Name"`
+		} `json:"data"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &payload) != nil || len(payload.Data) == 0 {
+		return 0
+	}
+	record := payload.Data[0]
+	if id, err := strconv.ParseInt(record.IDsite, 10, 64); err == nil {
+		return id
+	}
+	for _, text := range []string{record.Subject, record.Name} {
+		if m := orderNumber.FindStringSubmatch(text); m != nil {
+			id, _ := strconv.ParseInt(m[1], 10, 64)
+			return id
+		}
+	}
+	return 0
+}
+
+// auditRecordId is the record a request addresses, e.g. "Sales_Orders/<id>".
+func auditRecordId(pathSegments []string) string {
+	if len(pathSegments) < 2 {
+		return ""
+	}
+	switch id := pathSegments[1]; id {
+	case "upsert", "search":
+		return ""
+	default:
+		return id
+	}
+}
+
+// readAuditResponse takes the Zoho code and message of the first record, or of the whole
+// response when the request was refused, and the id of a created record.
+func readAuditResponse(call *entity.ZohoCall, body []byte) {
+	var resp struct {
+		Code    string                    `json:"code"`
+		Message string                    `json:"message"`
+		Data    []entity.ZohoResponseItem `json:"data"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &resp) != nil {
+		return
+	}
+	call.Code, call.Message = resp.Code, resp.Message
+	if len(resp.Data) == 0 || resp.Data[0].Code == "" {
+		return
+	}
+	item := resp.Data[0]
+	call.Code, call.Message = item.Code, item.Message
+	if call.RecordID == "" {
+		var details struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(item.Details, &details) == nil {
+			call.RecordID = details.ID
+		}
+	}
+}
+
+// auditBody masks the redacted fields of a JSON body and cuts it to the configured size.
+func (s *ZohoService) auditBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	text := string(body)
+	if len(s.auditRedact) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v any
+		if dec.Decode(&v) == nil {
+			if out, err := json.Marshal(redactValue(v, s.auditRedact)); err == nil {
+				text = string(out)
+			}
+		}
+	}
+	if s.auditMaxBody > 0 && len(text) > s.auditMaxBody {
+		text = strings.ToValidUTF8(text[:s.auditMaxBody], "") + "…"
+	}
+	return text
+}
+
+func redactValue(v any, fields map[string]bool) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if fields[key] {
+				if item != nil && item != "" {
+					value[key] = redacted
+				}
+				continue
+			}
+			value[key] = redactValue(item, fields)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactValue(item, fields)
+		}
+	}
+	return v
+}
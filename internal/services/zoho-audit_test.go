@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+)
+
+// chanCallLog reports each saved call on a channel, since they are saved in the background.
+type chanCallLog chan entity.ZohoCall
+
+func (l chanCallLog) SaveZohoCall(call entity.ZohoCall) error {
+	l <- call
+	return nil
+}
+
+func auditedService(t *testing.T, handler http.HandlerFunc) (*ZohoService, chanCallLog) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	conf := &config.Config{}
+	conf.Zoho.CrmUrl = server.URL
+	conf.Zoho.Scope = "crm"
+	conf.Zoho.ApiVersion = "v8"
+	conf.Zoho.Audit.MaxBody = 16384
+	conf.Zoho.Audit.Redact = []string{"Email", "Phone"}
+	s, err := NewZohoService(conf, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.refreshToken = "token"
+	s.tokenExpiry = time.Now().Add(time.Hour)
+
+	calls := make(chanCallLog, 1)
+	s.SetCallLog(calls)
+	return s, calls
+}
+
+func TestAuditRejectedPayment(t *testing.T) {
+	s, calls := auditedService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"data":[{"status":"error","code":"INVALID_DATA","message":"invalid data",`+
+			`"details":{"api_name":"Sells","json_path":"$.data[0].Sells"}}]}`)
+	})
+
+	_, err := s.CreatePayment(entity.ZohoPayment{
+		Name:  "Payment #17103",
+		Sells: &entity.ZohoSellsRef{ID: "739178000059413569"},
+		Sum:   entity.AmountOf(468),
+		Email: "jan@example.com",
+	})
+	if !errors.Is(err, ErrPaymentInvalidData) {
+		t.Fatalf("err = %v, want ErrPaymentInvalidData", err)
+	}
+
+	call := <-calls
+	if call.Method != http.MethodPost || call.Path != "Payments" || call.Status != http.StatusBadRequest {
+		t.Errorf("call = %s %s %d", call.Method, call.Path, call.Status)
+	}
+	if call.OrderID != 17103 || call.Code != "INVALID_DATA" || !call.Failed {
+		t.Errorf("call order_id = %d, code = %q, failed = %v", call.OrderID, call.Code, call.Failed)
+	}
+	if strings.Contains(call.RequestBody, "jan@example.com") || !strings.Contains(call.RequestBody, `"Email":"***"`) {
+		t.Errorf("request body not redacted: %s", call.RequestBody)
+	}
+	if !strings.Contains(call.ResponseBody, "Sells") {
+		t.Errorf("response body = %s, want the error details", call.ResponseBody)
+	}
+}
+
+func TestAuditUpdatedOrder(t *testing.T) {
+	s, calls := auditedService(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":[{"status":"success","code":"SUCCESS","message":"record updated",`+
+			`"details":{"id":"739178000059413569","Modified_Time":"2026-10-18T10:00:00+02:00"}}]}`)
+	})
+
+	if _, err := s.UpdateOrder(entity.ZohoOrder{IDsite: "17103", Subject: "Order #17103"}, "739178000059413569"); err != nil {
+		t.Fatal(err)
+	}
+
+	call := <-calls
+	if call.OrderID != 17103 || call.RecordID != "739178000059413569" || call.Failed {
+		t.Errorf("call order_id = %d, record_id = %q, failed = %v", call.OrderID, call.RecordID, call.Failed)
+	}
+	if call.Path != "Sales_Orders/739178000059413569" || call.Code != "SUCCESS" {
+		t.Errorf("call path = %q, code = %q", call.Path, call.Code)
+	}
+}
+
+func TestAuditBodyTruncated(t *testing.T) {
+	s := &ZohoService{auditMaxBody: 10}
+	if got := s.auditBody([]byte("ąąąąąąąąąą")); got != "ąąąąą…" {
+		t.Errorf("auditBody = %q", got)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 	"zohoclient/entity"
 	"zohoclient/internal/config"
@@ -23,6 +25,11 @@ type ZohoFunctionsService struct {
 	msgURL     string
 	log        *slog.Logger
 	httpClient *http.Client
+	callLog    CallLog
+	// In Zoho shadow mode messages are recorded in the call log instead of sent; their text
+	// and sender are masked there unless shadowRaw is set.
+	shadow    bool
+	shadowRaw bool
 }
 
 func NewZohoFunctionsService(conf *config.Config, log *slog.Logger) (*ZohoFunctionsService, error) {
@@ -39,11 +46,18 @@ func NewZohoFunctionsService(conf *config.Config, log *slog.Logger) (*ZohoFuncti
 		msgURL:     conf.SmartSender.ZohoMsgURL,
 		log:        log.With(sl.Module("zoho-func")),
 		httpClient: httputil.NewHTTPClient(30 * time.Second),
+		shadow:     conf.Zoho.Mode == "shadow",
+		shadowRaw:  conf.Zoho.Audit.ShadowRaw,
 	}
 
 	return service, nil
 }
 
+// SetCallLog sets where the messages held back in shadow mode are recorded.
+func (s *ZohoFunctionsService) SetCallLog(callLog CallLog) {
+	s.callLog = callLog
+}
+
 // SendMessages sends chat messages to Zoho CRM via the custom "getmessagefromsmartsender"
 // server-side function. Authenticated with zapikey query parameter.
 func (s *ZohoFunctionsService) SendMessages(contactID string, messages []entity.ZohoMessageItem) error {
@@ -61,6 +75,11 @@ func (s *ZohoFunctionsService) SendMessages(contactID string, messages []entity.
 		return fmt.Errorf("marshal message payload: %w", err)
 	}
 
+	if s.shadow {
+		s.shadowSend(payload, body)
+		return nil
+	}
+
 	if err := s.doRequest(body); err != nil {
 		return fmt.Errorf("send messages to Zoho: %w", err)
 	}
@@ -100,3 +119,52 @@ func (s *ZohoFunctionsService) doRequest(body []byte) error {
 
 	return nil
 }
+
+// shadowSend records messages instead of sending them to Zoho, the way ZohoService records
+// its writes in shadow mode.
+func (s *ZohoFunctionsService) shadowSend(payload entity.ZohoMessagePayload, body []byte) {
+	call := entity.ZohoCall{
+		Time:     time.Now(),
+		Method:   http.MethodPost,
+		Path:     s.functionPath(),
+		RecordID: payload.ContactID,
+		Status:   http.StatusOK,
+		Code:     entity.ZohoCallShadow,
+		Message:  shadowMessage,
+	}
+	if s.shadowRaw {
+		call.RequestBody = string(body)
+	} else {
+		masked := entity.ZohoMessagePayload{ContactID: payload.ContactID, Messages: make([]entity.ZohoMessageItem, len(payload.Messages))}
+		for i, m := range payload.Messages {
+			masked.Messages[i] = entity.ZohoMessageItem{MessageID: m.MessageID, ChatID: m.ChatID, Content: redacted, Sender: redacted}
+		}
+		if b, err := json.Marshal(masked); err == nil {
+			call.RequestBody = string(b)
+		}
+	}
+
+	log := s.log.With(
+		slog.String("path", call.Path),
+		slog.String("contact_id", payload.ContactID),
+		slog.Int("count", len(payload.Messages)),
+	)
+	log.Info("shadow write recorded")
+
+	if s.callLog != nil {
+		go func() {
+			if err := s.callLog.SaveZohoCall(call); err != nil {
+				log.With(sl.Err(err)).Warn("save shadow call")
+			}
+		}()
+	}
+}
+
+// functionPath is the path of the messages function, without the host and the API key.
+func (s *ZohoFunctionsService) functionPath() string {
+	u, err := url.Parse(s.msgURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Path, "/")
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+)
+
+// In shadow mode chat messages never reach the Zoho function: they are recorded, masked.
+func TestZohoFunctionsShadowNotSent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("%s %s sent in shadow mode", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	conf := &config.Config{}
+	conf.SmartSender.Enabled = true
+	conf.SmartSender.ZohoApiKey = "zapi-secret"
+	conf.SmartSender.ZohoMsgURL = server.URL + "/crm/v7/functions/getmessagefromsmartsender/actions/execute"
+	conf.Zoho.Mode = "shadow"
+	s, err := NewZohoFunctionsService(conf, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := make(chanCallLog, 2)
+	s.SetCallLog(calls)
+
+	messages := []entity.ZohoMessageItem{{MessageID: "m1", ChatID: "c1", Content: "my phone is 380501234567", Sender: "Jan"}}
+	if err = s.SendMessages("739178000012345678", messages); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case call := <-calls:
+		if call.Code != entity.ZohoCallShadow || call.Method != http.MethodPost || call.RecordID != "739178000012345678" ||
+			call.Path != "crm/v7/functions/getmessagefromsmartsender/actions/execute" {
+			t.Errorf("call = %s %s %s %s", call.Code, call.Method, call.Path, call.RecordID)
+		}
+		if strings.Contains(call.RequestBody, "380501234567") || strings.Contains(call.RequestBody, "Jan") ||
+			!strings.Contains(call.RequestBody, `"message_id":"m1"`) {
+			t.Errorf("request body = %s, want the messages with text and sender masked", call.RequestBody)
+		}
+		if strings.Contains(call.Path+call.RequestBody, "zapi-secret") {
+			t.Error("API key recorded")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shadow message not recorded")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
 	"time"
 	"zohoclient/entity"
 	"zohoclient/internal/config"
@@ -43,6 +45,11 @@ type ZohoService struct {
 	apiVersion   string
 	log          *slog.Logger
 	httpClient   *http.Client
+	callLog      CallLog
+	auditMaxBody int
+	auditRedact  map[string]bool
+	shadow       bool
+	shadowRaw    bool
 }
 
 func NewZohoService(conf *config.Config, log *slog.Logger) (*ZohoService, error) {
@@ -57,6 +64,21 @@ func NewZohoService(conf *config.Config, log *slog.Logger) (*ZohoService, error)
 		apiVersion:   conf.Zoho.ApiVersion,
 		log:          log.With(sl.Module("zoho")),
 		httpClient:   httputil.NewHTTPClient(30 * time.Second),
+		auditMaxBody: conf.Zoho.Audit.MaxBody,
+		auditRedact:  make(map[string]bool, len(conf.Zoho.Audit.Redact)),
+		shadowRaw:    conf.Zoho.Audit.ShadowRaw,
+	}
+	switch conf.Zoho.Mode {
+	case "", "live":
+	case "shadow":
+		service.shadow = true
+	default:
+		return nil, fmt.Errorf("unknown zoho mode %q, want live or shadow", conf.Zoho.Mode)
+	}
+	for _, field := range conf.Zoho.Audit.Redact {
+		if field = strings.TrimSpace(field); field != "" {
+			service.auditRedact[field] = true
+		}
 	}
 
 	return service, nil
@@ -87,6 +109,12 @@ func (s *ZohoService) RefreshToken() error {
 	return fmt.Errorf("refresh token failed after 3 attempts: %w", err)
 }
 
+// CheckToken requests an access token once, without the retries of RefreshToken, to verify
+// the credentials.
+func (s *ZohoService) CheckToken() error {
+	return s.requestToken()
+}
+
 func (s *ZohoService) requestToken() error {
 	form := url.Values{}
 	form.Add("client_id", s.clientID)
@@ -203,6 +231,43 @@ func (s *ZohoService) UpsertContact(contact *entity.ClientDetails) (string, erro
 	return s.upsertContact(payload, contactDuplicateCheckFields(payload), log)
 }
 
+// CreateB2BContact upserts a B2B client's contact person, linked to the company Account.
+// Contacts are matched on Email/Phone like CreateContact.
+func (s *ZohoService) CreateB2BContact(contact *entity.ClientDetails, accountID string) (string, error) {
+	log := s.log.With(
+		slog.String("email", contact.Email),
+		slog.String("phone", contact.Phone),
+		slog.String("account_id", accountID),
+	)
+
+	if err := util.ValidateEmail(contact.Email); err != nil {
+		log.Debug("invalid email")
+		contact.Email = ""
+	}
+
+	if contact.Email == "" && contact.Phone == "" {
+		return "", fmt.Errorf("email and phone are empty")
+	}
+
+	if contact.LastName == "" {
+		contact.LastName = "?"
+	}
+
+	payload := entity.Contact{
+		Email:     contact.Email,
+		Phone:     contact.Phone,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		City:      contact.City,
+		Country:   contact.Country,
+	}
+	if accountID != "" {
+		payload.AccountName = &entity.ZohoAccountRef{ID: accountID}
+	}
+
+	return s.upsertContact(payload, contactDuplicateCheckFields(payload), log)
+}
+
 // contactDuplicateCheckFields returns the subset of ["Email", "Phone"] that are
 // populated on the given contact, so Zoho upsert can match an existing record
 // instead of rejecting with DUPLICATE_DATA.
@@ -304,9 +369,9 @@ func (s *ZohoService) CreateOrder(orderData entity.ZohoOrder) (string, string, e
 		slog.String("subject", orderData.Subject),
 		slog.Float64("vat", orderData.VAT),
 		slog.Float64("discount", orderData.DiscountP),
-		slog.Float64("coupon", orderData.CouponValue),
-		slog.Float64("sub_total", orderData.SubTotal),
-		slog.Float64("total", orderData.GrandTotal),
+		slog.Float64("coupon", orderData.CouponValue.Float64()),
+		slog.Float64("sub_total", orderData.SubTotal.Float64()),
+		slog.Float64("total", orderData.GrandTotal.Float64()),
 	)
 	t := time.Now()
 	var err error
@@ -362,23 +427,23 @@ func (s *ZohoService) CreateB2BOrder(orderData entity.ZohoOrderB2B) (string, err
 
 	if orderData.GrandTotalUAH > 0 {
 		log = log.With(
-			slog.Float64("total_UAH", orderData.GrandTotalUAH),
-			slog.Float64("sub_total_UAH", orderData.GrandTotalUAH),
+			slog.String("total_UAH", orderData.GrandTotalUAH.String()),
+			slog.String("sub_total_UAH", orderData.GrandTotalUAH.String()),
 		)
 	} else if orderData.GrandTotalPLN > 0 {
 		log = log.With(
-			slog.Float64("total_PLN", orderData.GrandTotalPLN),
-			slog.Float64("sub_total_PLN", orderData.GrandTotalPLN),
+			slog.String("total_PLN", orderData.GrandTotalPLN.String()),
+			slog.String("sub_total_PLN", orderData.GrandTotalPLN.String()),
 		)
 	} else if orderData.GrandTotalUSD > 0 {
 		log = log.With(
-			slog.Float64("total_USD", orderData.GrandTotalUSD),
-			slog.Float64("sub_total_USD", orderData.GrandTotalUSD),
+			slog.String("total_USD", orderData.GrandTotalUSD.String()),
+			slog.String("sub_total_USD", orderData.GrandTotalUSD.String()),
 		)
 	} else if orderData.GrandTotalEUR > 0 {
 		log = log.With(
-			slog.Float64("total_EUR", orderData.GrandTotalEUR),
-			slog.Float64("sub_total_EUR", orderData.GrandTotalEUR),
+			slog.String("total_EUR", orderData.GrandTotalEUR.String()),
+			slog.String("sub_total_EUR", orderData.GrandTotalEUR.String()),
 		)
 	}
 
@@ -550,6 +615,324 @@ func (s *ZohoService) AddItemsToOrderB2B(_ string, items []*entity.Good) (string
 	return extractRecordID(item)
 }
 
+// FindAccountID returns the id of the Account whose field equals value, or "" when there is
+// none. When several match, the first one Zoho returns is used.
+func (s *ZohoService) FindAccountID(field, value string) (string, error) {
+	return s.findRecordID("Accounts", field, value)
+}
+
+// FindDealID returns the id of the Deal whose field equals value, or "" when there is none.
+// When several match, the first one Zoho returns is used.
+func (s *ZohoService) FindDealID(field, value string) (string, error) {
+	return s.findRecordID("Deals", field, value)
+}
+
+// FindPaymentID returns the id of the Payments record whose field equals value, or "" when
+// there is none. When several match, the first one Zoho returns is used.
+func (s *ZohoService) FindPaymentID(field, value string) (string, error) {
+	return s.findRecordID("Payments", field, value)
+}
+
+// findRecordID searches a module for the records whose field equals value and returns the id
+// of the first one, or "" when there is none.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/search-records.html
+func (s *ZohoService) findRecordID(module, field, value string) (string, error) {
+	query := url.Values{"criteria": {fmt.Sprintf("(%s:equals:%s)", field, escapeCriteria(value))}}
+	body, err := s.doRawRequestQuery(http.MethodGet, query, nil, module, "search")
+	if err != nil {
+		return "", err
+	}
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	var resp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("decode %s: %w", strings.ToLower(module), err)
+	}
+	if len(resp.Data) == 0 {
+		return "", nil
+	}
+	return resp.Data[0].ID, nil
+}
+
+// FindContactAccountID returns the Account linked to the Contact with the given email, or ""
+// when no such Contact exists or it is not linked to an Account.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/search-records.html
+func (s *ZohoService) FindContactAccountID(email string) (string, error) {
+	query := url.Values{"email": {email}}
+	body, err := s.doRawRequestQuery(http.MethodGet, query, nil, "Contacts", "search")
+	if err != nil {
+		return "", err
+	}
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	var resp struct {
+		Data []struct {
+			AccountName *entity.ZohoAccountRef `json:"Account_Name"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("decode contacts: %w", err)
+	}
+	for _, contact := range resp.Data {
+		if contact.AccountName != nil && contact.AccountName.ID != "" {
+			return contact.AccountName.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// CreateAccount creates a company record in the Accounts module.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/insert-records.html
+func (s *ZohoService) CreateAccount(account entity.ZohoAccount) (string, error) {
+	payload := map[string]interface{}{
+		"data": []entity.ZohoAccount{account},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal payload: %w", err)
+	}
+
+	apiResp, err := s.doRequest(http.MethodPost, body, "Accounts")
+	if err != nil {
+		return "", err
+	}
+
+	item := apiResp.Data[0]
+	if item.Status != "success" {
+		return "", formatZohoError("account not created", item)
+	}
+
+	return extractRecordID(item)
+}
+
+// UpdateAccount writes the non-empty fields of account to an existing Accounts record.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/update-specific-record.html
+func (s *ZohoService) UpdateAccount(id string, account entity.ZohoAccount) error {
+	payload := map[string]interface{}{
+		"data": []entity.ZohoAccount{account},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	apiResp, err := s.doRequest(http.MethodPut, body, "Accounts", id)
+	if err != nil {
+		return err
+	}
+
+	item := apiResp.Data[0]
+	if item.Status != "success" {
+		return formatZohoError("account not updated", item)
+	}
+	return nil
+}
+
+// UpdateB2BOrder patches an existing B2B Deal. Fields left unset in the update are not sent.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/update-specific-record.html
+func (s *ZohoService) UpdateB2BOrder(dealID string, update entity.ZohoOrderB2BUpdate) error {
+	payload := map[string]interface{}{
+		"data": []entity.ZohoOrderB2BUpdate{update},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	apiResp, err := s.doRequest(http.MethodPut, body, "Deals", dealID)
+	if err != nil {
+		return err
+	}
+
+	item := apiResp.Data[0]
+	if item.Status != "success" {
+		return formatZohoError("B2B order not updated", item)
+	}
+
+	s.log.With(
+		slog.String("id", dealID),
+		slog.String("stage", update.Status),
+	).Debug("B2B order updated")
+
+	return nil
+}
+
+// GetB2BOrderGoods returns the Goods records linked to a B2B Deal. Zoho answers a search
+// without matches with 204 No Content, which yields an empty slice.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/search-records.html
+func (s *ZohoService) GetB2BOrderGoods(dealID string) ([]entity.Good, error) {
+	var goods []entity.Good
+	page := 1
+	for {
+		query := url.Values{
+			"criteria": {fmt.Sprintf("(Deal:equals:%s)", dealID)},
+			"page":     {strconv.Itoa(page)},
+		}
+		body, err := s.doRawRequestQuery(http.MethodGet, query, nil, "Goods", "search")
+		if err != nil {
+			return nil, err
+		}
+		if len(body) == 0 {
+			return goods, nil
+		}
+
+		var resp struct {
+			Data []entity.Good `json:"data"`
+			Info struct {
+				MoreRecords bool `json:"more_records"`
+			} `json:"info"`
+		}
+		if err = json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("decode goods: %w", err)
+		}
+		goods = append(goods, resp.Data...)
+		if !resp.Info.MoreRecords {
+			return goods, nil
+		}
+		page++
+	}
+}
+
+// GetOrderPayments returns the Payments records linked to a Sales Order through the Sells
+// lookup; a search without matches yields an empty slice.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/search-records.html
+func (s *ZohoService) GetOrderPayments(zohoOrderID string) ([]entity.ZohoPaymentRecord, error) {
+	var payments []entity.ZohoPaymentRecord
+	page := 1
+	for {
+		query := url.Values{
+			"criteria": {fmt.Sprintf("(Sells:equals:%s)", zohoOrderID)},
+			"page":     {strconv.Itoa(page)},
+		}
+		body, err := s.doRawRequestQuery(http.MethodGet, query, nil, "Payments", "search")
+		if err != nil {
+			return nil, err
+		}
+		if len(body) == 0 {
+			return payments, nil
+		}
+
+		var resp struct {
+			Data []entity.ZohoPaymentRecord `json:"data"`
+			Info struct {
+				MoreRecords bool `json:"more_records"`
+			} `json:"info"`
+		}
+		if err = json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("decode payments: %w", err)
+		}
+		payments = append(payments, resp.Data...)
+		if !resp.Info.MoreRecords {
+			return payments, nil
+		}
+		page++
+	}
+}
+
+// SalesOrdersCreatedBetween returns the Sales Orders created in [from, to). Zoho's search
+// serves at most 2000 records, so a range holding more is an error rather than a short list.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/search-records.html
+func (s *ZohoService) SalesOrdersCreatedBetween(from, to time.Time) ([]entity.ZohoOrderRecord, error) {
+	const perPage = 200
+	criteria := fmt.Sprintf("((Created_Time:greater_equal:%s)and(Created_Time:less_than:%s))",
+		from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	var orders []entity.ZohoOrderRecord
+	page := 1
+	for {
+		query := url.Values{
+			"criteria": {criteria},
+			"fields":   {"Subject,ID_site,Status,Grand_Total,Modified_Time"},
+			"page":     {strconv.Itoa(page)},
+			"per_page": {strconv.Itoa(perPage)},
+		}
+		body, err := s.doRawRequestQuery(http.MethodGet, query, nil, "Sales_Orders", "search")
+		if err != nil {
+			return nil, err
+		}
+		if len(body) == 0 {
+			return orders, nil
+		}
+
+		var resp struct {
+			Data []entity.ZohoOrderRecord `json:"data"`
+			Info struct {
+				MoreRecords bool `json:"more_records"`
+			} `json:"info"`
+		}
+		if err = json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("decode orders: %w", err)
+		}
+		orders = append(orders, resp.Data...)
+		if !resp.Info.MoreRecords {
+			return orders, nil
+		}
+		if page*perPage >= 2000 {
+			return nil, fmt.Errorf("more than 2000 Sales Orders created in the range, narrow it")
+		}
+		page++
+	}
+}
+
+// UpdateB2BOrderGoods updates existing Goods records in place, matched by their record id.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/update-records.html
+func (s *ZohoService) UpdateB2BOrderGoods(items []*entity.Good) error {
+	for i, item := range items {
+		if item.ID == "" {
+			return fmt.Errorf("good %d has no record id", i)
+		}
+	}
+
+	payload := map[string]interface{}{
+		"data": items,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	apiResp, err := s.doRequest(http.MethodPut, body, "Goods")
+	if err != nil {
+		return err
+	}
+
+	for _, item := range apiResp.Data {
+		if item.Status != "success" {
+			return formatZohoError("goods not updated", item)
+		}
+	}
+	return nil
+}
+
+// DeleteB2BOrderGoods deletes Goods records by id. Zoho accepts up to 100 ids per call.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/delete-records.html
+func (s *ZohoService) DeleteB2BOrderGoods(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := url.Values{"ids": {strings.Join(ids, ",")}}
+	apiResp, err := s.doRequestQuery(http.MethodDelete, query, nil, "Goods")
+	if err != nil {
+		return err
+	}
+
+	for _, item := range apiResp.Data {
+		if item.Status != "success" {
+			return formatZohoError("goods not deleted", item)
+		}
+	}
+	return nil
+}
+
 // UpdateOrder updates an existing Sales Order record by its Zoho record ID, returning the
 // record's new Modified_Time so the caller can suppress the echo webhook this write triggers.
 // Ref: https://www.zoho.com/crm/developer/docs/api/v8/update-specific-record.html
@@ -558,9 +941,9 @@ func (s *ZohoService) UpdateOrder(orderData entity.ZohoOrder, id string) (string
 		slog.String("id", id),
 		slog.String("subject", orderData.Subject),
 		slog.Float64("vat", orderData.VAT),
-		slog.Float64("coupon", orderData.CouponValue),
-		slog.Float64("sub_total", orderData.SubTotal),
-		slog.Float64("total", orderData.GrandTotal),
+		slog.Float64("coupon", orderData.CouponValue.Float64()),
+		slog.Float64("sub_total", orderData.SubTotal.Float64()),
+		slog.Float64("total", orderData.GrandTotal.Float64()),
 	)
 
 	payload := map[string]interface{}{
@@ -666,94 +1049,97 @@ func (s *ZohoService) UpdateOrderItemRows(orderID string, rows []entity.OrderedI
 // doRawRequest is doRequest for endpoints whose response is a record rather than the standard
 // per-record status envelope.
 func (s *ZohoService) doRawRequest(method string, body []byte, pathSegments ...string) ([]byte, error) {
-	segments := append([]string{s.scope, s.apiVersion}, pathSegments...)
-	fullURL, err := buildURL(s.crmUrl, segments...)
+	return s.doRawRequestQuery(method, nil, body, pathSegments...)
+}
+
+// doRawRequestQuery is doRawRequest with URL query parameters, for search and bulk delete.
+func (s *ZohoService) doRawRequestQuery(method string, query url.Values, body []byte, pathSegments ...string) ([]byte, error) {
+	status, bodyBytes, err := s.send(method, query, body, pathSegments...)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = s.RefreshToken(); err != nil {
-		return nil, err
+	if status < 200 || status >= 300 {
+		return nil, fmt.Errorf("zoho api: %d %s: %s", status, http.StatusText(status), string(bodyBytes))
 	}
 
-	req, err := http.NewRequest(method, fullURL, bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Authorization", "Zoho-oauthtoken "+s.refreshToken)
-	req.Header.Set("Content-Type", "application/json")
+	return bodyBytes, nil
+}
 
-	resp, err := s.httpClient.Do(req)
+// doRequest executes an authenticated request against the Zoho CRM v8 REST API.
+// It automatically refreshes the OAuth token, constructs the full URL from path segments
+// (e.g., "Sales_Orders", "upsert"), and handles rate-limit (429) responses.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/api-limits.html
+func (s *ZohoService) doRequest(method string, body []byte, pathSegments ...string) (*entity.ZohoAPIResponse, error) {
+	return s.doRequestQuery(method, nil, body, pathSegments...)
+}
+
+// doRequestQuery is doRequest with URL query parameters.
+func (s *ZohoService) doRequestQuery(method string, query url.Values, body []byte, pathSegments ...string) (*entity.ZohoAPIResponse, error) {
+	_, bodyBytes, err := s.send(method, query, body, pathSegments...)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, err
 	}
-	defer httputil.CloseBody(resp.Body, s.log)
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("rate limited by Zoho API, retry after: %s", resp.Header.Get("Retry-After"))
+	var apiResp entity.ZohoAPIResponse
+	if err = json.Unmarshal(bodyBytes, &apiResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	if len(apiResp.Data) == 0 {
+		return nil, fmt.Errorf("empty response data")
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("zoho api: %s: %s", resp.Status, string(bodyBytes))
+	return &apiResp, nil
+}
+
+// send executes an authenticated request and returns the response status and body. Every
+// call, failed or not, goes to the audit log when one is set. In shadow mode only reads are
+// sent; writes are recorded and answered by shadowSend.
+func (s *ZohoService) send(method string, query url.Values, body []byte, pathSegments ...string) (status int, bodyBytes []byte, err error) {
+	if s.shadow && method != http.MethodGet {
+		return s.shadowSend(method, query, body, pathSegments)
 	}
 
-	return bodyBytes, nil
-}
+	start := time.Now()
+	defer func() {
+		s.audit(method, pathSegments, body, status, bodyBytes, err, time.Since(start))
+	}()
 
-// doRequest executes an authenticated request against the Zoho CRM v8 REST API.
-// It automatically refreshes the OAuth token, constructs the full URL from path segments
-// (e.g., "Sales_Orders", "upsert"), and handles rate-limit (429) responses.
-// Ref: https://www.zoho.com/crm/developer/docs/api/v8/api-limits.html
-func (s *ZohoService) doRequest(method string, body []byte, pathSegments ...string) (*entity.ZohoAPIResponse, error) {
-	segments := append([]string{s.scope, s.apiVersion}, pathSegments...)
-	fullURL, err := buildURL(s.crmUrl, segments...)
+	fullURL, err := s.apiURL(query, pathSegments...)
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 
 	if err = s.RefreshToken(); err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 
 	req, err := http.NewRequest(method, fullURL, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return 0, nil, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Authorization", "Zoho-oauthtoken "+s.refreshToken)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return 0, nil, fmt.Errorf("send request: %w", err)
 	}
 	defer httputil.CloseBody(resp.Body, s.log)
+	status = resp.StatusCode
 
-	// Check for rate limiting (v8 API has stricter limits)
-	if resp.StatusCode == http.StatusTooManyRequests {
-		retryAfter := resp.Header.Get("Retry-After")
-		return nil, fmt.Errorf("rate limited by Zoho API, retry after: %s", retryAfter)
-	}
-
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return status, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	var apiResp entity.ZohoAPIResponse
-	if err = json.Unmarshal(bodyBytes, &apiResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
-	}
-
-	if len(apiResp.Data) == 0 {
-		return nil, fmt.Errorf("empty response data")
+	// Check for rate limiting (v8 API has stricter limits)
+	if status == http.StatusTooManyRequests {
+		return status, bodyBytes, fmt.Errorf("rate limited by Zoho API, retry after: %s", resp.Header.Get("Retry-After"))
 	}
 
-	return &apiResp, nil
+	return status, bodyBytes, nil
 }
 
 // formatZohoError decodes error details from a failed Zoho API response item
@@ -787,6 +1173,25 @@ func extractRecordDetails(item entity.ZohoResponseItem) (entity.SuccessOrderDeta
 	return success, nil
 }
 
+// escapeCriteria escapes the characters that delimit a search criteria expression.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/search-records.html
+func escapeCriteria(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, ",", `\,`).Replace(value)
+}
+
+// apiURL builds the full CRM API URL for the given module path, with optional query parameters.
+func (s *ZohoService) apiURL(query url.Values, pathSegments ...string) (string, error) {
+	segments := append([]string{s.scope, s.apiVersion}, pathSegments...)
+	fullURL, err := buildURL(s.crmUrl, segments...)
+	if err != nil {
+		return "", err
+	}
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+	return fullURL, nil
+}
+
 func buildURL(base string, paths ...string) (string, error) {
 	u, err := url.Parse(base)
 	if err != nil {
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/config"
+	"zohoclient/internal/services/zohofake"
+)
+
+// fakeZohoService is a ZohoService talking to an in-process fake Zoho CRM.
+func fakeZohoService(t *testing.T) (*ZohoService, *zohofake.Server) {
+	t.Helper()
+	fake := zohofake.New(t)
+	conf := &config.Config{}
+	fake.Configure(conf)
+	s, err := NewZohoService(conf, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, fake
+}
+
+// An order as buildZohoOrder sends it: a product line carrying the discount, and the
+// non-taxable shipping line.
+func fakeOrder(contactId string) entity.ZohoOrder {
+	return entity.ZohoOrder{
+		ContactName: entity.ContactName{ID: contactId},
+		OrderedItems: []entity.OrderedItem{
+			{Product: entity.ZohoProduct{ID: "P1"}, Quantity: 2, ListPrice: entity.AmountOf(24.3902), DiscountP: 10, Total: entity.AmountOf(43.9024)},
+			{Product: entity.ZohoProduct{ID: "SHIP"}, Quantity: 1, ListPrice: entity.AmountOf(39.90), Total: entity.AmountOf(39.90)},
+		},
+		VAT:        23,
+		GrandTotal: entity.AmountOf(93.90),
+		Subject:    "Order #17103",
+		IDsite:     "17103",
+		Status:     "Нове",
+	}
+}
+
+func TestZohoService_OrderRoundTrip(t *testing.T) {
+	s, fake := fakeZohoService(t)
+	fake.SetNonTaxable("SHIP")
+
+	contactId, err := s.CreateContact(&entity.ClientDetails{Email: "jan@example.com", FirstName: "Jan", LastName: "Kowalski"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, created, err := s.CreateOrder(fakeOrder(contactId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := s.GetOrder(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 24.3902 x 2 x 0.9 x 1.23 + 39.90
+	if order.GrandTotal != entity.AmountOf(93.90) || order.ModifiedTime != created {
+		t.Errorf("order grand total %s, modified %s; want 93.90, %s", order.GrandTotal, order.ModifiedTime, created)
+	}
+	if len(order.OrderedItems) != 2 || order.OrderedItems[0].ID == "" || order.OrderedItems[1].ID == "" {
+		t.Fatalf("rows = %+v, want 2 with ids", order.OrderedItems)
+	}
+
+	// Lifting the discount on the product row updates it in place and raises the total.
+	row := order.OrderedItems[0]
+	modified, err := s.UpdateOrderItemRows(id, []entity.OrderedItemPatch{
+		{ID: row.ID, Product: row.Product, Quantity: 2, ListPrice: entity.AmountOf(24.3902), DiscountP: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order, err = s.GetOrder(id); err != nil {
+		t.Fatal(err)
+	}
+	if order.GrandTotal != entity.AmountOf(99.90) || len(order.OrderedItems) != 2 || order.OrderedItems[0].ID != row.ID {
+		t.Errorf("after update: grand total %s, rows %+v; want 99.90 with the same 2 rows", order.GrandTotal, order.OrderedItems)
+	}
+	if modified <= created || order.ModifiedTime != modified {
+		t.Errorf("modified %s after %s, record says %s", modified, created, order.ModifiedTime)
+	}
+
+	if n := fake.TokenRefreshes(); n != 1 {
+		t.Errorf("token refreshed %d times, want once", n)
+	}
+}
+
+func TestZohoService_SalesOrdersCreatedBetween(t *testing.T) {
+	s, fake := fakeZohoService(t)
+	for _, subject := range []string{"Order #1", "Order #2", "Manual"} {
+		fake.Put(zohofake.SalesOrders, zohofake.Record{"Subject": subject, "ID_site": strings.TrimPrefix(subject, "Order #")})
+	}
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	orders, err := s.SalesOrdersCreatedBetween(from, from.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 3 || orders[0].IDsite != "1" {
+		t.Errorf("orders = %+v, want the 3 created that day", orders)
+	}
+
+	if orders, err = s.SalesOrdersCreatedBetween(from.AddDate(0, 0, 1), from.AddDate(0, 0, 2)); err != nil || len(orders) != 0 {
+		t.Errorf("next day = %+v, %v, want none", orders, err)
+	}
+}
+
+func TestZohoService_UpsertContact(t *testing.T) {
+	s, fake := fakeZohoService(t)
+
+	id, err := s.UpsertContact(&entity.ClientDetails{Email: "jan@example.com", Phone: "+48600100200", LastName: "Kowalski"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.UpsertContact(&entity.ClientDetails{Email: "JAN@example.com", City: "Kraków"})
+	if err != nil || again != id {
+		t.Fatalf("upsert of the same email = %q, %v, want %q", again, err, id)
+	}
+	if contacts := fake.Records(zohofake.Contacts); len(contacts) != 1 || contacts[0]["field2"] != "Kraków" {
+		t.Errorf("contacts = %v, want one, updated", contacts)
+	}
+
+	// Zoho refuses the upsert but names the record it collided with.
+	fake.Inject(zohofake.DuplicateData(zohofake.Contacts, "Phone", id))
+	dup, err := s.UpsertContact(&entity.ClientDetails{Email: "anna@example.com", Phone: "+48600100200"})
+	if err != nil || dup != id {
+		t.Errorf("duplicate = %q, %v, want %q", dup, err, id)
+	}
+}
+
+func TestZohoService_Payments(t *testing.T) {
+	s, fake := fakeZohoService(t)
+	orderId := fake.Put(zohofake.SalesOrders, zohofake.Record{"Subject": "Order #17103"})
+	payment := entity.ZohoPayment{Name: "Payment #17103", Sells: &entity.ZohoSellsRef{ID: orderId}, Status: "held", Sum: entity.AmountOf(468)}
+
+	fake.Inject(zohofake.InvalidData(zohofake.Payments, "Sells"))
+	if _, err := s.CreatePayment(payment); !errors.Is(err, ErrPaymentInvalidData) {
+		t.Fatalf("err = %v, want ErrPaymentInvalidData", err)
+	}
+
+	paymentId, err := s.CreatePayment(payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.UpdatePaymentStatus(paymentId, "paid"); err != nil {
+		t.Fatal(err)
+	}
+
+	payments, err := s.GetOrderPayments(orderId)
+	if err != nil || len(payments) != 1 || payments[0].ID != paymentId || payments[0].Status != "paid" {
+		t.Errorf("payments = %+v, %v, want the paid payment", payments, err)
+	}
+	if payments, err = s.GetOrderPayments("no-such-order"); err != nil || len(payments) != 0 {
+		t.Errorf("payments of another order = %+v, %v, want none", payments, err)
+	}
+}
+
+func TestZohoService_Errors(t *testing.T) {
+	s, fake := fakeZohoService(t)
+
+	fake.Inject(zohofake.RateLimited(zohofake.SalesOrders))
+	if _, _, err := s.CreateOrder(fakeOrder("C1")); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("err = %v, want rate limited", err)
+	}
+	if n := len(fake.Records(zohofake.SalesOrders)); n != 0 {
+		t.Errorf("%d orders created by a refused request", n)
+	}
+
+	if _, err := s.GetOrder("739178000000000000"); err == nil {
+		t.Error("reading a missing order succeeded")
+	}
+	if _, err := s.UpdateOrder(fakeOrder("C1"), "739178000000000000"); err == nil || !strings.Contains(err.Error(), "INVALID_DATA") {
+		t.Errorf("updating a missing order: err = %v, want INVALID_DATA", err)
+	}
+}
+
+func TestZohoService_B2BDeal(t *testing.T) {
+	s, fake := fakeZohoService(t)
+
+	dealId, err := s.CreateB2BOrder(entity.ZohoOrderB2B{Subject: "B2B-1001", Status: "Нове замовлення", Currency: "PLN", GrandTotalPLN: entity.AmountOf(1230), OrderUID: "ord_1001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found, err := s.FindDealID("B2B_Order_UID", "ord_1001"); err != nil || found != dealId {
+		t.Errorf("deal by order uid = %q, %v, want %q", found, err, dealId)
+	}
+	if found, err := s.FindDealID("B2B_Order_UID", "ord_1002"); err != nil || found != "" {
+		t.Errorf("deal of another order = %q, %v, want none", found, err)
+	}
+
+	paymentId, err := s.CreatePayment(entity.ZohoPayment{Name: "B2B Payment 1001", Deal: &entity.ZohoDeal{ID: dealId}, Status: "paid", Sum: entity.AmountOf(1230)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found, err := s.FindPaymentID("Deal", dealId); err != nil || found != paymentId {
+		t.Errorf("payment of the deal = %q, %v, want %q", found, err, paymentId)
+	}
+	if err = s.UpdateB2BOrder(dealId, entity.ZohoOrderB2BUpdate{Status: "Оплачено формування ТТН"}); err != nil {
+		t.Fatal(err)
+	}
+	if deal := fake.Get(zohofake.Deals, dealId); deal["Stage"] != "Оплачено формування ТТН" || deal["Deal_Name"] != "B2B-1001" {
+		t.Errorf("deal = %v, want the stage updated and the rest kept", deal)
+	}
+
+	goods := []*entity.Good{
+		{Product: entity.ZohoProduct{ID: "P1"}, Deal: entity.ZohoDeal{ID: dealId}, Name: "P1", Quantity: 10},
+		{Product: entity.ZohoProduct{ID: "P2"}, Deal: entity.ZohoDeal{ID: dealId}, Name: "P2", Quantity: 5},
+	}
+	if _, err = s.AddItemsToOrderB2B(dealId, goods); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.GetB2BOrderGoods(dealId)
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("goods = %+v, %v, want 2", stored, err)
+	}
+
+	if err = s.DeleteB2BOrderGoods([]string{stored[0].ID, stored[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if stored, err = s.GetB2BOrderGoods(dealId); err != nil || len(stored) != 0 {
+		t.Errorf("goods after delete = %+v, %v, want none", stored, err)
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"zohoclient/entity"
+	"zohoclient/internal/lib/sl"
+)
+
+// shadowMessage is the message of every record a shadow write reports.
+const shadowMessage = "not sent: shadow mode"
+
+// Shadow reports whether writes are recorded instead of sent.
+func (s *ZohoService) Shadow() bool {
+	return s.shadow
+}
+
+// shadowSend records a write instead of sending it and answers it the way Zoho answers a
+// successful one: a SUCCESS record per record sent, carrying the id it addressed or a new
+// "shadow-" id, and the current time as Modified_Time. The bodies go to the call log redacted
+// and cut like those of live calls, unless zoho.audit.shadow_raw asks for them whole.
+func (s *ZohoService) shadowSend(method string, query url.Values, body []byte, pathSegments []string) (int, []byte, error) {
+	now := time.Now()
+	ids := shadowRecordIds(query, body, pathSegments)
+	modified := now.Format(time.RFC3339)
+
+	items := make([]map[string]any, len(ids))
+	for i, id := range ids {
+		items[i] = map[string]any{
+			"code":    "SUCCESS",
+			"status":  "success",
+			"message": shadowMessage,
+			"details": map[string]string{"id": id, "Created_Time": modified, "Modified_Time": modified},
+		}
+	}
+	resp, err := json.Marshal(map[string]any{"data": items})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	target := path.Join(pathSegments...)
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	call := entity.ZohoCall{
+		Time:         now,
+		Method:       method,
+		Path:         path.Join(pathSegments...),
+		OrderID:      auditOrderId(body),
+		RecordID:     ids[0],
+		RequestBody:  s.auditBody(body),
+		Status:       http.StatusOK,
+		Code:         entity.ZohoCallShadow,
+		Message:      shadowMessage,
+		ResponseBody: s.auditBody(resp),
+	}
+	if s.shadowRaw {
+		call.RequestBody, call.ResponseBody = string(body), string(resp)
+	}
+	log := s.log.With(
+		slog.String("method", method),
+		slog.String("path", target),
+		slog.Int64("order_id", call.OrderID),
+		slog.String("record_id", call.RecordID),
+	)
+	log.Info("shadow write recorded")
+	log.With(slog.String("body", call.RequestBody)).Debug("shadow write payload")
+
+	if s.callLog != nil {
+		go func() {
+			if err := s.callLog.SaveZohoCall(call); err != nil {
+				log.With(sl.Err(err)).Warn("save shadow call")
+			}
+		}()
+	}
+	return http.StatusOK, resp, nil
+}
+
+// shadowRecordIds names the records a write reports back, one per record sent: the id in
+// the path or in the record, the ids of a bulk delete, or a new one for a create.
+func shadowRecordIds(query url.Values, body []byte, pathSegments []string) []string {
+	if ids := query.Get("ids"); ids != "" {
+		return strings.Split(ids, ",")
+	}
+	pathId := auditRecordId(pathSegments)
+
+	var payload struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(body, &payload)
+
+	ids := make([]string, max(len(payload.Data), 1))
+	for i := range ids {
+		switch {
+		case i < len(payload.Data) && payload.Data[i].ID != "":
+			ids[i] = payload.Data[i].ID
+		case pathId != "":
+			ids[i] = pathId
+		default:
+			ids[i] = shadowId()
+		}
+	}
+	return ids
+}
+
+// shadowId makes up the id of a record created in shadow mode.
+func shadowId() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return "shadow-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"zohoclient/entity"
+)
+
+// In shadow mode a write never reaches Zoho: it is recorded and answered as created.
+func TestShadowCreateOrderNotSent(t *testing.T) {
+	s, calls := auditedService(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("%s %s sent in shadow mode", r.Method, r.URL.Path)
+	})
+	s.shadow = true
+
+	id, modified, err := s.CreateOrder(entity.ZohoOrder{IDsite: "17103", Subject: "Order #17103"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(id, "shadow-") {
+		t.Errorf("id = %q, want a shadow id", id)
+	}
+	if _, err = time.Parse(time.RFC3339, modified); err != nil {
+		t.Errorf("modified time %q: %v", modified, err)
+	}
+
+	call := <-calls
+	if call.Code != entity.ZohoCallShadow || call.Method != http.MethodPost || call.Path != "Sales_Orders" {
+		t.Errorf("call = %s %s %s", call.Code, call.Method, call.Path)
+	}
+	if call.OrderID != 17103 || call.RecordID != id {
+		t.Errorf("call order_id = %d, record_id = %q", call.OrderID, call.RecordID)
+	}
+
+	// Personal data is redacted like in live calls, unless the raw payload is asked for.
+	payment := entity.ZohoPayment{Name: "Payment #17103", Sells: &entity.ZohoSellsRef{ID: id}, Email: "jan@example.com"}
+	if _, err = s.CreatePayment(payment); err != nil {
+		t.Fatal(err)
+	}
+	if call = <-calls; strings.Contains(call.RequestBody, "jan@example.com") || !strings.Contains(call.RequestBody, `"Email":"***"`) {
+		t.Errorf("request body = %s, want the email redacted", call.RequestBody)
+	}
+	s.shadowRaw = true
+	if _, err = s.CreatePayment(payment); err != nil {
+		t.Fatal(err)
+	}
+	if call = <-calls; !strings.Contains(call.RequestBody, `"Email":"jan@example.com"`) {
+		t.Errorf("request body = %s, want it as sent with shadow_raw", call.RequestBody)
+	}
+}
+
+// Updates report the record they address; reads still go to Zoho.
+func TestShadowUpdateKeepsRecordId(t *testing.T) {
+	s, calls := auditedService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("%s %s sent in shadow mode", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"739178000059413569","Grand_Total":468}]}`))
+	})
+	s.shadow = true
+
+	if _, err := s.UpdateOrderItemRows("739178000059413569", []entity.OrderedItemPatch{{ID: "row-1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if call := <-calls; call.RecordID != "739178000059413569" {
+		t.Errorf("record_id = %q, want the updated order", call.RecordID)
+	}
+
+	if err := s.DeleteB2BOrderGoods([]string{"g1", "g2"}); err != nil {
+		t.Fatal(err)
+	}
+	<-calls
+
+	record, err := s.GetOrder("739178000059413569")
+	if err != nil || record.GrandTotal != entity.AmountOf(468) {
+		t.Errorf("GetOrder = %+v, %v, want the record from Zoho", record, err)
+	}
+	<-calls
+}
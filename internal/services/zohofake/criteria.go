@@ -0,0 +1,137 @@
+package zohofake
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// criteria is a parsed search criteria: "(Field:operator:value)", or such conditions joined
+// by "and" / "or" inside parentheses. Values escape "(", ")", "," and "\" with a backslash.
+// Ref: https://www.zoho.com/crm/developer/docs/api/v8/search-records.html
+type criteria struct {
+	field, operator, value string
+	join                   string // "and" or "or" for a group
+	terms                  []criteria
+}
+
+func parseCriteria(text string) (criteria, error) {
+	c, rest, err := parseGroup(text)
+	if err != nil {
+		return c, err
+	}
+	if rest != "" {
+		return c, fmt.Errorf("unexpected %q after the criteria", rest)
+	}
+	return c, nil
+}
+
+// parseGroup reads one parenthesised term and returns what follows it.
+func parseGroup(text string) (criteria, string, error) {
+	if !strings.HasPrefix(text, "(") {
+		return criteria{}, "", fmt.Errorf("criteria must start with '(': %q", text)
+	}
+	if strings.HasPrefix(text, "((") {
+		var group criteria
+		rest := text[1:]
+		for {
+			term, after, err := parseGroup(rest)
+			if err != nil {
+				return group, "", err
+			}
+			group.terms = append(group.terms, term)
+			switch {
+			case strings.HasPrefix(after, ")"):
+				return group, after[1:], nil
+			case strings.HasPrefix(after, "and"), strings.HasPrefix(after, "or"):
+				join := "and"
+				if strings.HasPrefix(after, "or") {
+					join = "or"
+				}
+				if group.join != "" && group.join != join {
+					return group, "", fmt.Errorf("mixed and/or in one group")
+				}
+				group.join = join
+				rest = after[len(join):]
+			default:
+				return group, "", fmt.Errorf("expected and, or or ')' at %q", after)
+			}
+		}
+	}
+
+	// A single condition; find its closing parenthesis, skipping escaped ones.
+	var b strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				b.WriteByte('\x00')
+				b.WriteByte(text[i])
+			}
+		case ')':
+			parts := strings.SplitN(b.String(), ":", 3)
+			if len(parts) != 3 {
+				return criteria{}, "", fmt.Errorf("condition %q is not field:operator:value", b.String())
+			}
+			value := strings.ReplaceAll(parts[2], "\x00", "")
+			return criteria{field: parts[0], operator: parts[1], value: value}, text[i+1:], nil
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return criteria{}, "", fmt.Errorf("unclosed condition %q", text)
+}
+
+// match reports whether a record satisfies the criteria.
+func (c criteria) match(record Record) bool {
+	if len(c.terms) > 0 {
+		for _, t := range c.terms {
+			if t.match(record) != (c.join == "and") {
+				return c.join != "and"
+			}
+		}
+		return c.join == "and"
+	}
+
+	got := fieldString(record[c.field])
+	switch c.operator {
+	case "equals":
+		return strings.EqualFold(got, c.value)
+	case "starts_with":
+		return strings.HasPrefix(strings.ToLower(got), strings.ToLower(c.value))
+	case "greater_equal", "greater_than", "less_than", "less_equal":
+		cmp := compare(got, c.value)
+		switch c.operator {
+		case "greater_equal":
+			return cmp >= 0
+		case "greater_than":
+			return cmp > 0
+		case "less_than":
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+	return false
+}
+
+// compare orders two values as times when both are, as numbers when both are, or as text.
+func compare(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA == nil && errB == nil {
+		return ta.Compare(tb)
+	}
+	na, nb := fieldNumber(a), fieldNumber(b)
+	if na != 0 || nb != 0 {
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
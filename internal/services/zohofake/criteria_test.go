@@ -0,0 +1,36 @@
+package zohofake
+
+import "testing"
+
+func TestCriteria(t *testing.T) {
+	record := Record{
+		"Name":         "Dark (PL), 5 l",
+		"Sells":        map[string]any{"id": "42"},
+		"Created_Time": "2026-10-01T09:00:01+02:00",
+	}
+	cases := map[string]bool{
+		`(Name:equals:Dark \(PL\)\, 5 l)`: true,
+		`(Sells:equals:42)`:               true,
+		`(Sells:equals:43)`:               false,
+		`((Created_Time:greater_equal:2026-10-01T00:00:00Z)and(Sells:equals:42))`:                  true,
+		`((Created_Time:less_than:2026-10-01T07:00:00Z)and(Sells:equals:42))`:                      false,
+		`((Created_Time:less_than:2026-10-01T07:00:00Z)or(Name:starts_with:dark))`:                 true,
+		`(((Sells:equals:1)or(Sells:equals:42))and(Created_Time:less_equal:2027-01-01T00:00:00Z))`: true,
+	}
+	for text, want := range cases {
+		c, err := parseCriteria(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		if got := c.match(record); got != want {
+			t.Errorf("%s matched %v, want %v", text, got, want)
+		}
+	}
+
+	for _, bad := range []string{"Name:equals:x", "(Name:equals)", "((A:equals:1)and(B:equals:2)or(C:equals:3))"} {
+		if _, err := parseCriteria(bad); err == nil {
+			t.Errorf("%s parsed", bad)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package zohofake
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Fault makes requests to a module fail the way Zoho fails them.
+type Fault struct {
+	Method string // HTTP method it applies to; empty for any
+	Module string // e.g. Payments; empty for any
+	Times  int    // requests it applies to; 0 for one
+
+	// Status 429 refuses the whole request as rate limited. Otherwise every record of a write
+	// is rejected with Code; reads are not affected.
+	Status      int
+	Code        string // e.g. INVALID_DATA, DUPLICATE_DATA
+	Field       string // api_name of the offending field
+	DuplicateID string // the existing record named by DUPLICATE_DATA
+}
+
+// RateLimited makes the next request to module fail with 429 Too Many Requests.
+func RateLimited(module string) Fault {
+	return Fault{Module: module, Status: http.StatusTooManyRequests}
+}
+
+// InvalidData makes the next write to module fail with INVALID_DATA on field.
+func InvalidData(module, field string) Fault {
+	return Fault{Module: module, Code: "INVALID_DATA", Field: field}
+}
+
+// DuplicateData makes the next write to module fail with DUPLICATE_DATA on field, naming
+// the existing record id.
+func DuplicateData(module, field, id string) Fault {
+	return Fault{Module: module, Code: "DUPLICATE_DATA", Field: field, DuplicateID: id}
+}
+
+// Inject queues a fault. Faults apply in the order injected, each to as many matching
+// requests as its Times.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// fault takes the first queued fault matching a request.
+func (s *Server) fault(method, module string) *Fault {
+	for i, f := range s.faults {
+		if (f.Method != "" && f.Method != method) || (f.Module != "" && f.Module != module) {
+			continue
+		}
+		if f.Status != http.StatusTooManyRequests && method == http.MethodGet {
+			continue
+		}
+		f.Times--
+		if f.Times == 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return f
+	}
+	return nil
+}
+
+// item is the response record of a write the fault rejects.
+func (f *Fault) item(module string, index int) map[string]any {
+	details := map[string]any{
+		"api_name":  f.Field,
+		"json_path": fmt.Sprintf("$.data[%d].%s", index, f.Field),
+	}
+	message := "invalid data"
+	if f.Code == "DUPLICATE_DATA" {
+		message = "duplicate data"
+		details["duplicate_record"] = map[string]any{
+			"id":     f.DuplicateID,
+			"module": map[string]any{"api_name": module},
+			"Owner":  map[string]any{"id": "1", "name": "Fake Zoho"},
+		}
+		details["more_records"] = false
+	}
+	return map[string]any{"code": f.Code, "status": "error", "message": message, "details": details}
+}
@@ -0,0 +1,625 @@
+// Package zohofake is an in-process Zoho CRM for tests: an httptest.Server that speaks enough of
+// the v8 REST API and the OAuth refresh flow for ZohoService to run against it unchanged.
+//
+// Records live in memory, per module, as the JSON objects they were sent as. The server adds
+// what Zoho adds: ids, Created_Time and Modified_Time, ids on subform rows, and for Sales_Orders
+// the row totals, Sub_Total and Grand_Total recomputed from List_Price, Quantity and DiscountP
+// (see recomputeOrder). Faults can be injected per module to test the error paths.
+package zohofake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"zohoclient/internal/config"
+)
+
+// Credentials the token endpoint accepts.
+const (
+	ClientID     = "fake-client"
+	ClientSecret = "fake-secret"
+	RefreshToken = "fake-refresh-token"
+)
+
+// Module names with behaviour of their own.
+const (
+	SalesOrders = "Sales_Orders"
+	Contacts    = "Contacts"
+	Deals       = "Deals"
+	Payments    = "Payments"
+)
+
+// orderedItems is the Sales_Orders subform the totals are computed from.
+const orderedItems = "Ordered_Items"
+
+// Record is a stored record, as decoded from JSON.
+type Record map[string]any
+
+// Server is the fake Zoho CRM. Its zero value is not usable; call New.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	records    map[string]map[string]Record
+	order      map[string][]string // ids per module in creation order, as search returns them
+	faults     []*Fault
+	nonTaxable map[string]bool
+	token      string
+	tokens     int
+	seq        int64
+	clock      time.Time
+	calls      map[string]int
+}
+
+// New starts a fake Zoho CRM that is closed with the test.
+func New(t testing.TB) *Server {
+	s := &Server{
+		records:    make(map[string]map[string]Record),
+		order:      make(map[string][]string),
+		nonTaxable: make(map[string]bool),
+		seq:        739178000059400000,
+		clock:      time.Date(2026, 10, 1, 9, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+		calls:      make(map[string]int),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/v2/token", s.handleToken)
+	mux.HandleFunc("/crm/v8/", s.handleAPI)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Configure points the Zoho section of conf at the server.
+func (s *Server) Configure(conf *config.Config) {
+	conf.Zoho.ClientId = ClientID
+	conf.Zoho.ClientSecret = ClientSecret
+	conf.Zoho.RefreshToken = RefreshToken
+	conf.Zoho.RefreshUrl = s.URL + "/oauth/v2/token"
+	conf.Zoho.CrmUrl = s.URL
+	conf.Zoho.Scope = "crm"
+	conf.Zoho.ApiVersion = "v8"
+}
+
+// SetNonTaxable marks products whose Sales Order lines are added to Grand_Total without VAT,
+// as the shipping item is in the live org.
+func (s *Server) SetNonTaxable(productIds ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range productIds {
+		s.nonTaxable[id] = true
+	}
+}
+
+// Put stores a record as if it had been made in Zoho, and returns its id.
+func (s *Server) Put(module string, record Record) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(module, clone(record))
+}
+
+// Get returns a copy of a stored record, or nil.
+func (s *Server) Get(module, id string) Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[module][id]; ok {
+		return clone(r)
+	}
+	return nil
+}
+
+// Records returns copies of a module's records in creation order.
+func (s *Server) Records(module string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []Record
+	for _, id := range s.order[module] {
+		list = append(list, clone(s.records[module][id]))
+	}
+	return list
+}
+
+// Calls counts the API requests made with method to a path, e.g. "POST Sales_Orders" or
+// "GET Payments/search".
+func (s *Server) Calls(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method+" "+path]
+}
+
+// TokenRefreshes counts the access tokens issued.
+func (s *Server) TokenRefreshes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("client_id") != ClientID ||
+		r.Form.Get("client_secret") != ClientSecret || r.Form.Get("refresh_token") != RefreshToken {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_code"})
+		return
+	}
+
+	s.mu.Lock()
+	s.tokens++
+	s.token = fmt.Sprintf("fake-access-token-%d", s.tokens)
+	token := s.token
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"scope":        "ZohoCRM.modules.ALL",
+		"api_domain":   s.URL,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/crm/v8/"), "/"), "/")
+	module, rest := segments[0], segments[1:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[r.Method+" "+strings.Join(segments, "/")]++
+
+	if s.token == "" || r.Header.Get("Authorization") != "Zoho-oauthtoken "+s.token {
+		writeJSON(w, http.StatusUnauthorized, apiError("INVALID_TOKEN", "invalid oauth token", nil))
+		return
+	}
+	if f := s.fault(r.Method, module); f != nil {
+		if f.Status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "60")
+			writeJSON(w, f.Status, apiError("TOO_MANY_REQUESTS", "too many requests continuously", nil))
+			return
+		}
+		if r.Method != http.MethodGet {
+			n := 1
+			if body, err := readData(r); err == nil && len(body.Data) > 0 {
+				n = len(body.Data)
+			}
+			items := make([]map[string]any, n)
+			for i := range items {
+				items[i] = f.item(module, i)
+			}
+			writeJSON(w, http.StatusBadRequest, map[string]any{"data": items})
+			return
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(rest) == 1 && rest[0] == "search":
+		s.search(w, r, module)
+	case r.Method == http.MethodGet && len(rest) == 1:
+		s.read(w, module, rest[0])
+	case r.Method == http.MethodPost && len(rest) == 0:
+		s.write(w, r, module, false)
+	case r.Method == http.MethodPost && len(rest) == 1 && rest[0] == "upsert":
+		s.write(w, r, module, true)
+	case r.Method == http.MethodPut && len(rest) <= 1:
+		id := ""
+		if len(rest) == 1 {
+			id = rest[0]
+		}
+		s.update(w, r, module, id)
+	case r.Method == http.MethodDelete && len(rest) == 0:
+		s.delete(w, module, strings.Split(r.URL.Query().Get("ids"), ","))
+	default:
+		writeJSON(w, http.StatusBadRequest, apiError("INVALID_URL_PATTERN", "please check if the URL trying to access is a correct one", nil))
+	}
+}
+
+// read answers GET <module>/<id>.
+func (s *Server) read(w http.ResponseWriter, module, id string) {
+	record, ok := s.records[module][id]
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, apiError("INVALID_DATA", "the related id given seems to be invalid",
+			map[string]any{"resource_path_index": 1}))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": []Record{record}})
+}
+
+// write answers POST <module> and POST <module>/upsert. An upsert updates the first record
+// matching one of the duplicate_check_fields, in the order given; when different fields
+// match different records it is refused with DUPLICATE_DATA, as Zoho does.
+func (s *Server) write(w http.ResponseWriter, r *http.Request, module string, upsert bool) {
+	body, err := readData(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError("INVALID_DATA", err.Error(), nil))
+		return
+	}
+
+	items := make([]map[string]any, len(body.Data))
+	created := false
+	for i, record := range body.Data {
+		if !upsert {
+			items[i] = success("record added", s.recordDetails(module, s.create(module, record)))
+			created = true
+			continue
+		}
+
+		matches := s.duplicates(module, record, body.DuplicateCheckFields)
+		switch len(matches) {
+		case 0:
+			items[i] = success("record added", s.recordDetails(module, s.create(module, record)))
+			items[i]["action"] = "insert"
+			created = true
+		case 1:
+			s.merge(module, matches[0].id, record)
+			items[i] = success("record updated", s.recordDetails(module, matches[0].id))
+			items[i]["action"] = "update"
+			items[i]["duplicate_field"] = matches[0].field
+		default:
+			items[i] = (&Fault{Code: "DUPLICATE_DATA", Field: matches[1].field, DuplicateID: matches[1].id}).item(module, i)
+		}
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, map[string]any{"data": items})
+}
+
+// update answers PUT <module>/<id>, which applies the first record to id, and PUT <module>,
+// which applies every record to the id it carries.
+func (s *Server) update(w http.ResponseWriter, r *http.Request, module, pathId string) {
+	body, err := readData(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError("INVALID_DATA", err.Error(), nil))
+		return
+	}
+
+	items := make([]map[string]any, len(body.Data))
+	failed := 0
+	for i, record := range body.Data {
+		id := pathId
+		if id == "" {
+			id, _ = record["id"].(string)
+		}
+		if _, ok := s.records[module][id]; !ok {
+			items[i] = (&Fault{Code: "INVALID_DATA", Field: "id"}).item(module, i)
+			items[i]["message"] = "the related id given seems to be invalid"
+			failed++
+			continue
+		}
+		s.merge(module, id, record)
+		items[i] = success("record updated", s.recordDetails(module, id))
+	}
+
+	status := http.StatusOK
+	if failed == len(items) && failed > 0 {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]any{"data": items})
+}
+
+// delete answers DELETE <module>?ids=...
+func (s *Server) delete(w http.ResponseWriter, module string, ids []string) {
+	items := make([]map[string]any, len(ids))
+	for i, id := range ids {
+		if _, ok := s.records[module][id]; !ok {
+			items[i] = (&Fault{Code: "INVALID_DATA", Field: "id"}).item(module, i)
+			items[i]["details"] = map[string]any{"id": id}
+			continue
+		}
+		delete(s.records[module], id)
+		s.order[module] = slices.DeleteFunc(s.order[module], func(v string) bool { return v == id })
+		items[i] = success("record deleted", map[string]any{"id": id})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// search answers GET <module>/search with criteria, or with email for Contacts. Zoho answers
+// a search without matches with 204 and no body.
+func (s *Server) search(w http.ResponseWriter, r *http.Request, module string) {
+	q := r.URL.Query()
+	match := func(Record) bool { return true }
+	switch {
+	case q.Get("criteria") != "":
+		c, err := parseCriteria(q.Get("criteria"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, apiError("INVALID_QUERY", err.Error(), nil))
+			return
+		}
+		match = c.match
+	case q.Get("email") != "":
+		email := q.Get("email")
+		match = func(rec Record) bool { return strings.EqualFold(fieldString(rec["Email"]), email) }
+	default:
+		writeJSON(w, http.StatusBadRequest, apiError("REQUIRED_PARAM_MISSING", "one of the expected parameter is missing",
+			map[string]any{"param": "criteria"}))
+		return
+	}
+
+	var found []Record
+	for _, id := range s.order[module] {
+		if rec := s.records[module][id]; match(rec) {
+			found = append(found, rec)
+		}
+	}
+	if len(found) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	page, perPage := intParam(q.Get("page"), 1), intParam(q.Get("per_page"), 200)
+	from := min((page-1)*perPage, len(found))
+	to := min(from+perPage, len(found))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": found[from:to],
+		"info": map[string]any{"page": page, "per_page": perPage, "count": to - from, "more_records": to < len(found)},
+	})
+}
+
+// create stores a new record under a fresh id.
+func (s *Server) create(module string, record Record) string {
+	s.seq++
+	id := strconv.FormatInt(s.seq, 10)
+	now := s.tick()
+	record["id"] = id
+	record["Created_Time"] = now
+	record["Modified_Time"] = now
+	record["Created_By"] = map[string]any{"id": "1", "name": "Fake Zoho"}
+	record["Modified_By"] = map[string]any{"id": "1", "name": "Fake Zoho"}
+	for field, value := range record {
+		if rows, ok := subformRows(value); ok {
+			record[field] = s.mergeRows(nil, rows)
+		}
+	}
+
+	if s.records[module] == nil {
+		s.records[module] = make(map[string]Record)
+	}
+	s.records[module][id] = record
+	s.order[module] = append(s.order[module], id)
+	if module == SalesOrders {
+		s.recomputeOrder(record)
+	}
+	return id
+}
+
+// merge writes the fields of patch over a stored record. Subforms follow Zoho's rules for
+// updates: a row with an id updates that row, a row without one is appended, a row sent with
+// "_delete" is removed, and an empty list removes every row; rows left out are kept.
+func (s *Server) merge(module, id string, patch Record) {
+	record := s.records[module][id]
+	for field, value := range patch {
+		switch field {
+		case "id", "Created_Time", "Created_By":
+			continue
+		}
+		if rows, ok := subformRows(value); ok {
+			existing, _ := subformRows(record[field])
+			record[field] = s.mergeRows(existing, rows)
+			continue
+		}
+		record[field] = value
+	}
+	record["Modified_Time"] = s.tick()
+	if module == SalesOrders {
+		s.recomputeOrder(record)
+	}
+}
+
+func (s *Server) mergeRows(existing, patch []map[string]any) []any {
+	if len(patch) == 0 {
+		return []any{}
+	}
+	rows := slices.Clone(existing)
+	for _, row := range patch {
+		id, _ := row["id"].(string)
+		i := slices.IndexFunc(rows, func(r map[string]any) bool { return id != "" && r["id"] == id })
+		_, remove := row["_delete"]
+		switch {
+		case remove && i >= 0:
+			rows = slices.Delete(rows, i, i+1)
+		case remove:
+		case i >= 0:
+			maps.Copy(rows[i], row)
+		default:
+			s.seq++
+			row = maps.Clone(row)
+			row["id"] = strconv.FormatInt(s.seq, 10)
+			rows = append(rows, row)
+		}
+	}
+
+	out := make([]any, len(rows))
+	for i, row := range rows {
+		out[i] = row
+	}
+	return out
+}
+
+// recomputeOrder sets the figures Zoho derives on a Sales Order, in the way buildZohoOrder
+// relies on: DiscountP is kept to 2 decimals, each row's Total is List_Price x Quantity x
+// (1 - DiscountP/100), Sub_Total is the sum of the taxable rows and Grand_Total is
+// Sub_Total x (1 + VAT/100) plus the non-taxable rows. What the client sent for these fields
+// is overwritten.
+func (s *Server) recomputeOrder(record Record) {
+	rows, _ := subformRows(record[orderedItems])
+	vat := fieldNumber(record["VAT"])
+
+	var taxable, untaxed float64
+	for _, row := range rows {
+		discountP := round(fieldNumber(row["DiscountP"]), 2)
+		total := fieldNumber(row["List_Price"]) * fieldNumber(row["Quantity"]) * (1 - discountP/100)
+		row["DiscountP"] = discountP
+		row["Total"] = round(total, 2)
+
+		product, _ := row["Product_Name"].(map[string]any)
+		if s.nonTaxable[fieldString(product["id"])] {
+			untaxed += total
+		} else {
+			taxable += total
+		}
+	}
+	record["Sub_Total"] = round(taxable, 2)
+	record["Grand_Total"] = round(taxable*(1+vat/100)+untaxed, 2)
+}
+
+// recordDetails is the details object of a successful write.
+func (s *Server) recordDetails(module, id string) map[string]any {
+	record := s.records[module][id]
+	return map[string]any{
+		"id":              id,
+		"Created_Time":    record["Created_Time"],
+		"Modified_Time":   record["Modified_Time"],
+		"Created_By":      record["Created_By"],
+		"Modified_By":     record["Modified_By"],
+		"$approval_state": "approved",
+	}
+}
+
+// match is a record matching a duplicate check field.
+type match struct {
+	id, field string
+}
+
+// duplicates finds the records sharing a value of the duplicate check fields with record,
+// one per distinct record.
+func (s *Server) duplicates(module string, record Record, fields []string) []match {
+	var found []match
+	for _, field := range fields {
+		value := fieldString(record[field])
+		if value == "" {
+			continue
+		}
+		for _, id := range s.order[module] {
+			if !strings.EqualFold(fieldString(s.records[module][id][field]), value) {
+				continue
+			}
+			if !slices.ContainsFunc(found, func(m match) bool { return m.id == id }) {
+				found = append(found, match{id: id, field: field})
+			}
+			break
+		}
+	}
+	return found
+}
+
+// tick advances the clock by a second and returns it as Zoho formats times, so every write
+// carries a later Modified_Time than the one before.
+func (s *Server) tick() string {
+	s.clock = s.clock.Add(time.Second)
+	return s.clock.Format(time.RFC3339)
+}
+
+// payload is the body of a write.
+type payload struct {
+	Data                 []Record `json:"data"`
+	DuplicateCheckFields []string `json:"duplicate_check_fields"`
+}
+
+func readData(r *http.Request) (payload, error) {
+	var body payload
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return body, err
+	}
+	if err = json.Unmarshal(raw, &body); err != nil {
+		return body, fmt.Errorf("body is not a valid JSON: %w", err)
+	}
+	if len(body.Data) == 0 {
+		return body, fmt.Errorf("data is empty")
+	}
+	return body, nil
+}
+
+func success(message string, details map[string]any) map[string]any {
+	return map[string]any{"code": "SUCCESS", "status": "success", "message": message, "details": details}
+}
+
+func apiError(code, message string, details map[string]any) map[string]any {
+	if details == nil {
+		details = map[string]any{}
+	}
+	return map[string]any{"code": code, "status": "error", "message": message, "details": details}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// subformRows returns value as subform rows when it is a list of objects.
+func subformRows(value any) ([]map[string]any, bool) {
+	list, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	rows := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		row, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
+
+// fieldString is a field's value as text; a lookup compares by its id.
+func fieldString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		return fieldString(v["id"])
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func fieldNumber(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+func round(value float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(value*p) / p
+}
+
+func intParam(value string, fallback int) int {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// clone deep-copies a record through JSON, so callers never share state with the server.
+func clone(record Record) Record {
+	raw, _ := json.Marshal(record)
+	var c Record
+	_ = json.Unmarshal(raw, &c)
+	return c
+}